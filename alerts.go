@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// KindAlertMarker is the NodeKind of AlertMarker nodes.
+var KindAlertMarker = ast.NewNodeKind("AlertMarker")
+
+// AlertMarker is the marker of a GitHub alert, such as [!WARNING], on the first line of a blockquote.
+// It's kept as is and never passed to the TextTransformer, while the rest of the blockquote is
+// translated as usual.
+type AlertMarker struct {
+	ast.BaseInline
+	// Segment is the marker in the source, from "[" to "]"
+	Segment text.Segment
+	// AlertType is the type of the alert in upper case, such as "WARNING"
+	AlertType string
+}
+
+// Kind implements ast.Node.Kind
+func (n *AlertMarker) Kind() ast.NodeKind {
+	return KindAlertMarker
+}
+
+// Dump implements ast.Node.Dump
+func (n *AlertMarker) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"AlertType": n.AlertType}, nil)
+}
+
+// alertMarkerPattern matches the first line of a GitHub alert, capturing its marker and type.
+var alertMarkerPattern = regexp.MustCompile(`^[ \t]*(\[!((?i)note|tip|important|warning|caution)\])[ \t]*\r?\n?$`)
+
+type alertTransformer struct{}
+
+// NewAlertTransformer returns a parser.ASTTransformer that replaces the text of GitHub alert markers
+// at the start of blockquotes with AlertMarker nodes.
+func NewAlertTransformer() parser.ASTTransformer {
+	return &alertTransformer{}
+}
+
+// Transform implements parser.ASTTransformer.Transform
+func (t *alertTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	for _, quote := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindBlockquote }) {
+		paragraph, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || paragraph.Lines().Len() == 0 {
+			continue
+		}
+		line := paragraph.Lines().At(0)
+		match := alertMarkerPattern.FindSubmatchIndex(line.Value(source))
+		if match == nil {
+			continue
+		}
+		// The marker is text unless a link reference definition made it a link
+		var marker []ast.Node
+		for c := paragraph.FirstChild(); c != nil; c = c.NextSibling() {
+			text, ok := c.(*ast.Text)
+			if !ok || text.Segment.Stop > line.Stop {
+				break
+			}
+			marker = append(marker, c)
+		}
+		if len(marker) == 0 || marker[len(marker)-1].(*ast.Text).Segment.Stop < line.Start+match[3] {
+			continue
+		}
+		for _, c := range marker {
+			paragraph.RemoveChild(paragraph, c)
+		}
+		segment := text.NewSegment(line.Start+match[2], line.Start+match[3])
+		alertType := string(source[line.Start+match[4] : line.Start+match[5]])
+		alert := &AlertMarker{Segment: segment, AlertType: strings.ToUpper(alertType)}
+		if first := paragraph.FirstChild(); first != nil {
+			paragraph.InsertBefore(paragraph, first, alert)
+		} else {
+			paragraph.AppendChild(paragraph, alert)
+		}
+	}
+}
+
+func (r *Renderer) renderAlertMarker(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := node.(*AlertMarker)
+		r.rc.writer.WriteBytes(n.Segment.Value(r.rc.source))
+		r.rc.lastRune = ']'
+		// The marker is on a line of its own
+		if n.NextSibling() != nil {
+			r.rc.writer.EndLine()
+		}
+	}
+	return ast.WalkContinue
+}
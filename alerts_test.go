@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestAlerts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+		calls    []string
+	}{
+		{
+			"Note",
+			"> [!NOTE]\n> Useful __information__.\n",
+			"> [!NOTE]\n> Nützliche **Informationen**.\n",
+			[]string{"Useful", "information", "."},
+		},
+		{
+			"Tip",
+			"> [!TIP]\n>\n> Helpful advice.\n",
+			"> [!TIP]\n>\n> Hilfreicher Rat.\n",
+			[]string{"Helpful advice."},
+		},
+		{
+			"Important",
+			"> [!important]\n> Key information.\n",
+			"> [!important]\n> Wichtige Information.\n",
+			[]string{"Key information."},
+		},
+		{
+			"Warning",
+			"* > [!WARNING]  \n  > Urgent info.\n",
+			"* > [!WARNING]\n  > Dringende Info.\n",
+			[]string{"Urgent info."},
+		},
+		{
+			"Caution",
+			"> [!CAUTION]\n",
+			"> [!CAUTION]\n",
+			nil,
+		},
+		{
+			"Not an alert",
+			"> [!NOTE] Useful information.\n",
+			"> \\[!NOTE\\] Nützliche Information.\n",
+			[]string{"[!NOTE] Useful information."},
+		},
+	}
+	translations := MapTransformer{
+		"Useful":                      "Nützliche",
+		"information":                 "Informationen",
+		"Helpful advice.":             "Hilfreicher Rat.",
+		"Key information.":            "Wichtige Information.",
+		"Urgent info.":                "Dringende Info.",
+		"[!NOTE] Useful information.": "[!NOTE] Nützliche Information.",
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithTextTransformer(translations))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+
+			recorder := &recordingTransformer{}
+			r = NewRenderer(WithTextTransformer(recorder))
+			md = goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			require.NoError(t, md.Convert([]byte(tc.source), &bytes.Buffer{}))
+			assert.Equal(t, tc.calls, recorder.calls)
+		})
+	}
+}
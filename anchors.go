@@ -0,0 +1,158 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// HeadingAnchor is the anchor of a heading, which links within the document can point to.
+type HeadingAnchor struct {
+	// Anchor is the id of the heading, without "#"
+	Anchor string
+	// Text is the plain text of the heading
+	Text string
+	// Position is where the heading is in the source
+	Position SourcePosition
+}
+
+// AnchorLink is a link to an anchor within the document, such as "[usage](#usage)".
+type AnchorLink struct {
+	// Anchor is the anchor the link points to, without "#"
+	Anchor string
+	// Broken is true if no heading has the anchor
+	Broken bool
+	// Position is where the link is in the source
+	Position SourcePosition
+	link     *ast.Link
+}
+
+// AnchorIndex holds the heading anchors of a document and the links pointing to them, returned by
+// IndexAnchors.
+type AnchorIndex struct {
+	// Anchors holds the anchors of the headings of the document in order
+	Anchors []HeadingAnchor
+	// Links holds the links to anchors within the document in order
+	Links []AnchorLink
+}
+
+// IndexAnchors returns the heading anchors and the links to them of the document doc parsed from
+// source. A heading's anchor is its "id" attribute, if set by the parser, or else a slug of its text
+// generated the way GitHub does: lowercased, with punctuation removed, spaces replaced by hyphens,
+// and a number appended to repeated slugs.
+func IndexAnchors(source []byte, doc ast.Node) *AnchorIndex {
+	index := &AnchorIndex{}
+	anchors := map[string]bool{}
+	slugs := map[string]int{}
+	for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindHeading }) {
+		text := plainText(source, n)
+		anchor := ""
+		if id, ok := n.AttributeString("id"); ok {
+			if id, ok := id.([]byte); ok {
+				anchor = string(id)
+			}
+		}
+		if anchor == "" {
+			anchor = uniqueSlug(slugs, slugify(text))
+		}
+		anchors[anchor] = true
+		index.Anchors = append(index.Anchors, HeadingAnchor{
+			Anchor:   anchor,
+			Text:     text,
+			Position: sourcePosition(source, n),
+		})
+	}
+	for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindLink }) {
+		link := n.(*ast.Link)
+		anchor, ok := strings.CutPrefix(string(link.Destination), "#")
+		if !ok {
+			continue
+		}
+		index.Links = append(index.Links, AnchorLink{
+			Anchor:   anchor,
+			Broken:   !anchors[anchor],
+			Position: sourcePosition(source, n),
+			link:     link,
+		})
+	}
+	return index
+}
+
+// slugify returns the GitHub style anchor of a heading with the given text.
+func slugify(text string) string {
+	slug := strings.Builder{}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			slug.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r):
+			slug.WriteRune(r)
+		}
+	}
+	return slug.String()
+}
+
+// uniqueSlug returns slug, with the lowest number appended that makes it unused if it's in slugs
+// already, and marks the result used in slugs, like github-slugger does. slugs counts the numbers
+// tried for each slug.
+func uniqueSlug(slugs map[string]int, slug string) string {
+	result := slug
+	for {
+		if _, ok := slugs[result]; !ok {
+			break
+		}
+		slugs[slug]++
+		result = fmt.Sprintf("%s-%d", slug, slugs[slug])
+	}
+	slugs[result] = 0
+	return result
+}
+
+// BrokenLinks returns the links to anchors that no heading has.
+func (i *AnchorIndex) BrokenLinks() []AnchorLink {
+	var broken []AnchorLink
+	for _, link := range i.Links {
+		if link.Broken {
+			broken = append(broken, link)
+		}
+	}
+	return broken
+}
+
+// RewriteLinks changes the destination of the links to each anchor in renames to the anchor it maps
+// to, e.g. after translating the headings changed their anchors, and returns the number of links
+// changed. The links are changed in the AST, so it must be rendered afterwards.
+func (i *AnchorIndex) RewriteLinks(renames map[string]string) int {
+	anchors := map[string]bool{}
+	for _, heading := range i.Anchors {
+		anchors[heading.Anchor] = true
+	}
+	changed := 0
+	for j := range i.Links {
+		link := &i.Links[j]
+		anchor, ok := renames[link.Anchor]
+		if !ok || anchor == link.Anchor {
+			continue
+		}
+		link.link.Destination = []byte("#" + anchor)
+		link.Anchor = anchor
+		link.Broken = !anchors[anchor]
+		changed++
+	}
+	return changed
+}
+
+// AnchorRenames returns the anchors of the headings of before that differ in after, mapped to their
+// anchors in after. Headings are paired by order, so the documents must have the same headings, such
+// as a document and its translation.
+func AnchorRenames(before, after *AnchorIndex) map[string]string {
+	renames := map[string]string{}
+	for i := 0; i < min(len(before.Anchors), len(after.Anchors)); i++ {
+		if before.Anchors[i].Anchor != after.Anchors[i].Anchor {
+			renames[before.Anchors[i].Anchor] = after.Anchors[i].Anchor
+		}
+	}
+	return renames
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestIndexAnchors(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("# Getting *Started*\n\n## Usage\n\nSee [usage](#usage), [again](#usage-1), [missing](#nope),\n" +
+		"[external](https://example.com#usage) and [start](#getting-started).\n\n## Usage\n\n## C++ & Go_lang, 2024!\n")
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+	index := IndexAnchors(source, doc)
+
+	assert.Equal([]HeadingAnchor{
+		{Anchor: "getting-started", Text: "Getting Started", Position: SourcePosition{Line: 1, Column: 3}},
+		{Anchor: "usage", Text: "Usage", Position: SourcePosition{Line: 3, Column: 4}},
+		{Anchor: "usage-1", Text: "Usage", Position: SourcePosition{Line: 8, Column: 4}},
+		{Anchor: "c--go_lang-2024", Text: "C++ & Go_lang, 2024!", Position: SourcePosition{Line: 10, Column: 4}},
+	}, index.Anchors)
+	var anchors []string
+	for _, link := range index.Links {
+		anchors = append(anchors, link.Anchor)
+	}
+	assert.Equal([]string{"usage", "usage-1", "nope", "getting-started"}, anchors)
+	if broken := index.BrokenLinks(); assert.Len(broken, 1) {
+		assert.Equal("nope", broken[0].Anchor)
+		assert.Equal(SourcePosition{Line: 5, Column: 42}, broken[0].Position)
+	}
+}
+
+func TestIndexAnchorsHeadingIDs(t *testing.T) {
+	source := []byte("# Title {#custom}\n\n# Other\n")
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithHeadingAttribute(), parser.WithAutoHeadingID()))
+	doc := md.Parser().Parse(text.NewReader(source))
+	index := IndexAnchors(source, doc)
+	if assert.Len(t, index.Anchors, 2) {
+		assert.Equal(t, "custom", index.Anchors[0].Anchor)
+		assert.Equal(t, "other", index.Anchors[1].Anchor)
+	}
+}
+
+// TestRewriteAnchorLinks tests that links to headings are fixed up after translating the headings
+func TestRewriteAnchorLinks(t *testing.T) {
+	require := require.New(t)
+	source := []byte("# Install\n\nSee [usage](#usage).\n\n# Usage\n\nSee [install](#install).\n")
+	translations := MapTransformer{"Install": "Installer", "Usage": "Utilisation", "See": "Voir",
+		"usage": "utilisation", "install": "installation"}
+	r := NewRenderer(WithTextTransformer(translations))
+	md := goldmark.New(goldmark.WithRenderer(r))
+	doc := md.Parser().Parse(text.NewReader(source))
+	translated := bytes.Buffer{}
+	require.NoError(md.Renderer().Render(&translated, source, doc))
+
+	translatedDoc := md.Parser().Parse(text.NewReader(translated.Bytes()))
+	translatedIndex := IndexAnchors(translated.Bytes(), translatedDoc)
+	require.Len(translatedIndex.BrokenLinks(), 2)
+
+	renames := AnchorRenames(IndexAnchors(source, doc), translatedIndex)
+	require.Equal(map[string]string{"install": "installer", "usage": "utilisation"}, renames)
+	require.Equal(2, translatedIndex.RewriteLinks(renames))
+	require.Empty(translatedIndex.BrokenLinks())
+
+	rewritten := bytes.Buffer{}
+	require.NoError(NewRenderer().Render(&rewritten, translated.Bytes(), translatedDoc))
+	require.Equal("# Installer\n\nVoir [utilisation](#utilisation).\n\n# Utilisation\n\nVoir [installation](#installer).\n",
+		rewritten.String())
+}
@@ -0,0 +1,244 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// maxASTDifferences is the most differences reported by DiffAST.
+const maxASTDifferences = 10
+
+// ASTDifference is a structural difference between two documents found by DiffAST.
+type ASTDifference struct {
+	// Path locates the differing node from the root, such as "Document/List[1]/ListItem[0]", where
+	// each index counts the children of the parent, with runs of text counting as one child
+	Path string
+	// Description says how the nodes differ
+	Description string
+	// A and B are the positions of the nodes in their sources, or zero if unknown
+	A, B SourcePosition
+}
+
+// SourcePosition is a 1-based line and column in a source, counting columns in bytes.
+type SourcePosition struct {
+	Line   int
+	Column int
+}
+
+func (p SourcePosition) String() string {
+	if p.Line == 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+func (d ASTDifference) String() string {
+	return fmt.Sprintf("%s: %s (a %s, b %s)", d.Path, d.Description, d.A, d.B)
+}
+
+// DiffAST compares the documents a and b, parsed from aSource and bSource, and returns the first
+// structural differences between them in document order. Only properties that affect the meaning of
+// the document are compared, and text is compared after unescaping, so a document and the parse of
+// its rendered output have no differences if the renderer preserved it.
+func DiffAST(aSource []byte, a ast.Node, bSource []byte, b ast.Node) []ASTDifference {
+	d := astDiffer{aSource: aSource, bSource: bSource}
+	d.diff(a.Kind().String(), diffNode{node: a}, diffNode{node: b})
+	return d.differences
+}
+
+// diffNode is a node compared by DiffAST. Runs of adjacent text nodes are compared as one node, with
+// node being the first of them and text their combined value.
+type diffNode struct {
+	node ast.Node
+	text *string
+}
+
+// astDiffer holds the state of DiffAST.
+type astDiffer struct {
+	aSource, bSource []byte
+	differences      []ASTDifference
+}
+
+// diff compares a and b along with their children.
+func (d *astDiffer) diff(path string, a, b diffNode) {
+	if len(d.differences) >= maxASTDifferences {
+		return
+	}
+	if a.node.Kind() != b.node.Kind() {
+		d.report(path, fmt.Sprintf("kind %s != %s", a.node.Kind(), b.node.Kind()), a, b)
+		return
+	}
+	if a.text != nil {
+		if *a.text != *b.text {
+			d.report(path, fmt.Sprintf("text %q != %q", *a.text, *b.text), a, b)
+		}
+		return
+	}
+	if aProps, bProps := astProperties(d.aSource, a.node), astProperties(d.bSource, b.node); aProps != bProps {
+		d.report(path, fmt.Sprintf("%s != %s", aProps, bProps), a, b)
+		return
+	}
+	if a.node.Kind() == ast.KindCodeSpan {
+		// The content is compared as a property
+		return
+	}
+
+	aChildren, bChildren := diffChildren(d.aSource, a.node), diffChildren(d.bSource, b.node)
+	for i := 0; i < min(len(aChildren), len(bChildren)); i++ {
+		d.diff(fmt.Sprintf("%s/%s[%d]", path, aChildren[i].node.Kind(), i), aChildren[i], bChildren[i])
+	}
+	switch {
+	case len(aChildren) > len(bChildren):
+		extra := aChildren[len(bChildren)]
+		d.report(fmt.Sprintf("%s/%s[%d]", path, extra.node.Kind(), len(bChildren)), "only in a",
+			extra, diffNode{})
+	case len(bChildren) > len(aChildren):
+		extra := bChildren[len(aChildren)]
+		d.report(fmt.Sprintf("%s/%s[%d]", path, extra.node.Kind(), len(aChildren)), "only in b",
+			diffNode{}, extra)
+	}
+}
+
+// report records a difference between a and b, either of which may be missing.
+func (d *astDiffer) report(path, description string, a, b diffNode) {
+	if len(d.differences) >= maxASTDifferences {
+		return
+	}
+	d.differences = append(d.differences, ASTDifference{
+		Path:        path,
+		Description: description,
+		A:           sourcePosition(d.aSource, a.node),
+		B:           sourcePosition(d.bSource, b.node),
+	})
+}
+
+// diffChildren returns the children of n to compare, merging runs of text.
+func diffChildren(source []byte, n ast.Node) []diffNode {
+	var children []diffNode
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if !isTextNode(c) {
+			children = append(children, diffNode{node: c})
+			continue
+		}
+		first := c
+		text := strings.Builder{}
+		for ; c.NextSibling() != nil && isTextNode(c.NextSibling()); c = c.NextSibling() {
+			text.WriteString(diffText(source, c))
+		}
+		text.WriteString(diffText(source, c))
+		value := text.String()
+		children = append(children, diffNode{node: first, text: &value})
+	}
+	return children
+}
+
+// isTextNode returns true for nodes whose text is merged with that of adjacent text nodes.
+func isTextNode(n ast.Node) bool {
+	return n.Kind() == ast.KindText || n.Kind() == ast.KindString
+}
+
+// diffText returns the unescaped value of a Text or String node, followed by a marker for any line
+// break.
+func diffText(source []byte, n ast.Node) string {
+	value := unescapedText(source, n)
+	if t, ok := n.(*ast.Text); ok {
+		if t.HardLineBreak() {
+			value += "<br>\n"
+		} else if t.SoftLineBreak() {
+			value += "\n"
+		}
+	}
+	return value
+}
+
+// unescapedText returns the value of a Text or String node, with escapes and references resolved.
+func unescapedText(source []byte, n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Text:
+		value := n.Value(source)
+		if !n.IsRaw() {
+			value = util.UnescapePunctuations(value)
+			value = util.ResolveNumericReferences(value)
+			value = util.ResolveEntityNames(value)
+		}
+		return string(value)
+	case *ast.String:
+		return string(n.Value)
+	}
+	return ""
+}
+
+// astProperties returns the properties of n that affect the meaning of the document.
+func astProperties(source []byte, n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Heading:
+		return fmt.Sprintf("level=%d", n.Level)
+	case *ast.List:
+		if n.IsOrdered() {
+			return fmt.Sprintf("ordered start=%d tight=%t", n.Start, n.IsTight)
+		}
+		return fmt.Sprintf("tight=%t", n.IsTight)
+	case *ast.Emphasis:
+		return fmt.Sprintf("level=%d", n.Level)
+	case *ast.Link:
+		// Titles keep their escapes, which depend on how the title is quoted
+		return fmt.Sprintf("destination=%q title=%q", n.Destination, util.UnescapePunctuations(n.Title))
+	case *ast.Image:
+		return fmt.Sprintf("destination=%q title=%q", n.Destination, util.UnescapePunctuations(n.Title))
+	case *ast.AutoLink:
+		return fmt.Sprintf("url=%q", n.URL(source))
+	case *ast.CodeSpan:
+		content := bytes.Buffer{}
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if t, ok := c.(*ast.Text); ok {
+				content.Write(t.Segment.Value(source))
+			}
+		}
+		return fmt.Sprintf("code=%q", content.Bytes())
+	case *ast.FencedCodeBlock:
+		var info []byte
+		if n.Info != nil {
+			info = n.Info.Value(source)
+		}
+		return fmt.Sprintf("info=%q code=%q", info, n.Lines().Value(source))
+	case *ast.CodeBlock:
+		return fmt.Sprintf("code=%q", n.Lines().Value(source))
+	case *ast.HTMLBlock:
+		return fmt.Sprintf("type=%d html=%q", n.HTMLBlockType, nodeText(source, n))
+	case *ast.RawHTML:
+		return fmt.Sprintf("html=%q", n.Segments.Value(source))
+	case *east.Table:
+		return fmt.Sprintf("alignments=%v", n.Alignments)
+	case *east.TableCell:
+		return fmt.Sprintf("alignment=%v", n.Alignment)
+	case *east.TaskCheckBox:
+		return fmt.Sprintf("checked=%t", n.IsChecked)
+	}
+	return ""
+}
+
+// sourcePosition returns the position of n in source, or zero if n is nil or its position is unknown.
+func sourcePosition(source []byte, n ast.Node) SourcePosition {
+	if n == nil {
+		return SourcePosition{}
+	}
+	start, _, ok := sourceRange(n)
+	if !ok || start > len(source) {
+		return SourcePosition{}
+	}
+	return offsetPosition(source, start)
+}
+
+// offsetPosition returns the position of the byte at offset in source.
+func offsetPosition(source []byte, offset int) SourcePosition {
+	lineStart := bytes.LastIndexByte(source[:offset], lineDelim) + 1
+	return SourcePosition{
+		Line:   bytes.Count(source[:offset], []byte{lineDelim}) + 1,
+		Column: offset - lineStart + 1,
+	}
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestDiffAST(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		expected []string
+	}{
+		{
+			"Formatting differences ignored",
+			"Title\n=====\n\n* a \\* b\n* c",
+			"# Title\n\n- a \\* b\n- c\n",
+			nil,
+		},
+		{
+			"Changed text",
+			"# Title\n\nSome *text*.",
+			"# Title\n\nSome *test*.",
+			[]string{`Document/Paragraph[1]/Emphasis[1]/Text[0]: text "text" != "test" (a 3:7, b 3:7)`},
+		},
+		{
+			"Changed properties",
+			"## a\n\n[b](/url)",
+			"### a\n\n[b](/other)",
+			[]string{
+				`Document/Heading[0]: level=2 != level=3 (a 1:4, b 1:5)`,
+				`Document/Paragraph[1]/Link[0]: destination="/url" title="" != destination="/other" title="" (a 3:2, b 3:2)`,
+			},
+		},
+		{
+			"Changed kind",
+			"a\n\n---",
+			"a\n---",
+			[]string{`Document/Paragraph[0]: kind Paragraph != Heading (a 1:1, b 1:1)`, `Document/ThematicBreak[1]: only in a (a ?, b ?)`},
+		},
+		{
+			"Missing node",
+			"- a\n- b",
+			"- a",
+			[]string{`Document/List[0]/ListItem[1]: only in a (a 2:3, b ?)`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := goldmark.DefaultParser()
+			a, b := []byte(tc.a), []byte(tc.b)
+			differences := DiffAST(a, parser.Parse(text.NewReader(a)), b, parser.Parse(text.NewReader(b)))
+			var actual []string
+			for _, d := range differences {
+				actual = append(actual, d.String())
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// UnmarshalASTJSON reads an AST written as JSON by PrintASTJSON, such as after it was edited by
+// another program, so it can be rendered. Text nodes refer to their source, so a new source holding
+// the text of the nodes is returned along with the root node. Source ranges in the JSON are ignored,
+// and an error is returned for kinds of nodes that aren't supported and for unknown keys.
+func UnmarshalASTJSON(data []byte) (source []byte, root ast.Node, err error) {
+	var node ASTNode
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&node); err != nil {
+		return nil, nil, err
+	}
+	b := astBuilder{}
+	root, err = b.build(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.source.Bytes(), root, nil
+}
+
+// astBuilder builds an AST from its JSON form, collecting the text of the nodes in source.
+type astBuilder struct {
+	source bytes.Buffer
+}
+
+// segment appends value to the source and returns its segment.
+func (b *astBuilder) segment(value string) text.Segment {
+	start := b.source.Len()
+	b.source.WriteString(value)
+	return text.NewSegment(start, b.source.Len())
+}
+
+// lines appends value to the source and sets it as the lines of n, one segment per line.
+func (b *astBuilder) lines(n ast.Node, value string) {
+	lines := n.Lines()
+	for len(value) > 0 {
+		line, rest, found := strings.Cut(value, "\n")
+		if found {
+			line += "\n"
+		}
+		lines.Append(b.segment(line))
+		value = rest
+	}
+}
+
+// build returns the node for j, along with its children.
+func (b *astBuilder) build(j ASTNode) (ast.Node, error) {
+	props := jsonProperties(maps.Clone(j.Properties))
+	var n ast.Node
+	switch j.Kind {
+	case "Document":
+		n = ast.NewDocument()
+	case "Paragraph":
+		n = ast.NewParagraph()
+	case "TextBlock":
+		n = ast.NewTextBlock()
+	case "Heading":
+		n = ast.NewHeading(props.int("level"))
+	case "ThematicBreak":
+		n = ast.NewThematicBreak()
+	case "Blockquote":
+		n = ast.NewBlockquote()
+	case "CodeBlock":
+		n = ast.NewCodeBlock()
+		b.lines(n, j.Text)
+	case "FencedCodeBlock":
+		var info *ast.Text
+		if value, ok := props.take("info").(string); ok {
+			info = ast.NewTextSegment(b.segment(value))
+		}
+		n = ast.NewFencedCodeBlock(info)
+		b.lines(n, j.Text)
+	case "HTMLBlock":
+		n = ast.NewHTMLBlock(ast.HTMLBlockType(props.int("type")))
+		b.lines(n, j.Text)
+	case "List":
+		marker := props.string("marker")
+		if len(marker) != 1 {
+			return nil, fmt.Errorf("invalid list marker %q", marker)
+		}
+		list := ast.NewList(marker[0])
+		list.IsTight = props.bool("tight")
+		list.Start = props.int("start")
+		n = list
+	case "ListItem":
+		n = ast.NewListItem(props.int("offset"))
+	case "Text":
+		t := ast.NewTextSegment(b.segment(j.Text))
+		t.SetSoftLineBreak(props.bool("softLineBreak"))
+		t.SetHardLineBreak(props.bool("hardLineBreak"))
+		n = t
+	case "String":
+		n = ast.NewString([]byte(j.Text))
+	case "CodeSpan":
+		n = ast.NewCodeSpan()
+	case "Emphasis":
+		n = ast.NewEmphasis(props.int("level"))
+	case "Link", "Image":
+		link := ast.NewLink()
+		link.Destination = []byte(props.string("destination"))
+		link.Title = []byte(props.string("title"))
+		n = link
+		if j.Kind == "Image" {
+			n = ast.NewImage(link)
+		}
+	case "AutoLink":
+		autoLinkType := ast.AutoLinkURL
+		if props.bool("email") {
+			autoLinkType = ast.AutoLinkEmail
+		}
+		label, url := props.string("label"), props.string("url")
+		bracketed := props.bool("bracketed")
+		if bracketed {
+			b.source.WriteByte('<')
+		}
+		autoLink := ast.NewAutoLink(autoLinkType, ast.NewTextSegment(b.segment(label)))
+		if bracketed {
+			b.source.WriteByte('>')
+		}
+		// Links found by Linkify have a protocol added to their label
+		if protocol, ok := strings.CutSuffix(url, "://"+label); ok {
+			autoLink.Protocol = []byte(protocol)
+		}
+		n = autoLink
+	case "RawHTML":
+		raw := ast.NewRawHTML()
+		raw.Segments.Append(b.segment(j.Text))
+		n = raw
+	case "Table":
+		table := east.NewTable()
+		for _, alignment := range props.strings("alignments") {
+			table.Alignments = append(table.Alignments, parseAlignment(alignment))
+		}
+		n = table
+	case "TableHeader":
+		n = east.NewTableHeader(east.NewTableRow(nil))
+	case "TableRow":
+		n = east.NewTableRow(nil)
+	case "TableCell":
+		cell := east.NewTableCell()
+		cell.Alignment = parseAlignment(props.string("alignment"))
+		n = cell
+	case "Strikethrough":
+		n = east.NewStrikethrough()
+	case "TaskCheckBox":
+		n = east.NewTaskCheckBox(props.bool("checked"))
+	default:
+		return nil, fmt.Errorf("unsupported node kind %q", j.Kind)
+	}
+	if n.Type() == ast.TypeBlock {
+		n.SetBlankPreviousLines(props.bool("blankPreviousLines"))
+	}
+	for name := range props {
+		return nil, fmt.Errorf("unknown property %q of %s node", name, j.Kind)
+	}
+	for name, value := range j.Attributes {
+		// The parser sets attribute values as bytes
+		if s, ok := value.(string); ok {
+			value = []byte(s)
+		}
+		n.SetAttributeString(name, value)
+	}
+
+	for _, child := range j.Children {
+		c, err := b.build(child)
+		if err != nil {
+			return nil, err
+		}
+		n.AppendChild(n, c)
+	}
+	return n, nil
+}
+
+// jsonProperties holds the properties of a node in its JSON form, which are removed as they're read.
+type jsonProperties map[string]any
+
+// take removes and returns the named attribute, or nil if it isn't set.
+func (a jsonProperties) take(name string) any {
+	value := a[name]
+	delete(a, name)
+	return value
+}
+
+func (a jsonProperties) int(name string) int {
+	value, _ := a.take(name).(float64)
+	return int(value)
+}
+
+func (a jsonProperties) bool(name string) bool {
+	value, _ := a.take(name).(bool)
+	return value
+}
+
+func (a jsonProperties) string(name string) string {
+	value, _ := a.take(name).(string)
+	return value
+}
+
+func (a jsonProperties) strings(name string) []string {
+	values, _ := a.take(name).([]any)
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		s, _ := value.(string)
+		strs = append(strs, s)
+	}
+	return strs
+}
+
+// parseAlignment returns the table alignment named s, as written by Alignment.String.
+func parseAlignment(s string) east.Alignment {
+	for _, alignment := range []east.Alignment{east.AlignLeft, east.AlignRight, east.AlignCenter} {
+		if alignment.String() == s {
+			return alignment
+		}
+	}
+	return east.AlignNone
+}
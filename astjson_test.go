@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestUnmarshalASTJSON(t *testing.T) {
+	source := []byte("# Title\n\nSome *text* with `code`, a [link](/url \"title\") and <https://example.com>.\\\n" +
+		"A ~~struck~~ line with <b>html</b>.\n\n> - [x] done\n>\n> - todo\n\n1. one\n2. two\n\n---\n\n" +
+		"```go\nfunc main() {}\n```\n\n    indented\n\n<div>\nblock\n</div>\n\n| a | b |\n| :-- | --: |\n| 1 | 2 |\n\n" +
+		"![image](i.png) www.example.com\n")
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.GFM, r))
+	doc := md.Parser().Parse(text.NewReader(source))
+	expected := bytes.Buffer{}
+	require.NoError(t, md.Renderer().Render(&expected, source, doc))
+
+	data := bytes.Buffer{}
+	require.NoError(t, PrintASTJSON(&data, source, doc))
+	newSource, newDoc, err := UnmarshalASTJSON(data.Bytes())
+	require.NoError(t, err)
+
+	actual := bytes.Buffer{}
+	require.NoError(t, md.Renderer().Render(&actual, newSource, newDoc))
+	assert.Equal(t, expected.String(), actual.String())
+	assert.Empty(t, DiffAST(source, doc, newSource, newDoc))
+}
+
+// TestUnmarshalASTJSONAttributes tests that HTML attributes are read back apart from the properties
+// of nodes, even where their names are the same, and keep their types
+func TestUnmarshalASTJSONAttributes(t *testing.T) {
+	source := []byte("# Title {#title level=2}\n")
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithHeadingAttribute()))
+	doc := md.Parser().Parse(text.NewReader(source))
+	data := bytes.Buffer{}
+	require.NoError(t, PrintASTJSON(&data, source, doc))
+	newSource, newDoc, err := UnmarshalASTJSON(data.Bytes())
+	require.NoError(t, err)
+	assert.Empty(t, DiffAST(source, doc, newSource, newDoc))
+
+	heading := newDoc.FirstChild().(*ast.Heading)
+	assert.Equal(t, 1, heading.Level)
+	level, _ := heading.AttributeString("level")
+	assert.Equal(t, float64(2), level)
+	id, _ := heading.AttributeString("id")
+	assert.Equal(t, []byte("title"), id)
+}
+
+func TestUnmarshalASTJSONErrors(t *testing.T) {
+	_, _, err := UnmarshalASTJSON([]byte(`{"kind": "Document", "children": [{"kind": "Unknown"}]}`))
+	assert.EqualError(t, err, `unsupported node kind "Unknown"`)
+	_, _, err = UnmarshalASTJSON([]byte(`{"kind": "List"}`))
+	assert.EqualError(t, err, `invalid list marker ""`)
+	_, _, err = UnmarshalASTJSON([]byte(`{"kind": "Heading", "properties": {"level": 1, "id": "a"}}`))
+	assert.EqualError(t, err, `unknown property "id" of Heading node`)
+	_, _, err = UnmarshalASTJSON([]byte(`{"kind": "Document", "children": [{"kind": "Paragraph", "level": 1}]}`))
+	assert.EqualError(t, err, `json: unknown field "level"`)
+	_, _, err = UnmarshalASTJSON([]byte(`[]`))
+	assert.Error(t, err)
+}
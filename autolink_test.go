@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+func TestAutoLinks(t *testing.T) {
+	testCases := []struct {
+		name         string
+		source       string
+		translations map[string]string
+		expected     string
+	}{
+		{
+			"Email autolink",
+			"<user@example.com>",
+			map[string]string{},
+			"<user@example.com>\n",
+		},
+		{
+			"Email autolink is not translated",
+			"Mail <user@example.com>",
+			map[string]string{"Mail": "邮件", "user@example.com": "用户@例子.com"},
+			"邮件 <user@example.com>\n",
+		},
+		{
+			"Mailto autolink keeps its scheme",
+			"<mailto:user@example.com>",
+			map[string]string{},
+			"<mailto:user@example.com>\n",
+		},
+		{
+			"Linkified email",
+			"Mail user@example.com now",
+			map[string]string{},
+			"Mail user@example.com now\n",
+		},
+		{
+			"Linkified www link",
+			"See www.example.com",
+			map[string]string{},
+			"See www.example.com\n",
+		},
+		{
+			"Linkified url",
+			"See https://example.com",
+			map[string]string{},
+			"See https://example.com\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(
+				goldmark.WithRenderer(NewRenderer(WithTextTransformer(MapTransformer(tc.translations)))),
+				goldmark.WithExtensions(extension.Linkify),
+			)
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestSourceOffset(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("foo bar")
+	offset, ok := sourceOffset(source, source[4:])
+	assert.True(ok)
+	assert.Equal(4, offset)
+	_, ok = sourceOffset(source, []byte("bar"))
+	assert.False(ok)
+	_, ok = sourceOffset(source, nil)
+	assert.False(ok)
+}
@@ -0,0 +1,73 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestBlankLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Headings",
+			"# Title\nText\n## Section\n---\n",
+			"# Title\n\nText\n\n## Section\n\n---\n",
+		},
+		{
+			"Lists",
+			"Text\n- a\n- b\n\n  c\n- d\n> quote\n",
+			"Text\n\n- a\n- b\n\n  c\n- d\n\n> quote\n",
+		},
+		{
+			"Tight nested lists",
+			"- a\n  - b\n  ```\n  code\n  ```\n- c\n",
+			"- a\n  - b\n  ```\n  code\n  ```\n- c\n",
+		},
+		{
+			"Code blocks",
+			"Text\n```\ncode\n```\nText\n\n    indented\n> ```\n> quoted\n> ```\n> text\n",
+			"Text\n\n```\ncode\n```\n\nText\n\n    indented\n\n> ```\n> quoted\n> ```\n>\n> text\n",
+		},
+		{
+			"Blank lines kept",
+			"Text\n\n\n\n# Title\n",
+			"Text\n\n# Title\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithBlankLines(BlankLinesAround))))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+// TestBlankLinesAfterEdit tests that blocks added to the AST are separated like those parsed
+func TestBlankLinesAfterEdit(t *testing.T) {
+	source := []byte("Text\n\nMore text\n\nAdded\n")
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+	// The last paragraph is replaced by a heading after the first
+	paragraph := doc.LastChild()
+	doc.RemoveChild(doc, paragraph)
+	heading := ast.NewHeading(2)
+	heading.AppendChild(heading, paragraph.FirstChild())
+	doc.InsertAfter(doc, doc.FirstChild(), heading)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, NewRenderer().Render(&buf, source, doc))
+	assert.Equal(t, "Text\n## Added\n\nMore text\n", buf.String())
+	buf.Reset()
+	require.NoError(t, NewRenderer(WithBlankLines(BlankLinesAround)).Render(&buf, source, doc))
+	assert.Equal(t, "Text\n\n## Added\n\nMore text\n", buf.String())
+}
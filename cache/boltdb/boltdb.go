@@ -0,0 +1,74 @@
+// Package boltdb is a cache.Store in an embedded bbolt database, kept in a module of its own so that
+// the renderer doesn't depend on bbolt:
+//
+//	db, err := boltdb.Open(".translations.db", time.Minute)
+//	...
+//	defer db.Close()
+//	translator := &remote.Translator{URL: endpoint, Target: "fr", Cache: db}
+package boltdb
+
+import (
+	"time"
+
+	"github.com/teekennedy/goldmark-markdown/cache"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DB is a cache.Store in a bbolt database file, holding the translations in a bucket by the
+// language/type/hash of their cache.Key, like the paths of cache.Dir. Each Put is committed on its
+// own, so translations survive runs that fail partway.
+//
+// The file is locked while it's open, so processes sharing it, such as concurrent runs of a
+// pipeline over the same documents, take turns: Open waits for the process holding it to close it.
+type DB struct {
+	db *bolt.DB
+}
+
+var _ cache.Store = &DB{}
+
+// bucket is the name of the bucket of the translations.
+var bucket = []byte("translations")
+
+// Open opens the database at path, creating it if it doesn't exist. If another process has it
+// open, Open waits for it to be closed, up to timeout, or forever if timeout is zero.
+func Open(path string, timeout time.Duration) (*DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database, releasing it to other processes.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Get implements cache.Store.Get.
+func (d *DB) Get(key cache.Key) (string, bool, error) {
+	var translation string
+	var ok bool
+	err := d.db.View(func(tx *bolt.Tx) error {
+		translations := tx.Bucket(bucket)
+		if translations == nil {
+			return nil
+		}
+		// The value is only valid during the transaction, so it's copied
+		if value := translations.Get([]byte(key.String())); value != nil {
+			translation, ok = string(value), true
+		}
+		return nil
+	})
+	return translation, ok, err
+}
+
+// Put implements cache.Store.Put.
+func (d *DB) Put(key cache.Key, translation string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		translations, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+		return translations.Put([]byte(key.String()), []byte(translation))
+	})
+}
@@ -0,0 +1,47 @@
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/teekennedy/goldmark-markdown/cache"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "translations.db")
+	db, err := Open(path, time.Second)
+	require.NoError(t, err)
+	key := cache.NewKey("fr", markdown.TextTypePlain, "Hello")
+	_, ok, err := db.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, db.Put(key, "Bonjour"))
+	require.NoError(t, db.Put(cache.NewKey("", markdown.TextTypeHTML, "Hello"), "<b>Bonjour</b>"))
+	_, ok, err = db.Get(cache.NewKey("fr", markdown.TextTypeHTML, "Hello"))
+	require.NoError(t, err)
+	assert.False(t, ok, "keys of other text types must not match")
+
+	// The file is locked while it's open
+	_, err = Open(path, 50*time.Millisecond)
+	assert.ErrorIs(t, err, bolt.ErrTimeout)
+
+	// Translations persist across runs
+	require.NoError(t, db.Close())
+	db, err = Open(path, time.Second)
+	require.NoError(t, err)
+	defer db.Close()
+	translation, ok, err := db.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Bonjour", translation)
+	translation, ok, err = db.Get(cache.NewKey("", markdown.TextTypeHTML, "Hello"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "<b>Bonjour</b>", translation)
+}
@@ -0,0 +1,152 @@
+// Package cache keeps translations across runs, so translating a document again only pays for the
+// text that changed since.
+//
+// A Store holds translations by Key, the target language, type and hash of the translated text. Dir
+// is a Store of files in a directory, which can be committed along with the documents, and the
+// boltdb module has a Store in an embedded database, which processes can share. The Translators of
+// the llm and remote packages take a Store, and Transformer puts a Store in front of any other
+// markdown.TextTransformer:
+//
+//	transformer := &cache.Transformer{Store: cache.Dir(".translations"), Language: "fr", Transformer: glossary}
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+)
+
+// Key is the key of a translation in a Store.
+type Key struct {
+	// Language is the language of the translation
+	Language string
+	// Type is the type of the translated text
+	Type markdown.TextType
+	// Hash is the hex-encoded SHA-256 hash of the translated text
+	Hash string
+}
+
+// NewKey returns the Key of the translation of text of textType to language.
+func NewKey(language string, textType markdown.TextType, text string) Key {
+	hash := sha256.Sum256([]byte(text))
+	return Key{Language: language, Type: textType, Hash: hex.EncodeToString(hash[:])}
+}
+
+// String returns key as language/type/hash, where type is "plain" or "html".
+func (k Key) String() string {
+	return k.Language + "/" + typeName(k.Type) + "/" + k.Hash
+}
+
+// Store stores translations. Its methods must be safe for concurrent use.
+type Store interface {
+	// Get returns the translation of key, and false if there's none.
+	Get(key Key) (string, bool, error)
+	// Put stores the translation of key.
+	Put(key Key, translation string) error
+}
+
+// Dir is a Store of a file per translation in a directory, at language/type/hash, where type is
+// "plain" or "html".
+type Dir string
+
+var _ Store = Dir("")
+
+// typeName returns the name textType is stored by, "plain" or "html".
+func typeName(textType markdown.TextType) string {
+	if textType == markdown.TextTypeHTML {
+		return "html"
+	}
+	return "plain"
+}
+
+// path returns the path of the file of key.
+func (d Dir) path(key Key) string {
+	return filepath.Join(string(d), filepath.Clean("/" + key.Language)[1:], typeName(key.Type), key.Hash)
+}
+
+// Get implements Store.Get.
+func (d Dir) Get(key Key) (string, bool, error) {
+	translation, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return string(translation), true, nil
+}
+
+// Put implements Store.Put. The file is written to a temporary file first, then renamed, so
+// concurrent runs never read a partial translation.
+func (d Dir) Put(key Key, translation string) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(translation)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Transformer is a markdown.TextTransformer that replaces text with its translation in Store, or
+// else with the translation of Transformer, which is then put in Store. The first error of Store is
+// kept in Err, after which text is passed to Transformer alone.
+type Transformer struct {
+	Store Store
+	// Language is the language of the translations of Transformer
+	Language    string
+	Transformer markdown.TextTransformer
+
+	mu  sync.Mutex
+	err error
+}
+
+var _ markdown.TextTransformer = &Transformer{}
+
+// Transform implements markdown.TextTransformer.
+func (t *Transformer) Transform(textType markdown.TextType, text string) (string, bool) {
+	key := NewKey(t.Language, textType, text)
+	if t.Err() == nil {
+		translation, ok, err := t.Store.Get(key)
+		if ok {
+			return translation, true
+		}
+		t.setErr(err)
+	}
+	translation, ok := t.Transformer.Transform(textType, text)
+	if ok && t.Err() == nil {
+		t.setErr(t.Store.Put(key, translation))
+	}
+	return translation, ok
+}
+
+// Err returns the first error of Store, if any.
+func (t *Transformer) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *Transformer) setErr(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = err
+	}
+}
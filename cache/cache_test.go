@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	markdown "github.com/teekennedy/goldmark-markdown"
+)
+
+func TestDir(t *testing.T) {
+	dir := Dir(t.TempDir())
+	key := NewKey("fr", markdown.TextTypePlain, "Hello")
+	_, ok, err := dir.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, dir.Put(key, "Bonjour"))
+	translation, ok, err := dir.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Bonjour", translation)
+	assert.FileExists(t, filepath.Join(string(dir), "fr", "plain", key.Hash))
+	assert.Equal(t, "fr/plain/"+key.Hash, key.String())
+
+	_, ok, err = dir.Get(NewKey("fr", markdown.TextTypeHTML, "Hello"))
+	require.NoError(t, err)
+	assert.False(t, ok, "keys of other text types must not match")
+
+	// Languages can't escape the directory
+	require.NoError(t, dir.Put(NewKey("../fr", markdown.TextTypePlain, "Hello"), "Bonjour"))
+	entries, err := os.ReadDir(filepath.Dir(string(dir)))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestTransformer(t *testing.T) {
+	calls := 0
+	counter := transformerFunc(func(textType markdown.TextType, text string) (string, bool) {
+		calls++
+		return strings.ToUpper(text), text != "Skip"
+	})
+	dir := Dir(t.TempDir())
+	source := []byte("# Hello\n\nHello world.\n\nSkip\n")
+
+	for i := 0; i < 2; i++ {
+		transformer := &Transformer{Store: dir, Language: "fr", Transformer: counter}
+		formatted, err := markdown.Format(source, markdown.WithTextTransformer(transformer))
+		require.NoError(t, err)
+		require.NoError(t, transformer.Err())
+		assert.Equal(t, "# HELLO\n\nHELLO WORLD.\n\nSkip\n", string(formatted))
+	}
+	assert.Equal(t, 4, calls, "translations of the second run must come from the store")
+}
+
+type transformerFunc func(textType markdown.TextType, text string) (string, bool)
+
+func (f transformerFunc) Transform(textType markdown.TextType, text string) (string, bool) {
+	return f(textType, text)
+}
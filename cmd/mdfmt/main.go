@@ -0,0 +1,306 @@
+// Command mdfmt formats markdown files.
+//
+// Usage:
+//
+//	mdfmt [flags] [path ...]
+//
+// Without paths, mdfmt formats standard input to standard output. Given paths, it formats each file
+// to standard output, or rewrites the files in place with -w. Directories are searched recursively
+// for markdown files, leaving out those ignored by .gitignore and .mdignore files or matching
+// -exclude patterns. With -check, it lists the files whose formatting changes instead and exits
+// with status 1 if there are any, so it can be used to check formatting in CI. With -d, it prints
+// the changes as unified diffs. With -print-config, it prints the effective configuration of the
+// renderer as JSON. With -serve, it keeps running to format the buffers of editors instead, using
+// the protocol of package server. The other flags set the options of the renderer; run mdfmt -h to
+// list them.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/teekennedy/goldmark-markdown/internal/diff"
+	"github.com/teekennedy/goldmark-markdown/internal/walk"
+	"github.com/teekennedy/goldmark-markdown/server"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs mdfmt with args and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mdfmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mdfmt [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	write := flags.Bool("w", false, "write the result to the file instead of standard output")
+	heading := newChoiceFlag(flags, "heading", "atx", "heading `style`", server.HeadingStyles)
+	bullet := newChoiceFlag(flags, "bullet", "preserve", "bullet list `marker`", server.BulletMarkers)
+	thematicBreak := newChoiceFlag(flags, "break", "-", "thematic break `character`", server.ThematicBreakStyles)
+	indent := newChoiceFlag(flags, "indent", "spaces", "code block `indentation`", server.IndentStyles)
+	numbering := newChoiceFlag(flags, "numbering", "start", "ordered list `numbering`", server.ListNumberings)
+	check := flags.Bool("check", false, "list the files whose formatting changes and exit with status 1 if any")
+	showDiff := flags.Bool("d", false, "print the diffs of the formatting instead of the result")
+	width := flags.Int("width", 0, "wrap paragraphs at `columns`, or keep their line breaks if 0")
+	preserve := flags.Bool("preserve", false, "preserve the syntax of the source where it's valid")
+	lines := &lineRangeFlag{}
+	flags.Var(lines, "lines", "only format the blocks intersecting the line `range` start-end of each file")
+	printConfig := flags.Bool("print-config", false, "print the effective renderer configuration as JSON and exit")
+	serve := flags.String("serve", "", "serve format requests on standard input and output if `address` is \"stdio\", or over HTTP on address")
+	walkOptions := walk.Options{}
+	walkOptions.RegisterFlags(flags)
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+
+	options := []markdown.Option{
+		markdown.WithHeadingStyle(heading.Value()),
+		markdown.WithBulletMarker(bullet.Value()),
+		markdown.WithThematicBreakStyle(thematicBreak.Value()),
+		markdown.WithIndentStyle(indent.Value()),
+		markdown.WithListNumbering(numbering.Value()),
+		markdown.WithLineWidth(markdown.LineWidth(*width)),
+	}
+	if *preserve {
+		options = append(options, markdown.WithStyleMode(markdown.StyleModePreserve))
+	}
+
+	if *printConfig {
+		config, err := json.MarshalIndent(markdown.NewConfig(options...), "", "  ")
+		if err != nil {
+			fmt.Fprintln(stderr, "mdfmt:", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(config))
+		return 0
+	}
+
+	if *serve != "" {
+		srv := &server.Server{Defaults: server.Options{
+			Heading:   heading.name,
+			Bullet:    bullet.name,
+			Break:     thematicBreak.name,
+			Indent:    indent.name,
+			Numbering: numbering.name,
+			Width:     *width,
+			Preserve:  preserve,
+		}}
+		var err error
+		if *serve == "stdio" {
+			err = srv.ServeStdio(stdin, stdout)
+		} else {
+			httpServer := &http.Server{
+				Addr:              *serve,
+				Handler:           srv,
+				ReadHeaderTimeout: 10 * time.Second,
+				ReadTimeout:       time.Minute,
+				WriteTimeout:      time.Minute,
+				IdleTimeout:       2 * time.Minute,
+			}
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mdfmt:", err)
+			return 1
+		}
+		return 0
+	}
+
+	f := &formatter{write: *write, list: *check, diff: *showDiff, stdout: stdout, options: options, lines: lines.value}
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "mdfmt: cannot use -w with standard input")
+			return 2
+		}
+		source, err := io.ReadAll(stdin)
+		if err == nil {
+			err = f.format(source)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mdfmt:", err)
+			return 1
+		}
+		return f.status()
+	}
+	status := 0
+	var paths []string
+	for _, root := range flags.Args() {
+		files, err := walk.Files([]string{root}, walkOptions)
+		if err != nil {
+			fmt.Fprintln(stderr, "mdfmt:", err)
+			status = 1
+		}
+		paths = append(paths, files...)
+	}
+	type result struct{ source, formatted []byte }
+	walk.Process(paths, walkOptions.Workers, func(path string) (result, error) {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return result{}, err
+		}
+		formatted, err := f.render(source)
+		if err != nil {
+			return result{}, fmt.Errorf("%s: %w", path, err)
+		}
+		return result{source, formatted}, nil
+	}, func(path string, r result, err error) {
+		if err == nil {
+			err = f.report(path, r.source, r.formatted)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mdfmt:", err)
+			status = 1
+		}
+	})
+	return max(status, f.status())
+}
+
+// choiceFlag is a flag whose value is one of a set of named values.
+type choiceFlag[T any] struct {
+	name   string
+	values map[string]T
+}
+
+// newChoiceFlag defines a flag on flags with the given name, default value name and usage, whose
+// value is one of values.
+func newChoiceFlag[T any](flags *flag.FlagSet, name, value, usage string, values map[string]T) *choiceFlag[T] {
+	f := &choiceFlag[T]{name: value, values: values}
+	flags.Var(f, name, usage+": "+server.Choices(f.values))
+	return f
+}
+
+func (f *choiceFlag[T]) String() string {
+	return f.name
+}
+
+func (f *choiceFlag[T]) Set(name string) error {
+	if _, ok := f.values[name]; !ok {
+		return fmt.Errorf("must be one of %s", server.Choices(f.values))
+	}
+	f.name = name
+	return nil
+}
+
+// Value returns the value of the flag.
+func (f *choiceFlag[T]) Value() T {
+	return f.values[f.name]
+}
+
+// lineRangeFlag is a flag holding a line range written as start-end, or a single line.
+type lineRangeFlag struct {
+	value *server.LineRange
+}
+
+func (f *lineRangeFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", f.value.Start, f.value.End)
+}
+
+func (f *lineRangeFlag) Set(value string) error {
+	start, end, found := strings.Cut(value, "-")
+	if !found {
+		end = start
+	}
+	r := &server.LineRange{}
+	var err error
+	if r.Start, err = strconv.Atoi(start); err != nil {
+		return fmt.Errorf("invalid start line %q", start)
+	}
+	if r.End, err = strconv.Atoi(end); err != nil {
+		return fmt.Errorf("invalid end line %q", end)
+	}
+	if r.Start < 1 || r.End < r.Start {
+		return fmt.Errorf("invalid line range %s", value)
+	}
+	f.value = r
+	return nil
+}
+
+// formatter formats markdown sources and reports the result according to the mode flags.
+type formatter struct {
+	write, list, diff bool
+	stdout            io.Writer
+	options           []markdown.Option
+	// lines limits formatting to the blocks intersecting a range of lines, if set
+	lines *server.LineRange
+	// changed is set if a source formatted with list set changes
+	changed bool
+}
+
+// format formats source, read from standard input, and reports the result.
+func (f *formatter) format(source []byte) error {
+	formatted, err := f.render(source)
+	if err != nil {
+		return err
+	}
+	return f.report("", source, formatted)
+}
+
+// render returns source formatted, only within the line range if set.
+func (f *formatter) render(source []byte) ([]byte, error) {
+	if f.lines != nil {
+		return markdown.FormatRange(source, f.lines.Start, f.lines.End, f.options...)
+	}
+	return markdown.Format(source, f.options...)
+}
+
+// report handles the formatted version of source, read from the file at path or from standard input
+// if path is empty. It's written to the standard output unless write, list or diff is set, in which
+// case the file is rewritten, its path listed and the diff printed respectively, if its content
+// changes.
+func (f *formatter) report(path string, source, formatted []byte) error {
+	if !f.write && !f.list && !f.diff {
+		_, err := f.stdout.Write(formatted)
+		return err
+	}
+	if string(formatted) == string(source) {
+		return nil
+	}
+	name := path
+	if name == "" {
+		name = "<standard input>"
+	}
+	if f.list {
+		f.changed = true
+		if _, err := fmt.Fprintln(f.stdout, name); err != nil {
+			return err
+		}
+	}
+	if f.diff {
+		if _, err := f.stdout.Write(diff.Unified(name+".orig", name, source, formatted)); err != nil {
+			return err
+		}
+	}
+	if f.write {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, formatted, info.Mode().Perm())
+	}
+	return nil
+}
+
+// status returns the exit code for the formatted sources, which is 1 if -check found one that
+// changes.
+func (f *formatter) status() int {
+	if f.changed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStdin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		source   string
+		expected string
+	}{
+		{
+			"Defaults",
+			[]string{},
+			"Title\n===\n\n* a\n* [x] b\n\n***\n",
+			"# Title\n\n* a\n* [x] b\n\n---\n",
+		},
+		{
+			"Options",
+			[]string{"-heading", "setext", "-bullet", "-", "-break", "_", "-numbering", "one"},
+			"# Title\n\n* a\n\n3. b\n\n***\n",
+			"Title\n===\n\n- a\n\n1. b\n\n___\n",
+		},
+		{
+			"Width",
+			[]string{"-width", "20"},
+			"The quick brown fox jumps over the lazy dog.\n",
+			"The quick brown fox\njumps over the lazy\ndog.\n",
+		},
+		{
+			"Preserve",
+			[]string{"-preserve"},
+			"# Title\n\n***\n",
+			"# Title\n\n***\n",
+		},
+		{
+			"Front matter",
+			[]string{},
+			"---\ntitle: Doc\n---\nText\n",
+			"---\ntitle: Doc\n---\nText\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			assert.Equal(t, 0, run(tc.args, strings.NewReader(tc.source), &stdout, &stderr))
+			assert.Equal(t, tc.expected, stdout.String())
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	require.NoError(t, os.WriteFile(a, []byte("A\n===\n"), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte("# B\n"), 0o644))
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 0, run([]string{a, b}, nil, &stdout, &stderr))
+	assert.Equal(t, "# A\n# B\n", stdout.String())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"-w", a, b}, nil, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	formatted, err := os.ReadFile(a)
+	require.NoError(t, err)
+	assert.Equal(t, "# A\n", string(formatted))
+	info, err := os.Stat(a)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	assert.Equal(t, 1, run([]string{filepath.Join(dir, "missing.md"), b}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "missing.md")
+	assert.Equal(t, "# B\n", stdout.String())
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 2, run([]string{"-bullet", "x"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `invalid value "x" for flag -bullet: must be one of *, +, -, preserve`)
+
+	stderr.Reset()
+	assert.Equal(t, 2, run([]string{"-w"}, strings.NewReader(""), &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "cannot use -w with standard input")
+
+	stderr.Reset()
+	assert.Equal(t, 0, run([]string{"-h"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "usage: mdfmt")
+}
+
+func TestRunCheckAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	require.NoError(t, os.WriteFile(a, []byte("A\n===\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("# B\n"), 0o644))
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 1, run([]string{"-check", a, b}, nil, &stdout, &stderr))
+	assert.Equal(t, a+"\n", stdout.String())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"-check", b}, nil, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"-d", a, b}, nil, &stdout, &stderr))
+	assert.Equal(t, "--- "+a+".orig\n+++ "+a+"\n@@ -1,2 +1,1 @@\n-A\n-===\n+# A\n", stdout.String())
+
+	stdout.Reset()
+	assert.Equal(t, 1, run([]string{"-check", "-d", "-bullet", "-"}, strings.NewReader("* a\n"), &stdout, &stderr))
+	assert.Equal(t, "<standard input>\n--- <standard input>.orig\n+++ <standard input>\n@@ -1,1 +1,1 @@\n-* a\n+- a\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	// The files are left unchanged
+	source, err := os.ReadFile(a)
+	require.NoError(t, err)
+	assert.Equal(t, "A\n===\n", string(source))
+}
+
+func TestRunDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":      "vendor/\n",
+		"a.md":            "A\n===\n",
+		"notes.txt":       "B\n===\n",
+		"docs/b.markdown": "B\n===\n",
+		"docs/c.md":       "# C\n",
+		"vendor/d.md":     "D\n===\n",
+		"build/e.md":      "E\n===\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 1, run([]string{"-check", "-exclude", "build", "-j", "2", dir}, nil, &stdout, &stderr))
+	assert.Equal(t, filepath.Join(dir, "a.md")+"\n"+filepath.Join(dir, "docs", "b.markdown")+"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunServeStdio(t *testing.T) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	input := `{"id": 1, "source": "# A\n"}` + "\n" + `{"id": 2, "source": "# B\n", "options": {"heading": "atx"}}` + "\n"
+	assert.Equal(t, 0, run([]string{"-serve", "stdio", "-heading", "setext"}, strings.NewReader(input), &stdout, &stderr))
+	assert.Equal(t, `{"id":1,"formatted":"A\n===\n"}`+"\n"+`{"id":2,"formatted":"# B\n"}`+"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunLines(t *testing.T) {
+	source := "A\n===\n\nB\n===\n"
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 0, run([]string{"-lines", "4-5"}, strings.NewReader(source), &stdout, &stderr))
+	assert.Equal(t, "A\n===\n\n# B\n", stdout.String())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"-lines", "1"}, strings.NewReader(source), &stdout, &stderr))
+	assert.Equal(t, "# A\n\nB\n===\n", stdout.String())
+
+	assert.Equal(t, 2, run([]string{"-lines", "5-4"}, strings.NewReader(source), &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `invalid value "5-4" for flag -lines: invalid line range 5-4`)
+}
+
+func TestRunPrintConfig(t *testing.T) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 0, run([]string{"-print-config", "-heading", "setext", "-preserve"}, strings.NewReader(""), &stdout, &stderr))
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), "\n  \"heading\": \"setext\",\n")
+	assert.Contains(t, stdout.String(), "\n  \"preserve\": true,\n")
+}
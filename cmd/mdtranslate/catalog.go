@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+)
+
+// catalog is the translatable text extracted from markdown files, in order of first appearance.
+type catalog struct {
+	sourceLang string
+	messages   []*message
+	index      map[string]*message
+}
+
+// message is a text of a catalog.
+type message struct {
+	text string
+	// html is set if the text is raw HTML, which translators must keep valid
+	html bool
+	// references are the paths of the files containing the text
+	references []string
+}
+
+// extractedText is a text passed to the TextTransformer of the renderer.
+type extractedText struct {
+	textType markdown.TextType
+	text     string
+}
+
+// extractTexts returns the text of source that the renderer passes to its TextTransformer, in
+// order.
+func extractTexts(source []byte) ([]extractedText, error) {
+	var texts []extractedText
+	recorder := textRecorder(func(textType markdown.TextType, text string) {
+		if strings.TrimSpace(text) != "" {
+			texts = append(texts, extractedText{textType, text})
+		}
+	})
+	if _, err := markdown.Format(source, markdown.WithTextTransformer(recorder)); err != nil {
+		return nil, err
+	}
+	return texts, nil
+}
+
+// add adds texts, extracted from the file at path or from standard input if path is empty, to the
+// catalog.
+func (c *catalog) add(path string, texts []extractedText) {
+	if c.index == nil {
+		c.index = map[string]*message{}
+	}
+	for _, t := range texts {
+		m, ok := c.index[t.text]
+		if !ok {
+			m = &message{text: t.text, html: t.textType == markdown.TextTypeHTML}
+			c.index[t.text] = m
+			c.messages = append(c.messages, m)
+		}
+		if path != "" && (len(m.references) == 0 || m.references[len(m.references)-1] != path) {
+			m.references = append(m.references, path)
+		}
+	}
+}
+
+// textRecorder is a markdown.TextTransformer that records the text passed to it without replacing
+// it.
+type textRecorder func(textType markdown.TextType, text string)
+
+func (r textRecorder) Transform(textType markdown.TextType, text string) (string, bool) {
+	r(textType, text)
+	return "", false
+}
+
+// catalogWriters write a catalog of untranslated messages in each format.
+var catalogWriters = map[string]func(w io.Writer, c *catalog) error{
+	"po":    writePO,
+	"xliff": writeXLIFF,
+	"json":  writeJSON,
+}
+
+// readTranslations reads the translations of the PO, XLIFF or JSON catalog at path, according to its
+// extension. Messages without a translation are left out.
+func readTranslations(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var translations map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".po":
+		translations, err = readPO(data)
+	case ".xliff", ".xlf":
+		translations, err = readXLIFF(data)
+	case ".json":
+		translations, err = readJSON(data)
+	default:
+		return nil, fmt.Errorf("%s: unknown catalog format, expected .po, .xliff, .xlf or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return translations, nil
+}
+
+// writePO writes c as a gettext PO template.
+func writePO(w io.Writer, c *catalog) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `msgid ""`)
+	fmt.Fprintln(bw, `msgstr ""`)
+	fmt.Fprintln(bw, `"Content-Type: text/plain; charset=UTF-8\n"`)
+	for _, m := range c.messages {
+		fmt.Fprintln(bw)
+		if m.html {
+			fmt.Fprintln(bw, "#. HTML")
+		}
+		if len(m.references) > 0 {
+			fmt.Fprintln(bw, "#:", strings.Join(m.references, " "))
+		}
+		writePOString(bw, "msgid", m.text)
+		writePOString(bw, "msgstr", "")
+	}
+	return bw.Flush()
+}
+
+// writePOString writes the keyword and quoted value of a PO field. Values with line breaks are
+// written one line per string, after an empty one.
+func writePOString(w io.Writer, keyword, value string) {
+	lines := strings.SplitAfter(value, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		fmt.Fprintf(w, "%s %s\n", keyword, quotePO(value))
+		return
+	}
+	fmt.Fprintf(w, "%s \"\"\n", keyword)
+	for _, line := range lines {
+		fmt.Fprintln(w, quotePO(line))
+	}
+}
+
+// quotePO returns s as a quoted PO string.
+func quotePO(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s) + `"`
+}
+
+// readPO returns the translations of a gettext PO file. The header and fuzzy messages are left out.
+func readPO(data []byte) (map[string]string, error) {
+	translations := map[string]string{}
+	var id, str *strings.Builder
+	fuzzy, nextFuzzy, inContext := false, false, false
+	current := (*strings.Builder)(nil)
+	flush := func() {
+		if id != nil && str != nil && id.Len() > 0 && str.Len() > 0 && !fuzzy {
+			translations[id.String()] = str.String()
+		}
+		id, str, current = nil, nil, nil
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		keyword, value, _ := strings.Cut(line, " ")
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#,"):
+			if strings.Contains(line, "fuzzy") {
+				nextFuzzy = true
+			}
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, `"`):
+			value = line
+		case keyword == "msgctxt" || keyword == "msgid":
+			// An entry starts at its context, if it has one, or else at its id
+			if keyword == "msgctxt" || !inContext {
+				flush()
+				fuzzy, nextFuzzy = nextFuzzy, false
+			}
+			inContext = keyword == "msgctxt"
+			if keyword == "msgid" {
+				id = &strings.Builder{}
+				current = id
+			} else {
+				// Contexts aren't used by the renderer, so their messages are keyed by text alone
+				current = &strings.Builder{}
+			}
+		case keyword == "msgstr" || keyword == "msgstr[0]":
+			str = &strings.Builder{}
+			current = str
+		case strings.HasPrefix(keyword, "msgid_plural") || strings.HasPrefix(keyword, "msgstr["):
+			// Plural forms are never extracted
+			current = &strings.Builder{}
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", i+1, keyword)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: string outside of a message", i+1)
+		}
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid string %s", i+1, value)
+		}
+		current.WriteString(unquoted)
+	}
+	flush()
+	return translations, nil
+}
+
+// xliff is an XLIFF 1.2 document.
+type xliff struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string      `xml:"version,attr"`
+	Files   []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string           `xml:"original,attr"`
+	SourceLanguage string           `xml:"source-language,attr"`
+	Datatype       string           `xml:"datatype,attr"`
+	Units          []xliffTransUnit `xml:"body>trans-unit"`
+}
+
+type xliffTransUnit struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source"`
+	Target *xliffValue `xml:"target"`
+	Notes  []string    `xml:"note"`
+}
+
+type xliffValue struct {
+	Value string `xml:",chardata"`
+}
+
+// writeXLIFF writes c as an XLIFF 1.2 document with a file for the catalog.
+func writeXLIFF(w io.Writer, c *catalog) error {
+	file := xliffFile{Original: "markdown", SourceLanguage: c.sourceLang, Datatype: "plaintext"}
+	for i, m := range c.messages {
+		unit := xliffTransUnit{ID: strconv.Itoa(i + 1), Source: m.text}
+		if m.html {
+			unit.Notes = append(unit.Notes, "HTML")
+		}
+		if len(m.references) > 0 {
+			unit.Notes = append(unit.Notes, strings.Join(m.references, " "))
+		}
+		file.Units = append(file.Units, unit)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(xliff{Version: "1.2", Files: []xliffFile{file}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// readXLIFF returns the translations of an XLIFF 1.2 document.
+func readXLIFF(data []byte) (map[string]string, error) {
+	doc := xliff{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	translations := map[string]string{}
+	for _, file := range doc.Files {
+		for _, unit := range file.Units {
+			if unit.Target != nil && unit.Target.Value != "" {
+				translations[unit.Source] = unit.Target.Value
+			}
+		}
+	}
+	return translations, nil
+}
+
+// writeJSON writes c as a JSON object mapping each text to an empty translation.
+func writeJSON(w io.Writer, c *catalog) error {
+	// Objects are written by hand to keep the order of the messages
+	buf := bytes.Buffer{}
+	buf.WriteString("{")
+	for i, m := range c.messages {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		text := bytes.Buffer{}
+		encoder := json.NewEncoder(&text)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(m.text); err != nil {
+			return err
+		}
+		buf.WriteString("\n  ")
+		buf.Write(bytes.TrimSuffix(text.Bytes(), []byte("\n")))
+		buf.WriteString(`: ""`)
+	}
+	if len(c.messages) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readJSON returns the translations of a JSON object mapping text to its translation.
+func readJSON(data []byte) (map[string]string, error) {
+	translations := map[string]string{}
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, err
+	}
+	for text, translation := range translations {
+		if translation == "" {
+			delete(translations, text)
+		}
+	}
+	return translations, nil
+}
@@ -0,0 +1,254 @@
+// Command mdtranslate extracts the translatable text of markdown files and applies translations to
+// them.
+//
+// Usage:
+//
+//	mdtranslate extract [flags] [path ...]
+//	mdtranslate apply -t catalog [flags] [path ...]
+//
+// Directories given as paths are searched recursively for markdown files, leaving out those ignored
+// by .gitignore and .mdignore files or matching -exclude patterns.
+//
+// The extract subcommand writes the text of the files, or of standard input without paths, to a
+// catalog of untranslated messages, in PO, XLIFF 1.2 or JSON format. Code, URLs and HTML comments
+// aren't extracted, since the renderer never translates them.
+//
+// The apply subcommand renders each file with its text replaced by the translations in a catalog,
+// which is a PO, XLIFF or JSON file chosen by its extension. A JSON catalog is an object mapping
+// each text to its translation, so it can also serve as a glossary. Text without a translation is
+// kept. The result is written to standard output, or back to the files with -w. With -review, each
+// translated block is followed by an HTML comment holding its source, for proofreading.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/teekennedy/goldmark-markdown/internal/walk"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs mdtranslate with args and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "extract":
+		return runExtract(args[1:], stdin, stdout, stderr)
+	case "apply":
+		return runApply(args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		usage(stderr)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "mdtranslate: unknown subcommand %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: mdtranslate extract [flags] [path ...]")
+	fmt.Fprintln(w, "       mdtranslate apply -t catalog [flags] [path ...]")
+}
+
+// runExtract runs the extract subcommand with args and returns its exit code.
+func runExtract(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mdtranslate extract", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mdtranslate extract [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "po", "catalog `format`: po, xliff or json")
+	output := flags.String("o", "", "write the catalog to `file` instead of standard output")
+	sourceLang := flags.String("source-lang", "en", "source `language` of XLIFF catalogs")
+	walkOptions := walk.Options{}
+	walkOptions.RegisterFlags(flags)
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	write, ok := catalogWriters[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "mdtranslate: unknown catalog format %q\n", *format)
+		return 2
+	}
+
+	catalog := &catalog{sourceLang: *sourceLang}
+	if flags.NArg() == 0 {
+		source, err := io.ReadAll(stdin)
+		if err == nil {
+			var texts []extractedText
+			texts, err = extractTexts(source)
+			catalog.add("", texts)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mdtranslate:", err)
+			return 1
+		}
+	}
+	status := 0
+	walk.Process(findFiles(flags.Args(), walkOptions, stderr, &status), walkOptions.Workers, func(path string) ([]extractedText, error) {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		texts, err := extractTexts(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return texts, nil
+	}, func(path string, texts []extractedText, err error) {
+		if err != nil {
+			fmt.Fprintln(stderr, "mdtranslate:", err)
+			status = 1
+			return
+		}
+		catalog.add(path, texts)
+	})
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(stderr, "mdtranslate:", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(w, catalog); err != nil {
+		fmt.Fprintln(stderr, "mdtranslate:", err)
+		return 1
+	}
+	return status
+}
+
+// runApply runs the apply subcommand with args and returns its exit code.
+func runApply(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mdtranslate apply", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mdtranslate apply -t catalog [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	catalogPath := flags.String("t", "", "read the translations from the PO, XLIFF or JSON catalog `file`")
+	write := flags.Bool("w", false, "write the result to the file instead of standard output")
+	preserve := flags.Bool("preserve", false, "preserve the syntax of the source where it's valid")
+	review := flags.Bool("review", false, "follow each translated block with a comment holding its source")
+	walkOptions := walk.Options{}
+	walkOptions.RegisterFlags(flags)
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if *catalogPath == "" {
+		fmt.Fprintln(stderr, "mdtranslate: missing -t catalog")
+		return 2
+	}
+	translations, err := readTranslations(*catalogPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "mdtranslate:", err)
+		return 1
+	}
+	options := []markdown.Option{markdown.WithTextTransformer(markdown.MapTransformer(translations))}
+	if *preserve {
+		options = append(options, markdown.WithStyleMode(markdown.StyleModePreserve))
+	}
+	if *review {
+		options = append(options, markdown.WithReviewComments(true))
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "mdtranslate: cannot use -w with standard input")
+			return 2
+		}
+		source, err := io.ReadAll(stdin)
+		if err == nil {
+			err = translate(source, stdout, options)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mdtranslate:", err)
+			return 1
+		}
+		return 0
+	}
+	status := 0
+	walk.Process(findFiles(flags.Args(), walkOptions, stderr, &status), walkOptions.Workers, func(path string) ([]byte, error) {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		translated, err := markdown.Format(source, options...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if *write && string(translated) == string(source) {
+			return nil, nil
+		}
+		return translated, nil
+	}, func(path string, translated []byte, err error) {
+		if err == nil {
+			err = output(path, translated, *write, stdout)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mdtranslate:", err)
+			status = 1
+		}
+	})
+	return status
+}
+
+// findFiles returns the files found under roots with options, printing an error to stderr and
+// setting status to 1 for each root that can't be walked.
+func findFiles(roots []string, options walk.Options, stderr io.Writer, status *int) []string {
+	var paths []string
+	for _, root := range roots {
+		files, err := walk.Files([]string{root}, options)
+		if err != nil {
+			fmt.Fprintln(stderr, "mdtranslate:", err)
+			*status = 1
+		}
+		paths = append(paths, files...)
+	}
+	return paths
+}
+
+// translate renders source to w with options.
+func translate(source []byte, w io.Writer, options []markdown.Option) error {
+	translated, err := markdown.Format(source, options...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(translated)
+	return err
+}
+
+// output writes the translation of the file at path to w, or back to the file if write is true. A
+// nil translation leaves the file unchanged.
+func output(path string, translated []byte, write bool, w io.Writer) error {
+	if !write {
+		_, err := w.Write(translated)
+		return err
+	}
+	if translated == nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, translated, info.Mode().Perm())
+}
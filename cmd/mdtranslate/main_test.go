@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = "# Hello\n\nSome *text*.\n\n<div>hi</div>\n\n```\ncode\n```\n"
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			"po",
+			`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: a.md
+msgid "Hello"
+msgstr ""
+
+#: a.md b.md
+msgid "Some"
+msgstr ""
+
+#: a.md
+msgid "text"
+msgstr ""
+
+#: a.md
+msgid "."
+msgstr ""
+
+#. HTML
+#: a.md
+msgid "<div>hi</div>\n"
+msgstr ""
+`,
+		},
+		{
+			"xliff",
+			`<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+  <file original="markdown" source-language="en" datatype="plaintext">
+    <body>
+      <trans-unit id="1">
+        <source>Hello</source>
+        <note>a.md</note>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>Some</source>
+        <note>a.md b.md</note>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>text</source>
+        <note>a.md</note>
+      </trans-unit>
+      <trans-unit id="4">
+        <source>.</source>
+        <note>a.md</note>
+      </trans-unit>
+      <trans-unit id="5">
+        <source>&lt;div&gt;hi&lt;/div&gt;&#xA;</source>
+        <note>HTML</note>
+        <note>a.md</note>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`,
+		},
+		{
+			"json",
+			"{\n  \"Hello\": \"\",\n  \"Some\": \"\",\n  \"text\": \"\",\n  \".\": \"\",\n  \"<div>hi</div>\\n\": \"\"\n}\n",
+		},
+	}
+	dir := t.TempDir()
+	chdir(t, dir)
+	require.NoError(t, os.WriteFile("a.md", []byte(testSource), 0o644))
+	require.NoError(t, os.WriteFile("b.md", []byte("Some\n"), 0o644))
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			assert.Equal(t, 0, run([]string{"extract", "-format", tc.format, "a.md", "b.md"}, nil, &stdout, &stderr))
+			assert.Equal(t, tc.expected, stdout.String())
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
+
+func TestReadPO(t *testing.T) {
+	translations, err := readPO([]byte(`#, fuzzy
+msgctxt "a.md"
+msgid "Hello"
+msgstr "Bonjour"
+
+msgctxt "a.md"
+msgid "Some"
+msgstr "Du"
+
+#, fuzzy
+msgid "text"
+msgstr "texte"
+
+msgid "code"
+msgstr "code"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Some": "Du", "code": "code"}, translations)
+}
+
+func TestApply(t *testing.T) {
+	expected := "# Bonjour\n\nDu *texte*.\n\n<div>salut</div>\n\n```\ncode\n```\n"
+	catalogs := map[string]string{
+		"fr.po": `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgid "Hello"
+msgstr "Bonjour"
+
+msgid "Some"
+msgstr "Du"
+
+#, fuzzy
+msgid "code"
+msgstr "fuzzy"
+
+msgid "text"
+msgstr ""
+"tex"
+"te"
+
+msgid ""
+"<div>hi</div>\n"
+msgstr "<div>salut</div>\n"
+`,
+		"fr.xliff": `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+  <file original="markdown" source-language="en" target-language="fr" datatype="plaintext">
+    <body>
+      <trans-unit id="1"><source>Hello</source><target>Bonjour</target></trans-unit>
+      <trans-unit id="2"><source>Some</source><target>Du</target></trans-unit>
+      <trans-unit id="3"><source>text</source><target>texte</target></trans-unit>
+      <trans-unit id="4"><source>.</source><target></target></trans-unit>
+      <trans-unit id="5"><source>&lt;div&gt;hi&lt;/div&gt;&#xA;</source><target>&lt;div&gt;salut&lt;/div&gt;&#xA;</target></trans-unit>
+    </body>
+  </file>
+</xliff>
+`,
+		"fr.json": `{"Hello": "Bonjour", "Some": "Du", "text": "texte", ".": "", "<div>hi</div>\n": "<div>salut</div>\n"}`,
+	}
+	dir := t.TempDir()
+	chdir(t, dir)
+	require.NoError(t, os.WriteFile("a.md", []byte(testSource), 0o644))
+	for name, catalog := range catalogs {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(name, []byte(catalog), 0o644))
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			assert.Equal(t, 0, run([]string{"apply", "-t", name, "a.md"}, nil, &stdout, &stderr))
+			assert.Equal(t, expected, stdout.String())
+			assert.Empty(t, stderr.String())
+
+			stdout.Reset()
+			assert.Equal(t, 0, run([]string{"apply", "-t", name}, strings.NewReader(testSource), &stdout, &stderr))
+			assert.Equal(t, expected, stdout.String())
+		})
+	}
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 0, run([]string{"apply", "-t", "fr.json", "-w", "a.md"}, nil, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	translated, err := os.ReadFile("a.md")
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(translated))
+}
+
+func TestApplyReview(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	require.NoError(t, os.WriteFile("fr.json", []byte(`{"Hello": "Bonjour", "text": "texte"}`), 0o644))
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 0, run([]string{"apply", "-t", "fr.json", "-review"}, strings.NewReader(testSource), &stdout, &stderr))
+	assert.Equal(t, "# Bonjour\n<!-- original: # Hello -->\n\nSome *texte*.\n<!-- original: Some *text*. -->\n\n"+
+		"<div>hi</div>\n\n```\ncode\n```\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestExtractThenApply(t *testing.T) {
+	dir := t.TempDir()
+	catalog := filepath.Join(dir, "messages.po")
+	// The backslashes escape the quotes in markdown, so the text has none
+	source := "Say \\\"hi\\\"\tnow\n"
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	require.Equal(t, 0, run([]string{"extract", "-o", catalog}, strings.NewReader(source), &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	data, err := os.ReadFile(catalog)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "msgid \"Say \\\"hi\\\"\\tnow\"\nmsgstr \"\"\n")
+
+	translated := strings.Replace(string(data), "msgstr \"\"\n", "msgstr \"Dis \\\"salut\\\"\"\n", 2)
+	require.NoError(t, os.WriteFile(catalog, []byte(translated), 0o644))
+	assert.Equal(t, 0, run([]string{"apply", "-t", catalog}, strings.NewReader(source), &stdout, &stderr))
+	assert.Equal(t, "Dis \"salut\"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestUsageErrors(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{}, 2, "usage: mdtranslate"},
+		{[]string{"help"}, 0, "usage: mdtranslate"},
+		{[]string{"translate"}, 2, `unknown subcommand "translate"`},
+		{[]string{"extract", "-format", "csv"}, 2, `unknown catalog format "csv"`},
+		{[]string{"apply"}, 2, "missing -t catalog"},
+		{[]string{"apply", "-t", "messages.csv"}, 1, "messages.csv"},
+		{[]string{"apply", "-h"}, 0, "usage: mdtranslate apply"},
+	}
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			assert.Equal(t, tc.code, run(tc.args, strings.NewReader(""), &stdout, &stderr))
+			assert.Contains(t, stderr.String(), tc.expected)
+		})
+	}
+}
+
+// chdir changes the working directory to dir until the test ends.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestExtractDirectory(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	files := map[string]string{
+		".mdignore":     "drafts/\n",
+		"a.md":          "One\n",
+		"docs/b.md":     "Two\n",
+		"docs/c.txt":    "Three\n",
+		"drafts/d.md":   "Four\n",
+		"docs/e.mdx":    "Five\n",
+		"docs/f.md.bak": "Six\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		require.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+	}
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	assert.Equal(t, 0, run([]string{"extract", "-format", "json", "-include", "*.md,*.mdx", "."}, nil, &stdout, &stderr))
+	assert.Equal(t, "{\n  \"One\": \"\",\n  \"Two\": \"\",\n  \"Five\": \"\"\n}\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
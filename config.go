@@ -0,0 +1,281 @@
+package markdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Names of the values of the options that NamedOptions doesn't take, for Config.MarshalJSON.
+var (
+	emptyListItemStyleNames = map[string]EmptyListItemStyle{
+		"bare": EmptyListItemStyleBare,
+		"nbsp": EmptyListItemStyleNBSP,
+	}
+	htmlCommentsNames = map[string]HTMLComments{
+		"preserve": HTMLCommentsPreserve,
+		"strip":    HTMLCommentsStrip,
+	}
+	inlineJoinNames = map[string]InlineJoin{
+		"space": InlineJoinSpace,
+		"none":  InlineJoinNone,
+		"smart": InlineJoinSmart,
+	}
+	emphasisFlankingNames = map[string]EmphasisFlanking{
+		"word-joiner": EmphasisFlankingWordJoiner,
+		"html":        EmphasisFlankingHTML,
+		"none":        EmphasisFlankingNone,
+	}
+	hugoShortcodesNames = map[string]HugoShortcodes{
+		"none":                HugoShortcodesNone,
+		"preserve":            HugoShortcodesPreserve,
+		"translate-arguments": HugoShortcodesTranslateArguments,
+	}
+	liquidTagsNames = map[string]LiquidTags{
+		"none":     LiquidTagsNone,
+		"preserve": LiquidTagsPreserve,
+	}
+	headingIDsNames = map[string]HeadingIDs{
+		"keep":     HeadingIDsKeep,
+		"strip":    HeadingIDsStrip,
+		"generate": HeadingIDsGenerate,
+	}
+	whitespaceNames = map[string]Whitespace{
+		"preserve":  WhitespacePreserve,
+		"collapse":  WhitespaceCollapse,
+		"normalize": WhitespaceNormalize,
+	}
+	blankLinesNames = map[string]BlankLines{
+		"preserve": BlankLinesPreserve,
+		"around":   BlankLinesAround,
+	}
+	cjkSpacingNames = map[string]CJKSpacing{
+		"preserve": CJKSpacingPreserve,
+		"add":      CJKSpacingAdd,
+		"remove":   CJKSpacingRemove,
+	}
+	footnotePlacementNames = map[string]FootnotePlacement{
+		"preserve": FootnotePlacementPreserve,
+		"section":  FootnotePlacementSection,
+		"end":      FootnotePlacementEnd,
+	}
+	mathBlocksNames = map[string]MathBlocks{
+		"none":     MathBlocksNone,
+		"preserve": MathBlocksPreserve,
+	}
+	htmlEscapingNames = map[string]HTMLEscaping{
+		"backslash": HTMLEscapingBackslash,
+		"entities":  HTMLEscapingEntities,
+		"code":      HTMLEscapingCode,
+	}
+	inlineHTMLNames = map[string]InlineHTML{
+		"preserve": InlineHTMLPreserve,
+		"convert":  InlineHTMLConvert,
+	}
+	imageAttributesNames = map[string]ImageAttributes{
+		"none":     ImageAttributesNone,
+		"preserve": ImageAttributesPreserve,
+	}
+	taskCheckBoxCaseNames = map[string]TaskCheckBoxCase{
+		"lower": TaskCheckBoxCaseLower,
+		"upper": TaskCheckBoxCaseUpper,
+	}
+	emphasisStyleNames = map[string]EmphasisStyle{
+		"asterisk":   EmphasisStyleAsterisk,
+		"underscore": EmphasisStyleUnderscore,
+		"preserve":   EmphasisStylePreserve,
+	}
+	strongStyleNames = map[string]StrongStyle{
+		"asterisk":   StrongStyleAsterisk,
+		"underscore": StrongStyleUnderscore,
+		"preserve":   StrongStylePreserve,
+	}
+	hardBreakStyleNames = map[string]HardBreakStyle{
+		"backslash": HardBreakStyleBackslash,
+		"spaces":    HardBreakStyleSpaces,
+	}
+	linkStyleNames = map[string]LinkStyle{
+		"inline":    LinkStyleInline,
+		"reference": LinkStyleReference,
+		"preserve":  LinkStylePreserve,
+	}
+	textEscapingNames = map[string]TextEscaping{
+		"minimal":    TextEscapingMinimal,
+		"smart":      TextEscapingSmart,
+		"aggressive": TextEscapingAggressive,
+	}
+	tableStyleNames = map[string]TableStyle{
+		"compact": TableStyleCompact,
+		"pretty":  TableStylePretty,
+	}
+)
+
+// Options returns the options that configure a Config like c, one for each of its fields, such as to
+// pass the configuration of a Renderer on to another.
+func (c *Config) Options() []Option {
+	return []Option{
+		WithIndentStyle(c.IndentStyle),
+		WithHeadingStyle(c.HeadingStyle),
+		WithThematicBreakStyle(c.ThematicBreakStyle),
+		WithThematicBreakLength(c.ThematicBreakLength),
+		WithNestedListLength(c.NestedListLength),
+		WithListNumbering(c.ListNumbering),
+		WithBulletMarker(c.BulletMarker),
+		WithEmptyListItemStyle(c.EmptyListItemStyle),
+		WithHTMLComments(c.HTMLComments),
+		WithStyleMode(c.StyleMode),
+		WithInlineJoin(c.InlineJoin),
+		WithEmphasisFlanking(c.EmphasisFlanking),
+		WithHugoShortcodes(c.HugoShortcodes),
+		WithLiquidTags(c.LiquidTags),
+		WithDialect(c.Dialect),
+		WithHeadingIDs(c.HeadingIDs),
+		WithWhitespace(c.Whitespace),
+		WithListIndentWidth(c.ListIndentWidth),
+		WithBlankLines(c.BlankLines),
+		WithCJKSpacing(c.CJKSpacing),
+		WithFootnotePlacement(c.FootnotePlacement),
+		WithMathBlocks(c.MathBlocks),
+		WithHTMLEscaping(c.HTMLEscaping),
+		WithInlineHTML(c.InlineHTML),
+		WithImageAttributes(c.ImageAttributes),
+		WithTaskCheckBoxCase(c.TaskCheckBoxCase),
+		WithEmphasisStyle(c.EmphasisStyle),
+		WithStrongStyle(c.StrongStyle),
+		WithLineWidth(c.LineWidth),
+		WithHardBreakStyle(c.HardBreakStyle),
+		WithLinkStyle(c.LinkStyle),
+		WithTextEscaping(c.TextEscaping),
+		WithTableStyle(c.TableStyle),
+		WithTargetLanguage(c.TargetLanguage),
+		WithTextTransformer(c.TextTransformer),
+		WithLinkTransformer(c.LinkTransformer),
+		WithMetrics(c.CollectMetrics),
+		WithValidation(c.Validate),
+		WithReviewComments(c.ReviewComments),
+		&withOutputTemplate{c.OutputTemplate},
+		WithNodeFilter(c.NodeFilter),
+		WithRawKinds(c.RawKinds...),
+	}
+}
+
+// configJSON is the JSON form of a Config.
+type configJSON struct {
+	Indent            string `json:"indent"`
+	Heading           string `json:"heading"`
+	Break             string `json:"break"`
+	BreakLength       int    `json:"break-length"`
+	NestedListLength  int    `json:"nested-list-length"`
+	Numbering         string `json:"numbering"`
+	Bullet            string `json:"bullet"`
+	EmptyListItem     string `json:"empty-list-item"`
+	HTMLComments      string `json:"html-comments"`
+	Preserve          bool   `json:"preserve"`
+	InlineJoin        string `json:"inline-join"`
+	EmphasisFlanking  string `json:"emphasis-flanking"`
+	HugoShortcodes    string `json:"hugo-shortcodes"`
+	LiquidTags        string `json:"liquid-tags"`
+	Dialect           string `json:"dialect"`
+	HeadingIDs        string `json:"heading-ids"`
+	Whitespace        string `json:"whitespace"`
+	ListIndentWidth   int    `json:"list-indent-width"`
+	BlankLines        string `json:"blank-lines"`
+	CJKSpacing        string `json:"cjk-spacing"`
+	FootnotePlacement string `json:"footnote-placement"`
+	MathBlocks        string `json:"math-blocks"`
+	HTMLEscaping      string `json:"html-escaping"`
+	InlineHTML        string `json:"inline-html"`
+	ImageAttributes   string `json:"image-attributes"`
+	TaskCheckBoxCase  string `json:"task-checkbox-case"`
+	EmphasisStyle     string `json:"emphasis-style"`
+	StrongStyle       string `json:"strong-style"`
+	LineWidth         int    `json:"line-width"`
+	HardBreakStyle    string `json:"hard-break-style"`
+	LinkStyle         string `json:"link-style"`
+	TextEscaping      string `json:"text-escaping"`
+	TableStyle        string `json:"table-style"`
+	TargetLanguage    string `json:"target-language"`
+	// TextTransformer is the type of the TextTransformer, if any
+	TextTransformer *string `json:"text-transformer"`
+	LinkTransformer bool    `json:"link-transformer"`
+	Metrics         bool    `json:"metrics"`
+	Validation      bool    `json:"validation"`
+	ReviewComments  bool    `json:"review-comments"`
+	// OutputTemplate is the name of the template of the OutputTemplate, if any
+	OutputTemplate *string `json:"output-template"`
+	NodeFilter     bool    `json:"node-filter"`
+	// RawKinds are the names of the kinds of nodes written verbatim
+	RawKinds []string `json:"raw-kinds"`
+}
+
+// MarshalJSON implements json.Marshaler, writing the effective configuration as an object of the
+// names of the options and their values, such as {"heading": "atx", "bullet": "preserve", ...}. The
+// options NamedOptions takes are named as it names them. Transformers and filters are written as
+// whether they're set, but for the TextTransformer, written as the name of its type, and the
+// OutputTemplate, written as the name of its template.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	j := configJSON{
+		Indent:            nameOf(IndentStyleNames, c.IndentStyle),
+		Heading:           nameOf(HeadingStyleNames, c.HeadingStyle),
+		Break:             nameOf(ThematicBreakStyleNames, c.ThematicBreakStyle),
+		BreakLength:       int(c.ThematicBreakLength),
+		NestedListLength:  int(c.NestedListLength),
+		Numbering:         nameOf(ListNumberingNames, c.ListNumbering),
+		Bullet:            nameOf(BulletMarkerNames, c.BulletMarker),
+		EmptyListItem:     nameOf(emptyListItemStyleNames, c.EmptyListItemStyle),
+		HTMLComments:      nameOf(htmlCommentsNames, c.HTMLComments),
+		Preserve:          c.StyleMode == StyleModePreserve,
+		InlineJoin:        nameOf(inlineJoinNames, c.InlineJoin),
+		EmphasisFlanking:  nameOf(emphasisFlankingNames, c.EmphasisFlanking),
+		HugoShortcodes:    nameOf(hugoShortcodesNames, c.HugoShortcodes),
+		LiquidTags:        nameOf(liquidTagsNames, c.LiquidTags),
+		Dialect:           nameOf(DialectNames, c.Dialect),
+		HeadingIDs:        nameOf(headingIDsNames, c.HeadingIDs),
+		Whitespace:        nameOf(whitespaceNames, c.Whitespace),
+		ListIndentWidth:   int(c.ListIndentWidth),
+		BlankLines:        nameOf(blankLinesNames, c.BlankLines),
+		CJKSpacing:        nameOf(cjkSpacingNames, c.CJKSpacing),
+		FootnotePlacement: nameOf(footnotePlacementNames, c.FootnotePlacement),
+		MathBlocks:        nameOf(mathBlocksNames, c.MathBlocks),
+		HTMLEscaping:      nameOf(htmlEscapingNames, c.HTMLEscaping),
+		InlineHTML:        nameOf(inlineHTMLNames, c.InlineHTML),
+		ImageAttributes:   nameOf(imageAttributesNames, c.ImageAttributes),
+		TaskCheckBoxCase:  nameOf(taskCheckBoxCaseNames, c.TaskCheckBoxCase),
+		EmphasisStyle:     nameOf(emphasisStyleNames, c.EmphasisStyle),
+		StrongStyle:       nameOf(strongStyleNames, c.StrongStyle),
+		LineWidth:         int(c.LineWidth),
+		HardBreakStyle:    nameOf(hardBreakStyleNames, c.HardBreakStyle),
+		LinkStyle:         nameOf(linkStyleNames, c.LinkStyle),
+		TextEscaping:      nameOf(textEscapingNames, c.TextEscaping),
+		TableStyle:        nameOf(tableStyleNames, c.TableStyle),
+		TargetLanguage:    string(c.TargetLanguage),
+		LinkTransformer:   c.LinkTransformer != nil,
+		Metrics:           c.CollectMetrics,
+		Validation:        c.Validate,
+		ReviewComments:    c.ReviewComments,
+		NodeFilter:        c.NodeFilter != nil,
+		RawKinds:          []string{},
+	}
+	for _, kind := range c.RawKinds {
+		j.RawKinds = append(j.RawKinds, kind.String())
+	}
+	if c.TextTransformer != nil {
+		name := fmt.Sprintf("%T", c.TextTransformer)
+		j.TextTransformer = &name
+	}
+	if c.OutputTemplate != nil && c.OutputTemplate.Template != nil {
+		name := c.OutputTemplate.Template.Name()
+		j.OutputTemplate = &name
+	}
+	return json.Marshal(j)
+}
+
+// nameOf returns the name of value in names, or value as a number if it has none.
+func nameOf[T ~int](names map[string]T, value T) string {
+	for name, v := range names {
+		if v == value {
+			return name
+		}
+	}
+	return strconv.Itoa(int(value))
+}
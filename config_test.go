@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"encoding/json"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+func TestConfigOptions(t *testing.T) {
+	config := &Config{
+		IndentStyle:         IndentStyleTabs,
+		HeadingStyle:        HeadingStyleSetext,
+		ThematicBreakStyle:  ThematicBreakStyleStarred,
+		ThematicBreakLength: 5,
+		NestedListLength:    4,
+		ListNumbering:       ListNumberingFromOne,
+		BulletMarker:        BulletMarkerPlus,
+		EmptyListItemStyle:  EmptyListItemStyleNBSP,
+		HTMLComments:        HTMLCommentsStrip,
+		StyleMode:           StyleModePreserve,
+		InlineJoin:          InlineJoinSmart,
+		EmphasisFlanking:    EmphasisFlankingHTML,
+		HugoShortcodes:      HugoShortcodesPreserve,
+		LiquidTags:          LiquidTagsPreserve,
+		Dialect:             DialectPandoc,
+		HeadingIDs:          HeadingIDsGenerate,
+		Whitespace:          WhitespaceNormalize,
+		ListIndentWidth:     4,
+		BlankLines:          BlankLinesAround,
+		CJKSpacing:          CJKSpacingAdd,
+		FootnotePlacement:   FootnotePlacementEnd,
+		MathBlocks:          MathBlocksPreserve,
+		HTMLEscaping:        HTMLEscapingCode,
+		InlineHTML:          InlineHTMLConvert,
+		ImageAttributes:     ImageAttributesPreserve,
+		TaskCheckBoxCase:    TaskCheckBoxCaseUpper,
+		EmphasisStyle:       EmphasisStyleUnderscore,
+		StrongStyle:         StrongStylePreserve,
+		LineWidth:           80,
+		HardBreakStyle:      HardBreakStyleSpaces,
+		LinkStyle:           LinkStyleReference,
+		TextEscaping:        TextEscapingSmart,
+		TableStyle:          TableStylePretty,
+		TargetLanguage:      "fr",
+		TextTransformer:     MapTransformer{"a": "b"},
+		CollectMetrics:      true,
+		Validate:            true,
+		ReviewComments:      true,
+		OutputTemplate:      &OutputTemplate{Template: template.Must(template.New("page").Parse("{{.Content}}"))},
+		RawKinds:            []ast.NodeKind{east.KindStrikethrough},
+	}
+	assert.Equal(t, config, NewConfig(config.Options()...))
+	assert.Equal(t, NewConfig(), NewConfig(NewConfig().Options()...))
+	assert.Equal(t, config, NewRenderer(config.Options()...).Config())
+}
+
+func TestConfigMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(NewConfig())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"indent": "spaces",
+		"heading": "atx",
+		"break": "-",
+		"break-length": 3,
+		"nested-list-length": 1,
+		"numbering": "start",
+		"bullet": "preserve",
+		"empty-list-item": "bare",
+		"html-comments": "preserve",
+		"preserve": false,
+		"inline-join": "space",
+		"emphasis-flanking": "word-joiner",
+		"hugo-shortcodes": "none",
+		"liquid-tags": "none",
+		"dialect": "commonmark",
+		"heading-ids": "keep",
+		"whitespace": "preserve",
+		"list-indent-width": 0,
+		"blank-lines": "preserve",
+		"cjk-spacing": "preserve",
+		"footnote-placement": "preserve",
+		"math-blocks": "none",
+		"html-escaping": "backslash",
+		"inline-html": "preserve",
+		"image-attributes": "none",
+		"task-checkbox-case": "lower",
+		"emphasis-style": "asterisk",
+		"strong-style": "asterisk",
+		"line-width": 0,
+		"hard-break-style": "backslash",
+		"link-style": "inline",
+		"text-escaping": "minimal",
+		"table-style": "compact",
+		"target-language": "",
+		"text-transformer": null,
+		"link-transformer": false,
+		"metrics": false,
+		"validation": false,
+		"review-comments": false,
+		"output-template": null,
+		"node-filter": false,
+		"raw-kinds": []
+	}`, string(data))
+
+	config := NewConfig(
+		WithHeadingStyle(HeadingStyleSetext),
+		WithTextTransformer(MapTransformer{}),
+		WithLinkTransformer(func(destination, title string, isImage bool) (string, string) { return destination, title }),
+		WithOutputTemplate(template.Must(template.New("page").Parse("")), nil),
+		WithRawKinds(east.KindStrikethrough),
+	)
+	data, err = json.Marshal(config)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "setext", fields["heading"])
+	assert.Equal(t, "markdown.MapTransformer", fields["text-transformer"])
+	assert.Equal(t, true, fields["link-transformer"])
+	assert.Equal(t, "page", fields["output-template"])
+	assert.Equal(t, []any{"Strikethrough"}, fields["raw-kinds"])
+}
@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// TextSegment is a run of plain text that the Renderer passes to its TextTransformer.
+type TextSegment struct {
+	// Start and Stop are the byte offsets of the text in the source, without surrounding whitespace
+	Start, Stop int
+	// Text is the text as passed to the TextTransformer, without backslash escapes
+	Text string
+}
+
+// TextEdit replaces the text of a TextSegment, e.g. with a correction made by a reviewer.
+type TextEdit struct {
+	// Start and Stop are the byte offsets of the replaced segment in the source
+	Start, Stop int
+	// Text replaces the text of the segment, and is escaped as needed like a translation
+	Text string
+}
+
+// transformPlainText returns the replacement for a run of plain text, from the text hook if set or
+// the TextTransformer otherwise.
+func (r *Renderer) transformPlainText(segment TextSegment) (string, bool) {
+	if r.textHook != nil {
+		return r.textHook(r, segment)
+	}
+	return r.transformText(TextTypePlain, segment.Text)
+}
+
+// TextSegments returns the runs of plain text that r would pass to its TextTransformer when rendering
+// the document doc parsed from source, in document order. The TextTransformer isn't called.
+func (r *Renderer) TextSegments(source []byte, doc ast.Node) ([]TextSegment, error) {
+	var segments []TextSegment
+	err := r.renderWithHook(io.Discard, source, doc, func(_ *Renderer, segment TextSegment) (string, bool) {
+		segments = append(segments, segment)
+		return "", false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// RenderWithEdits renders the document doc parsed from source to w like Render, with the text of the
+// segments in edits replaced. Each edit must cover exactly one of the segments returned by
+// TextSegments, so edits can't change the markup of the document, and an error is returned before
+// anything is written otherwise. Segments without edits are passed to the TextTransformer, if any.
+func (r *Renderer) RenderWithEdits(w io.Writer, source []byte, doc ast.Node, edits []TextEdit) error {
+	segments, err := r.TextSegments(source, doc)
+	if err != nil {
+		return err
+	}
+	if err := validateTextEdits(segments, edits); err != nil {
+		return err
+	}
+	replacements := map[[2]int]string{}
+	for _, edit := range edits {
+		replacements[[2]int{edit.Start, edit.Stop}] = edit.Text
+	}
+	return r.renderWithHook(w, source, doc, func(instance *Renderer, segment TextSegment) (string, bool) {
+		if text, ok := replacements[[2]int{segment.Start, segment.Stop}]; ok {
+			return text, true
+		}
+		if r.config.TextTransformer == nil {
+			return "", false
+		}
+		return instance.transformText(TextTypePlain, segment.Text)
+	})
+}
+
+// validateTextEdits returns an error for the first of edits, in source order, that doesn't cover
+// exactly one of segments or that replaces the same segment as another edit.
+func validateTextEdits(segments []TextSegment, edits []TextEdit) error {
+	bounds := map[[2]int]bool{}
+	for _, segment := range segments {
+		bounds[[2]int{segment.Start, segment.Stop}] = true
+	}
+	sorted := append([]TextEdit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	for i, edit := range sorted {
+		if !bounds[[2]int{edit.Start, edit.Stop}] {
+			return fmt.Errorf("edit of %d:%d doesn't match the bounds of a text segment", edit.Start, edit.Stop)
+		}
+		if i > 0 && sorted[i-1].Start == edit.Start {
+			return fmt.Errorf("edit of %d:%d replaces a segment that's already edited", edit.Start, edit.Stop)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTextSegments(t *testing.T) {
+	source := []byte("# The *Title*\n\n> Some \\*text with a [link](/url) and\n> a `code span`.\n")
+	r := NewRenderer()
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+	segments, err := r.TextSegments(source, doc)
+	require.NoError(t, err)
+	assert.Equal(t, []TextSegment{
+		{Start: 2, Stop: 5, Text: "The"},
+		{Start: 7, Stop: 12, Text: "Title"},
+		{Start: 17, Stop: 35, Text: "Some *text with a"},
+		{Start: 37, Stop: 41, Text: "link"},
+		{Start: 49, Stop: 56, Text: "and\na"},
+		{Start: 68, Stop: 69, Text: "."},
+	}, segments)
+}
+
+func TestRenderWithEdits(t *testing.T) {
+	source := []byte("# Title\n\nSome text with a [link](/url).\n")
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	testCases := []struct {
+		name     string
+		options  []Option
+		edits    []TextEdit
+		expected string
+		err      string
+	}{
+		{
+			"No edits",
+			[]Option{},
+			nil,
+			"# Title\n\nSome text with a [link](/url).\n",
+			"",
+		},
+		{
+			"Edits are escaped",
+			[]Option{},
+			[]TextEdit{{Start: 27, Stop: 31, Text: "*page*"}, {Start: 2, Stop: 7, Text: "Heading"}},
+			"# Heading\n\nSome text with a [\\*page\\*](/url).\n",
+			"",
+		},
+		{
+			"Other segments are transformed",
+			[]Option{WithTextTransformer(MapTransformer{"Title": "Titre", "link": "lien"})},
+			[]TextEdit{{Start: 27, Stop: 31, Text: "page"}},
+			"# Titre\n\nSome text with a [page](/url).\n",
+			"",
+		},
+		{
+			"Edit inside a segment",
+			[]Option{},
+			[]TextEdit{{Start: 14, Stop: 18, Text: "words"}},
+			"",
+			"edit of 14:18 doesn't match the bounds of a text segment",
+		},
+		{
+			"Edit across segments",
+			[]Option{},
+			[]TextEdit{{Start: 9, Stop: 31, Text: "A link"}},
+			"",
+			"edit of 9:31 doesn't match the bounds of a text segment",
+		},
+		{
+			"Duplicate edits",
+			[]Option{},
+			[]TextEdit{{Start: 2, Stop: 7, Text: "One"}, {Start: 2, Stop: 7, Text: "Two"}},
+			"",
+			"edit of 2:7 replaces a segment that's already edited",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := NewRenderer(tc.options...).RenderWithEdits(&buf, source, doc, tc.edits)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
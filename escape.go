@@ -0,0 +1,280 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	// atxHeadingStart matches text that would begin an ATX heading.
+	atxHeadingStart = regexp.MustCompile(`^#{1,6}(?:[ \t]|$)`)
+	// bulletListStart matches text that would begin a bullet list item.
+	bulletListStart = regexp.MustCompile(`^[-+*](?:[ \t]|$)`)
+	// orderedListStart matches text that would begin an ordered list item. The submatch is the
+	// delimiter following the item number.
+	orderedListStart = regexp.MustCompile(`^[0-9]{1,9}([.)])(?:[ \t]|$)`)
+	// lineOnlyStart matches text that would be a thematic break or setext heading underline.
+	lineOnlyStart = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:_[ \t]*){3,}|(?:-[ \t]*){2,}|=+[ \t]*)$`)
+	// codeFenceStart matches text that would open a fenced code block.
+	codeFenceStart = regexp.MustCompile("^(?:```+[^`]*|~~~+.*)$")
+	// tableDelimiterCell matches the content of a cell in a table's delimiter row.
+	tableDelimiterCell = regexp.MustCompile(`^:?-+:?$`)
+	// autoLinkURI matches an absolute URI that can be written as an autolink.
+	autoLinkURI = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\x00-\x20<>]*$`)
+	// autoLinkEmail matches an email address that can be written as an autolink.
+	autoLinkEmail = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?" +
+		"(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+)
+
+// escapeLineStarts escapes the beginning of each line in text that would otherwise be parsed as
+// the start of a block, such as a list item, blockquote, or heading. The first line of text is only
+// considered if atLineStart is true.
+func escapeLineStarts(text []byte, atLineStart bool) []byte {
+	lines := bytes.SplitAfter(text, []byte{lineDelim})
+	result := make([]byte, 0, len(text))
+	for i, line := range lines {
+		if i > 0 || atLineStart {
+			line = escapeLineStart(line)
+		}
+		result = append(result, line...)
+	}
+	return result
+}
+
+// escapeLineStart escapes the beginning of a single line if it would be parsed as a block start.
+func escapeLineStart(line []byte) []byte {
+	// Up to three spaces of indentation don't prevent a block from starting
+	indent := len(line) - len(bytes.TrimLeft(line, " "))
+	if indent > 3 {
+		return line
+	}
+	content := bytes.TrimRight(line[indent:], "\n")
+	escapeAt := -1
+	switch {
+	case len(content) == 0:
+		return line
+	case content[0] == '>',
+		atxHeadingStart.Match(content),
+		bulletListStart.Match(content),
+		codeFenceStart.Match(content),
+		lineOnlyStart.Match(content):
+		escapeAt = indent
+	default:
+		if m := orderedListStart.FindSubmatchIndex(content); m != nil {
+			escapeAt = indent + m[2]
+		}
+	}
+	if escapeAt < 0 {
+		return line
+	}
+	escaped := make([]byte, 0, len(line)+1)
+	escaped = append(escaped, line[:escapeAt]...)
+	escaped = append(escaped, '\\')
+	return append(escaped, line[escapeAt:]...)
+}
+
+// escapeInline backslash-escapes characters in text that would otherwise be parsed as inline markup,
+// such as emphasis, code spans, links, or raw HTML. It's used for text that didn't come from the
+// source document, where escapes have to be derived rather than preserved. HTML and character
+// references are escaped as set by escaping.
+func escapeInline(text []byte, escaping HTMLEscaping) []byte {
+	if escaping == HTMLEscapingCode {
+		return escapeInlineHTMLAsCode(text)
+	}
+	result := make([]byte, 0, len(text))
+	for i, c := range text {
+		var next byte
+		if i+1 < len(text) {
+			next = text[i+1]
+		}
+		switch c {
+		case '*', '_', '`', '[', ']':
+			result = append(result, '\\')
+		case '\\':
+			// A backslash is only an escape if followed by punctuation
+			if util.IsPunct(next) {
+				result = append(result, '\\')
+			}
+		case '<':
+			if escaping == HTMLEscapingEntities {
+				result = append(result, "&lt;"...)
+				continue
+			}
+			// Only escape what could be the start of raw HTML or an autolink
+			if util.IsAlphaNumeric(next) || next == '/' || next == '!' || next == '?' {
+				result = append(result, '\\')
+			}
+		case '>':
+			if escaping == HTMLEscapingEntities {
+				result = append(result, "&gt;"...)
+				continue
+			}
+		case '&':
+			if escaping == HTMLEscapingEntities {
+				result = append(result, "&amp;"...)
+				continue
+			}
+			// Only escape what would be read as a character reference
+			if characterReference.Match(text[i:]) {
+				result = append(result, '\\')
+			}
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// escapeText backslash-escapes the characters in text from the source that could be read as inline
+// markup, as set by escaping. before is the character written before text, and after the one that
+// follows it, or 0 if it's not known yet. Escapes that are already in text are kept as they are, as
+// are character references, which the source uses on purpose.
+func escapeText(text []byte, before, after rune, escaping TextEscaping) []byte {
+	if escaping == TextEscapingMinimal {
+		return text
+	}
+	result := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && util.IsPunct(text[i+1]):
+			result = append(result, c, text[i+1])
+			i++
+			continue
+		case c == '*' || c == '_' || c == '~':
+			// Flanking is decided for the whole run of delimiters
+			stop := i
+			for stop < len(text) && text[stop] == c {
+				stop++
+			}
+			escape := escaping == TextEscapingAggressive
+			if !escape {
+				prev, next := before, after
+				if i > 0 {
+					prev = lastRune(text[:i])
+				}
+				if stop < len(text) {
+					next = firstRune(text[stop:])
+				}
+				// Unknown neighbors could be either a word or punctuation
+				escape = next == 0 && (canDelimit(c, prev, 'a') || canDelimit(c, prev, '.')) ||
+					next != 0 && canDelimit(c, prev, next)
+			}
+			for ; i < stop; i++ {
+				if escape {
+					result = append(result, '\\')
+				}
+				result = append(result, c)
+			}
+			i--
+			continue
+		case c == '`' || c == '[':
+			result = append(result, '\\')
+		case c == ']' && escaping == TextEscapingAggressive:
+			result = append(result, '\\')
+		case c == '<':
+			var next byte
+			if i+1 < len(text) {
+				next = text[i+1]
+			}
+			// Only escape what could be the start of raw HTML or an autolink, unless aggressive
+			if escaping == TextEscapingAggressive || util.IsAlphaNumeric(next) || next == '/' || next == '!' ||
+				next == '?' {
+				result = append(result, '\\')
+			}
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// canDelimit returns true if a run of the delimiter character c between before and after can open or
+// close emphasis or strikethrough. Underscores can't do either within a word.
+func canDelimit(c byte, before, after rune) bool {
+	left, right := isLeftFlanking(before, after), isRightFlanking(before, after)
+	if c == '_' {
+		return left && (!right || util.IsPunctRune(before)) || right && (!left || util.IsPunctRune(after))
+	}
+	return left || right
+}
+
+var (
+	// characterReference matches an HTML entity or numeric character reference at the start of text.
+	characterReference = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	// inlineHTML matches HTML tags, comments and character references that can be written as code
+	// spans.
+	inlineHTML = regexp.MustCompile("</?[A-Za-z][^<>`]*>|<!--[^`]*?-->|&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});")
+)
+
+// escapeInlineHTMLAsCode escapes text like escapeInline, writing the HTML tags, comments and character
+// references in it as code spans, so they're shown as written rather than parsed as HTML.
+func escapeInlineHTMLAsCode(text []byte) []byte {
+	result := make([]byte, 0, len(text))
+	last := 0
+	for _, match := range inlineHTML.FindAllIndex(text, -1) {
+		result = append(result, escapeInline(text[last:match[0]], HTMLEscapingBackslash)...)
+		result = append(result, '`')
+		result = append(result, text[match[0]:match[1]]...)
+		result = append(result, '`')
+		last = match[1]
+	}
+	return append(result, escapeInline(text[last:], HTMLEscapingBackslash)...)
+}
+
+// escapeLinkTitle backslash-escapes the double quotes in a link title that would end the title when
+// enclosed in double quotes. Titles keep the escapes of the source, so quotes that are already
+// escaped are left as-is.
+func escapeLinkTitle(title []byte) []byte {
+	result := make([]byte, 0, len(title))
+	for i := 0; i < len(title); i++ {
+		switch {
+		case title[i] == '\\' && i+1 < len(title) && util.IsPunct(title[i+1]):
+			result = append(result, title[i])
+			i++
+		case title[i] == '"':
+			result = append(result, '\\')
+		}
+		result = append(result, title[i])
+	}
+	return result
+}
+
+// isAutoLink returns true if destination can be written as an autolink, which requires an absolute
+// URI without spaces or angle brackets, or an email address for email autolinks.
+func isAutoLink(destination []byte, email bool) bool {
+	if email {
+		return autoLinkEmail.Match(destination)
+	}
+	return autoLinkURI.Match(destination)
+}
+
+// escapeTableCell escapes the pipes in rendered table cell content that would otherwise end the
+// cell. Pipes inside code spans and link destinations need escaping too, as cells are split before
+// inline content is parsed.
+func escapeTableCell(cell []byte) []byte {
+	result := make([]byte, 0, len(cell))
+	backslashes := 0
+	for _, c := range cell {
+		if c == '|' && backslashes%2 == 0 {
+			result = append(result, '\\')
+		}
+		if c == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// isTableDelimiterRow returns true if every cell in the row looks like the cell of a delimiter row.
+func isTableDelimiterRow(cells [][]byte) bool {
+	for _, cell := range cells {
+		if !tableDelimiterCell.Match(cell) {
+			return false
+		}
+	}
+	return len(cells) > 0
+}
@@ -0,0 +1,284 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestEscapeLineStarts(t *testing.T) {
+	testCases := []struct {
+		name        string
+		text        string
+		atLineStart bool
+		expected    string
+	}{
+		{"Plain text", "foo\nbar", true, "foo\nbar"},
+		{"Bullet", "- foo", true, "\\- foo"},
+		{"Plus bullet", "+ foo", true, "\\+ foo"},
+		{"Star bullet", "* foo", true, "\\* foo"},
+		{"Hyphenated word", "-foo", true, "-foo"},
+		{"Ordered", "1. foo", true, "1\\. foo"},
+		{"Ordered paren", "12) foo", true, "12\\) foo"},
+		{"Number in prose", "1.5 million", true, "1.5 million"},
+		{"Blockquote", ">foo", true, "\\>foo"},
+		{"Heading", "# foo", true, "\\# foo"},
+		{"Hashtag", "#foo", true, "#foo"},
+		{"Too many hashes", "####### foo", true, "####### foo"},
+		{"Thematic break", "***", true, "\\***"},
+		{"Setext underline", "foo\n===", true, "foo\n\\==="},
+		{"Backtick fence", "``` foo", true, "\\``` foo"},
+		{"Tilde fence", "~~~", true, "\\~~~"},
+		{"Code span", "``` foo ```", true, "``` foo ```"},
+		{"Indented", "  - foo", true, "  \\- foo"},
+		{"Not at line start", "- foo\n- bar", false, "- foo\n\\- bar"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(escapeLineStarts([]byte(tc.text), tc.atLineStart)))
+		})
+	}
+}
+
+func TestEscapeInline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Plain text", "foo bar.", "foo bar."},
+		{"Emphasis", "*foo* _bar_", "\\*foo\\* \\_bar\\_"},
+		{"Code", "`foo`", "\\`foo\\`"},
+		{"Link", "[foo](bar)", "\\[foo\\](bar)"},
+		{"HTML", "<div> a < b", "\\<div> a < b"},
+		{"Character references", "&amp; &#35; &#x23; & a&lt;b", "\\&amp; \\&#35; \\&#x23; & a\\&lt;b"},
+		{"Literal backslash", "C:\\path", "C:\\path"},
+		{"Backslash before punctuation", "\\.", "\\\\."},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(escapeInline([]byte(tc.text), HTMLEscapingBackslash)))
+		})
+	}
+}
+
+// TestTranslatedEscapes tests that escaped punctuation keeps its meaning through translation
+func TestTranslatedEscapes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		source       string
+		translations map[string]string
+		expected     string
+	}{
+		{
+			"Untranslated escapes are preserved",
+			"\\*not emphasis\\*",
+			map[string]string{},
+			"\\*not emphasis\\*\n",
+		},
+		{
+			"Transformer receives unescaped text",
+			"\\*not emphasis\\*",
+			map[string]string{"*not emphasis*": "*pas d'emphase*"},
+			"\\*pas d'emphase\\*\n",
+		},
+		{
+			"Escapes in translated link text",
+			"[\\[note\\]](/uri)",
+			map[string]string{"[note]": "[remarque]"},
+			"[\\[remarque\\]](/uri)\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(
+				WithTextTransformer(MapTransformer(tc.translations)),
+			)))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+// TestHTMLEscaping tests that HTML and character references in translations are read back as text
+func TestHTMLEscaping(t *testing.T) {
+	source := "Press Enter & wait <b>here</b>\n"
+	translations := map[string]string{"Press Enter & wait": "Press <kbd>Enter</kbd> &amp; wait > 1s"}
+	testCases := []struct {
+		name     string
+		escaping HTMLEscaping
+		expected string
+	}{
+		{
+			"Backslash",
+			HTMLEscapingBackslash,
+			"Press \\<kbd>Enter\\</kbd> \\&amp; wait > 1s <b>here</b>\n",
+		},
+		{
+			"Entities",
+			HTMLEscapingEntities,
+			"Press &lt;kbd&gt;Enter&lt;/kbd&gt; &amp;amp; wait &gt; 1s <b>here</b>\n",
+		},
+		{
+			"Code",
+			HTMLEscapingCode,
+			"Press `<kbd>`Enter`</kbd>` `&amp;` wait > 1s <b>here</b>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(
+				WithTextTransformer(MapTransformer(translations)),
+				WithHTMLEscaping(tc.escaping),
+			)))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+
+			// The only HTML of the output is the HTML of the source
+			doc := md.Parser().Parse(text.NewReader(buf.Bytes()))
+			assert.Len(t, FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindRawHTML }), 2)
+		})
+	}
+}
+
+// TestEscapeLineStartsRendered tests that translated text can't change the structure of a document
+func TestEscapeLineStartsRendered(t *testing.T) {
+	testCases := []struct {
+		name         string
+		source       string
+		translations map[string]string
+		expected     string
+	}{
+		{
+			"Translation beginning with list marker",
+			"Hello",
+			map[string]string{"Hello": "- Hello"},
+			"\\- Hello\n",
+		},
+		{
+			"Translation with heading on second line",
+			"Hello",
+			map[string]string{"Hello": "Hello\n# World"},
+			"Hello\n\\# World\n",
+		},
+		{
+			"Translation after inline",
+			"*a* b",
+			map[string]string{"b": "> b"},
+			"*a* > b\n",
+		},
+		{
+			"Ordered number not starting at one",
+			"foo\n2. bar",
+			map[string]string{},
+			"foo\n2\\. bar\n",
+		},
+		{
+			"Code span content is not escaped",
+			"`foo\n- bar`",
+			map[string]string{},
+			"`foo\n- bar`\n",
+		},
+		{
+			"List item content",
+			"- \\- foo",
+			map[string]string{},
+			"- \\- foo\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(
+				WithTextTransformer(MapTransformer(tc.translations)),
+			)))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestTextEscaping(t *testing.T) {
+	testCases := []struct {
+		name       string
+		source     string
+		smart      string
+		aggressive string
+	}{
+		{
+			"Emphasis delimiters",
+			"2 * 3 x* snake_case *a*\n",
+			"2 * 3 x\\* snake_case *a*\n",
+			"2 \\* 3 x\\* snake\\_case *a*\n",
+		},
+		{
+			"Brackets and HTML",
+			"[a] <b a < b\n",
+			"\\[a] \\<b a < b\n",
+			"\\[a\\] \\<b a \\< b\n",
+		},
+		{
+			"Strikethrough delimiters",
+			"~a~ b ~ c\n",
+			"\\~a\\~ b ~ c\n",
+			"\\~a\\~ b \\~ c\n",
+		},
+		{
+			"Escapes and references",
+			"\\*a\\* \\[b] &amp; `c`\n",
+			"\\*a\\* \\[b] &amp; `c`\n",
+			"\\*a\\* \\[b\\] &amp; `c`\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for escaping, expected := range map[TextEscaping]string{
+				TextEscapingMinimal:    tc.source,
+				TextEscapingSmart:      tc.smart,
+				TextEscapingAggressive: tc.aggressive,
+			} {
+				md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithTextEscaping(escaping))))
+				buf := bytes.Buffer{}
+				require.NoError(t, md.Convert([]byte(tc.source), &buf))
+				assert.Equal(t, expected, buf.String())
+
+				// The output is read the same as the source
+				html, roundTrip := bytes.Buffer{}, bytes.Buffer{}
+				require.NoError(t, goldmark.Convert([]byte(tc.source), &html))
+				require.NoError(t, goldmark.Convert(buf.Bytes(), &roundTrip))
+				assert.Equal(t, html.String(), roundTrip.String())
+			}
+		})
+	}
+}
+
+// TestTextEscapingMovedText tests that text moved next to other text is still read as text
+func TestTextEscapingMovedText(t *testing.T) {
+	source := []byte("*a\n\nb*\n")
+	for escaping, emphasis := range map[TextEscaping]int{
+		TextEscapingMinimal:    1,
+		TextEscapingSmart:      0,
+		TextEscapingAggressive: 0,
+	} {
+		md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithTextEscaping(escaping))))
+		doc := md.Parser().Parse(text.NewReader(source))
+		first, second := doc.FirstChild(), doc.LastChild()
+		for c := second.FirstChild(); c != nil; c = second.FirstChild() {
+			first.AppendChild(first, c)
+		}
+		doc.RemoveChild(doc, second)
+
+		buf := bytes.Buffer{}
+		require.NoError(t, md.Renderer().Render(&buf, source, doc))
+		output := md.Parser().Parse(text.NewReader(buf.Bytes()))
+		assert.Len(t, FindAll(output, func(n ast.Node) bool { return n.Kind() == ast.KindEmphasis }), emphasis,
+			buf.String())
+	}
+}
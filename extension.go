@@ -0,0 +1,115 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
+)
+
+// Extend implements goldmark.Extender.Extend, adding the parsers and transformers of the syntax that
+// the configuration of the Renderer asks for, along with those of tables and alerts, which are always
+// added.
+func (r *Renderer) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithParagraphTransformers(
+			util.Prioritized(extension.NewTableParagraphTransformer(), 200),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(extension.NewTableASTTransformer(), 0),
+			util.Prioritized(NewAlertTransformer(), 100),
+		),
+	)
+	if r.config.HugoShortcodes != HugoShortcodesNone {
+		m.Parser().AddOptions(
+			parser.WithBlockParsers(util.Prioritized(NewShortcodeBlockParser(), 800)),
+			parser.WithInlineParsers(util.Prioritized(NewShortcodeParser(), 100)),
+		)
+	}
+	if r.config.LiquidTags != LiquidTagsNone {
+		m.Parser().AddOptions(
+			parser.WithBlockParsers(util.Prioritized(NewLiquidBlockParser(), 810)),
+			parser.WithInlineParsers(util.Prioritized(NewLiquidTagParser(), 110)),
+		)
+	}
+	if r.config.MathBlocks != MathBlocksNone {
+		m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(NewMathBlockParser(), 830)))
+	}
+	switch r.config.Dialect {
+	case DialectMkDocs:
+		m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(NewAdmonitionParser(), 820)))
+	case DialectPandoc:
+		m.Parser().AddOptions(
+			parser.WithBlockParsers(util.Prioritized(NewFencedDivParser(), 820)),
+			// Spans are parsed before links, which they look like until their attributes
+			parser.WithInlineParsers(util.Prioritized(NewSpanParser(), 190)),
+		)
+	}
+	if r.config.Dialect != DialectCommonMark {
+		m.Parser().AddOptions(
+			parser.WithBlockParsers(util.Prioritized(extension.NewFootnoteBlockParser(), 999)),
+			parser.WithInlineParsers(
+				util.Prioritized(NewAttributeListParser(), 120),
+				util.Prioritized(extension.NewFootnoteParser(), 101),
+			),
+		)
+	}
+	if r.config.ImageAttributes == ImageAttributesPreserve {
+		m.Parser().AddOptions(parser.WithInlineParsers(
+			util.Prioritized(NewImageSizeParser(), 195),
+			util.Prioritized(NewImageAttributeListParser(), 120),
+		))
+	}
+	if r.config.HeadingIDs != HeadingIDsKeep {
+		// The ids written or stripped are read back as attributes rather than as heading text
+		m.Parser().AddOptions(parser.WithHeadingAttribute())
+	}
+	if r.config.InlineHTML == InlineHTMLConvert {
+		m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(NewInlineHTMLTransformer(), 60)))
+	}
+	if r.config.LinkStyle == LinkStylePreserve {
+		m.Parser().AddOptions(parser.WithParagraphTransformers(
+			util.Prioritized(NewLinkReferenceDefinitionsTransformer(), 90),
+		))
+	}
+	if r.config.FootnotePlacement != FootnotePlacementPreserve {
+		m.Parser().AddOptions(parser.WithASTTransformers(
+			util.Prioritized(NewFootnotePlacementTransformer(r.config.FootnotePlacement), 50),
+		))
+	}
+}
+
+type markdownExtension struct {
+	renderer *Renderer
+}
+
+// NewExtension returns a goldmark.Extender that sets a Renderer configured with options as the
+// renderer of goldmark.Markdown, along with the parsers of the syntax it supports: tables,
+// strikethrough, task lists, footnotes and front matter, in addition to those of Renderer.Extend. It
+// replaces passing the Renderer to both goldmark.WithRenderer and goldmark.WithExtensions:
+//
+//	md := goldmark.New(goldmark.WithExtensions(markdown.NewExtension(
+//		markdown.WithBulletMarker(markdown.BulletMarkerDash),
+//	)))
+func NewExtension(options ...Option) goldmark.Extender {
+	return &markdownExtension{renderer: NewRenderer(options...)}
+}
+
+// Extend implements goldmark.Extender.Extend
+func (e *markdownExtension) Extend(m goldmark.Markdown) {
+	m.SetRenderer(e.renderer)
+	e.renderer.Extend(m)
+	// The HTML renderers of the extensions are left out by the Renderer
+	extension.TaskList.Extend(m)
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(NewFrontMatterParser(), 90)),
+		parser.WithInlineParsers(util.Prioritized(extension.NewStrikethroughParser(), 500)),
+	)
+	// Other dialects parse footnotes already
+	if e.renderer.config.Dialect == DialectCommonMark {
+		m.Parser().AddOptions(
+			parser.WithBlockParsers(util.Prioritized(extension.NewFootnoteBlockParser(), 999)),
+			parser.WithInlineParsers(util.Prioritized(extension.NewFootnoteParser(), 101)),
+		)
+	}
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestExtension(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  []Option
+		source   string
+		expected string
+	}{
+		{
+			"Front matter",
+			nil,
+			"---\ntitle: Guide\n---\n# Guide\n",
+			"---\ntitle: Guide\n---\n# Guide\n",
+		},
+		{
+			"TOML front matter",
+			nil,
+			"+++\ntitle = \"Guide\"\n+++\n\nText\n",
+			"+++\ntitle = \"Guide\"\n+++\n\nText\n",
+		},
+		{
+			"Thematic break",
+			nil,
+			"Text\n\n---\n\nMore\n",
+			"Text\n\n---\n\nMore\n",
+		},
+		{
+			"GFM",
+			[]Option{WithBulletMarker(BulletMarkerDash)},
+			"* [x] ~~done~~\n* [ ] todo[^1]\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n[^1]: Note.\n",
+			"- [x] ~~done~~\n- [ ] todo[^1]\n\n| a | b |\n| --- | --- |\n| 1 | 2 |\n\n[^1]: Note.\n",
+		},
+		{
+			"Dialect",
+			[]Option{WithDialect(DialectMkDocs)},
+			"!!! note\n    Text[^1] ~~old~~.\n\n[^1]: Note.\n",
+			"!!! note\n    Text[^1] ~~old~~.\n\n[^1]: Note.\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithExtensions(NewExtension(tc.options...)))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestFrontMatterNode(t *testing.T) {
+	source := []byte("---\ntitle: Guide\n---\n\n---\n")
+	md := goldmark.New(goldmark.WithExtensions(NewExtension()))
+	doc := md.Parser().Parse(text.NewReader(source))
+	require.Equal(t, 2, doc.ChildCount())
+	assert.Equal(t, KindFrontMatter, doc.FirstChild().Kind())
+	assert.Equal(t, 3, doc.FirstChild().Lines().Len())
+}
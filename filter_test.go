@@ -0,0 +1,77 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+)
+
+func TestNodeFilter(t *testing.T) {
+	withoutKinds := func(kinds ...ast.NodeKind) NodeFilter {
+		return func(node ast.Node) bool {
+			for _, kind := range kinds {
+				if node.Kind() == kind {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	testCases := []struct {
+		name     string
+		filter   NodeFilter
+		source   string
+		expected string
+	}{
+		{
+			"HTML blocks",
+			withoutKinds(ast.KindHTMLBlock),
+			"# A\n\n<div>\nx\n</div>\n\nB\n<!-- note -->\n",
+			"# A\n\nB\n",
+		},
+		{
+			"Leading block",
+			withoutKinds(ast.KindHTMLBlock),
+			"<div>\nx\n</div>\n\nB\n",
+			"B\n",
+		},
+		{
+			"Subtree",
+			withoutKinds(ast.KindBlockquote),
+			"a\n\n> b\n>\n> - c\n\nd\n",
+			"a\n\nd\n",
+		},
+		{
+			"Inlines",
+			withoutKinds(ast.KindRawHTML, ast.KindImage),
+			"a <b>bold</b> ![c](c.png) d\n",
+			"a bold  d\n",
+		},
+		{
+			"List items",
+			func(node ast.Node) bool {
+				item, ok := node.(*ast.ListItem)
+				return !ok || item.PreviousSibling() == nil
+			},
+			"- a\n- b\n- c\n\nd\n",
+			"- a\n\nd\n",
+		},
+		{
+			"Nothing left",
+			withoutKinds(ast.KindParagraph),
+			"a\n\nb\n",
+			"",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithNodeFilter(tc.filter))))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package markdown
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// wordJoiner is an invisible character that is neither whitespace nor punctuation, so it can stand
+// between an emphasis delimiter and punctuation to keep the delimiter flanking.
+var wordJoiner = []byte("\u2060")
+
+// emphasisDelimiters holds the positions of the delimiters of an emphasis node.
+type emphasisDelimiters struct {
+	// opening and closing are the positions of the opening and closing delimiters
+	opening, closing writerPosition
+	// level is the emphasis level, which is also the length of each delimiter
+	level int
+	// char is the delimiter character
+	char byte
+}
+
+// isLeftFlanking returns true if a delimiter run between before and after can open emphasis.
+func isLeftFlanking(before, after rune) bool {
+	return !util.IsSpaceRune(after) &&
+		(!util.IsPunctRune(after) || util.IsSpaceRune(before) || util.IsPunctRune(before))
+}
+
+// isRightFlanking returns true if a delimiter run between before and after can close emphasis.
+func isRightFlanking(before, after rune) bool {
+	return !util.IsSpaceRune(before) &&
+		(!util.IsPunctRune(before) || util.IsSpaceRune(after) || util.IsPunctRune(after))
+}
+
+// trimDelimiters trims delimiter characters from both ends of b, since flanking is decided for a
+// whole run of adjacent delimiters.
+func trimDelimiters(b []byte, char byte) []byte {
+	return bytes.Trim(b, string(char))
+}
+
+// emphasisDelimiter returns the delimiter character for emphasis, which is the configured one unless
+// the delimiter run would merge with that of a neighboring emphasis and be read differently, such as
+// in `*_a_*` or `*a*_b_`, or underscores would be within a word, such as in `a*b*c`.
+func (r *Renderer) emphasisDelimiter(n *ast.Emphasis) byte {
+	delimiter := r.config.EmphasisStyle.Delimiter()
+	if n.Level > 1 {
+		delimiter = r.config.StrongStyle.Delimiter()
+	}
+	if delimiter == 0 {
+		delimiter = sourceEmphasisDelimiter(n, r.rc.source)
+	}
+	other := byte('_')
+	if delimiter == '_' {
+		other = '*'
+		if isIntraword(n, r.rc.source) {
+			delimiter = '*'
+		}
+	}
+	if parent, ok := n.Parent().(*ast.Emphasis); ok && n.PreviousSibling() == nil && n.NextSibling() == nil {
+		// `**a**` is read as level 2 emphasis, and `***a***` as level 1 emphasis around level 2
+		// emphasis, so level 1 emphasis can't be nested directly with the same delimiter
+		if n.Level == 1 && r.emphasisDelimiter(parent) == delimiter {
+			return other
+		}
+	}
+	if prev, ok := n.PreviousSibling().(*ast.Emphasis); ok && r.emphasisDelimiter(prev) == delimiter {
+		return other
+	}
+	return delimiter
+}
+
+// sourceEmphasisDelimiter returns the delimiter character emphasis is written with in the source, or
+// '*' if it can't be found.
+func sourceEmphasisDelimiter(n *ast.Emphasis, source []byte) byte {
+	start, _, ok := sourceRange(n)
+	// The content starts after the opening delimiters of the emphasis nested at its start
+	start -= n.Level
+	for c, nested := n.FirstChild().(*ast.Emphasis); nested; c, nested = c.FirstChild().(*ast.Emphasis) {
+		start -= c.Level
+	}
+	if ok && start >= 0 && start < len(source) && (source[start] == '*' || source[start] == '_') {
+		return source[start]
+	}
+	return '*'
+}
+
+// isIntraword returns true if emphasis is preceded or followed by a letter or digit in the source,
+// where underscores can't open or close it.
+func isIntraword(n *ast.Emphasis, source []byte) bool {
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }
+	if prev, ok := n.PreviousSibling().(*ast.Text); ok && isWord(lastRune(prev.Segment.Value(source))) {
+		return true
+	}
+	next, ok := n.NextSibling().(*ast.Text)
+	return ok && isWord(firstRune(next.Segment.Value(source)))
+}
+
+// lastRune returns the last rune of b, or a newline if b is empty as that's where a line begins.
+func lastRune(b []byte) rune {
+	if len(b) == 0 {
+		return rune(lineDelim)
+	}
+	r, _ := utf8.DecodeLastRune(b)
+	return r
+}
+
+// firstRune returns the first rune of b, or a newline if b is empty as that's where a line ends.
+func firstRune(b []byte) rune {
+	if len(b) == 0 {
+		return rune(lineDelim)
+	}
+	r, _ := utf8.DecodeRune(b)
+	return r
+}
+
+// checkEmphasis is called after the closing delimiter of emphasis is written. It keeps the opening
+// delimiter able to open the emphasis, and checks the closing delimiter once the text following it
+// is written.
+func (r *Renderer) checkEmphasis(d emphasisDelimiters) {
+	if r.config.EmphasisFlanking == EmphasisFlankingNone {
+		return
+	}
+	r.resolveEmphasis(nil)
+	if before, after, ok := r.rc.writer.Buffer(d.opening); ok {
+		if !isLeftFlanking(lastRune(trimDelimiters(before, d.char)), firstRune(trimDelimiters(after, d.char))) {
+			if r.fixEmphasis(d, true) {
+				return
+			}
+			// The closing delimiter moved along with the text inserted before it
+			d.closing.offset += len(wordJoiner)
+		}
+	}
+	if before, _, ok := r.rc.writer.Buffer(d.closing); ok && util.IsPunctRune(lastRune(trimDelimiters(before, d.char))) {
+		// Whether punctuation can precede the closing delimiter depends on what follows it
+		r.rc.pendingEmphasis = &d
+	}
+}
+
+// resolveEmphasis checks that the pending closing delimiter, if any, can close its emphasis now
+// that next is about to be written after it.
+func (r *Renderer) resolveEmphasis(next []byte) {
+	d := r.rc.pendingEmphasis
+	if d == nil {
+		return
+	}
+	r.rc.pendingEmphasis = nil
+	before, after, ok := r.rc.writer.Buffer(d.closing)
+	if !ok {
+		return
+	}
+	after = trimDelimiters(after, d.char)
+	if len(after) == 0 {
+		after = trimDelimiters(next, d.char)
+	}
+	if !isRightFlanking(lastRune(trimDelimiters(before, d.char)), firstRune(after)) {
+		r.fixEmphasis(*d, false)
+	}
+}
+
+// fixEmphasis rewrites emphasis whose opening or closing delimiter can't be read as such. It returns
+// true if both delimiters were replaced.
+func (r *Renderer) fixEmphasis(d emphasisDelimiters, opening bool) bool {
+	_, _, openingInLine := r.rc.writer.Buffer(d.opening)
+	if r.config.EmphasisFlanking == EmphasisFlankingHTML && openingInLine {
+		tag := [...]string{"", "em", "strong"}[min(d.level, 2)]
+		// Replace the closing delimiter first so the opening delimiter's offset stays valid
+		r.rc.writer.ReplaceAt(d.closing.offset, d.level, []byte("</"+tag+">"))
+		r.rc.writer.ReplaceAt(d.opening.offset, d.level, []byte("<"+tag+">"))
+		return true
+	}
+	if opening {
+		r.rc.writer.InsertAt(d.opening.offset+d.level, wordJoiner)
+	} else {
+		r.rc.writer.InsertAt(d.closing.offset, wordJoiner)
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// TestEmphasisFlanking tests that translated emphasis is still read as emphasis when punctuation ends
+// up next to its delimiters
+func TestEmphasisFlanking(t *testing.T) {
+	translations := MapTransformer(map[string]string{
+		"This is": "这是",
+		"bold.":   "粗体。",
+		"text":    "文本",
+		"Say":     "说",
+		"quote":   "「引用」",
+		"now":     "现在",
+	})
+	source := "This is **bold.** text\n\nSay *quote* now"
+	tests := []struct {
+		name     string
+		flanking EmphasisFlanking
+		expected string
+	}{
+		{
+			"word joiner",
+			EmphasisFlankingWordJoiner,
+			"这是**粗体。\u2060**文本\n\n说*\u2060「引用」\u2060*现在\n",
+		},
+		{
+			"html",
+			EmphasisFlankingHTML,
+			"这是<strong>粗体。</strong>文本\n\n说<em>「引用」</em>现在\n",
+		},
+		{
+			"none",
+			EmphasisFlankingNone,
+			"这是**粗体。**文本\n\n说*「引用」*现在\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(
+				WithTextTransformer(translations),
+				WithInlineJoin(InlineJoinNone),
+				WithEmphasisFlanking(tt.flanking),
+			)))
+			var buf bytes.Buffer
+			assert.NoError(md.Convert([]byte(source), &buf))
+			assert.Equal(tt.expected, buf.String())
+
+			if tt.flanking == EmphasisFlankingWordJoiner {
+				// The output must still parse as emphasis
+				emphasis := 0
+				doc := md.Parser().Parse(text.NewReader(buf.Bytes()))
+				_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+					if entering && n.Kind() == ast.KindEmphasis {
+						emphasis++
+					}
+					return ast.WalkContinue, nil
+				})
+				assert.Equal(2, emphasis)
+			}
+		})
+	}
+}
+
+// TestEmphasisFlankingUntouched tests that emphasis that is already read correctly is left alone
+func TestEmphasisFlankingUntouched(t *testing.T) {
+	source := "**bold.** text, *a*b, (*c*), ***d.***, foo***bar***baz"
+	var buf bytes.Buffer
+	assert.NoError(t, goldmark.New(goldmark.WithRenderer(NewRenderer())).Convert([]byte(source), &buf))
+	assert.Equal(t, source+"\n", buf.String())
+}
+
+func TestEmphasisStyle(t *testing.T) {
+	source := []byte("*a* _b_ **c** __d__ *__e__* x*y*z ***f***\n")
+	testCases := []struct {
+		name     string
+		options  []Option
+		expected string
+	}{
+		{
+			"Asterisk",
+			[]Option{},
+			"*a* *b* **c** **d** ***e*** x*y*z ***f***\n",
+		},
+		{
+			"Underscore",
+			[]Option{WithEmphasisStyle(EmphasisStyleUnderscore), WithStrongStyle(StrongStyleUnderscore)},
+			"_a_ _b_ __c__ __d__ ___e___ x*y*z ___f___\n",
+		},
+		{
+			"Mixed",
+			[]Option{WithEmphasisStyle(EmphasisStyleUnderscore)},
+			"_a_ _b_ **c** **d** _**e**_ x*y*z _**f**_\n",
+		},
+		{
+			"Preserve",
+			[]Option{WithEmphasisStyle(EmphasisStylePreserve), WithStrongStyle(StrongStylePreserve)},
+			"*a* _b_ **c** __d__ *__e__* x*y*z ***f***\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(tc.options...)))
+			assert.NoError(t, md.Convert(source, &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// renderFootnote renders a footnote definition, with the lines after the first indented by 4 spaces.
+// The parser gathers the definitions into a FootnoteList where the first of them is.
+func (r *Renderer) renderFootnote(node ast.Node, entering bool) ast.WalkStatus {
+	n := node.(*east.Footnote)
+	if entering {
+		label := append(append([]byte("[^"), n.Ref...), "]: "...)
+		r.rc.writer.PushPrefix(label, 0, 0)
+		r.rc.writer.PushPrefix([]byte("    "), 1)
+	} else {
+		// Empty definitions have no content to write the label, so end the line explicitly
+		if !node.HasChildren() {
+			r.rc.writer.EndLine()
+		}
+		r.rc.writer.PopPrefix()
+		r.rc.writer.PopPrefix()
+	}
+	return ast.WalkContinue
+}
+
+func (r *Renderer) renderFootnoteLink(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := node.(*east.FootnoteLink)
+		r.rc.writer.WriteBytes([]byte("[^"))
+		r.rc.writer.WriteBytes(r.footnoteRef(n))
+		r.rc.writer.WriteBytes([]byte("]"))
+		r.rc.lastRune = ']'
+	}
+	return ast.WalkContinue
+}
+
+// renderFootnoteBacklink skips the backlinks that goldmark's footnote extension adds to definitions
+// for HTML output.
+func (r *Renderer) renderFootnoteBacklink(node ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+// footnoteRef returns the label of the footnote that link refers to. Links only hold the index of
+// the footnote, so the labels of the document's footnotes are looked up on first use.
+func (r *Renderer) footnoteRef(link *east.FootnoteLink) []byte {
+	if r.rc.footnoteRefs == nil {
+		r.rc.footnoteRefs = map[int][]byte{}
+		root := ast.Node(link)
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+		for _, n := range FindAll(root, func(n ast.Node) bool { return n.Kind() == east.KindFootnote }) {
+			footnote := n.(*east.Footnote)
+			r.rc.footnoteRefs[footnote.Index] = footnote.Ref
+		}
+	}
+	return r.rc.footnoteRefs[link.Index]
+}
+
+type footnotePlacementTransformer struct {
+	placement FootnotePlacement
+}
+
+// NewFootnotePlacementTransformer returns a parser.ASTTransformer that moves footnote definitions to
+// where placement puts them. The Renderer adds it to the parser in Extend.
+func NewFootnotePlacementTransformer(placement FootnotePlacement) parser.ASTTransformer {
+	return &footnotePlacementTransformer{placement}
+}
+
+// Transform implements parser.ASTTransformer.Transform
+func (t *footnotePlacementTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	if t.placement == FootnotePlacementPreserve {
+		return
+	}
+	var footnotes []*east.Footnote
+	byIndex := map[int]*east.Footnote{}
+	for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == east.KindFootnote }) {
+		footnote := n.(*east.Footnote)
+		footnotes = append(footnotes, footnote)
+		byIndex[footnote.Index] = footnote
+	}
+	if len(footnotes) == 0 {
+		return
+	}
+	for _, list := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == east.KindFootnoteList }) {
+		list.Parent().RemoveChild(list.Parent(), list)
+	}
+
+	// Footnotes are grouped in the order of their first references, followed by the footnotes
+	// referenced from them
+	var group []*east.Footnote
+	placed := map[*east.Footnote]bool{}
+	var add func(n ast.Node)
+	add = func(n ast.Node) {
+		for _, link := range FindAll(n, func(n ast.Node) bool { return n.Kind() == east.KindFootnoteLink }) {
+			footnote := byIndex[link.(*east.FootnoteLink).Index]
+			if footnote != nil && !placed[footnote] {
+				placed[footnote] = true
+				group = append(group, footnote)
+				add(footnote)
+			}
+		}
+	}
+	insert := func(before ast.Node) {
+		if len(group) == 0 {
+			return
+		}
+		list := east.NewFootnoteList()
+		for _, footnote := range group {
+			list.AppendChild(list, footnote)
+		}
+		list.Count = len(group)
+		group[0].SetBlankPreviousLines(true)
+		if before != nil {
+			doc.InsertBefore(doc, before, list)
+			before.SetBlankPreviousLines(true)
+		} else {
+			doc.AppendChild(doc, list)
+		}
+		group = nil
+	}
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		// Sections end at the next heading of the document
+		if t.placement == FootnotePlacementSection && c.Kind() == ast.KindHeading {
+			insert(c)
+		}
+		add(c)
+	}
+	// Footnotes that aren't referenced go last, in the order of the source
+	for _, footnote := range footnotes {
+		if !placed[footnote] {
+			group = append(group, footnote)
+		}
+	}
+	insert(nil)
+}
@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+func TestFootnotePlacement(t *testing.T) {
+	source := "# A\n\nText[^b] and[^a].\n\n[^a]: Note a[^n].\n\n# B\n\n[^unused]: Unused.\n\nMore[^c] and[^b].\n\n[^b]: Note b.\n[^c]: Note c.\n[^n]: Nested.\n"
+	testCases := []struct {
+		name      string
+		placement FootnotePlacement
+		expected  string
+	}{
+		{
+			"Preserve",
+			FootnotePlacementPreserve,
+			"# A\n\nText[^b] and[^a].\n\n[^a]: Note a[^n].\n\n[^unused]: Unused.\n\n[^b]: Note b.\n[^c]: Note c.\n[^n]: Nested.\n\n# B\n\nMore[^c] and[^b].\n",
+		},
+		{
+			"Section",
+			FootnotePlacementSection,
+			"# A\n\nText[^b] and[^a].\n\n[^b]: Note b.\n\n[^a]: Note a[^n].\n[^n]: Nested.\n\n# B\n\nMore[^c] and[^b].\n\n[^c]: Note c.\n\n[^unused]: Unused.\n",
+		},
+		{
+			"End",
+			FootnotePlacementEnd,
+			"# A\n\nText[^b] and[^a].\n\n# B\n\nMore[^c] and[^b].\n\n[^b]: Note b.\n\n[^a]: Note a[^n].\n[^n]: Nested.\n[^c]: Note c.\n\n[^unused]: Unused.\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithDialect(DialectPandoc), WithFootnotePlacement(tc.placement))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestFootnotePlacementGFM(t *testing.T) {
+	source := "# A\n\nText[^1].\n\n# B\n\nMore[^2].\n\n[^1]: One.\n[^2]: Two.\n"
+	r := NewRenderer(WithFootnotePlacement(FootnotePlacementSection))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.Footnote, r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, "# A\n\nText[^1].\n\n[^1]: One.\n\n# B\n\nMore[^2].\n\n[^2]: Two.\n", buf.String())
+}
@@ -0,0 +1,317 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Format parses source and renders it with a Renderer configured with options, with the Table and
+// TaskList extensions enabled. YAML or TOML front matter at the start of source is kept verbatim, and
+// the options under its FrontMatterKey, if any, override options.
+func Format(source []byte, options ...Option) ([]byte, error) {
+	frontMatter, body := splitFrontMatter(source)
+	md, err := newFormatter(frontMatter, options)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	buf.Write(frontMatter)
+	if err := md.Convert(body, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatFile reads the file at path and formats it like Format.
+func FormatFile(path string, options ...Option) ([]byte, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Format(source, options...)
+}
+
+// FormatTranslations parses source once and renders it like Format with each of transformers, such
+// as the translations of the document to several languages, returning the outputs by the keys of
+// transformers. The renderings share the parsed document and run concurrently. If any rendering
+// fails, the error of the first key in lexical order is returned.
+func FormatTranslations(source []byte, transformers map[string]TextTransformer, options ...Option) (map[string][]byte, error) {
+	frontMatter, body := splitFrontMatter(source)
+	formatters := make(map[string]goldmark.Markdown, len(transformers))
+	for key, transformer := range transformers {
+		md, err := newFormatter(frontMatter, append(options[:len(options):len(options)], WithTextTransformer(transformer)))
+		if err != nil {
+			return nil, err
+		}
+		formatters[key] = md
+	}
+	md, err := newFormatter(frontMatter, options)
+	if err != nil {
+		return nil, err
+	}
+	// Rendering doesn't modify the document, so it's shared by the renderings
+	doc := md.Parser().Parse(text.NewReader(body))
+
+	outputs := make(map[string][]byte, len(formatters))
+	errs := make(map[string]error, len(formatters))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for key, md := range formatters {
+		wg.Add(1)
+		go func(key string, md goldmark.Markdown) {
+			defer wg.Done()
+			buf := bytes.Buffer{}
+			buf.Write(frontMatter)
+			err := md.Renderer().Render(&buf, body, doc)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[key] = fmt.Errorf("%s: %w", key, err)
+				return
+			}
+			outputs[key] = buf.Bytes()
+		}(key, md)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		keys := make([]string, 0, len(errs))
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, errs[keys[0]]
+	}
+	return outputs, nil
+}
+
+// newFormatter returns the goldmark.Markdown used by Format for a document with the given front
+// matter.
+func newFormatter(frontMatter []byte, options []Option) (goldmark.Markdown, error) {
+	overrides, err := frontMatterOptions(frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRenderer(append(options[:len(options):len(options)], overrides...)...)
+	return goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.TaskList, r)), nil
+}
+
+// FormatRange formats the blocks of source that intersect the lines from start to end, numbered from
+// 1 and inclusive, like Format, and leaves the rest of source unchanged. Only whole top-level blocks
+// are formatted, so a range within a list or blockquote formats all of it, and adjacent lists are
+// formatted together so they stay separate. The separation from the block before the range is
+// formatted along with it. Link reference definitions aren't blocks, so they're left as they are,
+// and source is returned unchanged if the range holds no blocks.
+func FormatRange(source []byte, start, end int, options ...Option) ([]byte, error) {
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("invalid line range %d-%d", start, end)
+	}
+	frontMatter, body := splitFrontMatter(source)
+	// Make the range relative to the body
+	frontMatterLines := bytes.Count(frontMatter, []byte{lineDelim})
+	bodyStart, bodyEnd := start-frontMatterLines, end-frontMatterLines
+
+	md, err := newFormatter(frontMatter, options)
+	if err != nil {
+		return nil, err
+	}
+	doc := md.Parser().Parse(text.NewReader(body))
+	blocks := formatBlocks(body, doc)
+	first, last := -1, -1
+	for i, b := range blocks {
+		if b.startLine <= bodyEnd && b.endLine >= bodyStart {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return source, nil
+	}
+	// Lists that follow each other would be merged if formatted with the same marker
+	for first > 0 && blocks[first].nodes[0].Kind() == ast.KindList && blocks[first-1].lastNode().Kind() == ast.KindList {
+		first--
+	}
+	for last < len(blocks)-1 && blocks[last].lastNode().Kind() == ast.KindList && blocks[last+1].nodes[0].Kind() == ast.KindList {
+		last++
+	}
+
+	// The block before the range is rendered along with it, so the range is separated from it and
+	// continues from it as in a full rendering, and then cut from the output
+	var nodes []ast.Node
+	for _, b := range blocks[first : last+1] {
+		nodes = append(nodes, b.nodes...)
+	}
+	spliceStart := 0
+	if first > 0 {
+		spliceStart = blocks[first-1].stop
+	}
+	// Link reference definitions before the range are kept, along with the rest of the source
+	// between the blocks. The blank lines after them are replaced by the separation of the range.
+	if kept := bytes.TrimRight(body[spliceStart:blocks[first].start], " \t\r\n"); len(kept) > 0 {
+		if first > 0 {
+			spliceStart = lineEnd(body, spliceStart+len(kept)-1, blocks[first].start)
+		} else {
+			spliceStart = blocks[first].start
+		}
+	}
+	var prefix []byte
+	if first > 0 {
+		previous := blocks[first-1].lastNode()
+		rendered, err := renderNodes(md, body, previous)
+		if err != nil {
+			return nil, err
+		}
+		prefix = rendered
+		nodes = append([]ast.Node{previous}, nodes...)
+	}
+	rendered, err := renderNodes(md, body, nodes...)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(rendered, prefix) {
+		return nil, fmt.Errorf("lines %d-%d can't be formatted apart from the rest of the document", start, end)
+	}
+
+	formatted := bytes.Buffer{}
+	formatted.Write(frontMatter)
+	formatted.Write(body[:spliceStart])
+	formatted.Write(rendered[len(prefix):])
+	formatted.Write(body[blocks[last].stop:])
+	return formatted.Bytes(), nil
+}
+
+// formatBlock is a run of top-level blocks formatted together by FormatRange.
+type formatBlock struct {
+	nodes []ast.Node
+	// startLine and endLine are the first and last lines of the blocks in the source, from 1
+	startLine, endLine int
+	// start is the offset of the first line of the blocks, and stop the offset after the line
+	// ending of their last line
+	start, stop int
+}
+
+func (b formatBlock) lastNode() ast.Node {
+	return b.nodes[len(b.nodes)-1]
+}
+
+// formatBlocks returns the top-level blocks of doc, parsed from source, with their lines. The parser
+// doesn't record the position of some blocks, such as thematic breaks, which are grouped with the
+// block before them. A block ends at the last line of its content, or of the markup following it,
+// such as closing code fences and setext heading underlines, but before any link reference
+// definition, as the parser leaves those out of the AST.
+func formatBlocks(source []byte, doc ast.Node) []formatBlock {
+	var blocks []formatBlock
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		// Link reference definitions leave empty blocks behind, which render nothing
+		if c.Kind() == ast.KindTextBlock || c.Kind() == ast.KindParagraph {
+			if c.Lines().Len() == 0 && !c.HasChildren() {
+				continue
+			}
+		}
+		lineStart, ok := blockLineStart(source, c)
+		if len(blocks) == 0 && !ok {
+			lineStart, ok = 0, true
+		}
+		if ok {
+			blocks = append(blocks, formatBlock{start: lineStart})
+		}
+		blocks[len(blocks)-1].nodes = append(blocks[len(blocks)-1].nodes, c)
+	}
+	for i := range blocks {
+		limit := len(source)
+		if i+1 < len(blocks) {
+			limit = blocks[i+1].start
+		}
+		stop := blocks[i].start
+		if end := contentEnd(blocks[i].nodes); end > stop {
+			stop = lineEnd(source, end-1, limit)
+		}
+		// Take the markup lines after the content, up to a link reference definition, leaving out
+		// the blank lines before the next block
+		for next := stop; next < limit; {
+			nextStop := lineEnd(source, next, limit)
+			line := bytes.TrimLeft(source[next:nextStop], " \t>")
+			if bytes.HasPrefix(line, []byte("[")) {
+				break
+			}
+			if len(bytes.TrimSpace(line)) > 0 {
+				stop = nextStop
+			}
+			next = nextStop
+		}
+		blocks[i].stop = stop
+		blocks[i].startLine = bytes.Count(source[:blocks[i].start], []byte{lineDelim}) + 1
+		blocks[i].endLine = blocks[i].startLine + max(bytes.Count(source[blocks[i].start:stop], []byte{lineDelim})-1, 0)
+	}
+	return blocks
+}
+
+// contentEnd returns the offset after the last source position recorded by the parser in nodes and
+// their descendants, or 0 if there's none.
+func contentEnd(nodes []ast.Node) int {
+	end := 0
+	for _, node := range nodes {
+		_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if stop, ok := sourceBound(n, false); ok && entering {
+				end = max(end, stop)
+			}
+			return ast.WalkContinue, nil
+		})
+	}
+	return end
+}
+
+// lineEnd returns the offset after the line ending of the line of source holding offset, or limit
+// if the line doesn't end before it.
+func lineEnd(source []byte, offset, limit int) int {
+	if newline := bytes.IndexByte(source[offset:limit], lineDelim); newline >= 0 {
+		return offset + newline + 1
+	}
+	return limit
+}
+
+// blockLineStart returns the offset of the first line of the block node in source, or false if the
+// parser didn't record its position.
+func blockLineStart(source []byte, node ast.Node) (int, bool) {
+	offset, ok := sourceBound(node, true)
+	if !ok {
+		return 0, false
+	}
+	lineStart := bytes.LastIndexByte(source[:offset], lineDelim) + 1
+	// The opening fence of a code block isn't one of its lines
+	for n := node; n != nil && n.Type() == ast.TypeBlock; n = n.FirstChild() {
+		if _, ok := n.(*ast.FencedCodeBlock); ok && lineStart > 0 {
+			lineStart = bytes.LastIndexByte(source[:lineStart-1], lineDelim) + 1
+		}
+		if n.Lines().Len() > 0 {
+			break
+		}
+	}
+	return lineStart, true
+}
+
+// renderNodes renders nodes as the blocks of a document of their own, moving them out of their
+// parents.
+func renderNodes(md goldmark.Markdown, source []byte, nodes ...ast.Node) ([]byte, error) {
+	doc := ast.NewDocument()
+	for _, n := range nodes {
+		if parent := n.Parent(); parent != nil {
+			parent.RemoveChild(parent, n)
+		}
+		doc.AppendChild(doc, n)
+	}
+	buf := bytes.Buffer{}
+	if err := md.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
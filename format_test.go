@@ -0,0 +1,261 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	formatted, err := Format([]byte("---\ntitle: *Doc*\n---\nTitle\n===\n\n* [X] done\n"), WithHeadingStyle(HeadingStyleATX))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: *Doc*\n---\n# Title\n\n* [x] done\n", string(formatted))
+}
+
+func TestFormatRange(t *testing.T) {
+	source := "Title\n=====\n\nSome __text__\nwrapped\n\n```\ncode\n```\n***\n\n\n\n* a\n* b\n\n- c\n\nLast   paragraph\n"
+	testCases := []struct {
+		name       string
+		source     string
+		start, end int
+		options    []Option
+		expected   string
+	}{
+		{
+			"First block",
+			source,
+			1, 1,
+			[]Option{},
+			"# Title\n\nSome __text__\nwrapped\n\n```\ncode\n```\n***\n\n\n\n* a\n* b\n\n- c\n\nLast   paragraph\n",
+		},
+		{
+			"Setext underline",
+			source,
+			2, 2,
+			[]Option{},
+			"# Title\n\nSome __text__\nwrapped\n\n```\ncode\n```\n***\n\n\n\n* a\n* b\n\n- c\n\nLast   paragraph\n",
+		},
+		{
+			"Middle of paragraph",
+			source,
+			5, 5,
+			[]Option{},
+			"Title\n=====\n\nSome **text**\nwrapped\n\n```\ncode\n```\n***\n\n\n\n* a\n* b\n\n- c\n\nLast   paragraph\n",
+		},
+		{
+			"Blank lines only",
+			source,
+			11, 12,
+			[]Option{},
+			source,
+		},
+		{
+			"Unpositioned block grouped with the previous one",
+			source,
+			10, 10,
+			[]Option{WithThematicBreakStyle(ThematicBreakStyleUnderlined)},
+			"Title\n=====\n\nSome __text__\nwrapped\n\n```\ncode\n```\n___\n\n\n\n* a\n* b\n\n- c\n\nLast   paragraph\n",
+		},
+		{
+			"Adjacent lists",
+			source,
+			14, 14,
+			[]Option{WithBulletMarker(BulletMarkerDash)},
+			"Title\n=====\n\nSome __text__\nwrapped\n\n```\ncode\n```\n***\n\n- a\n- b\n\n* c\n\nLast   paragraph\n",
+		},
+		{
+			"Last block without line ending",
+			"One\n\nTwo _words_",
+			3, 3,
+			[]Option{},
+			"One\n\nTwo *words*\n",
+		},
+		{
+			"Required separation",
+			"Text\n***\n",
+			2, 2,
+			[]Option{},
+			"Text\n\n---\n",
+		},
+		{
+			"Front matter",
+			"---\ntitle: x\n---\nOne\n===\n\nTwo\n===\n",
+			1, 4,
+			[]Option{},
+			"---\ntitle: x\n---\n# One\n\nTwo\n===\n",
+		},
+		{
+			"Range past the end",
+			"One\n\nTwo\n---\n",
+			3, 100,
+			[]Option{},
+			"One\n\n## Two\n",
+		},
+		{
+			"Trailing definition outside the range",
+			"[x][r]\n\n[r]: /u\n",
+			1, 1,
+			[]Option{},
+			"[x](/u)\n\n[r]: /u\n",
+		},
+		{
+			"Range on a trailing definition",
+			"[x][r]\n\n[r]: /u\n",
+			3, 3,
+			[]Option{},
+			"[x][r]\n\n[r]: /u\n",
+		},
+		{
+			"Definitions between blocks",
+			"Some __text__\n\n[r]: /u\n[s]: /v\n\n\nMore __text__\n\n[t]: /w\n",
+			7, 7,
+			[]Option{},
+			"Some __text__\n\n[r]: /u\n[s]: /v\n\nMore **text**\n\n[t]: /w\n",
+		},
+		{
+			"Definition before the first block",
+			"[r]: /u\n\nSome __text__\n",
+			3, 3,
+			[]Option{},
+			"[r]: /u\n\nSome **text**\n",
+		},
+		{
+			"Definition after a setext underline and a closing fence",
+			"Title\n===\n```\ncode\n```\n[r]: /u\n\nSome __text__\n",
+			1, 5,
+			[]Option{},
+			"# Title\n```\ncode\n```\n[r]: /u\n\nSome __text__\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := FormatRange([]byte(tc.source), tc.start, tc.end, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(formatted))
+		})
+	}
+
+	_, err := FormatRange([]byte(source), 3, 2)
+	assert.EqualError(t, err, "invalid line range 3-2")
+}
+
+func TestFormatFrontMatterOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		options  []Option
+		expected string
+	}{
+		{
+			"YAML",
+			"---\ntitle: Guide\nmarkdown-format:\n  heading: setext\n  bullet: \"-\"\n  preserve: false\n---\n# Title\n\n* item\n",
+			[]Option{WithHeadingStyle(HeadingStyleATX)},
+			"---\ntitle: Guide\nmarkdown-format:\n  heading: setext\n  bullet: \"-\"\n  preserve: false\n---\nTitle\n===\n\n- item\n",
+		},
+		{
+			"TOML",
+			"+++\ntitle = \"Guide\"\n\n[markdown-format]\nheading = 'setext'\n\"break\" = \"_\"\n\n[params]\nheading = \"atx\"\n+++\n# Title\n\n***\n",
+			nil,
+			"+++\ntitle = \"Guide\"\n\n[markdown-format]\nheading = 'setext'\n\"break\" = \"_\"\n\n[params]\nheading = \"atx\"\n+++\nTitle\n===\n\n___\n",
+		},
+		{
+			"TOML comments",
+			"+++\n[markdown-format] # overrides\nheading = \"setext\" # like the rest\nbullet = '-'#dash\nwidth = 20 # columns\n+++\n# Title #1\n\n* item\n",
+			nil,
+			"+++\n[markdown-format] # overrides\nheading = \"setext\" # like the rest\nbullet = '-'#dash\nwidth = 20 # columns\n+++\nTitle #1\n===\n\n- item\n",
+		},
+		{
+			"Without overrides",
+			"---\ntitle: [unparsed\n---\nTitle\n=====\n",
+			[]Option{WithHeadingStyle(HeadingStyleATX)},
+			"---\ntitle: [unparsed\n---\n# Title\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := Format([]byte(tc.source), tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(formatted))
+		})
+	}
+
+	_, err := Format([]byte("---\nmarkdown-format:\n  heading: h1\n---\n"))
+	assert.EqualError(t, err, `invalid front matter markdown-format: invalid heading "h1": must be one of atx, atx-surround, full-width-setext, setext`)
+	_, err = Format([]byte("---\nmarkdown-format:\n  wrap: 80\n---\n"))
+	assert.EqualError(t, err, `invalid front matter markdown-format: unknown option "wrap"`)
+	_, err = Format([]byte("---\nmarkdown-format:\n  width: wide\n---\n"))
+	assert.EqualError(t, err, `invalid front matter markdown-format: invalid width "wide": must be a number of columns, or 0 not to wrap`)
+	_, err = Format([]byte("+++\n[markdown-format]\nheading = \"setext\" atx\n+++\n"))
+	assert.EqualError(t, err, `invalid front matter: unexpected "atx" after string`)
+	_, err = FormatRange([]byte("---\nmarkdown-format: setext\n---\n"), 1, 1)
+	assert.EqualError(t, err, "invalid front matter: markdown-format must be a mapping")
+}
+
+// languageTransformer translates with the MapTransformer of each language, or English by default.
+type languageTransformer map[string]MapTransformer
+
+func (t languageTransformer) Transform(textType TextType, text string) (string, bool) {
+	return t["en"].Transform(textType, text)
+}
+
+func (t languageTransformer) ForLanguage(lang string) TextTransformer {
+	return t[lang]
+}
+
+func TestFormatTargetLanguage(t *testing.T) {
+	transformer := languageTransformer{
+		"en": MapTransformer{"Hallo": "Hello"},
+		"fr": MapTransformer{"Hallo": "Bonjour"},
+	}
+	formatted, err := Format([]byte("---\nmarkdown-format:\n  lang: fr\n---\nHallo\n"), WithTextTransformer(transformer))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nmarkdown-format:\n  lang: fr\n---\nBonjour\n", string(formatted))
+	formatted, err = Format([]byte("+++\n[markdown-format]\nlang = \"fr\" # target\n+++\nHallo\n"), WithTextTransformer(transformer))
+	require.NoError(t, err)
+	assert.Equal(t, "+++\n[markdown-format]\nlang = \"fr\" # target\n+++\nBonjour\n", string(formatted))
+	formatted, err = Format([]byte("Hallo\n"), WithTextTransformer(transformer))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello\n", string(formatted))
+	// Transformers that translate to a single language are used as they are
+	formatted, err = Format([]byte("---\nmarkdown-format:\n  lang: fr\n---\nHallo\n"), WithTextTransformer(transformer["en"]))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nmarkdown-format:\n  lang: fr\n---\nHello\n", string(formatted))
+}
+
+func TestFormatFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nmarkdown-format:\n  numbering: one\n---\n3. a\n4. b\n"), 0o644))
+	formatted, err := FormatFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nmarkdown-format:\n  numbering: one\n---\n1. a\n2. b\n", string(formatted))
+
+	_, err = FormatFile(filepath.Join(t.TempDir(), "missing.md"))
+	assert.Error(t, err)
+}
+
+func TestFormatTranslations(t *testing.T) {
+	source := []byte("---\ntitle: Hello\nmarkdown-format:\n  heading: setext\n---\n# Hello\n\n* Some *text*\n\n| Hello |\n|---|\n| text |\n")
+	transformers := map[string]TextTransformer{
+		"de": MapTransformer{"Hello": "Hallo", "Some": "Etwas", "text": "Text"},
+		"fr": MapTransformer{"Hello": "Bonjour", "Some": "Du", "text": "texte"},
+		"en": nil,
+	}
+	outputs, err := FormatTranslations(source, transformers, WithBulletMarker(BulletMarkerDash))
+	require.NoError(t, err)
+	frontMatter := "---\ntitle: Hello\nmarkdown-format:\n  heading: setext\n---\n"
+	expected := map[string]string{
+		"de": frontMatter + "Hallo\n===\n\n- Etwas *Text*\n\n| Hallo |\n| ----- |\n| Text |\n",
+		"fr": frontMatter + "Bonjour\n===\n\n- Du *texte*\n\n| Bonjour |\n| ------- |\n| texte |\n",
+		"en": frontMatter + "Hello\n===\n\n- Some *text*\n\n| Hello |\n| ----- |\n| text |\n",
+	}
+	for key, output := range outputs {
+		assert.Equal(t, expected[key], string(output), key)
+	}
+	assert.Len(t, outputs, len(expected))
+
+	_, err = FormatTranslations([]byte("---\nmarkdown-format: setext\n---\n"), transformers)
+	assert.Error(t, err)
+}
@@ -0,0 +1,397 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterKey is the front matter key whose values override the options that Format, FormatRange
+// and FormatFile are called with for a document. Its values are named like the options of
+// NamedOptions, in YAML:
+//
+//	---
+//	title: Guide
+//	markdown-format:
+//	  heading: setext
+//	  bullet: "-"
+//	---
+//
+// or in TOML:
+//
+//	+++
+//	title = "Guide"
+//	[markdown-format]
+//	heading = "setext"
+//	+++
+const FrontMatterKey = "markdown-format"
+
+var (
+	// HeadingStyleNames are the heading styles by name.
+	HeadingStyleNames = map[string]HeadingStyle{
+		"atx":               HeadingStyleATX,
+		"atx-surround":      HeadingStyleATXSurround,
+		"setext":            HeadingStyleSetext,
+		"full-width-setext": HeadingStyleFullWidthSetext,
+	}
+	// BulletMarkerNames are the bullet markers by name.
+	BulletMarkerNames = map[string]BulletMarker{
+		"preserve": BulletMarkerPreserve,
+		"-":        BulletMarkerDash,
+		"*":        BulletMarkerAsterisk,
+		"+":        BulletMarkerPlus,
+	}
+	// ThematicBreakStyleNames are the thematic break styles by name.
+	ThematicBreakStyleNames = map[string]ThematicBreakStyle{
+		"-": ThematicBreakStyleDashed,
+		"*": ThematicBreakStyleStarred,
+		"_": ThematicBreakStyleUnderlined,
+	}
+	// IndentStyleNames are the indent styles by name.
+	IndentStyleNames = map[string]IndentStyle{
+		"spaces": IndentStyleSpaces,
+		"tabs":   IndentStyleTabs,
+	}
+	// ListNumberingNames are the list numberings by name.
+	ListNumberingNames = map[string]ListNumbering{
+		"start": ListNumberingFromStart,
+		"one":   ListNumberingFromOne,
+	}
+	// DialectNames are the dialects by name.
+	DialectNames = map[string]Dialect{
+		"commonmark": DialectCommonMark,
+		"mkdocs":     DialectMkDocs,
+		"pandoc":     DialectPandoc,
+	}
+)
+
+// namedOptions are the options of NamedOptions in the order they're applied, each returning the
+// option for the name of its value.
+var namedOptions = []struct {
+	name   string
+	option func(value string) (Option, error)
+}{
+	{"heading", func(value string) (Option, error) {
+		style, err := lookupName("heading", value, HeadingStyleNames)
+		return WithHeadingStyle(style), err
+	}},
+	{"bullet", func(value string) (Option, error) {
+		marker, err := lookupName("bullet", value, BulletMarkerNames)
+		return WithBulletMarker(marker), err
+	}},
+	{"break", func(value string) (Option, error) {
+		style, err := lookupName("break", value, ThematicBreakStyleNames)
+		return WithThematicBreakStyle(style), err
+	}},
+	{"indent", func(value string) (Option, error) {
+		style, err := lookupName("indent", value, IndentStyleNames)
+		return WithIndentStyle(style), err
+	}},
+	{"numbering", func(value string) (Option, error) {
+		numbering, err := lookupName("numbering", value, ListNumberingNames)
+		return WithListNumbering(numbering), err
+	}},
+	{"dialect", func(value string) (Option, error) {
+		dialect, err := lookupName("dialect", value, DialectNames)
+		return WithDialect(dialect), err
+	}},
+	{"width", func(value string) (Option, error) {
+		width, err := strconv.Atoi(value)
+		if err != nil || width < 0 {
+			return nil, fmt.Errorf("invalid width %q: must be a number of columns, or 0 not to wrap", value)
+		}
+		return WithLineWidth(LineWidth(width)), nil
+	}},
+	{"lang", func(value string) (Option, error) {
+		return WithTargetLanguage(TargetLanguage(value)), nil
+	}},
+	{"preserve", func(value string) (Option, error) {
+		preserve, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid preserve %q: must be true or false", value)
+		}
+		if preserve {
+			return WithStyleMode(StyleModePreserve), nil
+		}
+		return WithStyleMode(StyleModeNormalize), nil
+	}},
+}
+
+// NamedOptions returns the renderer options for values, which maps the names of options to the names
+// of their values: "heading", "bullet", "break", "indent", "numbering" and "dialect" to the names in
+// HeadingStyleNames, BulletMarkerNames, ThematicBreakStyleNames, IndentStyleNames, ListNumberingNames
+// and DialectNames, "width" to the LineWidth in columns, "lang" to the TargetLanguage, and "preserve"
+// to true or false for StyleModePreserve or StyleModeNormalize. An error is returned for the first
+// unknown name.
+func NamedOptions(values map[string]string) ([]Option, error) {
+	known := map[string]bool{}
+	for _, named := range namedOptions {
+		known[named.name] = true
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	var options []Option
+	for _, named := range namedOptions {
+		value, ok := values[named.name]
+		if !ok {
+			continue
+		}
+		option, err := named.option(value)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// lookupName returns the value of values with the given name, or an error naming the option if
+// there's none.
+func lookupName[T any](option, name string, values map[string]T) (T, error) {
+	value, ok := values[name]
+	if !ok {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return value, fmt.Errorf("invalid %s %q: must be one of %s", option, name, strings.Join(names, ", "))
+	}
+	return value, nil
+}
+
+// frontMatterOptions returns the options named under FrontMatterKey in frontMatter, as returned by
+// splitFrontMatter, if any.
+func frontMatterOptions(frontMatter []byte) ([]Option, error) {
+	// Front matter that doesn't override options isn't parsed, so it can't fail formatting
+	if !bytes.Contains(frontMatter, []byte(FrontMatterKey)) {
+		return nil, nil
+	}
+	delimiter, content, _ := bytes.Cut(frontMatter, []byte{lineDelim})
+	// Leave out the closing delimiter
+	content = content[:bytes.LastIndexByte(bytes.TrimRight(content, "\r\n"), lineDelim)+1]
+	var values map[string]string
+	var err error
+	if string(bytes.TrimRight(delimiter, " \t\r")) == "+++" {
+		values, err = tomlTableValues(content, FrontMatterKey)
+	} else {
+		values, err = yamlMappingValues(content, FrontMatterKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	options, err := NamedOptions(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid front matter %s: %w", FrontMatterKey, err)
+	}
+	return options, nil
+}
+
+// yamlMappingValues returns the keys and scalar values of the mapping under key in the YAML document
+// content, or nil if there's none.
+func yamlMappingValues(content []byte, key string) (map[string]string, error) {
+	document := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	value, ok := document[key]
+	if !ok {
+		return nil, nil
+	}
+	mapping, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a mapping", key)
+	}
+	values := make(map[string]string, len(mapping))
+	for name, value := range mapping {
+		values[name] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+// tomlTableValues returns the keys and values of the table named name in the TOML document content,
+// or nil if there's none. Only tables of key = value lines are read, with unquoted values such as
+// booleans and numbers taken as is, and comments left out.
+func tomlTableValues(content []byte, name string) (map[string]string, error) {
+	var values map[string]string
+	inTable := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			// Leave out the comment after the header
+			if end := strings.IndexByte(line, ']'); end >= 0 {
+				if rest := strings.TrimSpace(line[end+1:]); rest == "" || rest[0] == '#' {
+					line = line[:end+1]
+				}
+			}
+			inTable = line == "["+name+"]" || line == `["`+name+`"]`
+			if inTable {
+				values = map[string]string{}
+			}
+		case inTable:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid line in [%s]: %s", name, line)
+			}
+			key, err := unquoteTOML(strings.TrimSpace(key))
+			if err != nil {
+				return nil, err
+			}
+			value, err = unquoteTOML(strings.TrimSpace(value))
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// unquoteTOML returns the value of a TOML key or value, which is unquoted if it's a basic or literal
+// string, without the comment that may follow it.
+func unquoteTOML(s string) (string, error) {
+	var value, rest string
+	switch {
+	case strings.HasPrefix(s, `"`):
+		// The string ends at the first quote that isn't escaped
+		end := 1
+		for ; end < len(s) && s[end] != '"'; end++ {
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		unquoted, err := strconv.Unquote(s[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s[:end+1])
+		}
+		value, rest = unquoted, s[end+1:]
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		value, rest = s[1:end+1], s[end+2:]
+	default:
+		value, _, _ = strings.Cut(s, "#")
+		return strings.TrimSpace(value), nil
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after string", rest)
+	}
+	return value, nil
+}
+
+// KindFrontMatter is the NodeKind of FrontMatter nodes.
+var KindFrontMatter = ast.NewNodeKind("FrontMatter")
+
+// FrontMatter is the YAML or TOML front matter at the start of a document, as split off by Format.
+// Its lines include the delimiters.
+type FrontMatter struct {
+	ast.BaseBlock
+	// stop is the offset of the end of the front matter in the source while it's parsed
+	stop int
+}
+
+// Kind implements ast.Node.Kind
+func (n *FrontMatter) Kind() ast.NodeKind {
+	return KindFrontMatter
+}
+
+// IsRaw implements ast.Node.IsRaw
+func (n *FrontMatter) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump
+func (n *FrontMatter) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type frontMatterParser struct{}
+
+// NewFrontMatterParser returns a parser.BlockParser that parses YAML front matter delimited by "---"
+// or TOML front matter delimited by "+++" at the start of a document into a FrontMatter node, rather
+// than a thematic break or setext heading.
+func NewFrontMatterParser() parser.BlockParser {
+	return &frontMatterParser{}
+}
+
+// Trigger implements parser.BlockParser.Trigger
+func (p *frontMatterParser) Trigger() []byte {
+	return []byte{'-', '+'}
+}
+
+// Open implements parser.BlockParser.Open
+func (p *frontMatterParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	_, segment := reader.PeekLine()
+	if parent.Kind() != ast.KindDocument || parent.HasChildren() || segment.Start != 0 {
+		return nil, parser.NoChildren
+	}
+	frontMatter, _ := splitFrontMatter(reader.Source())
+	if len(frontMatter) == 0 {
+		return nil, parser.NoChildren
+	}
+	node := &FrontMatter{stop: len(frontMatter)}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.Continue
+func (p *frontMatterParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*FrontMatter)
+	line, segment := reader.PeekLine()
+	if line == nil || segment.Start >= n.stop {
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.Close
+func (p *frontMatterParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph
+func (p *frontMatterParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine
+func (p *frontMatterParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func (r *Renderer) renderFrontMatter(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.rc.writer.SetVerbatim(true)
+		lines := node.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			r.rc.writer.WriteBytes(line.Value(r.rc.source))
+			r.rc.writer.FlushLine()
+		}
+		r.rc.writer.SetVerbatim(false)
+	}
+	return ast.WalkContinue
+}
@@ -6,10 +6,10 @@ require (
 	github.com/rhysd/go-fakeio v1.0.0
 	github.com/stretchr/testify v1.7.0
 	github.com/yuin/goldmark v1.7.8
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
@@ -0,0 +1,87 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// headingAttributes returns the attribute list to write after the text of the heading n, such as
+// {#id .class}, or nil if there's none. text is the rendered text of the heading, which ids are
+// generated from.
+func (r *Renderer) headingAttributes(n *ast.Heading, text string) []byte {
+	attributes := sourceHeadingAttributes(n, r.rc.source)
+	if r.config.HeadingIDs == HeadingIDsKeep {
+		return attributes
+	}
+	var kept []string
+	if attributes != nil {
+		for _, attribute := range splitAttributes(string(attributes[1 : len(attributes)-1])) {
+			if !strings.HasPrefix(attribute, "#") && !strings.HasPrefix(attribute, "id=") {
+				kept = append(kept, attribute)
+			}
+		}
+	}
+	if r.config.HeadingIDs == HeadingIDsGenerate {
+		if r.rc.headingSlugs == nil {
+			r.rc.headingSlugs = map[string]int{}
+		}
+		if slug := slugify(strings.TrimSpace(text)); slug != "" {
+			kept = append([]string{"#" + uniqueSlug(r.rc.headingSlugs, slug)}, kept...)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return []byte("{" + strings.Join(kept, " ") + "}")
+}
+
+// sourceHeadingAttributes returns the attribute list following the text of the heading n in source,
+// such as {#id .class}, or nil if the heading wasn't parsed with one.
+func sourceHeadingAttributes(n *ast.Heading, source []byte) []byte {
+	lines := n.Lines()
+	if len(n.Attributes()) == 0 || lines.Len() == 0 {
+		return nil
+	}
+	rest := source[lines.At(lines.Len()-1).Stop:]
+	if end := bytes.IndexByte(rest, lineDelim); end >= 0 {
+		rest = rest[:end]
+	}
+	start, stop := bytes.IndexByte(rest, '{'), bytes.LastIndexByte(rest, '}')
+	if start < 0 || stop < start {
+		return nil
+	}
+	return rest[start : stop+1]
+}
+
+// splitAttributes returns the space separated attributes of an attribute list without its braces,
+// keeping quoted values whole.
+func splitAttributes(list string) []string {
+	var attributes []string
+	var quote rune
+	start := -1
+	for i, c := range list {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ' || c == '\t':
+			if start >= 0 {
+				attributes = append(attributes, list[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		attributes = append(attributes, list[start:])
+	}
+	return attributes
+}
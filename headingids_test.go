@@ -0,0 +1,91 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestHeadingIDs(t *testing.T) {
+	source := "# Title {#intro .big}\n\nSetext *heading* {#s}\n---\n\n## Plain\n\n## Plain\n\n### `code` and [link](u) {data-x=\"a b\" id=\"x\"}\n"
+	testCases := []struct {
+		name        string
+		ids         HeadingIDs
+		transformer TextTransformer
+		expected    string
+	}{
+		{
+			"Keep",
+			HeadingIDsKeep,
+			nil,
+			"# Title {#intro .big}\n\n## Setext *heading* {#s}\n\n## Plain\n\n## Plain\n\n### `code` and [link](u) {data-x=\"a b\" id=\"x\"}\n",
+		},
+		{
+			"Strip",
+			HeadingIDsStrip,
+			nil,
+			"# Title {.big}\n\n## Setext *heading*\n\n## Plain\n\n## Plain\n\n### `code` and [link](u) {data-x=\"a b\"}\n",
+		},
+		{
+			"Generate",
+			HeadingIDsGenerate,
+			nil,
+			"# Title {#title .big}\n\n## Setext *heading* {#setext-heading}\n\n## Plain {#plain}\n\n## Plain {#plain-1}\n\n### `code` and [link](u) {#code-and-link data-x=\"a b\"}\n",
+		},
+		{
+			"Generate from translations",
+			HeadingIDsGenerate,
+			MapTransformer{"Title": "Titre", "Plain": "Simple", "and": "et"},
+			"# Titre {#titre .big}\n\n## Setext *heading* {#setext-heading}\n\n## Simple {#simple}\n\n## Simple {#simple-1}\n\n### `code` et [link](u) {#code-et-link data-x=\"a b\"}\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(
+				goldmark.WithRenderer(NewRenderer(WithHeadingIDs(tc.ids), WithTextTransformer(tc.transformer))),
+				goldmark.WithParserOptions(parser.WithHeadingAttribute()),
+			)
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestHeadingIDsSetext(t *testing.T) {
+	md := goldmark.New(
+		goldmark.WithRenderer(NewRenderer(WithHeadingIDs(HeadingIDsGenerate), WithHeadingStyle(HeadingStyleSetext))),
+		goldmark.WithParserOptions(parser.WithHeadingAttribute(), parser.WithAutoHeadingID()),
+	)
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte("# Über uns\n"), &buf))
+	assert.Equal(t, "Über uns {#über-uns}\n===\n", buf.String())
+}
+
+// TestHeadingIDsUnique tests that generated ids are unique even if the text of a heading is the
+// slug of another with a number appended
+func TestHeadingIDsUnique(t *testing.T) {
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithHeadingIDs(HeadingIDsGenerate))))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte("# A\n\n# A\n\n# A 1\n\n# A-1\n"), &buf))
+	assert.Equal(t, "# A {#a}\n\n# A {#a-1}\n\n# A 1 {#a-1-1}\n\n# A-1 {#a-1-2}\n", buf.String())
+}
+
+// TestHeadingIDsFormat tests that Format reads the ids it writes back as attributes, so formatting
+// is idempotent
+func TestHeadingIDsFormat(t *testing.T) {
+	formatted, err := Format([]byte("# A\n\n## B {#b .x}\n"), WithHeadingIDs(HeadingIDsGenerate))
+	require.NoError(t, err)
+	assert.Equal(t, "# A {#a}\n\n## B {#b .x}\n", string(formatted))
+	again, err := Format(formatted, WithHeadingIDs(HeadingIDsGenerate))
+	require.NoError(t, err)
+	assert.Equal(t, string(formatted), string(again))
+
+	formatted, err = Format([]byte("# A {#a .x}\n"), WithHeadingIDs(HeadingIDsStrip))
+	require.NoError(t, err)
+	assert.Equal(t, "# A {.x}\n", string(formatted))
+}
@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+// recordingTransformer records the text passed to it without transforming anything.
+type recordingTransformer struct {
+	calls []string
+}
+
+func (t *recordingTransformer) Transform(textType TextType, text string) (string, bool) {
+	t.calls = append(t.calls, text)
+	return text, false
+}
+
+func TestHTMLComments(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  []Option
+		source   string
+		expected string
+	}{
+		{
+			"Block comment preserved",
+			[]Option{},
+			"a\n\n<!--\n  keep   me\n-->",
+			"a\n\n<!--\n  keep   me\n-->\n",
+		},
+		{
+			"Inline comment preserved",
+			[]Option{},
+			"a <!-- keep *me* --> b",
+			"a <!-- keep *me* --> b\n",
+		},
+		{
+			"Block comment stripped",
+			[]Option{WithHTMLComments(HTMLCommentsStrip)},
+			"a\n\n<!-- drop -->\n\nb",
+			"a\n\nb\n",
+		},
+		{
+			"Block comment between paragraphs stripped",
+			[]Option{WithHTMLComments(HTMLCommentsStrip)},
+			"a\n<!-- drop -->\nb",
+			"a\n\nb\n",
+		},
+		{
+			"Leading block comment stripped",
+			[]Option{WithHTMLComments(HTMLCommentsStrip)},
+			"<!-- drop -->\n\n# Title",
+			"# Title\n",
+		},
+		{
+			"Inline comment stripped",
+			[]Option{WithHTMLComments(HTMLCommentsStrip)},
+			"a <!-- drop --> b <span>keep</span>",
+			"a  b <span>keep</span>\n",
+		},
+		{
+			"Other HTML kept when stripping comments",
+			[]Option{WithHTMLComments(HTMLCommentsStrip)},
+			"<div>\nkeep\n</div>",
+			"<div>\nkeep\n</div>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(tc.options...)))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+// TestHTMLCommentsNotTransformed tests that comments are never passed to the TextTransformer
+func TestHTMLCommentsNotTransformed(t *testing.T) {
+	transformer := &recordingTransformer{}
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithTextTransformer(transformer))))
+	source := "<!-- block -->\n\ntext <!-- inline --> <b>html</b>\n"
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, source, buf.String())
+	assert.Equal(t, []string{"text", "<b>", "html", "</b>"}, transformer.calls)
+}
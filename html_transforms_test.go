@@ -60,6 +60,16 @@ func TestHTMLTransformations(t *testing.T) {
 			expected:    "之前\n\n<div>\n  <p>块 HTML</p>\n</div>\n\n之后\n",
 			htmlContent: "<div>\n  <p>Block HTML</p>\n</div>\n",
 		},
+		{
+			name:   "html block with closing line transformation",
+			source: "<pre>\nBlock\n</pre>\nAfter",
+			translations: map[string]string{
+				"<pre>\nBlock\n</pre>\n": "<pre>\n块\n</pre>\n",
+				"After":                  "之后",
+			},
+			expected:    "<pre>\n块\n</pre>\n之后\n",
+			htmlContent: "<pre>\nBlock\n</pre>\n",
+		},
 		{
 			name:   "mixed html and text transformation",
 			source: "Plain text and <em>emphasis</em> with HTML",
@@ -0,0 +1,167 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/teekennedy/goldmark-markdown/internal/walk"
+)
+
+// HugoTranslation is a translation of the pages of a Hugo content directory to a language.
+type HugoTranslation struct {
+	// Language is the code of the language, which the names of the translated pages are suffixed
+	// with, such as "zh" for index.zh.md
+	Language string
+	// Transformer translates the text of the pages
+	Transformer TextTransformer
+	// FrontMatterFields are the names of the top-level front matter fields whose string values are
+	// translated, such as "title" and "description"
+	FrontMatterFields []string
+	// Options are the options the bodies of the pages are rendered with, in addition to
+	// WithTextTransformer and WithHugoShortcodes(HugoShortcodesPreserve), which they can override
+	Options []Option
+}
+
+// hugoTranslatedPage matches the names of pages that are translations themselves, such as
+// index.zh.md or about.pt-br.md.
+var hugoTranslatedPage = regexp.MustCompile(`\.[a-z]{2,3}(-[a-zA-Z0-9]+)?\.(md|markdown)$`)
+
+// TranslateHugoContent translates the markdown pages under the Hugo content directory dir, including
+// those of page bundles, and writes each next to its page with the name suffixed by the language, so
+// content/posts/hello/index.md is translated to content/posts/hello/index.zh.md. Pages whose names
+// already have a language suffix are left out. Shortcodes are kept as they are, and front matter is
+// kept but for the values of the translated fields. It returns the paths of the written files.
+func TranslateHugoContent(dir string, translation HugoTranslation) ([]string, error) {
+	if translation.Language == "" {
+		return nil, fmt.Errorf("missing language")
+	}
+	files, err := walk.Files([]string{dir}, walk.Options{})
+	if err != nil {
+		return nil, err
+	}
+	options := append([]Option{
+		WithTextTransformer(translation.Transformer),
+		WithHugoShortcodes(HugoShortcodesPreserve),
+	}, translation.Options...)
+
+	var written []string
+	for _, path := range files {
+		if hugoTranslatedPage.MatchString(filepath.Base(path)) {
+			continue
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return written, err
+		}
+		frontMatter, body := splitFrontMatter(source)
+		frontMatter = translateFrontMatter(frontMatter, translation.FrontMatterFields, translation.Transformer)
+		translated, err := Format(append(frontMatter[:len(frontMatter):len(frontMatter)], body...), options...)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", path, err)
+		}
+		ext := filepath.Ext(path)
+		target := strings.TrimSuffix(path, ext) + "." + translation.Language + ext
+		if err := os.WriteFile(target, translated, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+var (
+	// yamlField matches a top-level field of YAML front matter, capturing its key and value
+	yamlField = regexp.MustCompile(`^([A-Za-z_][\w-]*)([ \t]*:[ \t]*)(.*?)([ \t]*\r?)$`)
+	// tomlField matches a top-level field of TOML front matter, capturing its key and value
+	tomlField = regexp.MustCompile(`^([A-Za-z_][\w-]*)([ \t]*=[ \t]*)(.*?)([ \t]*\r?)$`)
+)
+
+// translateFrontMatter returns frontMatter, as returned by splitFrontMatter, with the string values
+// of the top-level fields named fields translated by transformer. Values are quoted as they were,
+// unless a plain YAML value needs quotes, and values that span lines or tables are left as they are.
+func translateFrontMatter(frontMatter []byte, fields []string, transformer TextTransformer) []byte {
+	if len(frontMatter) == 0 || len(fields) == 0 || transformer == nil {
+		return frontMatter
+	}
+	translated := map[string]bool{}
+	for _, field := range fields {
+		translated[field] = true
+	}
+	pattern, quote := yamlField, quoteYAML
+	if bytes.HasPrefix(frontMatter, []byte("+++")) {
+		pattern, quote = tomlField, quoteTOML
+	}
+	lines := bytes.SplitAfter(frontMatter, []byte{lineDelim})
+	for i := 1; i < len(lines)-1; i++ {
+		line := bytes.TrimSuffix(lines[i], []byte{lineDelim})
+		match := pattern.FindSubmatch(line)
+		if match == nil || !translated[string(match[1])] {
+			continue
+		}
+		value, style, ok := unquoteFrontMatterValue(string(match[3]), pattern == tomlField)
+		if !ok {
+			continue
+		}
+		translation, ok := transformer.Transform(TextTypePlain, value)
+		if !ok || translation == value {
+			continue
+		}
+		lines[i] = []byte(string(match[1]) + string(match[2]) + quote(translation, style) + string(match[4]) +
+			string(lines[i][len(line):]))
+	}
+	return bytes.Join(lines, nil)
+}
+
+// unquoteFrontMatterValue returns the string of a YAML or TOML scalar value and the quote it's
+// quoted with, if any. It returns false for values that aren't strings on a single line.
+func unquoteFrontMatterValue(value string, toml bool) (string, byte, bool) {
+	if value == "" {
+		return "", 0, false
+	}
+	switch value[0] {
+	case '"':
+		unquoted, err := strconv.Unquote(value)
+		return unquoted, '"', err == nil
+	case '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", 0, false
+		}
+		inner := value[1 : len(value)-1]
+		if toml {
+			return inner, '\'', !strings.Contains(inner, "'")
+		}
+		return strings.ReplaceAll(inner, "''", "'"), '\'', true
+	}
+	// Plain YAML strings, leaving out other kinds of values and those with comments
+	if toml || strings.ContainsAny(value[:1], "|>[{&*!%@`#") || strings.Contains(value, " #") {
+		return "", 0, false
+	}
+	return value, 0, true
+}
+
+// quoteYAML returns value as a YAML scalar quoted with quote, or plain if quote is 0 and the value
+// can be read back as is.
+func quoteYAML(value string, quote byte) string {
+	switch {
+	case quote == '\'':
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case quote == 0 && value != "" && value == strings.TrimSpace(value) &&
+		!strings.ContainsAny(value[:1], "|>[]{}&*!%@`#'\",?:-") && !strings.Contains(value, ": ") &&
+		!strings.Contains(value, " #") && !strings.ContainsAny(value, "\n\r\t"):
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// quoteTOML returns value as a TOML string quoted with quote.
+func quoteTOML(value string, quote byte) string {
+	if quote == '\'' && !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+	return strconv.Quote(value)
+}
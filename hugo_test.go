@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateHugoContent(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"_index.md": "---\ntitle: Home\ndescription: 'It''s home'\ndraft: false\nsummary: Home # comment\n---\n" +
+			"Welcome __home__.\n",
+		"posts/hello/index.md": "+++\ntitle = \"Hello\"  \ntags = [\"Hello\"]\n+++\n" +
+			"Hello\n=====\n\n{{< figure src=\"a.png\" caption=\"Hello\" >}}\n",
+		"posts/hello/index.fr.md": "Bonjour\n",
+		"posts/hello/a.png":       "image",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	written, err := TranslateHugoContent(dir, HugoTranslation{
+		Language: "zh",
+		Transformer: MapTransformer{
+			"Home":           "主页: 首页",
+			"It's home":      "这是'家'",
+			"Welcome":        "欢迎来到",
+			"home":           "家",
+			"Hello":          "你好",
+			"Home # comment": "不",
+		},
+		FrontMatterFields: []string{"title", "description", "summary", "tags"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "_index.zh.md"),
+		filepath.Join(dir, "posts/hello/index.zh.md"),
+	}, written)
+
+	expected := map[string]string{
+		"_index.zh.md": "---\ntitle: \"主页: 首页\"\ndescription: '这是''家'''\ndraft: false\nsummary: Home # comment\n---\n" +
+			"欢迎来到 **家**.\n",
+		"posts/hello/index.zh.md": "+++\ntitle = \"你好\"  \ntags = [\"Hello\"]\n+++\n" +
+			"# 你好\n\n{{< figure src=\"a.png\" caption=\"Hello\" >}}\n",
+	}
+	for name, content := range expected {
+		translated, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(translated), name)
+	}
+
+	_, err = TranslateHugoContent(dir, HugoTranslation{})
+	assert.Error(t, err)
+}
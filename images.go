@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// imageSize matches the rest of an image with a size suffix after its alt text, such as
+// (img.png =300x200 "Title"), capturing its destination, width, height and title in double or single
+// quotes.
+var imageSize = regexp.MustCompile(`^\([ \t]*(<(?:[^<>\n\\]|\\.)*>|[^ \t\n<>()]+)[ \t]+=([0-9]*)x([0-9]*)` +
+	`(?:[ \t]+(?:"((?:[^"\n\\]|\\.)*)"|'((?:[^'\n\\]|\\.)*)'))?[ \t]*\)`)
+
+type imageSizeParser struct{}
+
+// NewImageSizeParser returns a parser.InlineParser that parses images with a size suffix after their
+// destination, such as ![alt](img.png =300x200), into Image nodes with width and height attributes.
+// The alt text of these images is kept as plain text.
+func NewImageSizeParser() parser.InlineParser {
+	return &imageSizeParser{}
+}
+
+// Trigger implements parser.InlineParser.Trigger
+func (p *imageSizeParser) Trigger() []byte {
+	return []byte{'!'}
+}
+
+// Parse implements parser.InlineParser.Parse
+func (p *imageSizeParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("![")) {
+		return nil
+	}
+	// The alt text ends at the matching bracket
+	altStop, depth := -1, 0
+	for i := 2; i < len(line) && altStop < 0; i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				altStop = i
+			}
+			depth--
+		}
+	}
+	if altStop < 0 {
+		return nil
+	}
+	m := imageSize.FindSubmatchIndex(line[altStop+1:])
+	if m == nil || m[5]-m[4]+m[7]-m[6] == 0 {
+		return nil
+	}
+	rest := line[altStop+1:]
+	image := ast.NewImage(ast.NewLink())
+	image.Destination = rest[m[2]:m[3]]
+	if image.Destination[0] == '<' {
+		image.Destination = image.Destination[1 : len(image.Destination)-1]
+	}
+	switch {
+	case m[8] >= 0:
+		image.Title = rest[m[8]:m[9]]
+	case m[10] >= 0:
+		image.Title = rest[m[10]:m[11]]
+	}
+	if m[5] > m[4] {
+		image.SetAttributeString("width", rest[m[4]:m[5]])
+	}
+	if m[7] > m[6] {
+		image.SetAttributeString("height", rest[m[6]:m[7]])
+	}
+	if altStop > 2 {
+		image.AppendChild(image, ast.NewTextSegment(text.NewSegment(segment.Start+2, segment.Start+altStop)))
+	}
+	block.Advance(altStop + 1 + m[1])
+	return image
+}
+
+type imageAttributeListParser struct{}
+
+// NewImageAttributeListParser returns a parser.InlineParser that parses attribute lists right after
+// images, such as ![alt](img.png){width=300}, into AttributeList nodes.
+func NewImageAttributeListParser() parser.InlineParser {
+	return &imageAttributeListParser{}
+}
+
+// Trigger implements parser.InlineParser.Trigger
+func (p *imageAttributeListParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Parse implements parser.InlineParser.Parse
+func (p *imageAttributeListParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if _, ok := parent.LastChild().(*ast.Image); !ok {
+		return nil
+	}
+	line, segment := block.PeekLine()
+	length := scanAttributeList(line)
+	if length == 0 {
+		return nil
+	}
+	block.Advance(length)
+	return &AttributeList{Segment: segment.WithStop(segment.Start + length)}
+}
+
+// imageSizeSuffix returns the size suffix of the image n, such as "=300x200", from its width and
+// height attributes, or nil if it has neither.
+func imageSizeSuffix(n *ast.Image) []byte {
+	width, hasWidth := n.AttributeString("width")
+	height, hasHeight := n.AttributeString("height")
+	if !hasWidth && !hasHeight {
+		return nil
+	}
+	suffix := []byte{'='}
+	if w, ok := width.([]byte); ok {
+		suffix = append(suffix, w...)
+	}
+	suffix = append(suffix, 'x')
+	if h, ok := height.([]byte); ok {
+		suffix = append(suffix, h...)
+	}
+	return suffix
+}
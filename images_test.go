@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestImageAttributes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Size",
+			"![alt](img.png =300x200) ![alt](img.png =300x) ![alt](<my img.png> =x200 'Title')\n",
+			"![alt](img.png =300x200) ![alt](img.png =300x) ![alt](<my img.png> =x200 \"Title\")\n",
+		},
+		{
+			"Attribute list",
+			"![alt](img.png){width=300} and ![alt](img.png){ width=50% .wide }\n",
+			"![alt](img.png){width=300} and ![alt](img.png){ width=50% .wide }\n",
+		},
+		{
+			"Not attributes",
+			"![alt](img.png =x) ![alt](img.png) {width=300} {a b}\n",
+			"![alt](img.png =x) ![alt](img.png) {width=300} {a b}\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithImageAttributes(ImageAttributesPreserve))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestImageSizeAttributes(t *testing.T) {
+	source := []byte("![alt](img.png =300x200)\n")
+	r := NewRenderer(WithImageAttributes(ImageAttributesPreserve))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	image := md.Parser().Parse(text.NewReader(source)).FirstChild().FirstChild().(*ast.Image)
+	assert.Equal(t, "img.png", string(image.Destination))
+	width, _ := image.AttributeString("width")
+	height, _ := image.AttributeString("height")
+	assert.Equal(t, []byte("300"), width)
+	assert.Equal(t, []byte("200"), height)
+}
+
+func TestImageAttributesTranslation(t *testing.T) {
+	source := "See ![a logo](logo.png =64x64) and ![a chart](chart.png){width=300} here\n"
+	recorder := &recordingTransformer{}
+	r := NewRenderer(WithImageAttributes(ImageAttributesPreserve), WithTextTransformer(recorder))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, source, buf.String())
+	assert.Equal(t, []string{"See", "a logo", "and", "a chart", "here"}, recorder.calls)
+}
@@ -0,0 +1,177 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// inlineHTMLTag matches an inline HTML tag without attributes that has a markdown equivalent,
+// capturing the slash of closing tags, the name of the tag and the slash of self-closing tags.
+var inlineHTMLTag = regexp.MustCompile(`(?i)^<(/?)(b|strong|i|em|code|br)[ \t]*(/?)>$`)
+
+type inlineHTMLTransformer struct{}
+
+// NewInlineHTMLTransformer returns a parser.ASTTransformer that replaces trivial inline HTML with
+// its markdown equivalent: <b> and <strong> with strong emphasis, <i> and <em> with emphasis, <code>
+// with code spans and <br> with hard line breaks. HTML that can't be written as markdown the same
+// way, such as tags with attributes or elements with spaces inside their tags, is left as it is. The
+// Renderer adds it to the parser in Extend.
+func NewInlineHTMLTransformer() parser.ASTTransformer {
+	return &inlineHTMLTransformer{}
+}
+
+// Transform implements parser.ASTTransformer.Transform
+func (t *inlineHTMLTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var parents []ast.Node
+	seen := map[ast.Node]bool{}
+	for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindRawHTML }) {
+		if !seen[n.Parent()] {
+			seen[n.Parent()] = true
+			parents = append(parents, n.Parent())
+		}
+	}
+	for _, parent := range parents {
+		convertInlineHTML(parent, source)
+	}
+}
+
+// convertInlineHTML replaces the trivial inline HTML among the children of parent with markdown.
+func convertInlineHTML(parent ast.Node, source []byte) {
+	for c := parent.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		name, closing, ok := inlineHTMLTagName(c, source)
+		switch {
+		case !ok || closing:
+		case name == "br":
+			next = convertLineBreak(c)
+		default:
+			if replacement := convertInlineHTMLElement(c, name, source); replacement != nil {
+				next = replacement.NextSibling()
+			}
+		}
+		c = next
+	}
+}
+
+// inlineHTMLTagName returns the name of the tag of n, in lower case, and whether it's a closing tag,
+// or false if n isn't a tag matched by inlineHTMLTag.
+func inlineHTMLTagName(n ast.Node, source []byte) (name string, closing bool, ok bool) {
+	raw, ok := n.(*ast.RawHTML)
+	if !ok || raw.Segments.Len() != 1 {
+		return "", false, false
+	}
+	segment := raw.Segments.At(0)
+	m := inlineHTMLTag.FindSubmatch(segment.Value(source))
+	if m == nil {
+		return "", false, false
+	}
+	name, closing = strings.ToLower(string(m[2])), len(m[1]) > 0
+	// Only line breaks are void elements
+	if len(m[3]) > 0 && (closing || name != "br") {
+		return "", false, false
+	}
+	return name, closing, true
+}
+
+// convertLineBreak replaces the <br> tag br with a hard line break at the end of the text before it,
+// returning the node after it. Breaks that don't follow text or end their block are left as they are.
+func convertLineBreak(br ast.Node) ast.Node {
+	next := br.NextSibling()
+	prev, ok := br.PreviousSibling().(*ast.Text)
+	if !ok || prev.HardLineBreak() {
+		return next
+	}
+	// The line ending after the tag is part of the hard line break
+	after := next
+	if t, ok := next.(*ast.Text); ok && t.Segment.Len() == 0 && t.SoftLineBreak() {
+		after = t.NextSibling()
+	}
+	if after == nil {
+		return next
+	}
+	parent := br.Parent()
+	for c := br; c != after; {
+		following := c.NextSibling()
+		parent.RemoveChild(parent, c)
+		c = following
+	}
+	prev.SetSoftLineBreak(false)
+	prev.SetHardLineBreak(true)
+	return after
+}
+
+// convertInlineHTMLElement replaces the element opened by the tag open with the markdown node named
+// by name, returning the node, or nil if the element isn't closed by a sibling of open or its content
+// can't be written as markdown.
+func convertInlineHTMLElement(open ast.Node, name string, source []byte) ast.Node {
+	var content []ast.Node
+	var closeTag ast.Node
+	depth := 0
+	for c := open.NextSibling(); c != nil; c = c.NextSibling() {
+		if tagName, closing, ok := inlineHTMLTagName(c, source); ok && tagName == name {
+			if !closing {
+				depth++
+			} else if depth == 0 {
+				closeTag = c
+				break
+			} else {
+				depth--
+			}
+		}
+		content = append(content, c)
+	}
+	if closeTag == nil || len(content) == 0 {
+		return nil
+	}
+	// Emphasis and code spans can't start or end with spaces
+	first, firstOK := content[0].(*ast.Text)
+	last, lastOK := content[len(content)-1].(*ast.Text)
+	if (firstOK && startsWithSpace(first.Value(source))) ||
+		(lastOK && (last.SoftLineBreak() || endsWithSpace(last.Value(source)))) {
+		return nil
+	}
+
+	var replacement ast.Node
+	switch name {
+	case "code":
+		for _, c := range content {
+			// Code spans are literal, so content with line breaks, references or escapes differs
+			t, ok := c.(*ast.Text)
+			if !ok || t.SoftLineBreak() || t.HardLineBreak() || bytes.ContainsAny(t.Value(source), "&\\") {
+				return nil
+			}
+		}
+		replacement = ast.NewCodeSpan()
+	case "b", "strong":
+		replacement = ast.NewEmphasis(2)
+	default:
+		replacement = ast.NewEmphasis(1)
+	}
+	parent := open.Parent()
+	parent.InsertBefore(parent, open, replacement)
+	for _, c := range content {
+		replacement.AppendChild(replacement, c)
+	}
+	parent.RemoveChild(parent, open)
+	parent.RemoveChild(parent, closeTag)
+	if replacement.Kind() == ast.KindEmphasis {
+		convertInlineHTML(replacement, source)
+	}
+	return replacement
+}
+
+// startsWithSpace returns true if b starts with whitespace.
+func startsWithSpace(b []byte) bool {
+	return len(b) > 0 && len(bytes.TrimLeft(b, " \t\n")) < len(b)
+}
+
+// endsWithSpace returns true if b ends with whitespace.
+func endsWithSpace(b []byte) bool {
+	return len(b) > 0 && len(bytes.TrimRight(b, " \t\n")) < len(b)
+}
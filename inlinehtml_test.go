@@ -0,0 +1,58 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestInlineHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Emphasis",
+			"A <b>bold</b>, <STRONG>strong</STRONG>, <i>italic</i> and <em>emphasized</em> word.\n",
+			"A **bold**, **strong**, *italic* and *emphasized* word.\n",
+		},
+		{
+			"Nested",
+			"<b>bold <i>and italic</i> text</b> and <b><code>x</code></b>\n",
+			"**bold *and italic* text** and **`x`**\n",
+		},
+		{
+			"Code",
+			"Run <code>go test ./...</code> or <code>a`b</code>, not <code>a *b*</code>\n",
+			"Run `go test ./...` or ``a`b``, not <code>a *b*</code>\n",
+		},
+		{
+			"Line breaks",
+			"one<br>\ntwo<br/>three<BR />\nfour\n",
+			"one\\\ntwo\\\nthree\\\nfour\n",
+		},
+		{
+			"Not converted",
+			"<b class=\"x\">a</b> <b> a </b> <b>unclosed <code>a&amp;b</code> end<br>\n",
+			"<b class=\"x\">a</b> <b> a </b> <b>unclosed <code>a&amp;b</code> end<br>\n",
+		},
+		{
+			"Blocks are kept",
+			"<b>\nbold\n</b>\n",
+			"<b>\nbold\n</b>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithInlineHTML(InlineHTMLConvert))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Package diff computes line-based unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// op is a single line of an edit script.
+type op struct {
+	kind byte // ' ', '-', or '+'
+	line []byte
+}
+
+// Unified returns a unified diff between a and b, labelled with the given names. It returns nil if
+// the inputs are equal.
+func Unified(aName, bName string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	ops := edits(splitLines(a), splitLines(b))
+
+	out := bytes.Buffer{}
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// Extend the hunk until context lines separate it from the next change
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*context {
+				break
+			}
+		}
+		hunkStart := max(start-context, 0)
+		hunkEnd := min(end+context, len(ops))
+		writeHunk(&out, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+	return out.Bytes()
+}
+
+// writeHunk writes ops[start:end] as a unified diff hunk.
+func writeHunk(out *bytes.Buffer, ops []op, start, end int) {
+	aLine, bLine := 1, 1
+	for _, o := range ops[:start] {
+		if o.kind != '+' {
+			aLine++
+		}
+		if o.kind != '-' {
+			bLine++
+		}
+	}
+	aLen, bLen := 0, 0
+	for _, o := range ops[start:end] {
+		if o.kind != '+' {
+			aLen++
+		}
+		if o.kind != '-' {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aLine, aLen, bLine, bLen)
+	for _, o := range ops[start:end] {
+		out.WriteByte(o.kind)
+		out.Write(o.line)
+		if !bytes.HasSuffix(o.line, []byte("\n")) {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// edits returns the shortest edit script transforming a into b, based on their longest common
+// subsequence of lines. The lines shared at the start and end are left out of the search, which
+// uses Hirschberg's algorithm, so memory grows with the number of lines rather than its square.
+func edits(a, b [][]byte) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && bytes.Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && bytes.Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	ops := make([]op, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+	// Lines are compared by number, the same for equal lines
+	numbers := map[string]int{}
+	number := func(lines [][]byte) []int {
+		numbered := make([]int, len(lines))
+		for i, line := range lines {
+			n, ok := numbers[string(line)]
+			if !ok {
+				n = len(numbers)
+				numbers[string(line)] = n
+			}
+			numbered[i] = n
+		}
+		return numbered
+	}
+	s := script{a: a[prefix : len(a)-suffix], b: b[prefix : len(b)-suffix]}
+	s.an, s.bn = number(s.a), number(s.b)
+	ops = s.edits(ops, 0, len(s.a), 0, len(s.b))
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+// script computes the edit script of lines a into lines b, numbered in an and bn.
+type script struct {
+	a, b   [][]byte
+	an, bn []int
+}
+
+// edits appends the edit script of a[aStart:aEnd] into b[bStart:bEnd] to ops. The lines of b are
+// split where the longest common subsequences of the halves of a with them add up to the longest,
+// and each half is edited into its part of b.
+func (s *script) edits(ops []op, aStart, aEnd, bStart, bEnd int) []op {
+	switch {
+	case aStart == aEnd:
+		for _, line := range s.b[bStart:bEnd] {
+			ops = append(ops, op{'+', line})
+		}
+		return ops
+	case bStart == bEnd:
+		for _, line := range s.a[aStart:aEnd] {
+			ops = append(ops, op{'-', line})
+		}
+		return ops
+	case aEnd-aStart == 1:
+		for j := bStart; j < bEnd; j++ {
+			if s.an[aStart] == s.bn[j] {
+				ops = s.edits(ops, aStart, aStart, bStart, j)
+				ops = append(ops, op{' ', s.a[aStart]})
+				return s.edits(ops, aEnd, aEnd, j+1, bEnd)
+			}
+		}
+		ops = append(ops, op{'-', s.a[aStart]})
+		return s.edits(ops, aEnd, aEnd, bStart, bEnd)
+	}
+	aMid := (aStart + aEnd) / 2
+	forward := lcsLengths(s.an[aStart:aMid], s.bn[bStart:bEnd], false)
+	backward := lcsLengths(s.an[aMid:aEnd], s.bn[bStart:bEnd], true)
+	split, longest := 0, -1
+	for k := range forward {
+		if length := forward[k] + backward[len(backward)-1-k]; length > longest {
+			split, longest = k, length
+		}
+	}
+	ops = s.edits(ops, aStart, aMid, bStart, bStart+split)
+	return s.edits(ops, aMid, aEnd, bStart+split, bEnd)
+}
+
+// lcsLengths returns the lengths of the longest common subsequences of a with each prefix of b, by
+// length of the prefix, or with each suffix of b, comparing a and b from their ends, if reverse is
+// set.
+func lcsLengths(a, b []int, reverse bool) []int {
+	at := func(lines []int, i int) int {
+		if reverse {
+			return lines[len(lines)-1-i]
+		}
+		return lines[i]
+	}
+	lengths := make([]int, len(b)+1)
+	for i := range a {
+		// diagonal is the length of the previous row at j
+		diagonal := 0
+		for j := range b {
+			up := lengths[j+1]
+			if at(a, i) == at(b, j) {
+				lengths[j+1] = diagonal + 1
+			} else if lengths[j] > up {
+				lengths[j+1] = lengths[j]
+			}
+			diagonal = up
+		}
+	}
+	return lengths
+}
+
+// splitLines splits data into lines, keeping line endings.
+func splitLines(data []byte) [][]byte {
+	var lines [][]byte
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			lines = append(lines, data)
+			break
+		}
+		lines = append(lines, data[:i+1])
+		data = data[i+1:]
+	}
+	return lines
+}
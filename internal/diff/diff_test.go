@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			"Equal",
+			"foo\nbar\n",
+			"foo\nbar\n",
+			"",
+		},
+		{
+			"Changed line",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"Separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			"Missing trailing newline",
+			"a",
+			"a\n",
+			"--- a\n+++ b\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(Unified("a", "b", []byte(tc.a), []byte(tc.b))))
+		})
+	}
+}
+
+// TestEdits tests that edit scripts turn a into b with the fewest changes, against the lengths of
+// the longest common subsequences found with the whole table of them
+func TestEdits(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	lines := func() [][]byte {
+		var lines [][]byte
+		for i := random.Intn(30); i > 0; i-- {
+			lines = append(lines, []byte(fmt.Sprintf("%d\n", random.Intn(5))))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := lines(), lines()
+		ops := edits(a, b)
+		var gotA, gotB [][]byte
+		common := 0
+		for _, o := range ops {
+			if o.kind != '+' {
+				gotA = append(gotA, o.line)
+			}
+			if o.kind != '-' {
+				gotB = append(gotB, o.line)
+			}
+			if o.kind == ' ' {
+				common++
+			}
+		}
+		assert.Equal(t, bytes.Join(a, nil), bytes.Join(gotA, nil))
+		assert.Equal(t, bytes.Join(b, nil), bytes.Join(gotB, nil))
+		assert.Equal(t, lcsLength(a, b), common, "%q %q", a, b)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b [][]byte) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if bytes.Equal(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+// TestUnifiedLarge tests that large files are diffed without a table of all pairs of their lines
+func TestUnifiedLarge(t *testing.T) {
+	a := strings.Builder{}
+	b := strings.Builder{}
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&a, "line %d\n", i)
+		if i%1000 == 0 {
+			fmt.Fprintf(&b, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+	diff := string(Unified("a", "b", []byte(a.String()), []byte(b.String())))
+	assert.Equal(t, 10, strings.Count(diff, "\n-line"))
+	assert.Equal(t, 10, strings.Count(diff, "\n+changed"))
+}
@@ -0,0 +1,144 @@
+package walk
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compilePattern compiles a gitignore style glob pattern into a regexp matching slash separated
+// paths. "*" and "?" don't match "/", "**" matches any number of directories, and brackets match a
+// character class. A pattern without a "/", other than a trailing one, matches the name of a file or
+// directory at any depth; otherwise it matches the whole path.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expr := strings.Builder{}
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// patternSet is a set of glob patterns matching a path if any of them does.
+type patternSet []*regexp.Regexp
+
+// newPatternSet compiles patterns into a patternSet.
+func newPatternSet(patterns []string) (patternSet, error) {
+	set := make(patternSet, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, re)
+	}
+	return set, nil
+}
+
+// match reports whether any pattern of s matches the slash separated path.
+func (s patternSet) match(path string) bool {
+	for _, re := range s {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRule is a pattern of an ignore file.
+type ignoreRule struct {
+	// dir is the slash separated path of the directory holding the ignore file, relative to the root,
+	// which the pattern is relative to
+	dir     string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// parseIgnoreFile returns the rules of an ignore file in dir, in the format of .gitignore. Invalid
+// patterns are skipped, like git does.
+func parseIgnoreFile(dir string, data []byte) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		// Trailing spaces are ignored unless escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{dir: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		rule.dirOnly = strings.HasSuffix(line, "/")
+		re, err := compilePattern(line)
+		if err != nil {
+			continue
+		}
+		rule.pattern = re
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ignored reports whether rules ignore the file or directory at the slash separated path relative to
+// the root. The last matching rule wins, so later rules and those of nested directories take
+// precedence.
+func ignored(rules []ignoreRule, path string, isDir bool) bool {
+	ignore := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := path
+		if rule.dir != "" {
+			var ok bool
+			if rel, ok = strings.CutPrefix(path, rule.dir+"/"); !ok {
+				continue
+			}
+		}
+		if rule.pattern.MatchString(rel) {
+			ignore = !rule.negate
+		}
+	}
+	return ignore
+}
@@ -0,0 +1,201 @@
+// Package walk finds the markdown files under directory trees and processes them concurrently, for
+// the commands of this module.
+package walk
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultInclude are the patterns of the files found by default.
+var DefaultInclude = []string{"*.md", "*.markdown"}
+
+// DefaultIgnoreFiles are the names of the ignore files honored by default.
+var DefaultIgnoreFiles = []string{".gitignore", ".mdignore"}
+
+// Options configure how files are found and processed.
+type Options struct {
+	// Include are the glob patterns of the files to find in directories, DefaultInclude if empty
+	Include []string
+	// Exclude are the glob patterns of the files and directories to leave out
+	Exclude []string
+	// IgnoreFiles are the names of the files holding gitignore style patterns of the files and
+	// directories to leave out, DefaultIgnoreFiles if nil
+	IgnoreFiles []string
+	// Workers is the number of files processed at a time, the number of CPUs if not positive
+	Workers int
+}
+
+// RegisterFlags defines the -include, -exclude and -j flags on flags, setting the options. Patterns
+// can be given as a comma separated list or by repeating the flag.
+func (o *Options) RegisterFlags(flags *flag.FlagSet) {
+	flags.Var((*patternsFlag)(&o.Include), "include", "glob `patterns` of the files to find in directories (default \"*.md,*.markdown\")")
+	flags.Var((*patternsFlag)(&o.Exclude), "exclude", "glob `patterns` of the files and directories to leave out")
+	flags.IntVar(&o.Workers, "j", 0, "number of files processed at a time (default the number of CPUs)")
+}
+
+// patternsFlag is a flag holding a list of patterns.
+type patternsFlag []string
+
+func (f *patternsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *patternsFlag) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			*f = append(*f, pattern)
+		}
+	}
+	return nil
+}
+
+// Files returns the files found under roots, in order of roots and then in lexical order. A root that
+// is a file is returned as is. Directories are walked recursively for files matching Include, leaving
+// out those matching Exclude or ignored by the ignore files of the walked directories, and ".git"
+// directories. Patterns are matched against paths relative to the root, with "/" separators.
+func Files(roots []string, options Options) ([]string, error) {
+	files, _, err := find(roots, options)
+	return files, err
+}
+
+// Dirs returns the directories walked by Files for roots: those that are roots or under one, but for
+// the ones it leaves out, in the same order.
+func Dirs(roots []string, options Options) ([]string, error) {
+	_, dirs, err := find(roots, options)
+	return dirs, err
+}
+
+// find returns the files and directories found under roots, as described by Files and Dirs.
+func find(roots []string, options Options) (files, dirs []string, err error) {
+	include := options.Include
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+	includes, err := newPatternSet(include)
+	if err != nil {
+		return nil, nil, err
+	}
+	excludes, err := newPatternSet(options.Exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+	ignoreFiles := options.IgnoreFiles
+	if ignoreFiles == nil {
+		ignoreFiles = DefaultIgnoreFiles
+	}
+
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		// The rules of each walked directory, including those of its parents
+		rules := map[string][]ignoreRule{}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if rel == "." {
+				rel = ""
+			}
+			parentRules := rules[parentDir(rel)]
+			if rel != "" {
+				if d.IsDir() && d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				if excludes.match(rel) || ignored(parentRules, rel, d.IsDir()) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			}
+			if !d.IsDir() {
+				if includes.match(rel) {
+					files = append(files, path)
+				}
+				return nil
+			}
+			dirs = append(dirs, path)
+			dirRules := parentRules
+			for _, name := range ignoreFiles {
+				data, err := os.ReadFile(filepath.Join(path, name))
+				if err != nil {
+					continue
+				}
+				// Copy on append so sibling directories don't share rules
+				dirRules = append(dirRules[:len(dirRules):len(dirRules)], parseIgnoreFile(rel, data)...)
+			}
+			rules[rel] = dirRules
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return files, dirs, nil
+}
+
+// parentDir returns the slash separated path of the directory holding rel, or "" for the root.
+func parentDir(rel string) string {
+	if i := strings.LastIndexByte(rel, '/'); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+// Process calls process for each of paths, with up to workers calls running at a time, or the
+// number of CPUs if workers isn't positive. The results are passed to done in the order of paths,
+// one at a time, so output written by done is deterministic.
+func Process[T any](paths []string, workers int, process func(path string) (T, error), done func(path string, result T, err error)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	type result struct {
+		value T
+		err   error
+		ready chan struct{}
+	}
+	results := make([]result, len(paths))
+	for i := range results {
+		results[i].ready = make(chan struct{})
+	}
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(workers, len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].value, results[i].err = process(paths[i])
+				close(results[i].ready)
+			}
+		}()
+	}
+	go func() {
+		for i := range paths {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	for i, path := range paths {
+		<-results[i].ready
+		done(path, results[i].value, results[i].err)
+	}
+	wg.Wait()
+}
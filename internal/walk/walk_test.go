@@ -0,0 +1,174 @@
+package walk
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePattern(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{"*.md", []string{"a.md", "docs/a.md", "docs/api/a.md"}, []string{"a.mdx", "a.md/b"}},
+		{"/a.md", []string{"a.md"}, []string{"docs/a.md"}},
+		{"docs/*.md", []string{"docs/a.md"}, []string{"a.md", "docs/api/a.md", "x/docs/a.md"}},
+		{"docs/**/*.md", []string{"docs/a.md", "docs/api/a.md"}, []string{"a.md"}},
+		{"**/build", []string{"build", "x/y/build"}, []string{"build2"}},
+		{"docs/**", []string{"docs/a", "docs/a/b"}, []string{"docs", "a/docs/b"}},
+		{"vendor/", []string{"vendor", "a/vendor"}, []string{"vendors"}},
+		{"a?.[mM][!x]", []string{"ab.md", "ac.Md"}, []string{"a.md", "ab.mx", "a/.md"}},
+		{`\#notes`, []string{"#notes"}, []string{"notes"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			re, err := compilePattern(tc.pattern)
+			require.NoError(t, err)
+			for _, path := range tc.matches {
+				assert.True(t, re.MatchString(path), path)
+			}
+			for _, path := range tc.misses {
+				assert.False(t, re.MatchString(path), path)
+			}
+		})
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	rules := parseIgnoreFile("", []byte("# comment\n\n*.tmp.md\nbuild/\n!keep.tmp.md\n"))
+	rules = append(rules, parseIgnoreFile("docs", []byte("/draft.md\ngenerated\n"))...)
+	testCases := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"a.tmp.md", false, true},
+		{"docs/a.tmp.md", false, true},
+		{"keep.tmp.md", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"docs/draft.md", false, true},
+		{"docs/api/draft.md", false, false},
+		{"draft.md", false, false},
+		{"docs/api/generated", true, true},
+		{"generated", true, false},
+		{"docs.md", false, false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, ignored(rules, tc.path, tc.isDir), tc.path)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":              "build/\n*.tmp.md\n",
+		"README.md":               "",
+		"notes.txt":               "",
+		"draft.tmp.md":            "",
+		"build/out.md":            "",
+		"docs/.mdignore":          "/private\n!keep.tmp.md\n",
+		"docs/index.markdown":     "",
+		"docs/keep.tmp.md":        "",
+		"docs/private/secret.md":  "",
+		"docs/api/private/ref.md": "",
+		"node_modules/pkg/doc.md": "",
+		".git/info.md":            "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	rel := func(paths []string) []string {
+		for i, path := range paths {
+			if r, err := filepath.Rel(root, path); err == nil {
+				paths[i] = filepath.ToSlash(r)
+			}
+		}
+		return paths
+	}
+
+	found, err := Files([]string{root}, Options{Exclude: []string{"node_modules"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "docs/api/private/ref.md", "docs/index.markdown", "docs/keep.tmp.md"}, rel(found))
+
+	found, err = Files([]string{root}, Options{
+		Include:     []string{"*.txt", "docs/**/*.md"},
+		IgnoreFiles: []string{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/api/private/ref.md", "docs/keep.tmp.md", "docs/private/secret.md", "notes.txt"}, rel(found))
+
+	// Files given as roots are kept regardless of the patterns
+	found, err = Files([]string{filepath.Join(root, "notes.txt"), filepath.Join(root, "docs")}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes.txt", "docs/api/private/ref.md", "docs/index.markdown", "docs/keep.tmp.md"}, rel(found))
+
+	_, err = Files([]string{filepath.Join(root, "missing")}, Options{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"build/out", "docs/api", "docs/private", ".git/objects"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0o644))
+
+	dirs, err := Dirs([]string{root}, Options{Exclude: []string{"private"}})
+	require.NoError(t, err)
+	expected := []string{root, filepath.Join(root, "docs"), filepath.Join(root, "docs", "api")}
+	assert.Equal(t, expected, dirs)
+}
+
+func TestProcess(t *testing.T) {
+	paths := []string{"a", "b", "c", "d", "e", "f"}
+	var running, maxRunning atomic.Int32
+	var order []string
+	Process(paths, 2, func(path string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		// Finish the earlier paths last
+		time.Sleep(time.Duration(len(paths)-strings.Index("abcdef", path)) * time.Millisecond)
+		if path == "c" {
+			return "", errors.New("failed")
+		}
+		return strings.ToUpper(path), nil
+	}, func(path string, result string, err error) {
+		if err != nil {
+			result = err.Error()
+		}
+		order = append(order, path+"="+result)
+	})
+	assert.Equal(t, []string{"a=A", "b=B", "c=failed", "d=D", "e=E", "f=F"}, order)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	called := false
+	Process(nil, 0, func(string) (int, error) { return 0, nil }, func(string, int, error) { called = true })
+	assert.False(t, called)
+}
+
+func TestRegisterFlags(t *testing.T) {
+	options := Options{}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	options.RegisterFlags(flags)
+	require.NoError(t, flags.Parse([]string{"-include", "*.md, *.mdx", "-exclude", "vendor", "-exclude", "build", "-j", "3"}))
+	assert.Equal(t, Options{Include: []string{"*.md", "*.mdx"}, Exclude: []string{"vendor", "build"}, Workers: 3}, options)
+}
@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pendingJoin holds the whitespace between translated text and the inline node following it, which
+// is only written once the text after the node is known.
+type pendingJoin struct {
+	// pos is where the whitespace belongs
+	pos writerPosition
+	// before is the last character of the translated text
+	before rune
+	// space is the whitespace to insert
+	space []byte
+}
+
+// inlineMarkup holds the characters of inline markup that can separate text from the text of a
+// neighboring inline node.
+const inlineMarkup = "*_~`[]!"
+
+// joinWithSpace returns true if whitespace should separate the text ending in before from the text
+// starting with after.
+func (r *Renderer) joinWithSpace(before, after rune) bool {
+	switch r.config.InlineJoin {
+	case InlineJoinNone:
+		return false
+	case InlineJoinSmart:
+		return !isSpacelessScript(before) || !isSpacelessScript(after)
+	}
+	return true
+}
+
+// isSpacelessScript returns true if r belongs to a script that doesn't separate words with spaces.
+func isSpacelessScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		// CJK symbols and punctuation, and fullwidth forms
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
+
+// deferJoin records the whitespace following the just written text, to be written once the text
+// after it is known.
+func (r *Renderer) deferJoin(written []byte, space string) {
+	before, _ := utf8.DecodeLastRune(written)
+	r.rc.pendingJoin = &pendingJoin{
+		pos:    r.rc.writer.Position(),
+		before: before,
+		space:  []byte(space),
+	}
+}
+
+// resolveJoin writes the pending whitespace, if any, unless next is text that shouldn't be
+// separated from the text preceding the whitespace.
+func (r *Renderer) resolveJoin(next []byte) {
+	p := r.rc.pendingJoin
+	if p == nil {
+		return
+	}
+	r.rc.pendingJoin = nil
+	// Whitespace at the end of a line is dropped anyway
+	_, after, ok := r.rc.writer.Buffer(p.pos)
+	if !ok {
+		return
+	}
+	// Look past inline markup written since the text for the first character after the join
+	after = bytes.TrimLeft(after, inlineMarkup)
+	if len(after) == 0 {
+		after = bytes.TrimLeft(next, inlineMarkup)
+	}
+	first, _ := utf8.DecodeRune(after)
+	if r.joinWithSpace(p.before, first) {
+		r.rc.writer.InsertAt(p.pos.offset, p.space)
+	}
+}
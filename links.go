@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// ExtractedLink is a link or image found by ExtractLinks.
+type ExtractedLink struct {
+	// Destination is the URL of the link or the source of the image
+	Destination string
+	// Text is the plain text of the link, or the alt text of the image
+	Text string
+	// Title is the title of the link or image, if any
+	Title string
+	// IsImage is true for images
+	IsImage bool
+	// IsAutoLink is true for autolinks such as <https://example.com>
+	IsAutoLink bool
+	// Position is the position of the text of the link in the source
+	Position SourcePosition
+}
+
+// ExtractLinks parses source and returns its links, images and autolinks in document order. Links
+// using reference definitions have the destination and title of the definition.
+func ExtractLinks(source []byte) []ExtractedLink {
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
+	doc := md.Parser().Parse(text.NewReader(source))
+
+	var links []ExtractedLink
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			links = append(links, ExtractedLink{
+				Destination: string(n.Destination),
+				Text:        plainText(source, n),
+				Title:       string(n.Title),
+				Position:    sourcePosition(source, n),
+			})
+		case *ast.Image:
+			links = append(links, ExtractedLink{
+				Destination: string(n.Destination),
+				Text:        plainText(source, n),
+				Title:       string(n.Title),
+				IsImage:     true,
+				Position:    sourcePosition(source, n),
+			})
+		case *ast.AutoLink:
+			link := ExtractedLink{
+				Destination: string(n.URL(source)),
+				Text:        string(n.Label(source)),
+				IsAutoLink:  true,
+			}
+			// Autolinks have no text nodes, but their label is a slice of the source
+			if start, ok := sourceOffset(source, n.Label(source)); ok {
+				link.Position = offsetPosition(source, start)
+			}
+			links = append(links, link)
+		}
+		return ast.WalkContinue, nil
+	})
+	return links
+}
@@ -0,0 +1,61 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+func TestExtractLinks(t *testing.T) {
+	source := []byte("See [the *docs*](/docs \"Docs\") and\n![logo](logo.png) or <https://example.com>.\n\n" +
+		"| [ref][r] |\n| --- |\n\n[r]: /ref\n")
+	assert.Equal(t, []ExtractedLink{
+		{Destination: "/docs", Text: "the docs", Title: "Docs", Position: SourcePosition{Line: 1, Column: 6}},
+		{Destination: "logo.png", Text: "logo", IsImage: true, Position: SourcePosition{Line: 2, Column: 3}},
+		{Destination: "https://example.com", Text: "https://example.com", IsAutoLink: true,
+			Position: SourcePosition{Line: 2, Column: 23}},
+		{Destination: "/ref", Text: "ref", Position: SourcePosition{Line: 4, Column: 4}},
+	}, ExtractLinks(source))
+}
+
+func TestLinkTransformer(t *testing.T) {
+	transformer := func(destination, title string, isImage bool) (string, string) {
+		switch {
+		case isImage:
+			return "/static/" + destination, title
+		case strings.HasPrefix(destination, "/"):
+			return "https://example.com" + destination, title + " (external)"
+		case destination == "https://old.example.com":
+			return "https://new.example.com", title
+		case destination == "me@example.com":
+			return "/contact me", title
+		case destination == "old@example.com":
+			return "new@example.com", title
+		}
+		return destination, title
+	}
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"Link", "[docs](/docs \"Docs\")", "[docs](https://example.com/docs \"Docs (external)\")\n"},
+		{"Image", "![logo](logo.png)", "![logo](/static/logo.png)\n"},
+		{"Unchanged link", "[a](https://example.com)", "[a](https://example.com)\n"},
+		{"Autolink", "<https://old.example.com>", "<https://new.example.com>\n"},
+		{"Autolink with invalid destination", "<me@example.com>", "[me@example.com](</contact me>)\n"},
+		{"Email autolink", "<old@example.com>", "<new@example.com>\n"},
+		{"Unchanged autolink", "<https://example.com>", "<https://example.com>\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithLinkTransformer(transformer))))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// LintDiagnostic is a style issue found by Lint.
+type LintDiagnostic struct {
+	// Rule is the name of the rule that found the issue
+	Rule string
+	// Message describes the issue
+	Message string
+	// Position is where the issue is in the source
+	Position SourcePosition
+}
+
+func (d LintDiagnostic) String() string {
+	return fmt.Sprintf("%s: %s (%s)", d.Position, d.Message, d.Rule)
+}
+
+// LintRule checks a document for a kind of style issue.
+type LintRule func(source []byte, doc ast.Node) []LintDiagnostic
+
+// Lint parses source and checks it with rules, returning the issues found ordered by position. The
+// rules report style that the renderer would change or that's inconsistent within the document, so
+// Lint can be run before deciding to format a document.
+func Lint(source []byte, rules ...LintRule) []LintDiagnostic {
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
+	doc := md.Parser().Parse(text.NewReader(source))
+
+	var diagnostics []LintDiagnostic
+	for _, rule := range rules {
+		diagnostics = append(diagnostics, rule(source, doc)...)
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Position, diagnostics[j].Position
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+	return diagnostics
+}
+
+// LintBulletMarkers reports bullet lists whose marker differs from that of the first bullet list.
+func LintBulletMarkers() LintRule {
+	return func(source []byte, doc ast.Node) []LintDiagnostic {
+		var diagnostics []LintDiagnostic
+		var first byte
+		for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindList }) {
+			list := n.(*ast.List)
+			if list.IsOrdered() {
+				continue
+			}
+			if first == 0 {
+				first = list.Marker
+				continue
+			}
+			if list.Marker != first {
+				diagnostics = append(diagnostics, LintDiagnostic{
+					Rule:     "bullet-markers",
+					Message:  fmt.Sprintf("bullet list uses %q, but the first bullet list uses %q", list.Marker, first),
+					Position: listMarkerPosition(source, list),
+				})
+			}
+		}
+		return diagnostics
+	}
+}
+
+// listMarkerPosition returns the position of the marker of the first item of list, or of its content
+// if the marker can't be found.
+func listMarkerPosition(source []byte, list *ast.List) SourcePosition {
+	start, _, ok := sourceRange(list)
+	if !ok {
+		return SourcePosition{}
+	}
+	for i := start - 1; i >= 0 && source[i] != lineDelim; i-- {
+		if source[i] == list.Marker {
+			return offsetPosition(source, i)
+		}
+	}
+	return offsetPosition(source, start)
+}
+
+// LintHeadingStyle reports headings whose style, ATX or setext, differs from that of the first
+// heading.
+func LintHeadingStyle() LintRule {
+	return func(source []byte, doc ast.Node) []LintDiagnostic {
+		var diagnostics []LintDiagnostic
+		first := ""
+		for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindHeading }) {
+			style, ok := sourceHeadingStyle(source, n.(*ast.Heading))
+			if !ok {
+				continue
+			}
+			if first == "" {
+				first = style
+				continue
+			}
+			if style != first {
+				diagnostics = append(diagnostics, LintDiagnostic{
+					Rule:     "heading-style",
+					Message:  fmt.Sprintf("heading uses %s style, but the first heading uses %s style", style, first),
+					Position: sourcePosition(source, n),
+				})
+			}
+		}
+		return diagnostics
+	}
+}
+
+// sourceHeadingStyle returns "ATX" or "setext" depending on how heading is written in the source.
+func sourceHeadingStyle(source []byte, heading *ast.Heading) (string, bool) {
+	start, _, ok := sourceRange(heading)
+	if !ok {
+		return "", false
+	}
+	lineStart := bytes.LastIndexByte(source[:start], lineDelim) + 1
+	// Only ATX headings have markup before their content
+	if bytes.IndexByte(source[lineStart:start], '#') >= 0 {
+		return "ATX", true
+	}
+	return "setext", true
+}
+
+// LintLineLength reports lines outside of code blocks that are wider than maxWidth columns.
+func LintLineLength(maxWidth int) LintRule {
+	return func(source []byte, doc ast.Node) []LintDiagnostic {
+		var diagnostics []LintDiagnostic
+		code := codeLines(source, doc)
+		for i, line := range bytes.Split(source, []byte{lineDelim}) {
+			line = bytes.TrimRight(line, "\r")
+			if width := displayWidth(line); width > maxWidth && !code[i+1] {
+				diagnostics = append(diagnostics, LintDiagnostic{
+					Rule:     "line-length",
+					Message:  fmt.Sprintf("line is %d columns wide, more than %d", width, maxWidth),
+					Position: SourcePosition{Line: i + 1, Column: 1},
+				})
+			}
+		}
+		return diagnostics
+	}
+}
+
+// LintTrailingSpaces reports lines outside of code blocks that end with whitespace, which the
+// renderer removes. Hard line breaks written as trailing spaces are written with a backslash instead.
+func LintTrailingSpaces() LintRule {
+	return func(source []byte, doc ast.Node) []LintDiagnostic {
+		var diagnostics []LintDiagnostic
+		code := codeLines(source, doc)
+		for i, line := range bytes.Split(source, []byte{lineDelim}) {
+			line = bytes.TrimRight(line, "\r")
+			trimmed := bytes.TrimRight(line, " \t")
+			if len(trimmed) < len(line) && !code[i+1] {
+				diagnostics = append(diagnostics, LintDiagnostic{
+					Rule:     "trailing-spaces",
+					Message:  "line ends with whitespace",
+					Position: SourcePosition{Line: i + 1, Column: len(trimmed) + 1},
+				})
+			}
+		}
+		return diagnostics
+	}
+}
+
+// codeLines returns the numbers of the lines that belong to the content of code blocks.
+func codeLines(source []byte, doc ast.Node) map[int]bool {
+	lines := map[int]bool{}
+	isCodeBlock := func(n ast.Node) bool {
+		return n.Kind() == ast.KindCodeBlock || n.Kind() == ast.KindFencedCodeBlock
+	}
+	for _, n := range FindAll(doc, isCodeBlock) {
+		for i := 0; i < n.Lines().Len(); i++ {
+			lines[offsetPosition(source, n.Lines().At(i).Start).Line] = true
+		}
+	}
+	return lines
+}
@@ -0,0 +1,25 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	source := []byte("# Title\n\n- a\n- b\n\nSection\n-------\n\n* c  \n  + d\n\n```\ntrailing   \n" +
+		"a line of code that is rather long\n```\n\nA line of prose that is rather long\n")
+	var actual []string
+	for _, d := range Lint(source, LintBulletMarkers(), LintHeadingStyle(), LintLineLength(30), LintTrailingSpaces()) {
+		actual = append(actual, d.String())
+	}
+	assert.Equal(t, []string{
+		"6:1: heading uses setext style, but the first heading uses ATX style (heading-style)",
+		"9:1: bullet list uses '*', but the first bullet list uses '-' (bullet-markers)",
+		"9:4: line ends with whitespace (trailing-spaces)",
+		"10:3: bullet list uses '+', but the first bullet list uses '-' (bullet-markers)",
+		"17:1: line is 35 columns wide, more than 30 (line-length)",
+	}, actual)
+
+	assert.Empty(t, Lint(source))
+}
@@ -0,0 +1,229 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindLiquidTag is the NodeKind of LiquidTag nodes.
+var KindLiquidTag = ast.NewNodeKind("LiquidTag")
+
+// LiquidTag is a Liquid tag or output within a paragraph, such as {% link about.md %} or
+// {{ page.title }}.
+type LiquidTag struct {
+	ast.BaseInline
+	// Segment is the tag in the source, from "{%" or "{{" to "%}" or "}}"
+	Segment text.Segment
+}
+
+// Kind implements ast.Node.Kind
+func (n *LiquidTag) Kind() ast.NodeKind {
+	return KindLiquidTag
+}
+
+// Dump implements ast.Node.Dump
+func (n *LiquidTag) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Segment": string(n.Segment.Value(source))}, nil)
+}
+
+// KindLiquidBlock is the NodeKind of LiquidBlock nodes.
+var KindLiquidBlock = ast.NewNodeKind("LiquidBlock")
+
+// LiquidBlock is a line holding nothing but Liquid tags. The lines of a raw, highlight or comment
+// tag include its content and end tag, since Jekyll doesn't render the content as markdown.
+type LiquidBlock struct {
+	ast.BaseBlock
+	// closing matches the line of the end tag while the block is parsed, or is nil if the block ends
+	closing *regexp.Regexp
+}
+
+// Kind implements ast.Node.Kind
+func (n *LiquidBlock) Kind() ast.NodeKind {
+	return KindLiquidBlock
+}
+
+// IsRaw implements ast.Node.IsRaw
+func (n *LiquidBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump
+func (n *LiquidBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// liquidVerbatimTags are the tags whose content isn't markdown.
+var liquidVerbatimTags = map[string]bool{"raw": true, "highlight": true, "comment": true}
+
+// scanLiquidTag returns the length of the Liquid tag or output at the start of line, and the name
+// of the tag, which is empty for outputs.
+func scanLiquidTag(line []byte) (length int, name []byte, ok bool) {
+	if len(line) < 2 || line[0] != '{' || (line[1] != '{' && line[1] != '%') {
+		return 0, nil, false
+	}
+	end := []byte("}}")
+	i := 2
+	if line[1] == '%' {
+		end = []byte("%}")
+		if i < len(line) && line[i] == '-' {
+			i++
+		}
+		i += util.TrimLeftSpaceLength(line[i:])
+		nameStart := i
+		for i < len(line) && (util.IsAlphaNumeric(line[i]) || line[i] == '_') {
+			i++
+		}
+		if i == nameStart {
+			return 0, nil, false
+		}
+		name = line[nameStart:i]
+	}
+	for i < len(line) && line[i] != '\n' {
+		switch {
+		case bytes.HasPrefix(line[i:], end):
+			return i + len(end), name, true
+		case line[i] == '"' || line[i] == '\'':
+			closing := bytes.IndexByte(line[i+1:], line[i])
+			if closing < 0 {
+				return 0, nil, false
+			}
+			i += closing + 2
+		default:
+			i++
+		}
+	}
+	return 0, nil, false
+}
+
+type liquidTagParser struct{}
+
+// NewLiquidTagParser returns a parser.InlineParser that parses Liquid tags and outputs into
+// LiquidTag nodes.
+func NewLiquidTagParser() parser.InlineParser {
+	return &liquidTagParser{}
+}
+
+// Trigger implements parser.InlineParser.Trigger
+func (p *liquidTagParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Parse implements parser.InlineParser.Parse
+func (p *liquidTagParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	length, _, ok := scanLiquidTag(line)
+	if !ok {
+		return nil
+	}
+	block.Advance(length)
+	return &LiquidTag{Segment: segment.WithStop(segment.Start + length)}
+}
+
+type liquidBlockParser struct{}
+
+// NewLiquidBlockParser returns a parser.BlockParser that parses lines holding nothing but Liquid tags
+// into LiquidBlock nodes. A raw, highlight or comment tag with an end tag on a later line of its own
+// takes in the lines up to it.
+func NewLiquidBlockParser() parser.BlockParser {
+	return &liquidBlockParser{}
+}
+
+// Trigger implements parser.BlockParser.Trigger
+func (p *liquidBlockParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Open implements parser.BlockParser.Open
+func (p *liquidBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	var lastName []byte
+	for i := pos; !util.IsBlank(line[i:]); {
+		length, name, ok := scanLiquidTag(line[i:])
+		if !ok {
+			return nil, parser.NoChildren
+		}
+		lastName = name
+		i += length
+		i += util.TrimLeftSpaceLength(line[i:])
+	}
+	node := &LiquidBlock{}
+	node.Lines().Append(segment.WithStart(segment.Start + pos))
+	reader.Advance(segment.Len() - 1)
+
+	if liquidVerbatimTags[string(lastName)] {
+		closing := `[ \t]*\{%-?\s*end` + regexp.QuoteMeta(string(lastName)) + `\s*-?%\}[ \t]*`
+		// Only take in lines if the tag is ended, or the rest of the document would be
+		if regexp.MustCompile(`(?m)^` + closing + `\r?$`).Match(reader.Source()[segment.Stop:]) {
+			node.closing = regexp.MustCompile(`^` + closing + `\r?\n?$`)
+		}
+	}
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.Continue
+func (p *liquidBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*LiquidBlock)
+	if n.closing == nil {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	if n.closing.Match(line) {
+		n.closing = nil
+	}
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.Close
+func (p *liquidBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	node.(*LiquidBlock).closing = nil
+}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph. Tags on a line of their
+// own within a paragraph stay inline, like links.
+func (p *liquidBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine
+func (p *liquidBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func (r *Renderer) renderLiquidTag(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		value := node.(*LiquidTag).Segment.Value(r.rc.source)
+		r.rc.writer.WriteBytes(value)
+		if len(value) > 0 {
+			r.rc.lastRune = rune(value[len(value)-1])
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *Renderer) renderLiquidBlock(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.rc.writer.SetVerbatim(true)
+		lines := node.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			r.rc.writer.WriteBytes(line.Value(r.rc.source))
+			r.rc.writer.FlushLine()
+		}
+		r.rc.writer.SetVerbatim(false)
+	}
+	return ast.WalkContinue
+}
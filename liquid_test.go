@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestLiquidTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Inline",
+			"Welcome to {{ site.title | append: \"_}}\" }}, see {% link _posts/a_b.md %}.\n",
+			"Welcome to {{ site.title | append: \"_}}\" }}, see {% link _posts/a_b.md %}.\n",
+		},
+		{
+			"Block tags",
+			"{% if page.show_toc %}\n\n* __toc__\n\n{% endif %}\n{% include note.html content='a_b' %}\n",
+			"{% if page.show_toc %}\n\n* **toc**\n\n{% endif %}\n{% include note.html content='a_b' %}\n",
+		},
+		{
+			"Verbatim content",
+			"{% raw %}\n* {{ a_b }}\n\n  __x__\n{% endraw %}\n\n{%- highlight ruby -%}\n# comment\n{%- endhighlight -%}\n",
+			"{% raw %}\n* {{ a_b }}\n\n  __x__\n{% endraw %}\n\n{%- highlight ruby -%}\n# comment\n{%- endhighlight -%}\n",
+		},
+		{
+			"Unended verbatim tag",
+			"{% comment %}\n* __a__\n",
+			"{% comment %}\n* **a**\n",
+		},
+		{
+			"Not tags",
+			"{%%} and {{ a_b\n",
+			"{%%} and {{ a_b\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithLiquidTags(LiquidTagsPreserve))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestLiquidTagsTranslation(t *testing.T) {
+	source := "Read {{ page.title }} on {% link about.md %} today\n\n{% include card.html title=\"Hello\" %}\n"
+	recorder := &recordingTransformer{}
+	r := NewRenderer(WithLiquidTags(LiquidTagsPreserve), WithTextTransformer(recorder))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, source, buf.String())
+	assert.Equal(t, []string{"Read", "on", "today"}, recorder.calls)
+}
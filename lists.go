@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ListNormalizer is a parser.ASTTransformer that normalizes lists in the AST the way the Renderer
+// normalizes them when rendering, so documents rendered by other renderers, or inspected after
+// parsing, have the same lists. Ordered lists are renumbered according to ListNumbering, and the
+// offset of each list item is set to the indentation the Renderer uses for its nested content
+// according to NestedListLength and ListIndentWidth. Task list markers are only recorded as checked or not in the AST,
+// so they're normalized by the Renderer alone.
+type ListNormalizer struct {
+	config *Config
+}
+
+var _ parser.ASTTransformer = &ListNormalizer{}
+
+// NewListNormalizer returns a new ListNormalizer configured by options, using the same defaults as
+// the Renderer.
+func NewListNormalizer(options ...Option) *ListNormalizer {
+	return &ListNormalizer{config: NewConfig(options...)}
+}
+
+// Transform implements parser.ASTTransformer.Transform
+func (t *ListNormalizer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	t.Normalize(doc)
+}
+
+// Normalize normalizes the lists of the AST rooted at n.
+func (t *ListNormalizer) Normalize(n ast.Node) {
+	for _, node := range FindAll(n, func(n ast.Node) bool { return n.Kind() == ast.KindList }) {
+		list := node.(*ast.List)
+		if list.IsOrdered() && t.config.ListNumbering == ListNumberingFromOne {
+			list.Start = 1
+		}
+		num := list.Start
+		for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+			// The item marker is the number, if any, and the marker character
+			marker := []byte{list.Marker}
+			if list.IsOrdered() {
+				marker = append([]byte(strconv.Itoa(num)), marker...)
+				num++
+			}
+			if item, ok := item.(*ast.ListItem); ok {
+				_, item.Offset = t.config.listItemPrefix(marker)
+			}
+		}
+	}
+}
+
+// listItemPrefix returns the prefix of the first line of a list item with marker, such as "1." or
+// "-", and the width of the indentation of the lines after it.
+func (c *Config) listItemPrefix(marker []byte) ([]byte, int) {
+	// Content indented 4 or more columns past the marker and a space is an indented code block
+	maxIndent := len(marker) + 4
+	if c.ListIndentWidth > ListIndentWidthMarker {
+		width := min(max(int(c.ListIndentWidth), len(marker)+1), maxIndent)
+		return append(marker, bytes.Repeat([]byte{' '}, width-len(marker))...), width
+	}
+	prefix := append(marker, ' ')
+	return prefix, min(len(prefix)*int(max(c.NestedListLength, NestedListLengthMinimum)), maxIndent)
+}
@@ -0,0 +1,98 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestListNormalizer(t *testing.T) {
+	assert := assert.New(t)
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithASTTransformers(
+		util.Prioritized(NewListNormalizer(WithListNumbering(ListNumberingFromOne)), 0),
+	)))
+	source := []byte("3.    three\n4. four\n\n   * nested\n\n9) nine\n10) ten\n")
+
+	buf := bytes.Buffer{}
+	assert.NoError(md.Convert(source, &buf))
+	assert.Equal("<ol>\n<li>\n<p>three</p>\n</li>\n<li>\n<p>four</p>\n<ul>\n<li>nested</li>\n</ul>\n</li>\n</ol>\n"+
+		"<ol>\n<li>nine</li>\n<li>ten</li>\n</ol>\n", buf.String())
+
+	var offsets []int
+	doc := md.Parser().Parse(text.NewReader(source))
+	for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindListItem }) {
+		offsets = append(offsets, n.(*ast.ListItem).Offset)
+	}
+	// Items are indented by the width of the prefix the Renderer writes, from 1.
+	assert.Equal([]int{3, 3, 2, 3, 3}, offsets)
+}
+
+func TestListNormalizerIndentWidth(t *testing.T) {
+	source := []byte("- a\n  1. b\n\n8. c\n9. d\n10. e\n")
+	testCases := []struct {
+		name     string
+		options  []Option
+		expected []int
+	}{
+		{"Nested list length", []Option{WithNestedListLength(2)}, []int{4, 6, 6, 6, 7}},
+		{"List indent width", []Option{WithListIndentWidth(4)}, []int{4, 4, 4, 4, 4}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := goldmark.New().Parser().Parse(text.NewReader(source))
+			NewListNormalizer(tc.options...).Normalize(doc)
+			var offsets []int
+			for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindListItem }) {
+				offsets = append(offsets, n.(*ast.ListItem).Offset)
+			}
+			assert.Equal(t, tc.expected, offsets)
+		})
+	}
+}
+
+// TestTaskCheckBox tests that task list markers are written as markdown and normalized
+func TestTaskCheckBox(t *testing.T) {
+	source := []byte("- [X] done\n- [ ]   todo\n- [x] also done\n\n  - [ ] nested\n")
+	testCases := []struct {
+		name     string
+		options  []Option
+		expected string
+	}{
+		{
+			"Normalized",
+			[]Option{},
+			"- [x] done\n- [ ] todo\n- [x] also done\n\n  - [ ] nested\n",
+		},
+		{
+			"Preserved",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"- [X] done\n- [ ] todo\n- [x] also done\n\n  - [ ] nested\n",
+		},
+		{
+			"Uppercase",
+			[]Option{WithTaskCheckBoxCase(TaskCheckBoxCaseUpper)},
+			"- [X] done\n- [ ] todo\n- [X] also done\n\n  - [ ] nested\n",
+		},
+		{
+			"Preserved over uppercase",
+			[]Option{WithTaskCheckBoxCase(TaskCheckBoxCaseUpper), WithStyleMode(StyleModePreserve)},
+			"- [X] done\n- [ ] todo\n- [x] also done\n\n  - [ ] nested\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(tc.options...)
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.TaskList, r))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert(source, &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,354 @@
+// Package llm translates markdown with large language models, through the chat completions API of
+// OpenAI or a compatible service, as a markdown.TextTransformer.
+//
+// A Translator sends the text of a document to the model in batches, retrying failed requests with
+// backoff, and checks that each translation keeps the placeholders of its text, such as template
+// variables and HTML tags, before the document is rendered with the translations:
+//
+//	t := &llm.Translator{
+//		Client:         &llm.OpenAI{APIKey: os.Getenv("OPENAI_API_KEY"), Model: "gpt-4o-mini"},
+//		TargetLanguage: "French",
+//		Glossary:       map[string]string{"pull request": "pull request"},
+//	}
+//	translated, err := t.TranslateMarkdown(ctx, source)
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/teekennedy/goldmark-markdown/cache"
+)
+
+// Client sends a conversation to a model.
+type Client interface {
+	// Complete returns the reply of the model to a system and a user message.
+	Complete(ctx context.Context, system, user string) (string, error)
+}
+
+// Defaults of the Translator fields left empty.
+const (
+	DefaultBatchSize  = 20
+	DefaultMaxRetries = 3
+	DefaultBackoff    = time.Second
+)
+
+// DefaultPlaceholders matches the placeholders that translations must keep: template actions such as
+// {{ .Name }}, named and printf verbs such as {name} and %s, HTML tags and URLs.
+var DefaultPlaceholders = regexp.MustCompile(`\{\{.*?\}\}|\{[A-Za-z_][\w.]*\}|%[-+# 0]*\d*(\.\d+)?[sdvfqx]|</?[A-Za-z][^<>]*>|https?://[^\s<>"]+`)
+
+// DefaultPrompt is the system prompt of a Translator without one.
+var DefaultPrompt = template.Must(template.New("prompt").Parse(`You translate text from markdown documents
+{{- with .SourceLanguage}} from {{.}}{{end}} to {{.TargetLanguage}}.
+{{- with .Tone}} Use a {{.}} tone.{{end}}
+The user sends a JSON array of strings. Reply with nothing but a JSON array of their translations, in the same order.
+Keep HTML tags, URLs, template actions such as {{"{{ .Name }}"}} and placeholders such as {name} or %s unchanged.
+{{- if .Glossary}}
+Translate these terms as given:
+{{- range $term, $translation := .Glossary}}
+- {{$term}}: {{$translation}}
+{{- end}}
+{{- end}}
+`))
+
+// PromptData is the data that the Prompt of a Translator is executed with.
+type PromptData struct {
+	SourceLanguage string
+	TargetLanguage string
+	Tone           string
+	Glossary       map[string]string
+}
+
+// Translator is a markdown.TextTransformer that replaces text with the translations made by a model
+// with Translate or TranslateMarkdown. Its methods are safe for concurrent use.
+type Translator struct {
+	// Client sends the requests to the model
+	Client Client
+	// SourceLanguage is the language of the text, which the model detects if empty
+	SourceLanguage string
+	// TargetLanguage is the language to translate to, such as "French" or "pt-BR"
+	TargetLanguage string
+	// Tone is the tone of the translations, such as "formal", if any
+	Tone string
+	// Glossary maps terms to their required translations
+	Glossary map[string]string
+	// Prompt is the template of the system prompt, executed with PromptData, DefaultPrompt if nil
+	Prompt *template.Template
+	// BatchSize is the number of texts translated per request, DefaultBatchSize if not positive
+	BatchSize int
+	// MaxRetries is the number of times a failed request is retried, DefaultMaxRetries if 0 and none
+	// if negative
+	MaxRetries int
+	// Backoff is the wait before the first retry, which is doubled for each retry after it,
+	// DefaultBackoff if not positive
+	Backoff time.Duration
+	// Placeholders matches the parts of text that translations must keep, DefaultPlaceholders if nil
+	Placeholders *regexp.Regexp
+	// Cache holds the translations to TargetLanguage of previous runs, which aren't requested again, if set
+	Cache cache.Store
+
+	mu           sync.RWMutex
+	translations map[string]string
+	// languages holds the Translators returned by ForLanguage by language
+	languages map[string]*Translator
+}
+
+var _ markdown.LanguageTransformer = &Translator{}
+
+// PlaceholderError is returned by Translate when a translation doesn't keep the placeholders of its
+// text after all retries.
+type PlaceholderError struct {
+	// Text is the translated text
+	Text string
+	// Translation is the last translation of the text
+	Translation string
+}
+
+func (e *PlaceholderError) Error() string {
+	return fmt.Sprintf("translation %q of %q changes its placeholders", e.Translation, e.Text)
+}
+
+// Transform implements markdown.TextTransformer, replacing text translated by Translate.
+func (t *Translator) Transform(textType markdown.TextType, text string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	translation, ok := t.translations[text]
+	return translation, ok
+}
+
+// ForLanguage implements markdown.LanguageTransformer, returning t if lang is its TargetLanguage, or
+// else a Translator to lang with the same settings, which is kept for the next call with lang.
+func (t *Translator) ForLanguage(lang string) markdown.TextTransformer {
+	return t.forLanguage(lang)
+}
+
+func (t *Translator) forLanguage(lang string) *Translator {
+	if lang == "" || lang == t.TargetLanguage {
+		return t
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if translator, ok := t.languages[lang]; ok {
+		return translator
+	}
+	translator := &Translator{
+		Client:         t.Client,
+		SourceLanguage: t.SourceLanguage,
+		TargetLanguage: lang,
+		Tone:           t.Tone,
+		Glossary:       t.Glossary,
+		Prompt:         t.Prompt,
+		BatchSize:      t.BatchSize,
+		MaxRetries:     t.MaxRetries,
+		Backoff:        t.Backoff,
+		Placeholders:   t.Placeholders,
+		Cache:          t.Cache,
+	}
+	if t.languages == nil {
+		t.languages = map[string]*Translator{}
+	}
+	t.languages[lang] = translator
+	return translator
+}
+
+// TranslateMarkdown translates the text of source with Translate, then formats source with the
+// translations and options, as markdown.Format does. A language set in the front matter of source
+// takes the place of TargetLanguage.
+func (t *Translator) TranslateMarkdown(ctx context.Context, source []byte, options ...markdown.Option) ([]byte, error) {
+	var texts []string
+	recorder := &textRecorder{record: func(text string) { texts = append(texts, text) }}
+	if _, err := markdown.Format(source, append(options[:len(options):len(options)], markdown.WithTextTransformer(recorder))...); err != nil {
+		return nil, err
+	}
+	if err := t.forLanguage(recorder.lang).Translate(ctx, texts); err != nil {
+		return nil, err
+	}
+	return markdown.Format(source, append(options[:len(options):len(options)], markdown.WithTextTransformer(t))...)
+}
+
+// Translate translates the texts that haven't been translated yet, in batches of BatchSize texts, for
+// Transform. It returns the first error of a batch that failed after all retries, keeping the
+// translations of the batches before it.
+func (t *Translator) Translate(ctx context.Context, texts []string) error {
+	system := bytes.Buffer{}
+	prompt := t.Prompt
+	if prompt == nil {
+		prompt = DefaultPrompt
+	}
+	err := prompt.Execute(&system, PromptData{
+		SourceLanguage: t.SourceLanguage,
+		TargetLanguage: t.TargetLanguage,
+		Tone:           t.Tone,
+		Glossary:       t.Glossary,
+	})
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	seen := map[string]bool{}
+	t.mu.RLock()
+	for _, text := range texts {
+		if _, ok := t.translations[text]; !ok && !seen[text] && strings.TrimSpace(text) != "" {
+			seen[text] = true
+			pending = append(pending, text)
+		}
+	}
+	t.mu.RUnlock()
+	if t.Cache != nil {
+		var missing []string
+		for _, text := range pending {
+			translation, ok, err := t.Cache.Get(cache.NewKey(t.TargetLanguage, markdown.TextTypePlain, text))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing = append(missing, text)
+				continue
+			}
+			t.mu.Lock()
+			if t.translations == nil {
+				t.translations = map[string]string{}
+			}
+			t.translations[text] = translation
+			t.mu.Unlock()
+		}
+		pending = missing
+	}
+
+	batchSize := t.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	for start := 0; start < len(pending); start += batchSize {
+		batch := pending[start:min(start+batchSize, len(pending))]
+		translations, err := t.translateBatch(ctx, system.String(), batch)
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		if t.translations == nil {
+			t.translations = map[string]string{}
+		}
+		for i, text := range batch {
+			t.translations[text] = translations[i]
+		}
+		t.mu.Unlock()
+		if t.Cache != nil {
+			for i, text := range batch {
+				if err := t.Cache.Put(cache.NewKey(t.TargetLanguage, markdown.TextTypePlain, text), translations[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// translateBatch returns the translations of batch, retrying with backoff.
+func (t *Translator) translateBatch(ctx context.Context, system string, batch []string) ([]string, error) {
+	user, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	retries := t.MaxRetries
+	if retries == 0 {
+		retries = DefaultMaxRetries
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		var translations []string
+		reply, err := t.Client.Complete(ctx, system, string(user))
+		if err == nil {
+			translations, err = t.parseReply(reply, batch)
+		}
+		if err == nil {
+			return translations, nil
+		}
+		var status *StatusError
+		if attempt >= retries || ctx.Err() != nil || (errors.As(err, &status) && !status.Retryable()) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+// codeFence matches a reply wrapped in a fenced code block, capturing its content.
+var codeFence = regexp.MustCompile("(?s)^```[a-z]*\\s*\\n(.*?)\\n?```$")
+
+// parseReply returns the translations of batch in reply, checking that each keeps the placeholders
+// of its text.
+func (t *Translator) parseReply(reply string, batch []string) ([]string, error) {
+	reply = strings.TrimSpace(reply)
+	if match := codeFence.FindStringSubmatch(reply); match != nil {
+		reply = match[1]
+	}
+	var translations []string
+	if err := json.Unmarshal([]byte(reply), &translations); err != nil {
+		return nil, fmt.Errorf("invalid reply %q: %w", reply, err)
+	}
+	if len(translations) != len(batch) {
+		return nil, fmt.Errorf("reply has %d translations for %d texts", len(translations), len(batch))
+	}
+	placeholders := t.Placeholders
+	if placeholders == nil {
+		placeholders = DefaultPlaceholders
+	}
+	for i, text := range batch {
+		if !samePlaceholders(placeholders, text, translations[i]) {
+			return nil, &PlaceholderError{Text: text, Translation: translations[i]}
+		}
+	}
+	return translations, nil
+}
+
+// samePlaceholders returns true if a and b have the same placeholders, in any order.
+func samePlaceholders(placeholders *regexp.Regexp, a, b string) bool {
+	pa, pb := placeholders.FindAllString(a, -1), placeholders.FindAllString(b, -1)
+	if len(pa) != len(pb) {
+		return false
+	}
+	sort.Strings(pa)
+	sort.Strings(pb)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// textRecorder is a markdown.TextTransformer that records the text passed to it without replacing
+// it, along with the language it's asked to translate to, if any.
+type textRecorder struct {
+	record func(text string)
+	lang   string
+}
+
+func (r *textRecorder) Transform(textType markdown.TextType, text string) (string, bool) {
+	r.record(text)
+	return "", false
+}
+
+// ForLanguage implements markdown.LanguageTransformer
+func (r *textRecorder) ForLanguage(lang string) markdown.TextTransformer {
+	r.lang = lang
+	return r
+}
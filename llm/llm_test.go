@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teekennedy/goldmark-markdown/cache"
+)
+
+// fakeClient translates by upper-casing each text, after failing the first failures requests.
+type fakeClient struct {
+	mu       sync.Mutex
+	failures []error
+	systems  []string
+	batches  [][]string
+	reply    func(texts []string) string
+}
+
+func (c *fakeClient) Complete(ctx context.Context, system, user string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var texts []string
+	if err := json.Unmarshal([]byte(user), &texts); err != nil {
+		return "", err
+	}
+	c.systems = append(c.systems, system)
+	c.batches = append(c.batches, texts)
+	if len(c.failures) > 0 {
+		err := c.failures[0]
+		c.failures = c.failures[1:]
+		return "", err
+	}
+	if c.reply != nil {
+		return c.reply(texts), nil
+	}
+	translations := make([]string, len(texts))
+	for i, text := range texts {
+		translations[i] = strings.ToUpper(text)
+	}
+	reply, _ := json.Marshal(translations)
+	return "```json\n" + string(reply) + "\n```", nil
+}
+
+func TestTranslateMarkdown(t *testing.T) {
+	client := &fakeClient{failures: []error{&StatusError{StatusCode: http.StatusTooManyRequests}}}
+	translator := &Translator{Client: client, TargetLanguage: "French", BatchSize: 2, Backoff: time.Millisecond}
+	translated, err := translator.TranslateMarkdown(context.Background(),
+		[]byte("# Title\n\nSome *text* and `code`.\n\n- Some\n- text\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "# TITLE\n\nSOME *TEXT* AND `code`.\n\n- SOME\n- TEXT\n", string(translated))
+	assert.Equal(t, [][]string{{"Title", "Some"}, {"Title", "Some"}, {"text", "and"}, {"."}}, client.batches)
+}
+
+func TestTranslateMarkdownLanguage(t *testing.T) {
+	client := &fakeClient{}
+	translator := &Translator{Client: client, TargetLanguage: "French"}
+	source := "---\nmarkdown-format:\n  lang: German\n---\nText\n"
+	translated, err := translator.TranslateMarkdown(context.Background(), []byte(source))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nmarkdown-format:\n  lang: German\n---\nTEXT\n", string(translated))
+	require.Len(t, client.systems, 1)
+	assert.Contains(t, client.systems[0], "to German.")
+	assert.Same(t, translator.ForLanguage("German"), translator.ForLanguage("German"))
+	assert.Same(t, translator, translator.ForLanguage("French"))
+}
+
+func TestTranslatePrompt(t *testing.T) {
+	client := &fakeClient{}
+	translator := &Translator{
+		Client:         client,
+		SourceLanguage: "English",
+		TargetLanguage: "German",
+		Tone:           "formal",
+		Glossary:       map[string]string{"pull request": "Pull-Request"},
+	}
+	require.NoError(t, translator.Translate(context.Background(), []string{"Open a pull request"}))
+	require.Len(t, client.systems, 1)
+	assert.Contains(t, client.systems[0], "from English to German. Use a formal tone.")
+	assert.Contains(t, client.systems[0], "- pull request: Pull-Request")
+}
+
+func TestTranslateErrors(t *testing.T) {
+	t.Run("Placeholders", func(t *testing.T) {
+		client := &fakeClient{reply: func(texts []string) string { return `["Bonjour {nom}"]` }}
+		translator := &Translator{Client: client, MaxRetries: 1, Backoff: time.Millisecond}
+		err := translator.Translate(context.Background(), []string{"Hello {name}"})
+		var placeholderErr *PlaceholderError
+		require.True(t, errors.As(err, &placeholderErr), "expected a PlaceholderError, got %v", err)
+		assert.Equal(t, "Bonjour {nom}", placeholderErr.Translation)
+		assert.Len(t, client.batches, 2)
+	})
+	t.Run("Not retryable", func(t *testing.T) {
+		client := &fakeClient{failures: []error{&StatusError{StatusCode: http.StatusBadRequest, Body: "bad"}}}
+		translator := &Translator{Client: client, Backoff: time.Millisecond}
+		err := translator.Translate(context.Background(), []string{"Hello"})
+		assert.EqualError(t, err, "Bad Request: bad")
+		assert.Len(t, client.batches, 1)
+	})
+	t.Run("No retries", func(t *testing.T) {
+		client := &fakeClient{failures: []error{&StatusError{StatusCode: http.StatusBadGateway}}}
+		translator := &Translator{Client: client, MaxRetries: -1}
+		assert.Error(t, translator.Translate(context.Background(), []string{"Hello"}))
+		assert.Len(t, client.batches, 1)
+	})
+	t.Run("Wrong length", func(t *testing.T) {
+		client := &fakeClient{reply: func(texts []string) string { return `[]` }}
+		translator := &Translator{Client: client, MaxRetries: -1}
+		err := translator.Translate(context.Background(), []string{"Hello"})
+		assert.EqualError(t, err, "reply has 0 translations for 1 texts")
+	})
+}
+
+func TestOpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		var request chatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.Model != "model" {
+			http.Error(w, "unknown model", http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, []chatMessage{{"system", "sys"}, {"user", "hi"}}, request.Messages)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"salut"}}]}`))
+	}))
+	defer server.Close()
+
+	client := &OpenAI{BaseURL: server.URL + "/v1", APIKey: "key", Model: "model"}
+	reply, err := client.Complete(context.Background(), "sys", "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "salut", reply)
+
+	client.Model = "other"
+	_, err = client.Complete(context.Background(), "sys", "hi")
+	var status *StatusError
+	require.True(t, errors.As(err, &status), "expected a StatusError, got %v", err)
+	assert.Equal(t, http.StatusNotFound, status.StatusCode)
+	assert.Equal(t, "unknown model", status.Body)
+	assert.False(t, status.Retryable())
+}
+
+func TestTranslateCache(t *testing.T) {
+	store := cache.Dir(t.TempDir())
+	client := &fakeClient{}
+	translator := &Translator{Client: client, TargetLanguage: "fr", Cache: store}
+	require.NoError(t, translator.Translate(context.Background(), []string{"one", "two"}))
+
+	translator = &Translator{Client: client, TargetLanguage: "fr", Cache: store}
+	translated, err := translator.TranslateMarkdown(context.Background(), []byte("one\n\ntwo\n\nthree\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\n\nTWO\n\nTHREE\n", string(translated))
+	assert.Equal(t, [][]string{{"one", "two"}, {"three"}}, client.batches)
+}
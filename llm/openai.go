@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultBaseURL is the base URL of the OpenAI API.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// OpenAI is a Client of the chat completions API of OpenAI, or of a compatible service at BaseURL.
+type OpenAI struct {
+	// BaseURL is the base URL of the API, DefaultBaseURL if empty
+	BaseURL string
+	// APIKey is sent as a bearer token, if set
+	APIKey string
+	// Model is the name of the model, such as "gpt-4o-mini"
+	Model string
+	// HTTPClient sends the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+var _ Client = &OpenAI{}
+
+// StatusError is returned by OpenAI.Complete for a response with an error status.
+type StatusError struct {
+	StatusCode int
+	// Body is the body of the response, which describes the error
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", http.StatusText(e.StatusCode), e.Body)
+}
+
+// Retryable returns true if the request may succeed when retried, after rate limiting or a server
+// error.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Complete implements Client.Complete.
+func (c *OpenAI) Complete(ctx context.Context, system, user string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: c.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	var chat chatResponse
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return "", err
+	}
+	if len(chat.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return chat.Choices[0].Message.Content, nil
+}
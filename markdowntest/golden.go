@@ -0,0 +1,100 @@
+// Package markdowntest provides utilities for testing markdown rendering.
+package markdowntest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/teekennedy/goldmark-markdown/internal/diff"
+	"github.com/yuin/goldmark"
+)
+
+const (
+	// InputSuffix is the file name suffix of golden test inputs.
+	InputSuffix = ".input.md"
+	// ExpectedSuffix is the file name suffix of the expected output for a golden test input.
+	ExpectedSuffix = ".expected.md"
+)
+
+// RunGolden converts each <name>.input.md file in dir with md and compares the result with the
+// contents of <name>.expected.md. Each pair runs as a subtest named <name>, which fails with a
+// unified diff if the output differs from what was expected.
+func RunGolden(t *testing.T, dir string, md goldmark.Markdown) {
+	t.Helper()
+	names, err := goldenNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatalf("no %s files found in %s", InputSuffix, dir)
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			actual, err := convertFile(md, filepath.Join(dir, name+InputSuffix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedPath := filepath.Join(dir, name+ExpectedSuffix)
+			expected, err := os.ReadFile(expectedPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := diff.Unified(expectedPath, "actual", expected, actual); d != nil {
+				t.Errorf("output differs from %s:\n%s", expectedPath, d)
+			}
+		})
+	}
+}
+
+// UpdateGolden converts each <name>.input.md file in dir with md and writes the result to
+// <name>.expected.md, creating or overwriting it. Use it to bootstrap or refresh a golden suite
+// after an intended change in output.
+func UpdateGolden(dir string, md goldmark.Markdown) error {
+	names, err := goldenNames(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		actual, err := convertFile(md, filepath.Join(dir, name+InputSuffix))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+ExpectedSuffix), actual, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goldenNames returns the sorted names of the golden test inputs in dir.
+func goldenNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), InputSuffix) {
+			names = append(names, strings.TrimSuffix(entry.Name(), InputSuffix))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// convertFile converts the markdown file at path with md.
+func convertFile(md goldmark.Markdown, path string) ([]byte, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	if err := md.Convert(source, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,37 @@
+package markdowntest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/yuin/goldmark"
+)
+
+func TestRunGolden(t *testing.T) {
+	md := goldmark.New(goldmark.WithRenderer(markdown.NewRenderer()))
+	RunGolden(t, "testdata/default", md)
+
+	md = goldmark.New(goldmark.WithRenderer(markdown.NewRenderer(
+		markdown.WithHeadingStyle(markdown.HeadingStyleSetext),
+	)))
+	RunGolden(t, "testdata/setext", md)
+}
+
+func TestUpdateGolden(t *testing.T) {
+	dir := t.TempDir()
+	input, err := os.ReadFile("testdata/default/basic.input.md")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "basic.input.md"), input, 0o644))
+	md := goldmark.New(goldmark.WithRenderer(markdown.NewRenderer()))
+
+	require.NoError(t, UpdateGolden(dir, md))
+	actual, err := os.ReadFile(filepath.Join(dir, "basic.expected.md"))
+	require.NoError(t, err)
+	expected, err := os.ReadFile("testdata/default/basic.expected.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
@@ -0,0 +1,121 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindMathBlock is the NodeKind of MathBlock nodes.
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathBlock is a display math block, from a line starting with $$ to a line ending with $$, such as
+// those rendered by MathJax or KaTeX. Its lines include the delimiters.
+type MathBlock struct {
+	ast.BaseBlock
+	// closed is true once the line ending the block is parsed
+	closed bool
+}
+
+// Kind implements ast.Node.Kind
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// IsRaw implements ast.Node.IsRaw
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathDelimiter is the delimiter at the start and end of display math.
+var mathDelimiter = []byte("$$")
+
+// mathClosingLine matches a line ending display math.
+var mathClosingLine = regexp.MustCompile(`(?m)\$\$[ \t]*\r?$`)
+
+type mathBlockParser struct{}
+
+// NewMathBlockParser returns a parser.BlockParser that parses display math delimited by $$ into
+// MathBlock nodes. Math that isn't closed is left to the other parsers.
+func NewMathBlockParser() parser.BlockParser {
+	return &mathBlockParser{}
+}
+
+// Trigger implements parser.BlockParser.Trigger
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser.Open
+func (p *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], mathDelimiter) {
+		return nil, parser.NoChildren
+	}
+	rest := util.TrimRightSpace(line[pos+len(mathDelimiter):])
+	closed := len(rest) >= len(mathDelimiter) && bytes.HasSuffix(rest, mathDelimiter)
+	// Only take in lines if the math is closed, or the rest of the document would be
+	if !closed && !mathClosingLine.Match(reader.Source()[segment.Stop:]) {
+		return nil, parser.NoChildren
+	}
+	node := &MathBlock{closed: closed}
+	node.Lines().Append(segment.WithStart(segment.Start + pos))
+	reader.Advance(segment.Len() - 1)
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.Continue
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*MathBlock)
+	if n.closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	n.closed = bytes.HasSuffix(util.TrimRightSpace(line), mathDelimiter)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.Close
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	node.(*MathBlock).closed = true
+}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph. Math within a paragraph
+// stays part of its text.
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func (r *Renderer) renderMathBlock(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.rc.writer.SetVerbatim(true)
+		lines := node.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			r.rc.writer.WriteBytes(line.Value(r.rc.source))
+			r.rc.writer.FlushLine()
+		}
+		r.rc.writer.SetVerbatim(false)
+	}
+	return ast.WalkContinue
+}
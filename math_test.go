@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestMathBlocks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Block",
+			"Text\n\n$$\nx_1 * y_2 = \\frac{a_b}{c}   \\\\\n# not a heading\n$$\n\nMore *text*\n",
+			"Text\n\n$$\nx_1 * y_2 = \\frac{a_b}{c}   \\\\\n# not a heading\n$$\n\nMore *text*\n",
+		},
+		{
+			"Single line",
+			"$$ a_1 * b_2 $$\n\n* __x__\n",
+			"$$ a_1 * b_2 $$\n\n* **x**\n",
+		},
+		{
+			"Closing line with content",
+			"$$ \\begin{aligned}\na_1 &= b_2\n\\end{aligned} $$\n",
+			"$$ \\begin{aligned}\na_1 &= b_2\n\\end{aligned} $$\n",
+		},
+		{
+			"In containers",
+			"> $$\n> a_1 *b*\n> $$\n\n- item\n\n  $$\n  a_1 *b*\n  $$\n",
+			"> $$\n> a_1 *b*\n> $$\n\n- item\n\n  $$\n  a_1 *b*\n  $$\n",
+		},
+		{
+			"Unclosed",
+			"$$\n__a__\n",
+			"$$\n**a**\n",
+		},
+		{
+			"Within paragraph",
+			"Text\n$$\n__a__\n$$\n",
+			"Text\n$$\n**a**\n$$\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithMathBlocks(MathBlocksPreserve))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestMathBlocksTranslation(t *testing.T) {
+	source := "The formula\n\n$$\nE = mc^2\n$$\n\nis famous\n"
+	recorder := &recordingTransformer{}
+	r := NewRenderer(WithMathBlocks(MathBlocksPreserve), WithTextTransformer(recorder))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, source, buf.String())
+	assert.Equal(t, []string{"The formula", "is famous"}, recorder.calls)
+}
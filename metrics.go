@@ -0,0 +1,70 @@
+package markdown
+
+import (
+	"io"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Metrics describes the work done by a single call to Render. Metrics are only collected when the
+// renderer is configured WithMetrics.
+type Metrics struct {
+	// NodeCounts holds the number of rendered nodes of each kind.
+	NodeCounts map[ast.NodeKind]int
+	// BytesWritten is the number of bytes written to the output.
+	BytesWritten int
+	// TransformerCalls is the number of times the TextTransformer was invoked.
+	TransformerCalls int
+	// TransformerLatency is the total time spent inside the TextTransformer.
+	TransformerLatency time.Duration
+}
+
+// newMetrics returns an empty Metrics object
+func newMetrics() *Metrics {
+	return &Metrics{NodeCounts: map[ast.NodeKind]int{}}
+}
+
+// countingWriter counts the bytes written to Writer, for the BytesWritten of output that's buffered
+// before it's written.
+type countingWriter struct {
+	io.Writer
+	written int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.written += n
+	return n, err
+}
+
+// Metrics returns the metrics collected during the most recent call to Render, or the zero value if
+// metrics collection is disabled.
+func (r *Renderer) Metrics() Metrics {
+	r.metricsMu.Lock()
+	defer r.metricsMu.Unlock()
+	if r.metrics == nil {
+		return Metrics{}
+	}
+	return *r.metrics
+}
+
+// transformText passes text to the configured TextTransformer, or the one for the TargetLanguage,
+// recording metrics if enabled.
+func (r *Renderer) transformText(textType TextType, text string) (string, bool) {
+	var result string
+	var ok bool
+	if r.rc.metrics == nil {
+		result, ok = r.rc.transformer.Transform(textType, text)
+	} else {
+		start := time.Now()
+		result, ok = r.rc.transformer.Transform(textType, text)
+		r.rc.metrics.TransformerLatency += time.Since(start)
+		r.rc.metrics.TransformerCalls++
+	}
+	// Review comments are written for the blocks whose text changed
+	if ok && result != text {
+		r.rc.textChanged = true
+	}
+	return result, ok
+}
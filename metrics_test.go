@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+)
+
+// TestMetrics tests that render metrics are collected when enabled
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("# Title\n\nSome *emphasis* and *more*.\n")
+	translations := MapTransformer{"Title": "标题"}
+
+	renderer := NewRenderer(WithMetrics(true), WithTextTransformer(translations))
+	md := goldmark.New(goldmark.WithRenderer(renderer))
+	buf := bytes.Buffer{}
+	assert.NoError(md.Convert(source, &buf))
+
+	metrics := renderer.Metrics()
+	assert.Equal(1, metrics.NodeCounts[ast.KindDocument])
+	assert.Equal(1, metrics.NodeCounts[ast.KindHeading])
+	assert.Equal(2, metrics.NodeCounts[ast.KindEmphasis])
+	assert.Equal(buf.Len(), metrics.BytesWritten)
+	// One call for the heading, then one call per run of text in the paragraph
+	assert.Equal(6, metrics.TransformerCalls)
+
+	// Bytes are counted as written to the output, after validation and the template
+	tmpl := template.Must(template.New("page").Parse("---\ntitle: x\n---\n{{.Content}}"))
+	renderer = NewRenderer(WithMetrics(true), WithValidation(true), WithOutputTemplate(tmpl, nil))
+	md = goldmark.New(goldmark.WithRenderer(renderer))
+	buf.Reset()
+	assert.NoError(md.Convert(source, &buf))
+	assert.Equal(buf.Len(), renderer.Metrics().BytesWritten)
+
+	// Metrics are not collected by default
+	renderer = NewRenderer()
+	md = goldmark.New(goldmark.WithRenderer(renderer))
+	assert.NoError(md.Convert(source, &bytes.Buffer{}))
+	assert.Equal(Metrics{}, renderer.Metrics())
+}
@@ -0,0 +1,252 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindAdmonition is the NodeKind of Admonition nodes.
+var KindAdmonition = ast.NewNodeKind("Admonition")
+
+// Admonition is a Python-Markdown admonition, such as !!! note "Title", or a collapsible block, such as
+// ??? tip. Its children are the blocks of its content, which is indented by 4 spaces in the source.
+type Admonition struct {
+	ast.BaseBlock
+	// Marker is "!!!" for an admonition, or "???" or "???+" for a collapsible block
+	Marker []byte
+	// Classes are the type of the admonition followed by any other classes, e.g. "note inline end"
+	Classes []byte
+	// Title is the position of the title in the source without the quotes, or nil if there's none
+	Title *text.Segment
+}
+
+// Kind implements ast.Node.Kind
+func (n *Admonition) Kind() ast.NodeKind {
+	return KindAdmonition
+}
+
+// Dump implements ast.Node.Dump
+func (n *Admonition) Dump(source []byte, level int) {
+	kv := map[string]string{"Marker": string(n.Marker), "Classes": string(n.Classes)}
+	if n.Title != nil {
+		kv["Title"] = string(n.Title.Value(source))
+	}
+	ast.DumpHelper(n, source, level, kv, nil)
+}
+
+// KindAttributeList is the NodeKind of AttributeList nodes.
+var KindAttributeList = ast.NewNodeKind("AttributeList")
+
+// AttributeList is a Python-Markdown attribute list, such as {: #id .class } or { data-x="y" }, which
+// sets the attributes of the heading, paragraph or inline element it follows.
+type AttributeList struct {
+	ast.BaseInline
+	// Segment is the attribute list in the source, from "{" to "}"
+	Segment text.Segment
+}
+
+// Kind implements ast.Node.Kind
+func (n *AttributeList) Kind() ast.NodeKind {
+	return KindAttributeList
+}
+
+// Dump implements ast.Node.Dump
+func (n *AttributeList) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Segment": string(n.Segment.Value(source))}, nil)
+}
+
+// admonitionPattern matches the first line of an admonition, capturing its marker, classes and title.
+var admonitionPattern = regexp.MustCompile(`^(!!!|\?\?\?\+?) ?([\w-]+(?: +[\w-]+)*)(?: +"(.*)")? *\r?\n?$`)
+
+type admonitionParser struct{}
+
+// NewAdmonitionParser returns a parser.BlockParser that parses Python-Markdown admonitions and
+// collapsible blocks into Admonition nodes.
+func NewAdmonitionParser() parser.BlockParser {
+	return &admonitionParser{}
+}
+
+// Trigger implements parser.BlockParser.Trigger
+func (p *admonitionParser) Trigger() []byte {
+	return []byte{'!', '?'}
+}
+
+// Open implements parser.BlockParser.Open
+func (p *admonitionParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	match := admonitionPattern.FindSubmatchIndex(line[pos:])
+	if match == nil {
+		return nil, parser.NoChildren
+	}
+	start := segment.Start + pos
+	node := &Admonition{
+		Marker:  line[pos+match[2] : pos+match[3]],
+		Classes: line[pos+match[4] : pos+match[5]],
+	}
+	if match[6] >= 0 {
+		title := text.NewSegment(start+match[6], start+match[7])
+		node.Title = &title
+	}
+	reader.Advance(segment.Len() - 1)
+	return node, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser.Continue
+func (p *admonitionParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+	if util.IsBlank(line) {
+		return parser.Continue | parser.HasChildren
+	}
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), 4)
+	if pos < 0 {
+		return parser.Close
+	}
+	reader.AdvanceAndSetPadding(pos, padding)
+	return parser.Continue | parser.HasChildren
+}
+
+// Close implements parser.BlockParser.Close
+func (p *admonitionParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph. Python-Markdown only
+// starts an admonition after a blank line.
+func (p *admonitionParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine
+func (p *admonitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// scanAttributeList returns the length of the attribute list at the start of line, or 0 if there's
+// none. Without the optional ":" after "{", every attribute must be an id, a class or a key=value
+// pair, so prose in braces isn't taken for an attribute list.
+func scanAttributeList(line []byte) int {
+	if len(line) < 3 || line[0] != '{' {
+		return 0
+	}
+	end := bytes.IndexAny(line[1:], "{}\n") + 1
+	if end <= 0 || line[end] != '}' {
+		return 0
+	}
+	attributes := line[1:end]
+	loose := attributes[0] == ':'
+	if loose {
+		attributes = attributes[1:]
+	}
+	found := false
+	for i := util.TrimLeftSpaceLength(attributes); i < len(attributes); i += util.TrimLeftSpaceLength(attributes[i:]) {
+		start := i
+		for i < len(attributes) && !util.IsSpace(attributes[i]) && attributes[i] != '=' {
+			i++
+		}
+		switch {
+		case i < len(attributes) && attributes[i] == '=' && i > start:
+			i++
+			if i < len(attributes) && (attributes[i] == '"' || attributes[i] == '\'') {
+				closing := bytes.IndexByte(attributes[i+1:], attributes[i])
+				if closing < 0 {
+					return 0
+				}
+				i += closing + 2
+				found = true
+				continue
+			}
+		case (attributes[start] == '#' || attributes[start] == '.') && i > start+1:
+		case !loose:
+			return 0
+		}
+		// Python-Markdown ignores anything else in a list starting with "{:"
+		for i < len(attributes) && !util.IsSpace(attributes[i]) {
+			i++
+		}
+		found = true
+	}
+	if !found {
+		return 0
+	}
+	return end + 1
+}
+
+type attributeListParser struct{}
+
+// NewAttributeListParser returns a parser.InlineParser that parses Python-Markdown attribute lists
+// into AttributeList nodes.
+func NewAttributeListParser() parser.InlineParser {
+	return &attributeListParser{}
+}
+
+// Trigger implements parser.InlineParser.Trigger
+func (p *attributeListParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Parse implements parser.InlineParser.Parse
+func (p *attributeListParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	length := scanAttributeList(line)
+	if length == 0 {
+		return nil
+	}
+	block.Advance(length)
+	return &AttributeList{Segment: segment.WithStop(segment.Start + length)}
+}
+
+func (r *Renderer) renderAdmonition(node ast.Node, entering bool) ast.WalkStatus {
+	n := node.(*Admonition)
+	if entering {
+		r.rc.writer.WriteBytes(n.Marker)
+		r.rc.writer.WriteBytes([]byte{' '})
+		r.rc.writer.WriteBytes(n.Classes)
+		if n.Title != nil {
+			r.rc.writer.WriteBytes([]byte(` "`))
+			r.rc.writer.WriteBytes([]byte(r.admonitionTitle(*n.Title)))
+			r.rc.writer.WriteBytes([]byte{'"'})
+		}
+		r.rc.writer.EndLine()
+		if first := n.FirstChild(); first != nil && first.HasBlankPreviousLines() {
+			r.rc.writer.EndLine()
+		}
+		// The content is indented by 4 spaces regardless of the indent style, as Python-Markdown
+		// expects
+		r.rc.writer.PushPrefix([]byte("    "))
+	} else {
+		r.rc.writer.PopPrefix()
+	}
+	return ast.WalkContinue
+}
+
+// admonitionTitle returns the title of an admonition at segment, translated if configured. Titles are
+// plain text that ends at the last quote of the line, so quotes within them need no escaping.
+func (r *Renderer) admonitionTitle(segment text.Segment) string {
+	title := string(segment.Value(r.rc.source))
+	if r.rc.skipTranslation || (r.config.TextTransformer == nil && r.textHook == nil) ||
+		strings.TrimSpace(title) == "" {
+		return title
+	}
+	translation, ok := r.transformPlainText(TextSegment{Start: segment.Start, Stop: segment.Stop, Text: title})
+	if !ok {
+		return title
+	}
+	return strings.Join(strings.Fields(translation), " ")
+}
+
+func (r *Renderer) renderAttributeList(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := node.(*AttributeList)
+		r.rc.writer.WriteBytes(n.Segment.Value(r.rc.source))
+		r.rc.lastRune = '}'
+	}
+	return ast.WalkContinue
+}
@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestDialectMkDocs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Admonitions",
+			"!!! note \"A \"quoted\" title\"\n\n    Content __here__.\n\n    * item\n\n???+ tip inline end\n    Body\n\n!!! warning \"\"\n    x\n",
+			"!!! note \"A \"quoted\" title\"\n\n    Content **here**.\n\n    * item\n\n???+ tip inline end\n    Body\n\n!!! warning \"\"\n    x\n",
+		},
+		{
+			"Nested admonitions",
+			"* item\n\n    ??? example\n\n        !!! info\n            ```go\n            a_b()\n            ```\n",
+			"* item\n\n  ??? example\n\n      !!! info\n          ```go\n          a_b()\n          ```\n",
+		},
+		{
+			"Admonition after paragraph",
+			"Text\n!!! note\n    Content\n",
+			"Text\n!!! note\nContent\n",
+		},
+		{
+			"Highlighted code in admonition",
+			"!!! example\n    ``` py linenums=\"1\" hl_lines=\"2 3\" title=\"main.py\"\n    a_b()\n    ```\n",
+			"!!! example\n    ```py linenums=\"1\" hl_lines=\"2 3\" title=\"main.py\"\n    a_b()\n    ```\n",
+		},
+		{
+			"Attribute lists",
+			"# Title {#intro .big}\n\nA [link](x.md){: target=\"_blank\" } and {braces here}.\n{: .lead data-a_b='c d' }\n",
+			"# Title {#intro .big}\n\nA [link](x.md){: target=\"_blank\" } and {braces here}.\n{: .lead data-a_b='c d' }\n",
+		},
+		{
+			"Footnotes",
+			"Text[^1] and more[^note].\n\n[^1]: The __first__.\n    More.\n\n    ```\n    code\n    ```\n[^note]:\n\n[^unused]: Kept.\n",
+			"Text[^1] and more[^note].\n\n[^1]: The **first**.\n    More.\n\n    ```\n    code\n    ```\n[^note]:\n\n[^unused]: Kept.\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithDialect(DialectMkDocs))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestDialectMkDocsTranslation(t *testing.T) {
+	source := "## Setup {: #setup }\n\n!!! tip \"Read this\"\n    See the note[^a].\n\n[^a]: Details.\n"
+	recorder := &recordingTransformer{}
+	r := NewRenderer(WithDialect(DialectMkDocs), WithTextTransformer(recorder))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, source, buf.String())
+	assert.Equal(t, []string{"Setup", "Read this", "See the note", ".", "Details."}, recorder.calls)
+}
+
+func TestDialectCommonMark(t *testing.T) {
+	source := "!!! note\n    Content {: .x }\n"
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, "!!! note\nContent {: .x }\n", buf.String())
+}
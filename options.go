@@ -1,6 +1,9 @@
 package markdown
 
 import (
+	"text/template"
+
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/renderer"
 )
 
@@ -8,6 +11,15 @@ type TextTransformer interface {
 	Transform(textType TextType, text string) (string, bool)
 }
 
+// LanguageTransformer is a TextTransformer that can translate to other languages than its own. When
+// the TargetLanguage of a Renderer is set, text is passed to the TextTransformer returned by
+// ForLanguage instead.
+type LanguageTransformer interface {
+	TextTransformer
+	// ForLanguage returns the TextTransformer that translates to lang
+	ForLanguage(lang string) TextTransformer
+}
+
 // Config struct holds configurations for the markdown based renderer.
 type Config struct {
 	IndentStyle
@@ -15,7 +27,43 @@ type Config struct {
 	ThematicBreakStyle
 	ThematicBreakLength
 	NestedListLength
+	ListNumbering
+	BulletMarker
+	EmptyListItemStyle
+	HTMLComments
+	StyleMode
+	InlineJoin
+	EmphasisFlanking
+	HugoShortcodes
+	LiquidTags
+	Dialect
+	HeadingIDs
+	Whitespace
+	ListIndentWidth
+	BlankLines
+	CJKSpacing
+	FootnotePlacement
+	MathBlocks
+	HTMLEscaping
+	InlineHTML
+	ImageAttributes
+	TaskCheckBoxCase
+	EmphasisStyle
+	StrongStyle
+	LineWidth
+	HardBreakStyle
+	LinkStyle
+	TextEscaping
+	TableStyle
+	TargetLanguage
 	TextTransformer TextTransformer
+	LinkTransformer LinkTransformer
+	CollectMetrics  bool
+	Validate        bool
+	ReviewComments  bool
+	OutputTemplate  *OutputTemplate
+	NodeFilter      NodeFilter
+	RawKinds        []ast.NodeKind
 }
 
 // NewConfig returns a new Config with defaults and the given options.
@@ -26,7 +74,37 @@ func NewConfig(options ...Option) *Config {
 		ThematicBreakStyle:  ThematicBreakStyle(ThematicBreakStyleDashed),
 		ThematicBreakLength: ThematicBreakLength(ThematicBreakLengthMinimum),
 		NestedListLength:    NestedListLength(NestedListLengthMinimum),
+		ListNumbering:       ListNumbering(ListNumberingFromStart),
+		BulletMarker:        BulletMarker(BulletMarkerPreserve),
+		EmptyListItemStyle:  EmptyListItemStyle(EmptyListItemStyleBare),
+		HTMLComments:        HTMLComments(HTMLCommentsPreserve),
+		StyleMode:           StyleMode(StyleModeNormalize),
+		InlineJoin:          InlineJoin(InlineJoinSpace),
+		EmphasisFlanking:    EmphasisFlanking(EmphasisFlankingWordJoiner),
+		HugoShortcodes:      HugoShortcodes(HugoShortcodesNone),
+		LiquidTags:          LiquidTags(LiquidTagsNone),
+		Dialect:             Dialect(DialectCommonMark),
+		HeadingIDs:          HeadingIDs(HeadingIDsKeep),
+		Whitespace:          Whitespace(WhitespacePreserve),
+		ListIndentWidth:     ListIndentWidth(ListIndentWidthMarker),
+		BlankLines:          BlankLines(BlankLinesPreserve),
+		CJKSpacing:          CJKSpacing(CJKSpacingPreserve),
+		FootnotePlacement:   FootnotePlacement(FootnotePlacementPreserve),
+		MathBlocks:          MathBlocks(MathBlocksNone),
+		HTMLEscaping:        HTMLEscaping(HTMLEscapingBackslash),
+		InlineHTML:          InlineHTML(InlineHTMLPreserve),
+		ImageAttributes:     ImageAttributes(ImageAttributesNone),
+		TaskCheckBoxCase:    TaskCheckBoxCase(TaskCheckBoxCaseLower),
+		EmphasisStyle:       EmphasisStyle(EmphasisStyleAsterisk),
+		StrongStyle:         StrongStyle(StrongStyleAsterisk),
+		LineWidth:           LineWidth(LineWidthNone),
+		HardBreakStyle:      HardBreakStyle(HardBreakStyleBackslash),
+		LinkStyle:           LinkStyle(LinkStyleInline),
+		TextEscaping:        TextEscaping(TextEscapingMinimal),
+		TableStyle:          TableStyle(TableStyleCompact),
+		TargetLanguage:      "",
 		TextTransformer:     nil,
+		LinkTransformer:     nil,
 	}
 	for _, opt := range options {
 		opt.SetMarkdownOption(c)
@@ -47,8 +125,80 @@ func (c *Config) SetOption(name renderer.OptionName, value interface{}) {
 		c.ThematicBreakLength = value.(ThematicBreakLength)
 	case optNestedListLength:
 		c.NestedListLength = value.(NestedListLength)
+	case optListNumbering:
+		c.ListNumbering = value.(ListNumbering)
+	case optBulletMarker:
+		c.BulletMarker = value.(BulletMarker)
+	case optEmptyListItemStyle:
+		c.EmptyListItemStyle = value.(EmptyListItemStyle)
+	case optHTMLComments:
+		c.HTMLComments = value.(HTMLComments)
+	case optStyleMode:
+		c.StyleMode = value.(StyleMode)
+	case optInlineJoin:
+		c.InlineJoin = value.(InlineJoin)
+	case optEmphasisFlanking:
+		c.EmphasisFlanking = value.(EmphasisFlanking)
+	case optHugoShortcodes:
+		c.HugoShortcodes = value.(HugoShortcodes)
+	case optLiquidTags:
+		c.LiquidTags = value.(LiquidTags)
+	case optDialect:
+		c.Dialect = value.(Dialect)
 	case optTextTransformer:
 		c.TextTransformer = value.(TextTransformer)
+	case optLinkTransformer:
+		c.LinkTransformer = value.(LinkTransformer)
+	case optMetrics:
+		c.CollectMetrics = value.(bool)
+	case optValidation:
+		c.Validate = value.(bool)
+	case optReviewComments:
+		c.ReviewComments = value.(bool)
+	case optOutputTemplate:
+		c.OutputTemplate = value.(*OutputTemplate)
+	case optNodeFilter:
+		c.NodeFilter = value.(NodeFilter)
+	case optRawKinds:
+		c.RawKinds = value.([]ast.NodeKind)
+	case optHeadingIDs:
+		c.HeadingIDs = value.(HeadingIDs)
+	case optWhitespace:
+		c.Whitespace = value.(Whitespace)
+	case optListIndentWidth:
+		c.ListIndentWidth = value.(ListIndentWidth)
+	case optBlankLines:
+		c.BlankLines = value.(BlankLines)
+	case optCJKSpacing:
+		c.CJKSpacing = value.(CJKSpacing)
+	case optFootnotePlacement:
+		c.FootnotePlacement = value.(FootnotePlacement)
+	case optMathBlocks:
+		c.MathBlocks = value.(MathBlocks)
+	case optHTMLEscaping:
+		c.HTMLEscaping = value.(HTMLEscaping)
+	case optInlineHTML:
+		c.InlineHTML = value.(InlineHTML)
+	case optImageAttributes:
+		c.ImageAttributes = value.(ImageAttributes)
+	case optTaskCheckBoxCase:
+		c.TaskCheckBoxCase = value.(TaskCheckBoxCase)
+	case optEmphasisStyle:
+		c.EmphasisStyle = value.(EmphasisStyle)
+	case optStrongStyle:
+		c.StrongStyle = value.(StrongStyle)
+	case optLineWidth:
+		c.LineWidth = value.(LineWidth)
+	case optHardBreakStyle:
+		c.HardBreakStyle = value.(HardBreakStyle)
+	case optLinkStyle:
+		c.LinkStyle = value.(LinkStyle)
+	case optTextEscaping:
+		c.TextEscaping = value.(TextEscaping)
+	case optTableStyle:
+		c.TableStyle = value.(TableStyle)
+	case optTargetLanguage:
+		c.TargetLanguage = value.(TargetLanguage)
 	}
 }
 
@@ -270,7 +420,9 @@ func (o *withNestedListLength) SetMarkdownOption(c *Config) {
 	c.NestedListLength = o.value
 }
 
-// WithNestedListLength is a functional option that sets the length of nested list indentation.
+// WithNestedListLength is a functional option that sets the length of nested list indentation, as
+// a multiple of the width of the item prefix. It's capped at 3 columns past the content of the
+// item's first line, beyond which nested content would be read as an indented code block.
 func WithNestedListLength(style NestedListLength) interface {
 	renderer.Option
 	Option
@@ -278,6 +430,458 @@ func WithNestedListLength(style NestedListLength) interface {
 	return &withNestedListLength{style}
 }
 
+// ============================================================================
+// ListNumbering Option
+// ============================================================================
+
+// optListNumbering is an option name used in WithListNumbering
+const optListNumbering renderer.OptionName = "ListNumbering"
+
+// ListNumbering is an enum expressing how the items of ordered lists are numbered.
+type ListNumbering int
+
+const (
+	// ListNumberingFromStart numbers items sequentially from the list's start number. This is the
+	// default and zero value.
+	// Ex: 3. Foo
+	//     4. Bar
+	ListNumberingFromStart = iota
+	// ListNumberingFromOne numbers items sequentially from 1, regardless of the list's start number.
+	// Ex: 1. Foo
+	//     2. Bar
+	ListNumberingFromOne
+)
+
+type withListNumbering struct {
+	value ListNumbering
+}
+
+func (o *withListNumbering) SetConfig(c *renderer.Config) {
+	c.Options[optListNumbering] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withListNumbering) SetMarkdownOption(c *Config) {
+	c.ListNumbering = o.value
+}
+
+// WithListNumbering is a functional option that sets how ordered list items are numbered.
+func WithListNumbering(style ListNumbering) interface {
+	renderer.Option
+	Option
+} {
+	return &withListNumbering{style}
+}
+
+// ============================================================================
+// BulletMarker Option
+// ============================================================================
+
+// optBulletMarker is an option name used in WithBulletMarker
+const optBulletMarker renderer.OptionName = "BulletMarker"
+
+// BulletMarker is an enum expressing the marker used for the items of bullet lists.
+type BulletMarker int
+
+const (
+	// BulletMarkerPreserve uses the marker each list is written with in the source. This is the
+	// default and zero value.
+	BulletMarkerPreserve = iota
+	// BulletMarkerDash uses '-' character for bullet list items.
+	// Ex: - Foo
+	BulletMarkerDash
+	// BulletMarkerAsterisk uses '*' character for bullet list items.
+	// Ex: * Foo
+	BulletMarkerAsterisk
+	// BulletMarkerPlus uses '+' character for bullet list items.
+	// Ex: + Foo
+	BulletMarkerPlus
+)
+
+// Marker returns the marker character, or 0 for BulletMarkerPreserve
+func (b BulletMarker) Marker() byte {
+	return [...]byte{0, '-', '*', '+'}[b]
+}
+
+type withBulletMarker struct {
+	value BulletMarker
+}
+
+func (o *withBulletMarker) SetConfig(c *renderer.Config) {
+	c.Options[optBulletMarker] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withBulletMarker) SetMarkdownOption(c *Config) {
+	c.BulletMarker = o.value
+}
+
+// WithBulletMarker is a functional option that sets the marker used for bullet list items.
+func WithBulletMarker(style BulletMarker) interface {
+	renderer.Option
+	Option
+} {
+	return &withBulletMarker{style}
+}
+
+// ============================================================================
+// EmptyListItemStyle Option
+// ============================================================================
+
+// optEmptyListItemStyle is an option name used in WithEmptyListItemStyle
+const optEmptyListItemStyle renderer.OptionName = "EmptyListItemStyle"
+
+// EmptyListItemStyle is an enum expressing how list items without content are rendered.
+type EmptyListItemStyle int
+
+const (
+	// EmptyListItemStyleBare renders only the list marker. This is the default and zero value.
+	// Ex: -
+	EmptyListItemStyleBare = iota
+	// EmptyListItemStyleNBSP renders the list marker followed by a non-breaking space entity, so the
+	// item is visible in renderers that hide empty items.
+	// Ex: - &nbsp;
+	EmptyListItemStyleNBSP
+)
+
+// Placeholder returns the content written for an empty list item
+func (e EmptyListItemStyle) Placeholder() []byte {
+	return [...][]byte{nil, []byte("&nbsp;")}[e]
+}
+
+type withEmptyListItemStyle struct {
+	value EmptyListItemStyle
+}
+
+func (o *withEmptyListItemStyle) SetConfig(c *renderer.Config) {
+	c.Options[optEmptyListItemStyle] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withEmptyListItemStyle) SetMarkdownOption(c *Config) {
+	c.EmptyListItemStyle = o.value
+}
+
+// WithEmptyListItemStyle is a functional option that sets how list items without content are
+// rendered.
+func WithEmptyListItemStyle(style EmptyListItemStyle) interface {
+	renderer.Option
+	Option
+} {
+	return &withEmptyListItemStyle{style}
+}
+
+// ============================================================================
+// HTMLComments Option
+// ============================================================================
+
+// optHTMLComments is an option name used in WithHTMLComments
+const optHTMLComments renderer.OptionName = "HTMLComments"
+
+// HTMLComments is an enum expressing how HTML comments are rendered.
+type HTMLComments int
+
+const (
+	// HTMLCommentsPreserve renders HTML comments exactly as they appear in the source, and never
+	// passes them to the TextTransformer. This is the default and zero value.
+	HTMLCommentsPreserve = iota
+	// HTMLCommentsStrip removes HTML comments from the output.
+	HTMLCommentsStrip
+)
+
+type withHTMLComments struct {
+	value HTMLComments
+}
+
+func (o *withHTMLComments) SetConfig(c *renderer.Config) {
+	c.Options[optHTMLComments] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withHTMLComments) SetMarkdownOption(c *Config) {
+	c.HTMLComments = o.value
+}
+
+// WithHTMLComments is a functional option that sets whether HTML comments are preserved or
+// stripped.
+func WithHTMLComments(style HTMLComments) interface {
+	renderer.Option
+	Option
+} {
+	return &withHTMLComments{style}
+}
+
+// ============================================================================
+// StyleMode Option
+// ============================================================================
+
+// optStyleMode is an option name used in WithStyleMode
+const optStyleMode renderer.OptionName = "StyleMode"
+
+// StyleMode is an enum expressing whether markdown syntax is normalized or kept as written.
+type StyleMode int
+
+const (
+	// StyleModeNormalize renders every construct in the configured or canonical style. This is the
+	// default and zero value.
+	StyleModeNormalize = iota
+	// StyleModePreserve reuses the syntax of the source document wherever it is still valid, such as
+	// the delimiters of code spans, keeping diffs minimal when formatting existing documents.
+	StyleModePreserve
+)
+
+type withStyleMode struct {
+	value StyleMode
+}
+
+func (o *withStyleMode) SetConfig(c *renderer.Config) {
+	c.Options[optStyleMode] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withStyleMode) SetMarkdownOption(c *Config) {
+	c.StyleMode = o.value
+}
+
+// WithStyleMode is a functional option that sets whether source syntax is normalized or preserved.
+func WithStyleMode(mode StyleMode) interface {
+	renderer.Option
+	Option
+} {
+	return &withStyleMode{mode}
+}
+
+// ============================================================================
+// InlineJoin Option
+// ============================================================================
+
+// optInlineJoin is an option name used in WithInlineJoin
+const optInlineJoin renderer.OptionName = "InlineJoin"
+
+// InlineJoin is an enum expressing how translated text is joined to neighboring inline nodes, such
+// as emphasis and links, when the source separated them with whitespace.
+type InlineJoin int
+
+const (
+	// InlineJoinSpace keeps the whitespace from the source. This is the default and zero value.
+	// Ex: 中文 **加粗**
+	InlineJoinSpace = iota
+	// InlineJoinNone removes the whitespace.
+	// Ex: 中文**加粗**
+	InlineJoinNone
+	// InlineJoinSmart removes the whitespace if the text on both sides is written in a script that
+	// doesn't separate words with spaces, such as Chinese or Japanese, and keeps it otherwise.
+	// Ex: 中文**加粗** English
+	InlineJoinSmart
+)
+
+type withInlineJoin struct {
+	value InlineJoin
+}
+
+func (o *withInlineJoin) SetConfig(c *renderer.Config) {
+	c.Options[optInlineJoin] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withInlineJoin) SetMarkdownOption(c *Config) {
+	c.InlineJoin = o.value
+}
+
+// WithInlineJoin is a functional option that sets how translated text is joined to neighboring
+// inline nodes.
+func WithInlineJoin(join InlineJoin) interface {
+	renderer.Option
+	Option
+} {
+	return &withInlineJoin{join}
+}
+
+// ============================================================================
+// EmphasisFlanking Option
+// ============================================================================
+
+// optEmphasisFlanking is an option name used in WithEmphasisFlanking
+const optEmphasisFlanking renderer.OptionName = "EmphasisFlanking"
+
+// EmphasisFlanking is an enum expressing how emphasis is kept intact when its delimiters would no
+// longer be read as opening or closing it, such as when translated text puts punctuation inside
+// the delimiters and a letter outside them.
+type EmphasisFlanking int
+
+const (
+	// EmphasisFlankingWordJoiner inserts an invisible word joiner (U+2060) between the delimiter
+	// and the punctuation. This is the default and zero value.
+	// Ex: **粗体。⁠**文本
+	EmphasisFlankingWordJoiner = iota
+	// EmphasisFlankingHTML renders the emphasis as HTML tags instead.
+	// Ex: <strong>粗体。</strong>文本
+	EmphasisFlankingHTML
+	// EmphasisFlankingNone leaves the delimiters as they are.
+	// Ex: **粗体。**文本
+	EmphasisFlankingNone
+)
+
+type withEmphasisFlanking struct {
+	value EmphasisFlanking
+}
+
+func (o *withEmphasisFlanking) SetConfig(c *renderer.Config) {
+	c.Options[optEmphasisFlanking] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withEmphasisFlanking) SetMarkdownOption(c *Config) {
+	c.EmphasisFlanking = o.value
+}
+
+// WithEmphasisFlanking is a functional option that sets how emphasis is kept intact when its
+// delimiters wouldn't be read as such.
+func WithEmphasisFlanking(flanking EmphasisFlanking) interface {
+	renderer.Option
+	Option
+} {
+	return &withEmphasisFlanking{flanking}
+}
+
+// ============================================================================
+// HugoShortcodes Option
+// ============================================================================
+
+// optHugoShortcodes is an option name used in WithHugoShortcodes
+const optHugoShortcodes renderer.OptionName = "HugoShortcodes"
+
+// HugoShortcodes is an enum expressing whether Hugo shortcodes are parsed and how they're translated.
+type HugoShortcodes int
+
+const (
+	// HugoShortcodesNone parses shortcodes as markdown text. This is the default and zero value.
+	HugoShortcodesNone = iota
+	// HugoShortcodesPreserve parses shortcodes, such as {{< figure src="a.png" >}} or {{% note %}},
+	// and renders them exactly as they appear in the source. They're never escaped, wrapped or passed
+	// to the TextTransformer, and the content of paired {{< >}} shortcodes, which Hugo doesn't render
+	// as markdown, is kept verbatim too.
+	HugoShortcodesPreserve
+	// HugoShortcodesTranslateArguments parses shortcodes like HugoShortcodesPreserve, but passes the
+	// values of their double quoted arguments to the TextTransformer as plain text.
+	HugoShortcodesTranslateArguments
+)
+
+type withHugoShortcodes struct {
+	value HugoShortcodes
+}
+
+func (o *withHugoShortcodes) SetConfig(c *renderer.Config) {
+	c.Options[optHugoShortcodes] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withHugoShortcodes) SetMarkdownOption(c *Config) {
+	c.HugoShortcodes = o.value
+}
+
+// WithHugoShortcodes is a functional option that sets whether Hugo shortcodes are parsed and
+// preserved. Shortcodes are parsed by the Renderer's goldmark.Extender, so the option must be passed
+// to NewRenderer.
+func WithHugoShortcodes(shortcodes HugoShortcodes) interface {
+	renderer.Option
+	Option
+} {
+	return &withHugoShortcodes{shortcodes}
+}
+
+// ============================================================================
+// LiquidTags Option
+// ============================================================================
+
+// optLiquidTags is an option name used in WithLiquidTags
+const optLiquidTags renderer.OptionName = "LiquidTags"
+
+// LiquidTags is an enum expressing whether Liquid tags, as used by Jekyll, are parsed.
+type LiquidTags int
+
+const (
+	// LiquidTagsNone parses Liquid tags as markdown text. This is the default and zero value.
+	LiquidTagsNone = iota
+	// LiquidTagsPreserve parses Liquid tags, such as {% include note.html %} or {{ site.title }},
+	// and renders them exactly as they appear in the source. They're never escaped, wrapped or passed
+	// to the TextTransformer, and the content of raw, highlight and comment blocks is kept verbatim
+	// too.
+	LiquidTagsPreserve
+)
+
+type withLiquidTags struct {
+	value LiquidTags
+}
+
+func (o *withLiquidTags) SetConfig(c *renderer.Config) {
+	c.Options[optLiquidTags] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withLiquidTags) SetMarkdownOption(c *Config) {
+	c.LiquidTags = o.value
+}
+
+// WithLiquidTags is a functional option that sets whether Liquid tags are parsed and preserved. Tags
+// are parsed by the Renderer's goldmark.Extender, so the option must be passed to NewRenderer.
+func WithLiquidTags(tags LiquidTags) interface {
+	renderer.Option
+	Option
+} {
+	return &withLiquidTags{tags}
+}
+
+// ============================================================================
+// Dialect Option
+// ============================================================================
+
+// optDialect is an option name used in WithDialect
+const optDialect renderer.OptionName = "Dialect"
+
+// Dialect is an enum expressing which flavor of markdown documents are written in, which sets the
+// syntax extensions that are parsed and preserved.
+type Dialect int
+
+const (
+	// DialectCommonMark parses CommonMark with GitHub Flavored Markdown tables. This is the default
+	// and zero value.
+	DialectCommonMark = iota
+	// DialectMkDocs also parses the Python-Markdown extensions that MkDocs Material sites rely on:
+	// admonitions and collapsible blocks, such as !!! note "Title" or ???+ tip, attribute lists, such
+	// as {: #id .class }, and footnotes. Admonition titles are passed to the TextTransformer as plain
+	// text, and attribute lists are kept verbatim.
+	DialectMkDocs
+	// DialectPandoc also parses the pandoc extensions that are common in academic writing: fenced
+	// divs, such as ::: {.note}, bracketed spans, such as [text]{.smallcaps}, attribute lists and
+	// footnotes. The attributes are kept verbatim, while the content of divs and the text of spans are
+	// translated like the rest of the document.
+	DialectPandoc
+)
+
+type withDialect struct {
+	value Dialect
+}
+
+func (o *withDialect) SetConfig(c *renderer.Config) {
+	c.Options[optDialect] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withDialect) SetMarkdownOption(c *Config) {
+	c.Dialect = o.value
+}
+
+// WithDialect is a functional option that sets the flavor of markdown that documents are written in.
+// Syntax extensions are parsed by the Renderer's goldmark.Extender, so the option must be passed to
+// NewRenderer.
+func WithDialect(dialect Dialect) interface {
+	renderer.Option
+	Option
+} {
+	return &withDialect{dialect}
+}
+
 // ============================================================================
 // TextTransformer Option
 // ============================================================================
@@ -319,3 +923,1061 @@ func (t MapTransformer) Transform(textType TextType, text string) (string, bool)
 	v, ok := t[text]
 	return v, ok
 }
+
+// ============================================================================
+// LinkTransformer Option
+// ============================================================================
+
+// optLinkTransformer is an option name used in WithLinkTransformer
+const optLinkTransformer renderer.OptionName = "LinkTransformer"
+
+// LinkTransformer rewrites the destination and title of a link, image, or autolink as it's rendered.
+// Autolinks have no title, and are written as links if given one or a destination that can't be an
+// autolink. The destination of an email autolink is the email address.
+type LinkTransformer func(destination, title string, isImage bool) (string, string)
+
+type withLinkTransformer struct {
+	value LinkTransformer
+}
+
+func (o *withLinkTransformer) SetConfig(c *renderer.Config) {
+	c.Options[optLinkTransformer] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withLinkTransformer) SetMarkdownOption(c *Config) {
+	c.LinkTransformer = o.value
+}
+
+// WithLinkTransformer is a functional option that sets a function to rewrite link destinations and
+// titles, such as to rewrite relative paths or localize URLs.
+func WithLinkTransformer(transformer LinkTransformer) interface {
+	renderer.Option
+	Option
+} {
+	return &withLinkTransformer{transformer}
+}
+
+// ============================================================================
+// Metrics Option
+// ============================================================================
+
+// optMetrics is an option name used in WithMetrics
+const optMetrics renderer.OptionName = "Metrics"
+
+type withMetrics struct {
+	value bool
+}
+
+func (o *withMetrics) SetConfig(c *renderer.Config) {
+	c.Options[optMetrics] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withMetrics) SetMarkdownOption(c *Config) {
+	c.CollectMetrics = o.value
+}
+
+// WithMetrics is a functional option that enables collection of render metrics, which can be read
+// with Renderer.Metrics after each call to Render.
+func WithMetrics(enabled bool) interface {
+	renderer.Option
+	Option
+} {
+	return &withMetrics{enabled}
+}
+
+// ============================================================================
+// Validation Option
+// ============================================================================
+
+// optValidation is an option name used in WithValidation
+const optValidation renderer.OptionName = "Validation"
+
+type withValidation struct {
+	value bool
+}
+
+func (o *withValidation) SetConfig(c *renderer.Config) {
+	c.Options[optValidation] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withValidation) SetMarkdownOption(c *Config) {
+	c.Validate = o.value
+}
+
+// WithValidation is a functional option that reparses the rendered markdown with a strict parser for
+// the Dialect, such that Render returns a *ValidationError instead of writing output that loses
+// constructs of the source, such as tables or strikethrough that the dialect doesn't recognize.
+func WithValidation(enabled bool) interface {
+	renderer.Option
+	Option
+} {
+	return &withValidation{enabled}
+}
+
+// ============================================================================
+// Review Comments Option
+// ============================================================================
+
+// optReviewComments is an option name used in WithReviewComments
+const optReviewComments renderer.OptionName = "ReviewComments"
+
+type withReviewComments struct {
+	value bool
+}
+
+func (o *withReviewComments) SetConfig(c *renderer.Config) {
+	c.Options[optReviewComments] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withReviewComments) SetMarkdownOption(c *Config) {
+	c.ReviewComments = o.value
+}
+
+// WithReviewComments is a functional option that writes an HTML comment with the source of each
+// paragraph, heading or table after it, if the TextTransformer changed its text, so translations can
+// be proofread against the original.
+func WithReviewComments(enabled bool) interface {
+	renderer.Option
+	Option
+} {
+	return &withReviewComments{enabled}
+}
+
+// ============================================================================
+// Output Template Option
+// ============================================================================
+
+// optOutputTemplate is an option name used in WithOutputTemplate
+const optOutputTemplate renderer.OptionName = "OutputTemplate"
+
+// OutputTemplate is a template that rendered markdown is passed through before it's written.
+type OutputTemplate struct {
+	// Template is executed with OutputTemplateData
+	Template *template.Template
+	// Vars are the variables of OutputTemplateData, such as the target language of a translation
+	Vars map[string]string
+}
+
+type withOutputTemplate struct {
+	value *OutputTemplate
+}
+
+func (o *withOutputTemplate) SetConfig(c *renderer.Config) {
+	c.Options[optOutputTemplate] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withOutputTemplate) SetMarkdownOption(c *Config) {
+	c.OutputTemplate = o.value
+}
+
+// WithOutputTemplate is a functional option that writes the output of executing tmpl with the
+// rendered markdown and vars, as OutputTemplateData, instead of the rendered markdown, e.g. to add a
+// banner saying a page is machine translated:
+//
+//	> This page was machine translated to {{.Vars.lang}} on {{.Date.Format "2006-01-02"}}.
+//
+//	{{.Content}}
+func WithOutputTemplate(tmpl *template.Template, vars map[string]string) interface {
+	renderer.Option
+	Option
+} {
+	return &withOutputTemplate{&OutputTemplate{Template: tmpl, Vars: vars}}
+}
+
+// ============================================================================
+// NodeFilter Option
+// ============================================================================
+
+// optNodeFilter is an option name used in WithNodeFilter
+const optNodeFilter renderer.OptionName = "NodeFilter"
+
+// NodeFilter returns false for the nodes to leave out of the output, along with their children. It's
+// called as nodes are rendered, and again to separate the blocks around them, so it should depend on
+// nothing but the node.
+type NodeFilter func(node ast.Node) bool
+
+type withNodeFilter struct {
+	value NodeFilter
+}
+
+func (o *withNodeFilter) SetConfig(c *renderer.Config) {
+	c.Options[optNodeFilter] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withNodeFilter) SetMarkdownOption(c *Config) {
+	c.NodeFilter = o.value
+}
+
+// WithNodeFilter is a functional option that leaves the nodes that filter returns false for out of
+// the output, along with their children, such as to remove HTML blocks or internal notes when
+// publishing.
+func WithNodeFilter(filter NodeFilter) interface {
+	renderer.Option
+	Option
+} {
+	return &withNodeFilter{filter}
+}
+
+// ============================================================================
+// RawKinds Option
+// ============================================================================
+
+// optRawKinds is an option name used in WithRawKinds
+const optRawKinds renderer.OptionName = "RawKinds"
+
+type withRawKinds struct {
+	value []ast.NodeKind
+}
+
+func (o *withRawKinds) SetConfig(c *renderer.Config) {
+	c.Options[optRawKinds] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withRawKinds) SetMarkdownOption(c *Config) {
+	c.RawKinds = o.value
+}
+
+// WithRawKinds is a functional option that writes nodes of kinds as their source verbatim, such as
+// the nodes of goldmark extensions that the Renderer doesn't know about, which it otherwise fails to
+// render. These kinds take precedence over the renderers of the kinds, if any.
+func WithRawKinds(kinds ...ast.NodeKind) interface {
+	renderer.Option
+	Option
+} {
+	return &withRawKinds{kinds}
+}
+
+// ============================================================================
+// HeadingIDs Option
+// ============================================================================
+
+// optHeadingIDs is an option name used in WithHeadingIDs
+const optHeadingIDs renderer.OptionName = "HeadingIDs"
+
+// HeadingIDs is an enum expressing how the ids of headings are written, as attributes such as
+// {#id}, which are parsed by goldmark with parser.WithHeadingAttribute.
+type HeadingIDs int
+
+const (
+	// HeadingIDsKeep writes the attributes of headings as they're written in the source. This is the
+	// default and zero value.
+	HeadingIDsKeep = iota
+	// HeadingIDsStrip leaves the ids out of the attributes of headings, keeping their other
+	// attributes.
+	HeadingIDsStrip
+	// HeadingIDsGenerate writes an id for each heading, replacing the id in the source, if any. It's
+	// a slug of the rendered text of the heading, so translated headings get translated ids, made
+	// the way GitHub does, as IndexAnchors does.
+	HeadingIDsGenerate
+)
+
+type withHeadingIDs struct {
+	value HeadingIDs
+}
+
+func (o *withHeadingIDs) SetConfig(c *renderer.Config) {
+	c.Options[optHeadingIDs] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withHeadingIDs) SetMarkdownOption(c *Config) {
+	c.HeadingIDs = o.value
+}
+
+// WithHeadingIDs is a functional option that sets whether the ids of headings are kept, stripped or
+// generated from their text.
+func WithHeadingIDs(ids HeadingIDs) interface {
+	renderer.Option
+	Option
+} {
+	return &withHeadingIDs{ids}
+}
+
+// ============================================================================
+// Whitespace Option
+// ============================================================================
+
+// optWhitespace is an option name used in WithWhitespace
+const optWhitespace renderer.OptionName = "Whitespace"
+
+// Whitespace is an enum expressing how whitespace within the text of paragraphs and other prose is
+// rendered. Code, HTML and URLs are always kept as they are.
+type Whitespace int
+
+const (
+	// WhitespacePreserve renders whitespace as it appears in the source. This is the default and zero
+	// value.
+	WhitespacePreserve = iota
+	// WhitespaceCollapse collapses runs of spaces and tabs into a single space.
+	WhitespaceCollapse
+	// WhitespaceNormalize also replaces non-breaking, ideographic and other Unicode spaces with
+	// spaces before collapsing them, such as in OCR'd or pasted text.
+	WhitespaceNormalize
+)
+
+type withWhitespace struct {
+	value Whitespace
+}
+
+func (o *withWhitespace) SetConfig(c *renderer.Config) {
+	c.Options[optWhitespace] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withWhitespace) SetMarkdownOption(c *Config) {
+	c.Whitespace = o.value
+}
+
+// WithWhitespace is a functional option that sets whether whitespace in prose is kept, collapsed or
+// normalized.
+func WithWhitespace(whitespace Whitespace) interface {
+	renderer.Option
+	Option
+} {
+	return &withWhitespace{whitespace}
+}
+
+// ============================================================================
+// ListIndentWidth Option
+// ============================================================================
+
+// optListIndentWidth is an option name used in WithListIndentWidth
+const optListIndentWidth renderer.OptionName = "ListIndentWidth"
+
+// ListIndentWidth configures the column that the content of list items starts at, relative to the
+// item marker.
+type ListIndentWidth int
+
+const (
+	// ListIndentWidthMarker starts the content of each item after its marker and a space, so "- "
+	// is followed by 2 columns of indentation and "10. " by 4, multiplied by NestedListLength for
+	// the lines after the first. This is the default and zero value.
+	ListIndentWidthMarker = 0
+)
+
+type withListIndentWidth struct {
+	value ListIndentWidth
+}
+
+func (o *withListIndentWidth) SetConfig(c *renderer.Config) {
+	c.Options[optListIndentWidth] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withListIndentWidth) SetMarkdownOption(c *Config) {
+	c.ListIndentWidth = o.value
+}
+
+// WithListIndentWidth is a functional option that sets a fixed width for the indentation of the
+// content of list items, such as 4 for Python-Markdown, which requires nested content to be indented
+// by 4 spaces. Markers are padded with spaces to the width, which is widened for wider markers, and
+// narrowed to 4 spaces past the marker at most, beyond which content is read as an indented code
+// block. NestedListLength doesn't apply.
+func WithListIndentWidth(width ListIndentWidth) interface {
+	renderer.Option
+	Option
+} {
+	return &withListIndentWidth{width}
+}
+
+// ============================================================================
+// BlankLines Option
+// ============================================================================
+
+// optBlankLines is an option name used in WithBlankLines
+const optBlankLines renderer.OptionName = "BlankLines"
+
+// BlankLines is an enum expressing where blocks are separated by blank lines.
+type BlankLines int
+
+const (
+	// BlankLinesPreserve separates blocks by a blank line where the source does, or where the
+	// markdown requires one. This is the default and zero value.
+	BlankLinesPreserve = iota
+	// BlankLinesAround also separates headings, lists and code blocks from the blocks before and
+	// after them by a blank line, but for those in list items, which would make tight lists loose.
+	BlankLinesAround
+)
+
+type withBlankLines struct {
+	value BlankLines
+}
+
+func (o *withBlankLines) SetConfig(c *renderer.Config) {
+	c.Options[optBlankLines] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withBlankLines) SetMarkdownOption(c *Config) {
+	c.BlankLines = o.value
+}
+
+// WithBlankLines is a functional option that sets whether headings, lists and code blocks are always
+// separated from other blocks by a blank line, so the output doesn't depend on the blank lines of
+// the source or those recorded in an edited AST.
+func WithBlankLines(blankLines BlankLines) interface {
+	renderer.Option
+	Option
+} {
+	return &withBlankLines{blankLines}
+}
+
+// ============================================================================
+// CJKSpacing Option
+// ============================================================================
+
+// optCJKSpacing is an option name used in WithCJKSpacing
+const optCJKSpacing renderer.OptionName = "CJKSpacing"
+
+// CJKSpacing is an enum expressing whether Chinese and Japanese text is spaced from the Latin
+// letters and digits next to it, such as "使用 Go 1.22 编译" rather than "使用Go 1.22编译". Only
+// the text of prose is spaced, within runs of text, so code, URLs and markup are left as they are.
+type CJKSpacing int
+
+const (
+	// CJKSpacingPreserve keeps the spacing of the source. This is the default and zero value.
+	CJKSpacingPreserve = iota
+	// CJKSpacingAdd inserts a space between CJK characters and Latin letters or digits.
+	CJKSpacingAdd
+	// CJKSpacingRemove removes the spaces between CJK characters and Latin letters or digits.
+	CJKSpacingRemove
+)
+
+type withCJKSpacing struct {
+	value CJKSpacing
+}
+
+func (o *withCJKSpacing) SetConfig(c *renderer.Config) {
+	c.Options[optCJKSpacing] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withCJKSpacing) SetMarkdownOption(c *Config) {
+	c.CJKSpacing = o.value
+}
+
+// WithCJKSpacing is a functional option that sets whether spaces are added or removed between CJK
+// text and Latin letters or digits, such as in translated technical documentation.
+func WithCJKSpacing(spacing CJKSpacing) interface {
+	renderer.Option
+	Option
+} {
+	return &withCJKSpacing{spacing}
+}
+
+// ============================================================================
+// FootnotePlacement Option
+// ============================================================================
+
+// optFootnotePlacement is an option name used in WithFootnotePlacement
+const optFootnotePlacement renderer.OptionName = "FootnotePlacement"
+
+// FootnotePlacement is an enum expressing where footnote definitions are written. Definitions are
+// written in the order of their first references, with those referenced from other footnotes after
+// them, and unreferenced definitions last in the order of the source. Footnotes are moved by an AST
+// transformer that the Renderer adds to the parser in Extend.
+type FootnotePlacement int
+
+const (
+	// FootnotePlacementPreserve writes definitions where the parser puts them: together, where the
+	// first of them is in the source. This is the default and zero value.
+	FootnotePlacementPreserve = iota
+	// FootnotePlacementSection writes definitions at the end of the section where they're first
+	// referenced, before the next heading of the document.
+	FootnotePlacementSection
+	// FootnotePlacementEnd writes all definitions at the end of the document.
+	FootnotePlacementEnd
+)
+
+type withFootnotePlacement struct {
+	value FootnotePlacement
+}
+
+func (o *withFootnotePlacement) SetConfig(c *renderer.Config) {
+	c.Options[optFootnotePlacement] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withFootnotePlacement) SetMarkdownOption(c *Config) {
+	c.FootnotePlacement = o.value
+}
+
+// WithFootnotePlacement is a functional option that sets where footnote definitions are written.
+func WithFootnotePlacement(placement FootnotePlacement) interface {
+	renderer.Option
+	Option
+} {
+	return &withFootnotePlacement{placement}
+}
+
+// ============================================================================
+// MathBlocks Option
+// ============================================================================
+
+// optMathBlocks is an option name used in WithMathBlocks
+const optMathBlocks renderer.OptionName = "MathBlocks"
+
+// MathBlocks is an enum expressing whether display math blocks delimited by $$ are parsed. Math
+// parsed by another goldmark extension can be kept verbatim with WithRawKinds instead.
+type MathBlocks int
+
+const (
+	// MathBlocksNone parses display math as markdown text. This is the default and zero value.
+	MathBlocksNone = iota
+	// MathBlocksPreserve parses display math, from a line starting with $$ to a line ending with $$,
+	// and renders it exactly as it appears in the source. Formulas are never escaped, wrapped or
+	// passed to the TextTransformer.
+	MathBlocksPreserve
+)
+
+type withMathBlocks struct {
+	value MathBlocks
+}
+
+func (o *withMathBlocks) SetConfig(c *renderer.Config) {
+	c.Options[optMathBlocks] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withMathBlocks) SetMarkdownOption(c *Config) {
+	c.MathBlocks = o.value
+}
+
+// WithMathBlocks is a functional option that sets whether display math blocks are parsed and
+// preserved. Math is parsed by the Renderer's goldmark.Extender, so the option must be passed to
+// NewRenderer.
+func WithMathBlocks(math MathBlocks) interface {
+	renderer.Option
+	Option
+} {
+	return &withMathBlocks{math}
+}
+
+// ============================================================================
+// HTMLEscaping Option
+// ============================================================================
+
+// optHTMLEscaping is an option name used in WithHTMLEscaping
+const optHTMLEscaping renderer.OptionName = "HTMLEscaping"
+
+// HTMLEscaping is an enum expressing how HTML and character references in text that didn't come from
+// the source, such as the output of the TextTransformer, are escaped, so that a translation can't
+// inject HTML into the document. HTML and references written in the source are kept as they are.
+type HTMLEscaping int
+
+const (
+	// HTMLEscapingBackslash backslash-escapes the "<" of what would be read as an HTML tag or autolink
+	// and the "&" of character references, such as \<b> and \&amp;. This is the default and zero
+	// value.
+	HTMLEscapingBackslash = iota
+	// HTMLEscapingEntities writes every "<", ">" and "&" as the character references &lt;, &gt; and
+	// &amp;.
+	HTMLEscapingEntities
+	// HTMLEscapingCode writes HTML tags, comments and character references as code spans, such as
+	// `<kbd>`, showing them as written.
+	HTMLEscapingCode
+)
+
+type withHTMLEscaping struct {
+	value HTMLEscaping
+}
+
+func (o *withHTMLEscaping) SetConfig(c *renderer.Config) {
+	c.Options[optHTMLEscaping] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withHTMLEscaping) SetMarkdownOption(c *Config) {
+	c.HTMLEscaping = o.value
+}
+
+// WithHTMLEscaping is a functional option that sets how HTML in transformed text is escaped.
+func WithHTMLEscaping(escaping HTMLEscaping) interface {
+	renderer.Option
+	Option
+} {
+	return &withHTMLEscaping{escaping}
+}
+
+// ============================================================================
+// InlineHTML Option
+// ============================================================================
+
+// optInlineHTML is an option name used in WithInlineHTML
+const optInlineHTML renderer.OptionName = "InlineHTML"
+
+// InlineHTML is an enum expressing whether trivial inline HTML is rewritten as markdown.
+type InlineHTML int
+
+const (
+	// InlineHTMLPreserve keeps inline HTML as it is. This is the default and zero value.
+	InlineHTMLPreserve = iota
+	// InlineHTMLConvert rewrites <b> and <strong> as strong emphasis, <i> and <em> as emphasis,
+	// <code> as code spans and <br> as hard line breaks, if they have no attributes. Other HTML is
+	// kept as it is. The HTML is converted by an AST transformer that the Renderer adds to the parser
+	// in Extend.
+	InlineHTMLConvert
+)
+
+type withInlineHTML struct {
+	value InlineHTML
+}
+
+func (o *withInlineHTML) SetConfig(c *renderer.Config) {
+	c.Options[optInlineHTML] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withInlineHTML) SetMarkdownOption(c *Config) {
+	c.InlineHTML = o.value
+}
+
+// WithInlineHTML is a functional option that sets whether trivial inline HTML is rewritten as
+// markdown, such as when cleaning up documents exported from HTML.
+func WithInlineHTML(html InlineHTML) interface {
+	renderer.Option
+	Option
+} {
+	return &withInlineHTML{html}
+}
+
+// ============================================================================
+// ImageAttributes Option
+// ============================================================================
+
+// optImageAttributes is an option name used in WithImageAttributes
+const optImageAttributes renderer.OptionName = "ImageAttributes"
+
+// ImageAttributes is an enum expressing whether the sizes and attributes of images are parsed.
+type ImageAttributes int
+
+const (
+	// ImageAttributesNone parses image sizes and attributes as markdown text. This is the default and
+	// zero value.
+	ImageAttributesNone = iota
+	// ImageAttributesPreserve parses size suffixes, as in ![alt](img.png =300x200), and attribute
+	// lists right after images, as in ![alt](img.png){width=300}, and renders them as they appear in
+	// the source. They're never passed to the TextTransformer, while the alt text is translated as
+	// usual.
+	ImageAttributesPreserve
+)
+
+type withImageAttributes struct {
+	value ImageAttributes
+}
+
+func (o *withImageAttributes) SetConfig(c *renderer.Config) {
+	c.Options[optImageAttributes] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withImageAttributes) SetMarkdownOption(c *Config) {
+	c.ImageAttributes = o.value
+}
+
+// WithImageAttributes is a functional option that sets whether the sizes and attributes of images
+// are parsed and preserved. They're parsed by the Renderer's goldmark.Extender, so the option must be
+// passed to NewRenderer.
+func WithImageAttributes(attributes ImageAttributes) interface {
+	renderer.Option
+	Option
+} {
+	return &withImageAttributes{attributes}
+}
+
+// ============================================================================
+// TaskCheckBoxCase Option
+// ============================================================================
+
+// optTaskCheckBoxCase is an option name used in WithTaskCheckBoxCase
+const optTaskCheckBoxCase renderer.OptionName = "TaskCheckBoxCase"
+
+// TaskCheckBoxCase is an enum expressing the case of the x of checked task list items, as in
+// "- [x] done". In StyleModePreserve, the case of the source is kept.
+type TaskCheckBoxCase int
+
+const (
+	// TaskCheckBoxCaseLower writes checked boxes as [x]. This is the default and zero value.
+	TaskCheckBoxCaseLower = iota
+	// TaskCheckBoxCaseUpper writes checked boxes as [X].
+	TaskCheckBoxCaseUpper
+)
+
+type withTaskCheckBoxCase struct {
+	value TaskCheckBoxCase
+}
+
+func (o *withTaskCheckBoxCase) SetConfig(c *renderer.Config) {
+	c.Options[optTaskCheckBoxCase] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withTaskCheckBoxCase) SetMarkdownOption(c *Config) {
+	c.TaskCheckBoxCase = o.value
+}
+
+// WithTaskCheckBoxCase is a functional option that sets the case of the x of checked task list items.
+func WithTaskCheckBoxCase(checkBoxCase TaskCheckBoxCase) interface {
+	renderer.Option
+	Option
+} {
+	return &withTaskCheckBoxCase{checkBoxCase}
+}
+
+// ============================================================================
+// EmphasisStyle Option
+// ============================================================================
+
+// optEmphasisStyle is an option name used in WithEmphasisStyle
+const optEmphasisStyle renderer.OptionName = "EmphasisStyle"
+
+// EmphasisStyle is an enum expressing the delimiter of emphasis. The other delimiter is still used
+// where the chosen one would be read differently, such as for nested emphasis or for underscores
+// within a word.
+type EmphasisStyle int
+
+const (
+	// EmphasisStyleAsterisk uses '*' characters for emphasis. This is the default and zero value.
+	// Ex: *Foo*
+	EmphasisStyleAsterisk = iota
+	// EmphasisStyleUnderscore uses '_' characters for emphasis.
+	// Ex: _Foo_
+	EmphasisStyleUnderscore
+	// EmphasisStylePreserve uses the delimiter each emphasis is written with in the source.
+	EmphasisStylePreserve
+)
+
+// Delimiter returns the delimiter character, or 0 for EmphasisStylePreserve
+func (e EmphasisStyle) Delimiter() byte {
+	return [...]byte{'*', '_', 0}[e]
+}
+
+type withEmphasisStyle struct {
+	value EmphasisStyle
+}
+
+func (o *withEmphasisStyle) SetConfig(c *renderer.Config) {
+	c.Options[optEmphasisStyle] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withEmphasisStyle) SetMarkdownOption(c *Config) {
+	c.EmphasisStyle = o.value
+}
+
+// WithEmphasisStyle is a functional option that sets the delimiter of emphasis.
+func WithEmphasisStyle(style EmphasisStyle) interface {
+	renderer.Option
+	Option
+} {
+	return &withEmphasisStyle{style}
+}
+
+// ============================================================================
+// StrongStyle Option
+// ============================================================================
+
+// optStrongStyle is an option name used in WithStrongStyle
+const optStrongStyle renderer.OptionName = "StrongStyle"
+
+// StrongStyle is an enum expressing the delimiter of strong emphasis, with the same exceptions as
+// EmphasisStyle.
+type StrongStyle int
+
+const (
+	// StrongStyleAsterisk uses '**' for strong emphasis. This is the default and zero value.
+	// Ex: **Foo**
+	StrongStyleAsterisk = iota
+	// StrongStyleUnderscore uses '__' for strong emphasis.
+	// Ex: __Foo__
+	StrongStyleUnderscore
+	// StrongStylePreserve uses the delimiter each strong emphasis is written with in the source.
+	StrongStylePreserve
+)
+
+// Delimiter returns the delimiter character, or 0 for StrongStylePreserve
+func (s StrongStyle) Delimiter() byte {
+	return [...]byte{'*', '_', 0}[s]
+}
+
+type withStrongStyle struct {
+	value StrongStyle
+}
+
+func (o *withStrongStyle) SetConfig(c *renderer.Config) {
+	c.Options[optStrongStyle] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withStrongStyle) SetMarkdownOption(c *Config) {
+	c.StrongStyle = o.value
+}
+
+// WithStrongStyle is a functional option that sets the delimiter of strong emphasis.
+func WithStrongStyle(style StrongStyle) interface {
+	renderer.Option
+	Option
+} {
+	return &withStrongStyle{style}
+}
+
+// ============================================================================
+// LineWidth Option
+// ============================================================================
+
+// optLineWidth is an option name used in WithLineWidth
+const optLineWidth renderer.OptionName = "LineWidth"
+
+// LineWidth configures the column that the text of paragraphs is wrapped at.
+type LineWidth int
+
+const (
+	// LineWidthNone keeps the line breaks of paragraphs as they are in the source. This is the
+	// default and zero value.
+	LineWidthNone = 0
+)
+
+type withLineWidth struct {
+	value LineWidth
+}
+
+func (o *withLineWidth) SetConfig(c *renderer.Config) {
+	c.Options[optLineWidth] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withLineWidth) SetMarkdownOption(c *Config) {
+	c.LineWidth = o.value
+}
+
+// WithLineWidth is a functional option that reflows the text of paragraphs to lines of at most width
+// columns, including the prefixes of the blocks they're in, such as blockquote markers and list
+// indentation. Soft line breaks are replaced, and lines are broken at spaces and between CJK
+// characters, counted as two columns wide. Code spans, links, images, autolinks and inline HTML
+// aren't broken, so lines with them may be wider, as may lines with words wider than width.
+func WithLineWidth(width LineWidth) interface {
+	renderer.Option
+	Option
+} {
+	return &withLineWidth{width}
+}
+
+// ============================================================================
+// HardBreakStyle Option
+// ============================================================================
+
+// optHardBreakStyle is an option name used in WithHardBreakStyle
+const optHardBreakStyle renderer.OptionName = "HardBreakStyle"
+
+// HardBreakStyle is an enum expressing how hard line breaks are written.
+type HardBreakStyle int
+
+const (
+	// HardBreakStyleBackslash ends the line with a backslash. This is the default and zero value.
+	// Ex: Foo\
+	HardBreakStyleBackslash = iota
+	// HardBreakStyleSpaces ends the line with two spaces, which are invisible in most editors.
+	// Ex: "Foo  "
+	HardBreakStyleSpaces
+)
+
+type withHardBreakStyle struct {
+	value HardBreakStyle
+}
+
+func (o *withHardBreakStyle) SetConfig(c *renderer.Config) {
+	c.Options[optHardBreakStyle] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withHardBreakStyle) SetMarkdownOption(c *Config) {
+	c.HardBreakStyle = o.value
+}
+
+// WithHardBreakStyle is a functional option that sets how hard line breaks are written.
+func WithHardBreakStyle(style HardBreakStyle) interface {
+	renderer.Option
+	Option
+} {
+	return &withHardBreakStyle{style}
+}
+
+// ============================================================================
+// LinkStyle Option
+// ============================================================================
+
+// optLinkStyle is an option name used in WithLinkStyle
+const optLinkStyle renderer.OptionName = "LinkStyle"
+
+// LinkStyle is an enum expressing how the destinations and titles of links and images are written.
+type LinkStyle int
+
+const (
+	// LinkStyleInline writes destinations and titles in parentheses after the link text. This is the
+	// default and zero value.
+	// Ex: [Foo](https://example.com)
+	LinkStyleInline = iota
+	// LinkStyleReference writes links as references to definitions at the end of the document,
+	// labeled with numbers in order of first use. Links to the same destination with the same title
+	// share a definition. Images with sizes and links with empty destinations are written inline.
+	// Ex: [Foo][1] ... [1]: https://example.com
+	LinkStyleReference
+	// LinkStylePreserve writes links as they're written in the source, keeping reference links and
+	// their definitions, which the parser otherwise drops, as they are. Links whose destinations or
+	// titles the LinkTransformer changes are written inline.
+	LinkStylePreserve
+)
+
+type withLinkStyle struct {
+	value LinkStyle
+}
+
+func (o *withLinkStyle) SetConfig(c *renderer.Config) {
+	c.Options[optLinkStyle] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withLinkStyle) SetMarkdownOption(c *Config) {
+	c.LinkStyle = o.value
+}
+
+// WithLinkStyle is a functional option that sets how the destinations and titles of links and images
+// are written.
+func WithLinkStyle(style LinkStyle) interface {
+	renderer.Option
+	Option
+} {
+	return &withLinkStyle{style}
+}
+
+// ============================================================================
+// TextEscaping Option
+// ============================================================================
+
+// optTextEscaping is an option name used in WithTextEscaping
+const optTextEscaping renderer.OptionName = "TextEscaping"
+
+// TextEscaping is an enum expressing which characters of the text from the source are backslash
+// escaped so they aren't read as markup. Text replaced by a TextTransformer is always fully escaped.
+type TextEscaping int
+
+const (
+	// TextEscapingMinimal keeps text as it's written in the source, escaping only what would start a
+	// new block at the start of a line. This is the default and zero value.
+	TextEscapingMinimal = iota
+	// TextEscapingSmart also escapes the characters that could be read as inline markup where they
+	// are, such as the asterisks and underscores that can open or close emphasis, backticks, opening
+	// brackets and the angle brackets that could start raw HTML or an autolink.
+	// Ex: 2*3*4 becomes 2\*3\*4, while 2 * 3 is kept as is
+	TextEscapingSmart
+	// TextEscapingAggressive escapes all the characters that have a meaning in inline markup,
+	// wherever they are.
+	// Ex: snake_case becomes snake\_case
+	TextEscapingAggressive
+)
+
+type withTextEscaping struct {
+	value TextEscaping
+}
+
+func (o *withTextEscaping) SetConfig(c *renderer.Config) {
+	c.Options[optTextEscaping] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withTextEscaping) SetMarkdownOption(c *Config) {
+	c.TextEscaping = o.value
+}
+
+// WithTextEscaping is a functional option that sets which characters of the text from the source are
+// escaped.
+func WithTextEscaping(escaping TextEscaping) interface {
+	renderer.Option
+	Option
+} {
+	return &withTextEscaping{escaping}
+}
+
+// ============================================================================
+// TableStyle Option
+// ============================================================================
+
+// optTableStyle is an option name used in WithTableStyle
+const optTableStyle renderer.OptionName = "TableStyle"
+
+// TableStyle is an enum expressing how the cells of tables are laid out.
+type TableStyle int
+
+const (
+	// TableStyleCompact writes cells as they are, without padding. Delimiters are as wide as their
+	// columns. This is the default and zero value.
+	// Ex: | a | bc |
+	TableStyleCompact = iota
+	// TableStylePretty pads every cell to the display width of its column, as aligned, so the
+	// columns line up. Widths are measured after text is transformed, counting wide characters such
+	// as CJK as two columns.
+	// Ex: | a   | bc  |
+	TableStylePretty
+)
+
+type withTableStyle struct {
+	value TableStyle
+}
+
+func (o *withTableStyle) SetConfig(c *renderer.Config) {
+	c.Options[optTableStyle] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withTableStyle) SetMarkdownOption(c *Config) {
+	c.TableStyle = o.value
+}
+
+// WithTableStyle is a functional option that sets how the cells of tables are laid out.
+func WithTableStyle(style TableStyle) interface {
+	renderer.Option
+	Option
+} {
+	return &withTableStyle{style}
+}
+
+// ============================================================================
+// TargetLanguage Option
+// ============================================================================
+
+// optTargetLanguage is an option name used in WithTargetLanguage
+const optTargetLanguage renderer.OptionName = "TargetLanguage"
+
+// TargetLanguage is the language that text is translated to, such as "fr", when the TextTransformer
+// is a LanguageTransformer. The empty string, which is the default and zero value, keeps the
+// language of the TextTransformer.
+type TargetLanguage string
+
+type withTargetLanguage struct {
+	value TargetLanguage
+}
+
+func (o *withTargetLanguage) SetConfig(c *renderer.Config) {
+	c.Options[optTargetLanguage] = o.value
+}
+
+// SetMarkdownOption implements renderer.Option
+func (o *withTargetLanguage) SetMarkdownOption(c *Config) {
+	c.TargetLanguage = o.value
+}
+
+// WithTargetLanguage is a functional option that sets the language that a LanguageTransformer
+// translates to, such as to translate a document to the language named in its front matter.
+func WithTargetLanguage(lang TargetLanguage) interface {
+	renderer.Option
+	Option
+} {
+	return &withTargetLanguage{lang}
+}
@@ -27,6 +27,9 @@ func TestRendererOptions(t *testing.T) {
 				WithThematicBreakStyle(ThematicBreakStyleDashed),
 				WithThematicBreakLength(ThematicBreakLengthMinimum),
 				WithNestedListLength(NestedListLengthMinimum),
+				WithListNumbering(ListNumberingFromStart),
+				WithInlineJoin(InlineJoinSpace),
+				WithEmphasisFlanking(EmphasisFlankingWordJoiner),
 			},
 			NewConfig(),
 		},
@@ -0,0 +1,326 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindFencedDiv is the NodeKind of FencedDiv nodes.
+var KindFencedDiv = ast.NewNodeKind("FencedDiv")
+
+// FencedDiv is a pandoc fenced div, such as ::: {.note}, whose children are the blocks between its
+// opening and closing fences.
+type FencedDiv struct {
+	ast.BaseBlock
+	// Fence is the run of colons of the opening fence
+	Fence []byte
+	// AttributeList is the rest of the opening fence, such as {.note #id} or a bare class name
+	AttributeList []byte
+	// ClosingFence is the run of colons of the closing fence
+	ClosingFence []byte
+	// closing is the position of the closing fence in the source while the div is parsed
+	closing int
+}
+
+// Kind implements ast.Node.Kind
+func (n *FencedDiv) Kind() ast.NodeKind {
+	return KindFencedDiv
+}
+
+// Dump implements ast.Node.Dump
+func (n *FencedDiv) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Fence":         string(n.Fence),
+		"AttributeList": string(n.AttributeList),
+		"ClosingFence":  string(n.ClosingFence),
+	}, nil)
+}
+
+// KindSpan is the NodeKind of Span nodes.
+var KindSpan = ast.NewNodeKind("Span")
+
+// Span is a pandoc bracketed span, such as [text]{.smallcaps}, whose children are the inlines of its
+// text.
+type Span struct {
+	ast.BaseInline
+	// AttributeList is the position of the attribute list following the text in the source, from "{"
+	// to "}"
+	AttributeList text.Segment
+}
+
+// Kind implements ast.Node.Kind
+func (n *Span) Kind() ast.NodeKind {
+	return KindSpan
+}
+
+// Dump implements ast.Node.Dump
+func (n *Span) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"AttributeList": string(n.AttributeList.Value(source))}, nil)
+}
+
+var (
+	// fencedDivOpening matches the opening fence of a fenced div, capturing its colons and attributes
+	fencedDivOpening = regexp.MustCompile(`^(:{3,})[ \t]*(\S(?:.*\S)?)[ \t]*\r?\n?$`)
+	// fencedDivClosing matches the closing fence of a fenced div, which has no attributes
+	fencedDivClosing = regexp.MustCompile(`^:{3,}[ \t]*\r?\n?$`)
+)
+
+// findFencedDivClosing returns the position in source of the fence that closes a div opened just
+// before it, skipping the fences of nested divs, or -1 if the div isn't closed.
+func findFencedDivClosing(source []byte) int {
+	depth := 1
+	for offset := 0; offset < len(source); {
+		line := source[offset:]
+		if end := bytes.IndexByte(line, '\n'); end >= 0 {
+			line = line[:end+1]
+		}
+		// Fences may be inside container blocks, such as blockquotes
+		fence := bytes.TrimLeft(line, " \t>")
+		switch {
+		case fencedDivClosing.Match(fence):
+			depth--
+			if depth == 0 {
+				return offset + len(line) - len(fence)
+			}
+		case fencedDivOpening.Match(fence):
+			depth++
+		}
+		offset += len(line)
+	}
+	return -1
+}
+
+type fencedDivParser struct{}
+
+// NewFencedDivParser returns a parser.BlockParser that parses pandoc fenced divs into FencedDiv
+// nodes. Divs that aren't closed are left to be parsed as paragraphs.
+func NewFencedDivParser() parser.BlockParser {
+	return &fencedDivParser{}
+}
+
+// Trigger implements parser.BlockParser.Trigger
+func (p *fencedDivParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+// Open implements parser.BlockParser.Open
+func (p *fencedDivParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	match := fencedDivOpening.FindSubmatch(line[pos:])
+	if match == nil {
+		return nil, parser.NoChildren
+	}
+	closing := findFencedDivClosing(reader.Source()[segment.Stop:])
+	if closing < 0 {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(segment.Len() - 1)
+	return &FencedDiv{Fence: match[1], AttributeList: match[2], closing: segment.Stop + closing}, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser.Continue
+func (p *fencedDivParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*FencedDiv)
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	indent := util.TrimLeftSpaceLength(line)
+	if segment.Start+indent != n.closing {
+		return parser.Continue | parser.HasChildren
+	}
+	n.ClosingFence = bytes.TrimRight(line[indent:], " \t\r\n")
+	reader.Advance(segment.Len() - 1)
+	return parser.Close
+}
+
+// Close implements parser.BlockParser.Close
+func (p *fencedDivParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph
+func (p *fencedDivParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine
+func (p *fencedDivParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// spanOpenersKey is the parser.ContextKey of the spanOpeners of the block being parsed.
+var spanOpenersKey = parser.NewContextKey()
+
+// kindSpanOpener is the NodeKind of spanOpener nodes.
+var kindSpanOpener = ast.NewNodeKind("SpanOpener")
+
+// spanOpener is the "[" of a bracketed span while its text is parsed. It's replaced by a Span once
+// its "]" is reached, or by text if another construct takes in the "]".
+type spanOpener struct {
+	ast.BaseInline
+	// Segment is the "[" in the source
+	Segment text.Segment
+	// closing is the position of the matching "]" in the source
+	closing int
+	// bottom is the last delimiter before the span, which delimiters within it can't be paired with
+	bottom ast.Node
+}
+
+// Kind implements ast.Node.Kind
+func (n *spanOpener) Kind() ast.NodeKind {
+	return kindSpanOpener
+}
+
+// Dump implements ast.Node.Dump
+func (n *spanOpener) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// findSpanClosing returns the offset of the "]" that matches the "[" at the start of line, skipping
+// escaped brackets and code spans, or -1 if there's none on the line.
+func findSpanClosing(line []byte) int {
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			run := i
+			for i < len(line) && line[i] == '`' {
+				i++
+			}
+			// A code span ends at the next run of as many backticks
+			for j := i; j < len(line); j++ {
+				if line[j] != '`' {
+					continue
+				}
+				closing := j
+				for j < len(line) && line[j] == '`' {
+					j++
+				}
+				if j-closing == i-run {
+					i = j
+					break
+				}
+			}
+			i--
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+type spanParser struct{}
+
+// NewSpanParser returns a parser.InlineParser that parses pandoc bracketed spans into Span nodes. It
+// must take precedence over goldmark's link parser.
+func NewSpanParser() parser.InlineParser {
+	return &spanParser{}
+}
+
+// Trigger implements parser.InlineParser.Trigger
+func (p *spanParser) Trigger() []byte {
+	return []byte{'[', ']'}
+}
+
+// Parse implements parser.InlineParser.Parse
+func (p *spanParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	openers, _ := pc.Get(spanOpenersKey).([]*spanOpener)
+	if line[0] == '[' {
+		closing := findSpanClosing(line)
+		if closing < 0 || scanAttributeList(line[closing+1:]) == 0 {
+			return nil
+		}
+		opener := &spanOpener{
+			Segment: segment.WithStop(segment.Start + 1),
+			closing: segment.Start + closing,
+			bottom:  pc.LastDelimiter(),
+		}
+		pc.Set(spanOpenersKey, append(openers, opener))
+		block.Advance(1)
+		return opener
+	}
+
+	i := len(openers) - 1
+	for i >= 0 && openers[i].closing != segment.Start {
+		i--
+	}
+	if i < 0 || openers[i].Parent() != parent {
+		return nil
+	}
+	opener := openers[i]
+	pc.Set(spanOpenersKey, append(openers[:i:i], openers[i+1:]...))
+	parser.ProcessDelimiters(opener.bottom, pc)
+	length := scanAttributeList(line[1:])
+	span := &Span{AttributeList: text.NewSegment(segment.Start+1, segment.Start+1+length)}
+	for c := opener.NextSibling(); c != nil; {
+		next := c.NextSibling()
+		parent.RemoveChild(parent, c)
+		span.AppendChild(span, c)
+		c = next
+	}
+	parent.RemoveChild(parent, opener)
+	block.Advance(1 + length)
+	return span
+}
+
+// CloseBlock implements parser.CloseBlocker. The "[" of spans that weren't closed is text.
+func (p *spanParser) CloseBlock(parent ast.Node, block text.Reader, pc parser.Context) {
+	openers, _ := pc.Get(spanOpenersKey).([]*spanOpener)
+	for _, opener := range openers {
+		if opener.Parent() != nil {
+			ast.MergeOrReplaceTextSegment(opener.Parent(), opener, opener.Segment)
+		}
+	}
+	pc.Set(spanOpenersKey, nil)
+}
+
+func (r *Renderer) renderFencedDiv(node ast.Node, entering bool) ast.WalkStatus {
+	n := node.(*FencedDiv)
+	if entering {
+		r.rc.writer.WriteBytes(n.Fence)
+		r.rc.writer.WriteBytes([]byte{' '})
+		r.rc.writer.WriteBytes(n.AttributeList)
+		r.rc.writer.EndLine()
+		if first := n.FirstChild(); first != nil && first.HasBlankPreviousLines() {
+			r.rc.writer.EndLine()
+		}
+	} else {
+		r.rc.writer.FlushLine()
+		if n.ClosingFence != nil {
+			r.rc.writer.WriteBytes(n.ClosingFence)
+		} else {
+			r.rc.writer.WriteBytes(n.Fence)
+		}
+		r.rc.writer.EndLine()
+	}
+	return ast.WalkContinue
+}
+
+func (r *Renderer) renderSpan(node ast.Node, entering bool) ast.WalkStatus {
+	n := node.(*Span)
+	if entering {
+		r.rc.writer.WriteBytes([]byte("["))
+		r.rc.lastRune = '['
+	} else {
+		r.rc.writer.WriteBytes([]byte("]"))
+		r.rc.writer.WriteBytes(n.AttributeList.Value(r.rc.source))
+		r.rc.lastRune = '}'
+	}
+	return ast.WalkContinue
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestDialectPandoc(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Fenced divs",
+			"::: {.note #n}\nSome __text__.\n\n:::: warning ::::\n\n* item\n::::\n:::\n",
+			"::: {.note #n}\nSome **text**.\n\n:::: warning ::::\n\n* item\n::::\n:::\n",
+		},
+		{
+			"Fenced div in blockquote",
+			"> ::: aside\n> Quoted\n> :::\n",
+			"> ::: aside\n> Quoted\n> :::\n",
+		},
+		{
+			"Unclosed fenced div",
+			"::: note\nText\n",
+			"::: note\nText\n",
+		},
+		{
+			"Fenced div after paragraph",
+			"Text\n::: note\nMore\n:::\n",
+			"Text\n::: note\nMore\n:::\n",
+		},
+		{
+			"Spans",
+			"Some [text with __emph__]{.smallcaps} and [a [link](u) b]{lang=fr}, [x]{.y}[z](u).\n",
+			"Some [text with **emph**]{.smallcaps} and [a [link](u) b]{lang=fr}, [x]{.y}[z](u).\n",
+		},
+		{
+			"Not spans",
+			"[unclosed]{.x and [`a]`]{.c} and *a [b* c]{.d} and [link](u){#id}\n",
+			"[unclosed]{.x and [`a]`]{.c} and *a [b* c]{.d} and [link](u){#id}\n",
+		},
+		{
+			"Footnotes",
+			"Text[^1].\n\n[^1]: Note.\n",
+			"Text[^1].\n\n[^1]: Note.\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithDialect(DialectPandoc))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestDialectPandocTranslation(t *testing.T) {
+	source := "::: {.note lang=en}\nSee [the *guide*]{.smallcaps} first.\n:::\n"
+	recorder := &recordingTransformer{}
+	r := NewRenderer(WithDialect(DialectPandoc), WithTextTransformer(recorder))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	buf := bytes.Buffer{}
+	require.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, source, buf.String())
+	assert.Equal(t, []string{"See", "the", "guide", "first."}, recorder.calls)
+}
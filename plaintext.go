@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+)
+
+// PlainTextRenderer is an implementation of renderer.Renderer that renders nodes as plain text without
+// any markdown formatting, e.g. for search indexing or summaries. Blocks are separated by blank lines,
+// except in tight lists. List items are written with "-" or their number, links are written as
+// "text (url)" and images as their alt text. HTML is left out.
+type PlainTextRenderer struct{}
+
+var _ renderer.Renderer = &PlainTextRenderer{}
+
+// NewPlainTextRenderer returns a new PlainTextRenderer.
+func NewPlainTextRenderer() *PlainTextRenderer {
+	return &PlainTextRenderer{}
+}
+
+// AddOptions implements renderer.Renderer.AddOptions. The PlainTextRenderer has no options.
+func (r *PlainTextRenderer) AddOptions(...renderer.Option) {}
+
+// Render implements renderer.Renderer.Render
+func (r *PlainTextRenderer) Render(w io.Writer, source []byte, n ast.Node) error {
+	text := plainTextBlock(source, n)
+	if text == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, text+"\n")
+	return err
+}
+
+// plainTextBlock returns the plain text of the block n, without a trailing newline.
+func plainTextBlock(source []byte, n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Paragraph, *ast.TextBlock, *ast.Heading:
+		return strings.TrimSpace(plainTextInline(source, n))
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		return strings.TrimSuffix(string(n.Lines().Value(source)), "\n")
+	case *ast.HTMLBlock, *ast.ThematicBreak:
+		return ""
+	case *ast.List:
+		separator := "\n\n"
+		if n.IsTight {
+			separator = "\n"
+		}
+		items := []string{}
+		for i, item := 0, n.FirstChild(); item != nil; i, item = i+1, item.NextSibling() {
+			marker := "- "
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", n.Start+i)
+			}
+			items = append(items, marker+indentLines(plainTextChildren(source, item, separator), len(marker)))
+		}
+		return strings.Join(items, separator)
+	case *east.Table:
+		rows := []string{}
+		for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+			cells := []string{}
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, strings.TrimSpace(plainTextInline(source, cell)))
+			}
+			rows = append(rows, strings.Join(cells, "\t"))
+		}
+		return strings.Join(rows, "\n")
+	}
+	return plainTextChildren(source, n, "\n\n")
+}
+
+// plainTextChildren returns the plain text of the child blocks of n, joined by separator.
+func plainTextChildren(source []byte, n ast.Node, separator string) string {
+	blocks := []string{}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if text := plainTextBlock(source, c); text != "" {
+			blocks = append(blocks, text)
+		}
+	}
+	return strings.Join(blocks, separator)
+}
+
+// indentLines indents all but the first line of text by width spaces, leaving blank lines empty.
+func indentLines(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", width) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainTextInline returns the plain text of the inline children of n.
+func plainTextInline(source []byte, n ast.Node) string {
+	text := strings.Builder{}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			text.WriteString(unescapedText(source, c))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				text.WriteByte('\n')
+			}
+		case *ast.String:
+			text.WriteString(unescapedText(source, c))
+		case *ast.CodeSpan:
+			for t := c.FirstChild(); t != nil; t = t.NextSibling() {
+				if t, ok := t.(*ast.Text); ok {
+					text.Write(t.Segment.Value(source))
+				}
+			}
+		case *ast.Link:
+			label := plainTextInline(source, c)
+			text.WriteString(label)
+			if destination := string(c.Destination); destination != "" && destination != label {
+				text.WriteString(" (" + destination + ")")
+			}
+		case *ast.AutoLink:
+			text.Write(c.Label(source))
+		case *ast.RawHTML:
+		case *east.TaskCheckBox:
+			if c.IsChecked {
+				text.WriteString("[x] ")
+			} else {
+				text.WriteString("[ ] ")
+			}
+		default:
+			// Images are written as their alt text, and emphasis as its content
+			text.WriteString(plainTextInline(source, c))
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,72 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+func TestPlainTextRenderer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Headings and paragraphs",
+			"# The *Title*\n\nSome **bold** and `code` text,\nwith an escaped \\* &amp; entity.\n\nSetext\n---\n",
+			"The Title\n\nSome bold and code text,\nwith an escaped * & entity.\n\nSetext\n",
+		},
+		{
+			"Links and images",
+			"A [link](/url \"title\"), an ![image](i.png), <https://example.com> and [https://x.com](https://x.com).\n",
+			"A link (/url), an image, https://example.com and https://x.com.\n",
+		},
+		{
+			"Lists",
+			"* one\n* two\n  continued\n  + nested\n\n3) three\n\n4) four\n",
+			"- one\n- two\n  continued\n  - nested\n\n3. three\n\n4. four\n",
+		},
+		{
+			"Task list",
+			"- [x] done\n- [ ] todo\n",
+			"- [x] done\n- [ ] todo\n",
+		},
+		{
+			"Blockquote and code",
+			"> quoted\n> text\n\n```go\nfunc main() {}\n```\n\n---\n\n    indented\n",
+			"quoted\ntext\n\nfunc main() {}\n\nindented\n",
+		},
+		{
+			"HTML left out",
+			"<div>\nblock\n</div>\n\nSome <b>inline</b> html.\n",
+			"Some inline html.\n",
+		},
+		{
+			"Table",
+			"| a | *b* |\n|---|---|\n| 1 | `2` |\n",
+			"a\tb\n1\t2\n",
+		},
+		{
+			"Strikethrough and hard line break",
+			"~~struck~~ text\\\nnext line\n",
+			"struck text\nnext line\n",
+		},
+		{
+			"Empty document",
+			"<!-- comment -->\n",
+			"",
+		},
+	}
+	md := goldmark.New(goldmark.WithRenderer(NewPlainTextRenderer()), goldmark.WithExtensions(extension.GFM))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
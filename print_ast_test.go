@@ -2,9 +2,15 @@ package markdown
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/text"
 )
 
@@ -266,3 +272,184 @@ End of table.
 		}
 	}
 }
+
+// TestPrintASTJSON tests that the JSON output can be decoded back into the tree
+func TestPrintASTJSON(t *testing.T) {
+	markdown := []byte("# Title\n\nSome *text* and [a link](https://example.com \"t\").\n\n<!-- c -->\n\n" +
+		"| a | b |\n|:--|--:|\n| 1 | 2 |\n")
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
+	doc := md.Parser().Parse(text.NewReader(markdown))
+
+	var buf bytes.Buffer
+	if err := PrintASTJSON(&buf, markdown, doc); err != nil {
+		t.Fatalf("PrintASTJSON returned an error: %v", err)
+	}
+	t.Logf("AST JSON:\n%s", buf.String())
+
+	var root ASTNode
+	if err := json.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatalf("Failed to decode AST JSON: %v", err)
+	}
+	require.Equal(t, "Document", root.Kind)
+	require.Len(t, root.Children, 4)
+
+	heading := root.Children[0]
+	require.Equal(t, "Heading", heading.Kind)
+	require.Equal(t, float64(1), heading.Properties["level"])
+	require.Equal(t, &SourceRange{Start: 2, Stop: 7}, heading.Source)
+	require.Equal(t, "Title", heading.Children[0].Text)
+
+	paragraph := root.Children[1]
+	require.Equal(t, "Emphasis", paragraph.Children[1].Kind)
+	require.Equal(t, "text", paragraph.Children[1].Children[0].Text)
+	link := paragraph.Children[3]
+	require.Equal(t, "Link", link.Kind)
+	require.Equal(t, "https://example.com", link.Properties["destination"])
+	require.Equal(t, "t", link.Properties["title"])
+	require.Equal(t, "a link", string(markdown[link.Source.Start:link.Source.Stop]))
+
+	html := root.Children[2]
+	require.Equal(t, "HTMLBlock", html.Kind)
+	require.Equal(t, "<!-- c -->\n", html.Text)
+
+	table := root.Children[3]
+	require.Equal(t, []any{"left", "right"}, table.Properties["alignments"])
+	require.Equal(t, "right", table.Children[0].Children[1].Properties["alignment"])
+}
+
+// TestPrintASTDOT tests the Graphviz output of the AST
+func TestPrintASTDOT(t *testing.T) {
+	markdown := []byte("# Title\n\nA \"quoted\" paragraph that is rather long.\n")
+	doc := goldmark.DefaultParser().Parse(text.NewReader(markdown))
+
+	var buf bytes.Buffer
+	if err := PrintASTDOT(&buf, markdown, doc); err != nil {
+		t.Fatalf("PrintASTDOT returned an error: %v", err)
+	}
+	require.Equal(t, `digraph AST {
+  node [shape=box, fontname="monospace"];
+  n0 [label="Document"];
+  n1 [label="Heading"];
+  n2 [label="Text\nTitle"];
+  n1 -> n2;
+  n0 -> n1;
+  n3 [label="Paragraph"];
+  n4 [label="Text\nA \"quoted\" paragraph…"];
+  n3 -> n4;
+  n0 -> n3;
+}
+`, buf.String())
+}
+
+// TestPrintASTPositions tests printing the source range of each node
+func TestPrintASTPositions(t *testing.T) {
+	markdown := []byte("# Title\n\nSome *text*\nhere.\n")
+
+	var buf bytes.Buffer
+	if err := PrintASTFromMarkdown(&buf, markdown, WithASTPositions()); err != nil {
+		t.Fatalf("PrintASTFromMarkdown returned an error: %v", err)
+	}
+	t.Logf("AST Output with positions:\n%s", buf.String())
+
+	expected := []string{
+		"Heading @2-7 (1:3-1:8) [Level=1]",
+		"Emphasis @15-19 (3:7-3:11) [Level=1]",
+		`Text @21-26 (4:1-4:6) ["here."]`,
+	}
+	for _, e := range expected {
+		require.Contains(t, buf.String(), e)
+	}
+}
+
+// TestPrintASTColor tests colored output, which is off unless the writer is a terminal
+func TestPrintASTColor(t *testing.T) {
+	markdown := []byte("# Title\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown))
+	require.NotContains(t, buf.String(), "\x1b[")
+
+	buf.Reset()
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTColor(ASTColorAlways)))
+	t.Logf("Colored AST Output:\n%s", buf.String())
+	require.Contains(t, buf.String(), "\x1b[1;34mHeading\x1b[0m\x1b[33m [Level=1]\x1b[0m")
+	require.Contains(t, buf.String(), "\x1b[1;34mText\x1b[0m\x1b[32m [\"Title\"]\x1b[0m")
+}
+
+// TestPrintASTKinds tests printing only some kinds of nodes
+func TestPrintASTKinds(t *testing.T) {
+	markdown := []byte("# Title\n\nSome [*text*](/a)\n\n- [b](/b)\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTKinds(ast.KindHeading, ast.KindLink)))
+	require.Equal(t, "AST Tree:\nHeading [Level=1]\nLink [/a]\nLink [/b]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithoutASTKinds(ast.KindText, ast.KindParagraph)))
+	t.Logf("AST Output without text:\n%s", buf.String())
+	require.NotContains(t, buf.String(), "Text [")
+	require.NotContains(t, buf.String(), "Paragraph")
+	require.Contains(t, buf.String(), "Emphasis [Level=1]")
+}
+
+// TestPrintASTLimits tests limiting the depth and text length of the output
+func TestPrintASTLimits(t *testing.T) {
+	markdown := []byte("A long paragraph of text\n\n- item\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTMaxDepth(1), WithASTMaxText(6)))
+	require.Equal(t, "AST Tree:\nDocument\n└── Paragraph [+1 children]\n└── List [Tight=true] [Bullet] [+1 children]\n",
+		buf.String())
+
+	buf.Reset()
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTMaxText(6)))
+	require.Contains(t, buf.String(), `Text ["A long…"]`)
+	require.Contains(t, buf.String(), `Text ["item"]`)
+}
+
+// TestPrintASTFromMarkdownExtensions tests printing the AST of a pipeline with other extensions
+func TestPrintASTFromMarkdownExtensions(t *testing.T) {
+	markdown := []byte("- [x] ~~done~~\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown))
+	require.NotContains(t, buf.String(), "Strikethrough")
+
+	buf.Reset()
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTMarkdown(md)))
+	t.Logf("AST Output with GFM:\n%s", buf.String())
+	require.Contains(t, buf.String(), "TaskCheckBox [Checked=true]")
+	require.Contains(t, buf.String(), "Strikethrough")
+}
+
+// TestPrintASTDescribers tests describing nodes added by extensions
+func TestPrintASTDescribers(t *testing.T) {
+	markdown := []byte("# Notes\n\nText[^1].\n\n[^1]: A footnote.\n")
+	md := goldmark.New(goldmark.WithExtensions(extension.Footnote))
+	describeLink := func(source []byte, n ast.Node) string {
+		return fmt.Sprintf("Index=%d", n.(*east.FootnoteLink).Index)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTMarkdown(md),
+		WithASTDescriber(east.KindFootnoteLink, describeLink),
+		WithASTDescriber(ast.KindHeading, func(source []byte, n ast.Node) string { return "" })))
+	require.Contains(t, buf.String(), "FootnoteLink [Index=1]\n")
+	require.Contains(t, buf.String(), "Heading\n")
+
+	RegisterASTDescriber(east.KindFootnoteLink, describeLink)
+	RegisterASTDescriber(ast.KindHeading, func(source []byte, n ast.Node) string { return "Registered" })
+	defer RegisterASTDescriber(east.KindFootnoteLink, nil)
+	defer RegisterASTDescriber(ast.KindHeading, nil)
+	buf.Reset()
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTMarkdown(md),
+		WithASTDescriber(ast.KindHeading, func(source []byte, n ast.Node) string { return "Option" })))
+	require.Contains(t, buf.String(), "FootnoteLink [Index=1]\n")
+	require.Contains(t, buf.String(), "Heading [Option]\n")
+
+	RegisterASTDescriber(ast.KindHeading, nil)
+	buf.Reset()
+	require.NoError(t, PrintASTFromMarkdown(&buf, markdown, WithASTMarkdown(md)))
+	require.Contains(t, buf.String(), "Heading [Level=1]\n")
+}
@@ -0,0 +1,53 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+)
+
+// FindAll returns the nodes under and including n for which predicate returns true, in document
+// order.
+func FindAll(n ast.Node, predicate func(ast.Node) bool) []ast.Node {
+	var nodes []ast.Node
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && predicate(n) {
+			nodes = append(nodes, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	return nodes
+}
+
+// FirstHeading returns the first heading of the given level under n, or of any level if level is 0.
+// It returns nil if there's no such heading.
+func FirstHeading(n ast.Node, level int) *ast.Heading {
+	var heading *ast.Heading
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering && (level == 0 || h.Level == level) {
+			heading = h
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return heading
+}
+
+// NodesUnderHeading returns the blocks in the section of the first heading under n whose plain text
+// is title. The section ends at the next heading of the same or a higher level. It returns nil if
+// there's no such heading.
+func NodesUnderHeading(source []byte, n ast.Node, title string) []ast.Node {
+	headings := FindAll(n, func(n ast.Node) bool {
+		return n.Kind() == ast.KindHeading && plainText(source, n) == title
+	})
+	if len(headings) == 0 {
+		return nil
+	}
+	heading := headings[0].(*ast.Heading)
+	nodes := []ast.Node{}
+	for c := heading.NextSibling(); c != nil; c = c.NextSibling() {
+		if h, ok := c.(*ast.Heading); ok && h.Level <= heading.Level {
+			break
+		}
+		nodes = append(nodes, c)
+	}
+	return nodes
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestQueryHelpers(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("# Guide\n\nIntro\n\n## Install\n\nRun [it](/run).\n\n### Linux\n\n- [apt](/apt)\n\n## Usage\n\nUse it.\n")
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	links := FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindLink })
+	if assert.Len(links, 2) {
+		assert.Equal("/run", string(links[0].(*ast.Link).Destination))
+		assert.Equal("/apt", string(links[1].(*ast.Link).Destination))
+	}
+
+	assert.Equal("Guide", plainText(source, FirstHeading(doc, 0)))
+	assert.Equal("Linux", plainText(source, FirstHeading(doc, 3)))
+	assert.Nil(FirstHeading(doc, 4))
+
+	section := NodesUnderHeading(source, doc, "Install")
+	if assert.Len(section, 3) {
+		assert.Equal(ast.KindParagraph, section[0].Kind())
+		assert.Equal(ast.KindHeading, section[1].Kind())
+		assert.Equal(ast.KindList, section[2].Kind())
+	}
+	assert.Len(NodesUnderHeading(source, doc, "Usage"), 1)
+	assert.Len(NodesUnderHeading(source, doc, "Guide"), 7)
+	assert.Nil(NodesUnderHeading(source, doc, "Missing"))
+}
@@ -0,0 +1,243 @@
+package markdown
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// rawKindSet returns the set of kinds, or nil if there are none.
+func rawKindSet(kinds []ast.NodeKind) map[ast.NodeKind]bool {
+	if len(kinds) == 0 {
+		return nil
+	}
+	set := make(map[ast.NodeKind]bool, len(kinds))
+	for _, kind := range kinds {
+		set[kind] = true
+	}
+	return set
+}
+
+func (r *Renderer) renderRaw(node ast.Node, entering bool) ast.WalkStatus {
+	if node.Type() == ast.TypeBlock {
+		r.renderBlockSeparator(node, entering)
+		if entering {
+			r.rc.writer.SetVerbatim(true)
+			for _, line := range rawBlockLines(node, r.rc.source) {
+				r.rc.writer.WriteBytes(line)
+				r.rc.writer.FlushLine()
+			}
+			r.rc.writer.SetVerbatim(false)
+		}
+		return ast.WalkSkipChildren
+	}
+	if entering {
+		start, stop := rawInlineRange(node, r.rc.source)
+		block := node.Parent()
+		for block != nil && block.Type() != ast.TypeBlock {
+			block = block.Parent()
+		}
+		// Write the part of each line of the block within the range, leaving out container prefixes
+		written := false
+		lines := block.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			if line.Stop <= start || line.Start >= stop {
+				continue
+			}
+			value := bytes.TrimRight(r.rc.source[max(line.Start, start):min(line.Stop, stop)], "\r\n")
+			if written {
+				r.rc.writer.EndLine()
+			}
+			r.rc.writer.WriteBytes(value)
+			written = true
+			if len(value) > 0 {
+				r.rc.lastRune, _ = utf8.DecodeLastRune(value)
+			}
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+// rawInlineRange returns the range of the source of the inline node n, including the delimiters
+// around its content. Delimiters extend to the neighboring text, or the ends of the lines of the
+// block, while next to other inlines, whose own delimiters are in between, only the run of the
+// punctuation character next to the content is taken.
+func rawInlineRange(n ast.Node, source []byte) (start, stop int) {
+	block := n.Parent()
+	for block != nil && block.Type() != ast.TypeBlock {
+		block = block.Parent()
+	}
+	lines := block.Lines()
+	if lines.Len() == 0 {
+		return 0, 0
+	}
+	content, contentStop, ok := sourceRange(n)
+	start, stop = lines.At(0).Start, lines.At(lines.Len()-1).Stop
+	for i := 0; ok && i < lines.Len(); i++ {
+		if line := lines.At(i); line.Start <= content && content < line.Stop {
+			start = line.Start
+		}
+		if line := lines.At(i); line.Start < contentStop && contentStop <= line.Stop {
+			stop = line.Stop
+		}
+	}
+	for stop > start && (source[stop-1] == '\n' || source[stop-1] == '\r') {
+		stop--
+	}
+	startExact, stopExact := n.Parent() == block, n.Parent() == block
+	if prev := n.PreviousSibling(); prev != nil {
+		if _, prevStop, ok := sourceRange(prev); ok {
+			_, isText := prev.(*ast.Text)
+			start, startExact = prevStop, isText
+		}
+	}
+	if next := n.NextSibling(); next != nil {
+		if nextStart, _, ok := sourceRange(next); ok {
+			_, isText := next.(*ast.Text)
+			stop, stopExact = nextStart, isText
+		}
+	}
+	if !ok {
+		return start, max(start, stop)
+	}
+	if !startExact {
+		opening := content
+		for opening > start && source[opening-1] == source[content-1] && util.IsPunct(source[opening-1]) {
+			opening--
+		}
+		start = opening
+	}
+	if !stopExact {
+		closing := contentStop
+		for closing < stop && source[closing] == source[contentStop] && util.IsPunct(source[closing]) {
+			closing++
+		}
+		stop = closing
+	}
+	return start, stop
+}
+
+// rawBlockLines returns the lines of the source of the block node n without the prefixes of the
+// containers it's in. Its content is extended with the adjacent lines that aren't blank or part of
+// other nodes, such as the fences or markers of the block.
+func rawBlockLines(n ast.Node, source []byte) [][]byte {
+	// The adjacent nodes in the source bound the block
+	lower, upper := 0, len(source)
+bounds:
+	for p := n; p != nil; p = p.Parent() {
+		for prev := p.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
+			if _, stop, ok := sourceRange(prev); ok {
+				lower = stop
+				break bounds
+			}
+		}
+	}
+	for p := n; p != nil && upper == len(source); p = p.Parent() {
+		for next := p.NextSibling(); next != nil; next = next.NextSibling() {
+			if start, _, ok := sourceRange(next); ok {
+				upper = start
+				break
+			}
+		}
+	}
+	start, stop, ok := sourceRange(n)
+	if !ok {
+		// Without content, the block is the lines that follow the previous node
+		start = lower
+		for start < upper {
+			lineStop := rawLineStop(source, start)
+			if !isBlankRawLine(source[start:lineStop]) {
+				break
+			}
+			start = lineStop
+		}
+		stop = start
+	}
+	// Extend the content to whole lines, then to the adjacent lines within the bounds
+	for start > 0 && source[start-1] != lineDelim {
+		start--
+	}
+	if stop > start && source[stop-1] != lineDelim {
+		stop = rawLineStop(source, stop)
+	}
+	for start > lower {
+		lineStart := start - 1
+		for lineStart > 0 && source[lineStart-1] != lineDelim {
+			lineStart--
+		}
+		if lineStart < lower || isBlankRawLine(source[lineStart:start]) {
+			break
+		}
+		start = lineStart
+	}
+	for stop < upper {
+		lineStop := rawLineStop(source, stop)
+		if lineStop > upper || isBlankRawLine(source[stop:lineStop]) {
+			break
+		}
+		stop = lineStop
+	}
+
+	var containers []ast.Node
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		containers = append([]ast.Node{p}, containers...)
+	}
+	var lines [][]byte
+	for start < stop {
+		lineStop := rawLineStop(source, start)
+		lines = append(lines, trimContainerPrefixes(source, start, lineStop, containers))
+		start = lineStop
+	}
+	return lines
+}
+
+// rawLineStop returns the position after the end of the line at pos in source.
+func rawLineStop(source []byte, pos int) int {
+	if end := bytes.IndexByte(source[pos:], lineDelim); end >= 0 {
+		return pos + end + 1
+	}
+	return len(source)
+}
+
+// isBlankRawLine returns true if line has nothing but whitespace and blockquote markers.
+func isBlankRawLine(line []byte) bool {
+	return len(bytes.Trim(line, " \t\r\n>")) == 0
+}
+
+// trimContainerPrefixes returns the line of source from start to stop without the prefixes of the
+// containers that its content is nested in, outermost first, such as blockquote markers and list item
+// indentation.
+func trimContainerPrefixes(source []byte, start, stop int, containers []ast.Node) []byte {
+	line := source[start:stop]
+	for _, c := range containers {
+		switch c := c.(type) {
+		case *ast.Blockquote:
+			rest := trimSpaces(line, 3)
+			if len(rest) > 0 && rest[0] == '>' {
+				line = trimSpaces(rest[1:], 1)
+			}
+		case *ast.ListItem:
+			// The first line of the item starts with its marker
+			if itemStart, _, ok := sourceRange(c); ok && itemStart >= start && itemStart < stop {
+				line = line[min(len(line), len(line)-len(trimSpaces(line, 3))+c.Offset):]
+			} else {
+				line = trimSpaces(line, c.Offset)
+			}
+		case *Admonition, *east.Footnote:
+			line = trimSpaces(line, 4)
+		}
+	}
+	return line
+}
+
+// trimSpaces returns line without up to n leading spaces.
+func trimSpaces(line []byte, n int) []byte {
+	for i := 0; i < n && len(line) > 0 && line[0] == ' '; i++ {
+		line = line[1:]
+	}
+	return line
+}
@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestRawKinds(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+	}{
+		{
+			"Inline",
+			"a ~~b~~ c ~~*d*~~ *~~e~~* ~~f\ng~~\n\n~~x~~\n",
+		},
+		{
+			"Block",
+			"Apple\n:   Pomaceous fruit\n\n    More text.\n\nOrange\n: Citrus\n",
+		},
+		{
+			"Block in blockquote",
+			"> Term\n> : Definition\n> more\n\nText\n",
+		},
+		{
+			"Block in list",
+			"- Term\n  : Def\n- > T\n  > : D\n\n1. x\n\n   T\n   : D\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithRawKinds(east.KindStrikethrough, east.KindDefinitionList))
+			md := goldmark.New(goldmark.WithRenderer(r),
+				goldmark.WithExtensions(extension.Strikethrough, extension.DefinitionList, r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.source, buf.String())
+		})
+	}
+}
+
+// kindUnknown is the NodeKind of unknown nodes.
+var kindUnknown = ast.NewNodeKind("Unknown")
+
+// unknown is an inline node of an extension the Renderer doesn't know about.
+type unknown struct {
+	ast.BaseInline
+}
+
+func (n *unknown) Kind() ast.NodeKind {
+	return kindUnknown
+}
+
+func (n *unknown) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+func TestRenderUnknownKind(t *testing.T) {
+	source := []byte("a ::b:: c\n")
+	doc := ast.NewDocument()
+	paragraph := ast.NewParagraph()
+	paragraph.Lines().Append(text.NewSegment(0, 9))
+	doc.AppendChild(doc, paragraph)
+	paragraph.AppendChild(paragraph, ast.NewTextSegment(text.NewSegment(0, 2)))
+	node := &unknown{}
+	node.AppendChild(node, ast.NewTextSegment(text.NewSegment(4, 5)))
+	paragraph.AppendChild(paragraph, node)
+	paragraph.AppendChild(paragraph, ast.NewTextSegment(text.NewSegment(7, 9)))
+
+	err := NewRenderer().Render(&bytes.Buffer{}, source, doc)
+	assert.EqualError(t, err, "no renderer for Unknown nodes, which WithRawKinds can write verbatim")
+
+	buf := bytes.Buffer{}
+	require.NoError(t, NewRenderer(WithRawKinds(kindUnknown)).Render(&buf, source, doc))
+	assert.Equal(t, string(source), buf.String())
+}
+
+// TestRenderUnknownKindInTable tests that the error of an unknown kind of node in a table cell isn't
+// lost while the table is rendered
+func TestRenderUnknownKindInTable(t *testing.T) {
+	source := []byte("| a | b |\n|---|---|\n| @ | d |\n\nafter para\n")
+	doc := goldmark.New(goldmark.WithExtensions(extension.Table)).Parser().Parse(text.NewReader(source))
+	require.NoError(t, ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering && string(t.Segment.Value(source)) == "@" {
+			node := &unknown{}
+			n.Parent().ReplaceChild(n.Parent(), n, node)
+			node.AppendChild(node, n)
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	}))
+
+	buf := bytes.Buffer{}
+	err := NewRenderer().Render(&buf, source, doc)
+	assert.EqualError(t, err, "no renderer for Unknown nodes, which WithRawKinds can write verbatim")
+
+	buf.Reset()
+	require.NoError(t, NewRenderer(WithRawKinds(kindUnknown)).Render(&buf, source, doc))
+	assert.Equal(t, "| a | b |\n| --- | --- |\n| @ | d |\n\nafter para\n", buf.String())
+}
+
+// TestRawLastRune tests that text after a raw node is joined to the last character of the node, even
+// if it's made of more than one byte
+func TestRawLastRune(t *testing.T) {
+	source := []byte("a 中 b\n")
+	doc := ast.NewDocument()
+	paragraph := ast.NewParagraph()
+	paragraph.Lines().Append(text.NewSegment(0, 8))
+	doc.AppendChild(doc, paragraph)
+	paragraph.AppendChild(paragraph, ast.NewTextSegment(text.NewSegment(0, 2)))
+	node := &unknown{}
+	node.AppendChild(node, ast.NewTextSegment(text.NewSegment(2, 5)))
+	paragraph.AppendChild(paragraph, node)
+	paragraph.AppendChild(paragraph, ast.NewTextSegment(text.NewSegment(5, 7)))
+
+	buf := bytes.Buffer{}
+	r := NewRenderer(WithRawKinds(kindUnknown), WithInlineJoin(InlineJoinSmart),
+		WithTextTransformer(MapTransformer{"b": "文"}))
+	require.NoError(t, r.Render(&buf, source, doc))
+	assert.Equal(t, "a 中文\n", buf.String())
+}
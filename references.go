@@ -0,0 +1,188 @@
+package markdown
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// linkDefinition is a link reference definition written at the end of the document.
+type linkDefinition struct {
+	label, destination, title []byte
+}
+
+// linkReference returns the label of the definition of destination and title, adding a definition
+// numbered in order of first use if there's none yet.
+func (r *Renderer) linkReference(destination, title []byte) []byte {
+	key := string(destination) + "\n" + string(title)
+	if label, ok := r.rc.linkLabels[key]; ok {
+		return label
+	}
+	if r.rc.linkLabels == nil {
+		r.rc.linkLabels = map[string][]byte{}
+	}
+	label := []byte(strconv.Itoa(len(r.rc.linkDefinitions) + 1))
+	r.rc.linkLabels[key] = label
+	r.rc.linkDefinitions = append(r.rc.linkDefinitions, linkDefinition{label, destination, title})
+	return label
+}
+
+// renderLinkDefinitions writes the definitions of the links written as references after the rest of
+// the document.
+func (r *Renderer) renderLinkDefinitions(node ast.Node, entering bool) ast.WalkStatus {
+	if entering || len(r.rc.linkDefinitions) == 0 {
+		return ast.WalkContinue
+	}
+	r.rc.writer.FlushLine()
+	r.rc.writer.EndLine()
+	for _, definition := range r.rc.linkDefinitions {
+		r.rc.writer.WriteBytes([]byte("["))
+		r.rc.writer.WriteBytes(definition.label)
+		r.rc.writer.WriteBytes([]byte("]: "))
+		r.writeLinkTarget(definition.destination, nil, definition.title)
+		r.rc.writer.EndLine()
+	}
+	return ast.WalkContinue
+}
+
+// KindLinkReferenceDefinitions is the NodeKind of LinkReferenceDefinitions nodes.
+var KindLinkReferenceDefinitions = ast.NewNodeKind("LinkReferenceDefinitions")
+
+// LinkReferenceDefinitions is a run of link reference definitions, such as [id]: /url "title",
+// which the parser otherwise drops once the links referring to them are resolved. Its lines are the
+// lines of the definitions.
+type LinkReferenceDefinitions struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.Kind
+func (n *LinkReferenceDefinitions) Kind() ast.NodeKind {
+	return KindLinkReferenceDefinitions
+}
+
+// IsRaw implements ast.Node.IsRaw
+func (n *LinkReferenceDefinitions) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump
+func (n *LinkReferenceDefinitions) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type linkReferenceDefinitionsTransformer struct{}
+
+// NewLinkReferenceDefinitionsTransformer returns a parser.ParagraphTransformer that keeps the link
+// reference definitions at the start of paragraphs as LinkReferenceDefinitions nodes before the
+// paragraphs. It parses the definitions on behalf of parser.LinkReferenceParagraphTransformer, so
+// it must run before it.
+func NewLinkReferenceDefinitionsTransformer() parser.ParagraphTransformer {
+	return &linkReferenceDefinitionsTransformer{}
+}
+
+// Transform implements parser.ParagraphTransformer.Transform
+func (t *linkReferenceDefinitionsTransformer) Transform(node *ast.Paragraph, reader text.Reader, pc parser.Context) {
+	parent, next := node.Parent(), node.NextSibling()
+	lines := slices.Clone(node.Lines().Sliced(0, node.Lines().Len()))
+	parser.LinkReferenceParagraphTransformer.Transform(node, reader, pc)
+	// Definitions are only parsed from the start of the paragraph, so they're the lines it lost
+	definitions := &LinkReferenceDefinitions{}
+	for _, line := range lines[:len(lines)-node.Lines().Len()] {
+		definitions.Lines().Append(line)
+	}
+	if definitions.Lines().Len() == 0 {
+		return
+	}
+	if node.Parent() != nil {
+		definitions.SetBlankPreviousLines(node.HasBlankPreviousLines())
+		node.SetBlankPreviousLines(false)
+		parent.InsertBefore(parent, node, definitions)
+		return
+	}
+	// The paragraph was all definitions, and was replaced by an empty text block
+	textBlock := parent.LastChild()
+	if next != nil {
+		textBlock = next.PreviousSibling()
+	}
+	definitions.SetBlankPreviousLines(textBlock.HasBlankPreviousLines())
+	parent.ReplaceChild(parent, textBlock, definitions)
+}
+
+func (r *Renderer) renderLinkReferenceDefinitions(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		lines := node.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			r.rc.writer.WriteBytes(line.Value(r.rc.source))
+			r.rc.writer.FlushLine()
+		}
+	}
+	return ast.WalkContinue
+}
+
+// sourceLinkReference returns the label of the reference that the link or image n is written with in
+// the source, and the reference following its text: nothing for a shortcut reference such as
+// [label], [] for a collapsed reference such as [label][], or the label in brackets for a full
+// reference such as [text][label]. ok is false for inline links, or if the text isn't found.
+func sourceLinkReference(n ast.Node, source []byte) (label, reference []byte, ok bool) {
+	start, stop, ok := sourceRange(n)
+	if !ok {
+		return nil, nil, false
+	}
+	for start > 0 && (source[start-1] != '[' || start > 1 && source[start-2] == '\\') {
+		start--
+	}
+	closing := closingBracket(source, stop)
+	if start == 0 || closing < 0 {
+		return nil, nil, false
+	}
+	after := source[closing+1:]
+	switch {
+	case len(after) > 0 && after[0] == '(':
+		return nil, nil, false
+	case len(after) > 0 && after[0] == '[':
+		if labelStop := closingBracket(after, 1); labelStop == 1 {
+			return source[start:closing], after[:2], true
+		} else if labelStop > 1 {
+			return after[1:labelStop], after[:labelStop+1], true
+		}
+	}
+	return source[start:closing], nil, true
+}
+
+// closingBracket returns the position of the first closing bracket in source from pos on that isn't
+// escaped, or -1 if there's none.
+func closingBracket(source []byte, pos int) int {
+	for i := pos; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// renderLinkReference closes the text of a link or image written with the reference label in the
+// source. References are written as they are in the source, unless the text may be transformed, in
+// which case shortcut and collapsed references are written as full references to keep the label.
+func (r *Renderer) renderLinkReference(label, reference []byte) {
+	r.rc.writer.WriteBytes([]byte("]"))
+	if len(reference) <= 2 && (r.config.TextTransformer != nil || r.textHook != nil) {
+		reference = append(append([]byte("["), label...), ']')
+	}
+	r.rc.writer.WriteBytes(reference)
+}
+
+// sourceReference returns the reference that the link or image n is written with in the source, as
+// returned by sourceLinkReference, in LinkStylePreserve.
+func (r *Renderer) sourceReference(n ast.Node) (label, reference []byte, ok bool) {
+	if r.config.LinkStyle != LinkStylePreserve {
+		return nil, nil, false
+	}
+	return sourceLinkReference(n, r.rc.source)
+}
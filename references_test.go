@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+func TestLinkStyleReference(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Links",
+			"See [the docs](https://example.com/docs) and [the blog](https://example.com/blog \"Blog\").\n",
+			"See [the docs][1] and [the blog][2].\n\n[1]: https://example.com/docs\n[2]: https://example.com/blog \"Blog\"\n",
+		},
+		{
+			"Deduplicated",
+			"[a](https://example.com) [b](https://example.com) [c](https://example.com \"Title\")\n",
+			"[a][1] [b][1] [c][2]\n\n[1]: https://example.com\n[2]: https://example.com \"Title\"\n",
+		},
+		{
+			"Images",
+			"![logo](logo.png) in [![badge](badge.svg)](https://ci.example.com)\n",
+			"![logo][1] in [![badge][2]][3]\n\n[1]: logo.png\n[2]: badge.svg\n[3]: https://ci.example.com\n",
+		},
+		{
+			"Containers",
+			"# Title\n\n> A [quote](/quote).\n\n- An [item](</a b>).\n\n| a |\n|---|\n| [cell](/cell) |\n",
+			"# Title\n\n> A [quote][1].\n\n- An [item][2].\n\n| a |\n| --------- |\n| [cell][3] |\n\n[1]: /quote\n[2]: </a b>\n[3]: /cell\n",
+		},
+		{
+			"Inline only",
+			"[empty]() and <https://example.com>\n",
+			"[empty]() and <https://example.com>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithLinkStyle(LinkStyleReference))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.Table, r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+
+			// The references are read as the links they replace
+			html := goldmark.New(goldmark.WithExtensions(extension.Table))
+			expectedHTML, actualHTML := bytes.Buffer{}, bytes.Buffer{}
+			require.NoError(t, html.Convert([]byte(tc.source), &expectedHTML))
+			require.NoError(t, html.Convert(buf.Bytes(), &actualHTML))
+			assert.Equal(t, expectedHTML.String(), actualHTML.String())
+		})
+	}
+}
+
+func TestLinkStylePreserve(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  []Option
+		source   string
+		expected string
+	}{
+		{
+			"References",
+			[]Option{},
+			"[Full][docs], [collapsed][], [shortcut] and ![image][logo] [inline](/a).\n\n" +
+				"[docs]: https://example.com/docs \"Docs\"\n[collapsed]: /collapsed\n" +
+				"[shortcut]:\n  /shortcut\n[logo]: logo.png\n",
+			"[Full][docs], [collapsed][], [shortcut] and ![image][logo] [inline](/a).\n\n" +
+				"[docs]: https://example.com/docs \"Docs\"\n[collapsed]: /collapsed\n" +
+				"[shortcut]:\n  /shortcut\n[logo]: logo.png\n",
+		},
+		{
+			"Definitions before text",
+			[]Option{},
+			"# Title\n[a]: /a\nSee [a].\n\n> [b]: /b\n>\n> See [b][].\n",
+			"# Title\n[a]: /a\nSee [a].\n\n> [b]: /b\n>\n> See [b][].\n",
+		},
+		{
+			"Transformed text",
+			[]Option{WithTextTransformer(MapTransformer{"shortcut": "raccourci", "collapsed": "réduit"})},
+			"[shortcut] and [collapsed][]\n\n[shortcut]: /s\n[collapsed]: /c\n",
+			"[raccourci][shortcut] and [réduit][collapsed]\n\n[shortcut]: /s\n[collapsed]: /c\n",
+		},
+		{
+			"Transformed link",
+			[]Option{WithLinkTransformer(func(destination, title string, isImage bool) (string, string) {
+				if destination == "/old" {
+					return "/new", title
+				}
+				return destination, title
+			})},
+			"[a][old] and [b][kept]\n\n[old]: /old\n[kept]: /kept\n",
+			"[a](/new) and [b][kept]\n\n[old]: /old\n[kept]: /kept\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(append(tc.options, WithLinkStyle(LinkStylePreserve))...)
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestLinkReferenceDefinitionsDropped(t *testing.T) {
+	source := "See [a].\n\n[a]: /a\n"
+	buf := bytes.Buffer{}
+	require.NoError(t, goldmark.New(goldmark.WithRenderer(NewRenderer())).Convert([]byte(source), &buf))
+	assert.Equal(t, "See [a](/a).\n", buf.String())
+}
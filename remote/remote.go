@@ -0,0 +1,341 @@
+// Package remote translates markdown with a translation service over HTTP, as a
+// markdown.TextTransformer, so the renderer can sit in front of any translation backend.
+//
+// A Translator sends the text of a document to the service in batches, each in a POST request whose
+// JSON body is a Request:
+//
+//	{"source": "en", "target": "fr", "texts": [{"type": "plain", "text": "Hello"}, {"type": "html", "text": "<b>"}]}
+//
+// and the service replies with a Response holding the translations in the same order:
+//
+//	{"translations": ["Bonjour", "<b>"]}
+//
+// Requests time out after Timeout and are retried with backoff after network errors, timeouts and
+// responses with status 429 or 5xx.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/teekennedy/goldmark-markdown/cache"
+)
+
+// Defaults of the Translator fields left empty.
+const (
+	DefaultBatchSize  = 100
+	DefaultMaxRetries = 3
+	DefaultBackoff    = 500 * time.Millisecond
+	DefaultTimeout    = 30 * time.Second
+)
+
+// Text is a text to translate in a Request.
+type Text struct {
+	// Type is "plain" for text, or "html" for raw HTML whose tags must be kept
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Request is the body of a request to a translation service.
+type Request struct {
+	// Source is the language of the texts, if known
+	Source string `json:"source,omitempty"`
+	// Target is the language to translate to
+	Target string `json:"target"`
+	Texts  []Text `json:"texts"`
+}
+
+// Response is the body of a reply of a translation service, holding a translation for each of the
+// texts of the Request, in order.
+type Response struct {
+	Translations []string `json:"translations"`
+}
+
+// StatusError is returned for a response with an error status.
+type StatusError struct {
+	StatusCode int
+	// Body is the body of the response, which describes the error
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", http.StatusText(e.StatusCode), e.Body)
+}
+
+// Retryable returns true if the request may succeed when retried, after rate limiting or a server
+// error.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Translator is a markdown.TextTransformer that replaces text with the translations made by a
+// translation service with Translate or TranslateMarkdown. Its methods are safe for concurrent use.
+type Translator struct {
+	// URL is the endpoint that requests are posted to
+	URL string
+	// Header holds additional headers of the requests, such as Authorization
+	Header http.Header
+	// Source is the language of the text, if known
+	Source string
+	// Target is the language to translate to
+	Target string
+	// BatchSize is the number of texts translated per request, DefaultBatchSize if not positive
+	BatchSize int
+	// Timeout is the time limit of each request, DefaultTimeout if not positive
+	Timeout time.Duration
+	// MaxRetries is the number of times a failed request is retried, DefaultMaxRetries if 0 and none
+	// if negative
+	MaxRetries int
+	// Backoff is the wait before the first retry, which is doubled for each retry after it,
+	// DefaultBackoff if not positive
+	Backoff time.Duration
+	// HTTPClient sends the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Cache holds the translations to Target of previous runs, which aren't requested again, if set
+	Cache cache.Store
+
+	mu           sync.RWMutex
+	translations map[Text]string
+	// languages holds the Translators returned by ForLanguage by language
+	languages map[string]*Translator
+}
+
+var _ markdown.LanguageTransformer = &Translator{}
+
+// textOf returns the Text of text of textType.
+func textOf(textType markdown.TextType, text string) Text {
+	if textType == markdown.TextTypeHTML {
+		return Text{Type: "html", Text: text}
+	}
+	return Text{Type: "plain", Text: text}
+}
+
+// textType returns the markdown.TextType of t.
+func (t Text) textType() markdown.TextType {
+	if t.Type == "html" {
+		return markdown.TextTypeHTML
+	}
+	return markdown.TextTypePlain
+}
+
+// Transform implements markdown.TextTransformer, replacing text translated by Translate.
+func (t *Translator) Transform(textType markdown.TextType, text string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	translation, ok := t.translations[textOf(textType, text)]
+	return translation, ok
+}
+
+// ForLanguage implements markdown.LanguageTransformer, returning t if lang is its Target, or else a
+// Translator to lang with the same settings, which is kept for the next call with lang.
+func (t *Translator) ForLanguage(lang string) markdown.TextTransformer {
+	return t.forLanguage(lang)
+}
+
+func (t *Translator) forLanguage(lang string) *Translator {
+	if lang == "" || lang == t.Target {
+		return t
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if translator, ok := t.languages[lang]; ok {
+		return translator
+	}
+	translator := &Translator{
+		URL:        t.URL,
+		Header:     t.Header,
+		Source:     t.Source,
+		Target:     lang,
+		BatchSize:  t.BatchSize,
+		Timeout:    t.Timeout,
+		MaxRetries: t.MaxRetries,
+		Backoff:    t.Backoff,
+		HTTPClient: t.HTTPClient,
+		Cache:      t.Cache,
+	}
+	if t.languages == nil {
+		t.languages = map[string]*Translator{}
+	}
+	t.languages[lang] = translator
+	return translator
+}
+
+// TranslateMarkdown translates the text of source with Translate, then formats source with the
+// translations and options, as markdown.Format does. A language set in the front matter of source
+// takes the place of Target.
+func (t *Translator) TranslateMarkdown(ctx context.Context, source []byte, options ...markdown.Option) ([]byte, error) {
+	var texts []Text
+	recorder := &textRecorder{record: func(text Text) { texts = append(texts, text) }}
+	if _, err := markdown.Format(source, append(options[:len(options):len(options)], markdown.WithTextTransformer(recorder))...); err != nil {
+		return nil, err
+	}
+	if err := t.forLanguage(recorder.lang).Translate(ctx, texts); err != nil {
+		return nil, err
+	}
+	return markdown.Format(source, append(options[:len(options):len(options)], markdown.WithTextTransformer(t))...)
+}
+
+// Translate translates the texts that haven't been translated yet, in batches of BatchSize texts, for
+// Transform. It returns the first error of a batch that failed after all retries, keeping the
+// translations of the batches before it.
+func (t *Translator) Translate(ctx context.Context, texts []Text) error {
+	var pending []Text
+	seen := map[Text]bool{}
+	t.mu.RLock()
+	for _, text := range texts {
+		if _, ok := t.translations[text]; !ok && !seen[text] && strings.TrimSpace(text.Text) != "" {
+			seen[text] = true
+			pending = append(pending, text)
+		}
+	}
+	t.mu.RUnlock()
+	if t.Cache != nil {
+		var missing []Text
+		for _, text := range pending {
+			translation, ok, err := t.Cache.Get(cache.NewKey(t.Target, text.textType(), text.Text))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing = append(missing, text)
+				continue
+			}
+			t.mu.Lock()
+			if t.translations == nil {
+				t.translations = map[Text]string{}
+			}
+			t.translations[text] = translation
+			t.mu.Unlock()
+		}
+		pending = missing
+	}
+
+	batchSize := t.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	for start := 0; start < len(pending); start += batchSize {
+		batch := pending[start:min(start+batchSize, len(pending))]
+		translations, err := t.translateBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		if t.translations == nil {
+			t.translations = map[Text]string{}
+		}
+		for i, text := range batch {
+			t.translations[text] = translations[i]
+		}
+		t.mu.Unlock()
+		if t.Cache != nil {
+			for i, text := range batch {
+				if err := t.Cache.Put(cache.NewKey(t.Target, text.textType(), text.Text), translations[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// translateBatch returns the translations of batch, retrying with backoff.
+func (t *Translator) translateBatch(ctx context.Context, batch []Text) ([]string, error) {
+	body, err := json.Marshal(Request{Source: t.Source, Target: t.Target, Texts: batch})
+	if err != nil {
+		return nil, err
+	}
+	retries := t.MaxRetries
+	if retries == 0 {
+		retries = DefaultMaxRetries
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		translations, err := t.post(ctx, body)
+		if err == nil && len(translations) != len(batch) {
+			return nil, fmt.Errorf("response has %d translations for %d texts", len(translations), len(batch))
+		}
+		if err == nil {
+			return translations, nil
+		}
+		var status *StatusError
+		if attempt >= retries || ctx.Err() != nil || (errors.As(err, &status) && !status.Retryable()) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+// post sends a request with body to the service within Timeout, returning the translations of its
+// response.
+func (t *Translator) post(ctx context.Context, body []byte) ([]string, error) {
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range t.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return response.Translations, nil
+}
+
+// textRecorder is a markdown.TextTransformer that records the text passed to it without replacing
+// it, along with the language it's asked to translate to, if any.
+type textRecorder struct {
+	record func(text Text)
+	lang   string
+}
+
+func (r *textRecorder) Transform(textType markdown.TextType, text string) (string, bool) {
+	r.record(textOf(textType, text))
+	return "", false
+}
+
+// ForLanguage implements markdown.LanguageTransformer
+func (r *textRecorder) ForLanguage(lang string) markdown.TextTransformer {
+	r.lang = lang
+	return r
+}
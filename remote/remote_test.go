@@ -0,0 +1,131 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teekennedy/goldmark-markdown/cache"
+)
+
+// service is a translation service that upper-cases plain text, after failing with the first
+// statuses.
+type service struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []Request
+}
+
+func (s *service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var request Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.requests = append(s.requests, request)
+	if r.Header.Get("Authorization") != "Bearer key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		http.Error(w, "failed", status)
+		return
+	}
+	response := Response{}
+	for _, text := range request.Texts {
+		if text.Type == "plain" {
+			text.Text = strings.ToUpper(text.Text)
+		}
+		response.Translations = append(response.Translations, text.Text)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func TestTranslateMarkdown(t *testing.T) {
+	s := &service{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	translator := &Translator{
+		URL:       server.URL,
+		Header:    http.Header{"Authorization": {"Bearer key"}},
+		Target:    "fr",
+		BatchSize: 3,
+		Backoff:   time.Millisecond,
+	}
+	translated, err := translator.TranslateMarkdown(context.Background(),
+		[]byte("# Title\n\nSome <b>bold</b> text.\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "# TITLE\n\nSOME <b>BOLD</b> TEXT.\n", string(translated))
+	require.Len(t, s.requests, 3)
+	assert.Equal(t, Request{Target: "fr", Texts: []Text{
+		{"plain", "Title"}, {"plain", "Some"}, {"html", "<b>"},
+	}}, s.requests[1])
+	assert.Equal(t, Request{Target: "fr", Texts: []Text{
+		{"plain", "bold"}, {"html", "</b>"}, {"plain", "text."},
+	}}, s.requests[2])
+}
+
+func TestTranslateMarkdownLanguage(t *testing.T) {
+	s := &service{}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	translator := &Translator{URL: server.URL, Header: http.Header{"Authorization": {"Bearer key"}}, Target: "fr"}
+	source := "+++\n[markdown-format]\nlang = \"de\"\n+++\nText\n"
+	translated, err := translator.TranslateMarkdown(context.Background(), []byte(source))
+	require.NoError(t, err)
+	assert.Equal(t, "+++\n[markdown-format]\nlang = \"de\"\n+++\nTEXT\n", string(translated))
+	assert.Equal(t, []Request{{Target: "de", Texts: []Text{{"plain", "Text"}}}}, s.requests)
+}
+
+func TestTranslateErrors(t *testing.T) {
+	server := httptest.NewServer(&service{})
+	defer server.Close()
+
+	translator := &Translator{URL: server.URL, Backoff: time.Millisecond}
+	err := translator.Translate(context.Background(), []Text{{"plain", "Hello"}})
+	var status *StatusError
+	require.True(t, errors.As(err, &status), "expected a StatusError, got %v", err)
+	assert.Equal(t, http.StatusUnauthorized, status.StatusCode)
+	assert.Equal(t, "unauthorized", status.Body)
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The connection is watched for the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+	translator = &Translator{URL: slow.URL, Timeout: 10 * time.Millisecond, MaxRetries: 1, Backoff: time.Millisecond}
+	err = translator.Translate(context.Background(), []Text{{"plain", "Hello"}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTranslateCache(t *testing.T) {
+	s := &service{}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	store := cache.Dir(t.TempDir())
+	header := http.Header{"Authorization": {"Bearer key"}}
+	for _, source := range []string{"one\n", "one <b>two</b>\n"} {
+		translator := &Translator{URL: server.URL, Header: header, Target: "fr", Cache: store}
+		_, err := translator.TranslateMarkdown(context.Background(), []byte(source))
+		require.NoError(t, err)
+	}
+	require.Len(t, s.requests, 2)
+	assert.Equal(t, []Text{{"html", "<b>"}, {"plain", "two"}, {"html", "</b>"}}, s.requests[1].Texts)
+}
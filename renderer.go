@@ -9,8 +9,10 @@ import (
 	"strings"
 	"sync"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
 	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/text"
@@ -31,7 +33,9 @@ func NewRenderer(options ...Option) *Renderer {
 	return r
 }
 
-// Renderer is an implementation of renderer.Renderer that renders nodes as Markdown
+// Renderer is an implementation of renderer.Renderer that renders nodes as Markdown. A Renderer can
+// be shared by goroutines once configured: each call to Render is made by an instance of its own,
+// which has the configuration and node renderers of the Renderer, but keeps its own state.
 type Renderer struct {
 	config               *Config
 	rc                   renderContext
@@ -39,6 +43,14 @@ type Renderer struct {
 	maxKind              int
 	nodeRendererFuncs    []nodeRenderer
 	initSync             sync.Once
+	// instances holds the instances that are done rendering, for reuse by later calls to Render
+	instances sync.Pool
+	// metrics holds the metrics of the most recent render, if enabled
+	metrics   *Metrics
+	metricsMu sync.Mutex
+	// textHook, if set, is passed each run of plain text instead of the TextTransformer, along with
+	// the instance rendering it
+	textHook func(r *Renderer, segment TextSegment) (string, bool)
 }
 
 var _ renderer.Renderer = &Renderer{}
@@ -59,10 +71,30 @@ func (r *Renderer) AddOptions(opts ...renderer.Option) {
 	for i := l - 1; i >= 0; i-- {
 		v := config.NodeRenderers[i]
 		nr, _ := v.Value.(renderer.NodeRenderer)
+		if isReplacedHTMLRenderer(nr) {
+			continue
+		}
 		nr.RegisterFuncs(r)
 	}
 }
 
+// Config returns a copy of the effective configuration of r, including defaults.
+func (r *Renderer) Config() *Config {
+	config := *r.config
+	return &config
+}
+
+// isReplacedHTMLRenderer returns true if nr is one of goldmark's extension renderers that writes HTML
+// for nodes the Renderer writes as markdown itself.
+func isReplacedHTMLRenderer(nr renderer.NodeRenderer) bool {
+	switch nr.(type) {
+	case *extension.TableHTMLRenderer, *extension.TaskCheckBoxHTMLRenderer, *extension.FootnoteHTMLRenderer,
+		*extension.StrikethroughHTMLRenderer:
+		return true
+	}
+	return false
+}
+
 func (r *Renderer) Register(kind ast.NodeKind, fun renderer.NodeRendererFunc) {
 	r.nodeRendererFuncsTmp[kind] = fun
 	if int(kind) > r.maxKind {
@@ -70,57 +102,204 @@ func (r *Renderer) Register(kind ast.NodeKind, fun renderer.NodeRendererFunc) {
 	}
 }
 
-// Render implements renderer.Renderer.Render
+// Render implements renderer.Renderer.Render. It's safe to call from multiple goroutines at once.
 func (r *Renderer) Render(w io.Writer, source []byte, n ast.Node) error {
-	r.rc = newRenderContext(w, source, r.config)
+	return r.renderWithHook(w, source, n, nil)
+}
+
+// renderWithHook renders n like Render, passing the runs of plain text to textHook if set.
+func (r *Renderer) renderWithHook(w io.Writer, source []byte, n ast.Node, textHook func(r *Renderer, segment TextSegment) (string, bool)) error {
 	r.initSync.Do(func() {
-		r.nodeRendererFuncs = make([]nodeRenderer, r.maxKind+1)
-		// add default functions
-		// blocks
-		r.nodeRendererFuncs[ast.KindDocument] = r.renderBlockSeparator
-		r.nodeRendererFuncs[ast.KindHeading] = r.chainRenderers(r.renderBlockSeparator, r.renderHeading)
-		r.nodeRendererFuncs[ast.KindBlockquote] = r.chainRenderers(r.renderBlockSeparator, r.renderBlockquote)
-		r.nodeRendererFuncs[ast.KindCodeBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderCodeBlock)
-		r.nodeRendererFuncs[ast.KindFencedCodeBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderFencedCodeBlock)
-		r.nodeRendererFuncs[ast.KindHTMLBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderHTMLBlock)
-		r.nodeRendererFuncs[ast.KindList] = r.chainRenderers(r.renderBlockSeparator, r.renderList)
-		r.nodeRendererFuncs[ast.KindListItem] = r.chainRenderers(r.renderBlockSeparator, r.renderListItem)
-		r.nodeRendererFuncs[ast.KindParagraph] = r.renderBlockSeparator
-		r.nodeRendererFuncs[ast.KindTextBlock] = r.renderBlockSeparator
-		r.nodeRendererFuncs[ast.KindThematicBreak] = r.chainRenderers(r.renderBlockSeparator, r.renderThematicBreak)
-
-		// inlines
-		r.nodeRendererFuncs[ast.KindAutoLink] = r.renderAutoLink
-		r.nodeRendererFuncs[ast.KindCodeSpan] = r.renderCodeSpan
-		r.nodeRendererFuncs[ast.KindEmphasis] = r.renderEmphasis
-		r.nodeRendererFuncs[ast.KindImage] = r.renderImage
-		r.nodeRendererFuncs[ast.KindLink] = r.renderLink
-		r.nodeRendererFuncs[ast.KindRawHTML] = r.renderRawHTML
-		r.nodeRendererFuncs[ast.KindText] = r.renderText
-		// TODO: add KindString
-		// r.nodeRendererFuncs[ast.KindString] = r.renderString
-
-		for kind, fun := range r.nodeRendererFuncsTmp {
-			r.nodeRendererFuncs[kind] = r.transform(fun)
-		}
-		r.nodeRendererFuncsTmp = nil
+		r.maxKind = max(r.maxKind, int(east.KindTaskCheckBox), int(KindShortcode), int(KindShortcodeBlock),
+			int(KindLiquidTag), int(KindLiquidBlock), int(KindMathBlock), int(KindLinkReferenceDefinitions), int(KindFrontMatter), int(KindAdmonition), int(KindAttributeList),
+			int(KindFencedDiv), int(KindSpan), int(KindAlertMarker), int(east.KindTable),
+			int(east.KindFootnote), int(east.KindFootnoteList), int(east.KindFootnoteLink), int(east.KindFootnoteBacklink),
+			int(east.KindStrikethrough))
+		r.instances.New = func() any { return r.newInstance() }
 	})
+	instance := r.instances.Get().(*Renderer)
+	defer r.instances.Put(instance)
+	instance.textHook = textHook
+	defer func() { instance.textHook = nil }()
+	err := instance.render(w, source, n)
+	if instance.rc.metrics != nil {
+		r.metricsMu.Lock()
+		r.metrics = instance.rc.metrics
+		r.metricsMu.Unlock()
+	}
+	// The instance doesn't keep the document alive while it's unused
+	instance.rc = renderContext{}
+	return err
+}
+
+// newInstance returns a Renderer that renders with the configuration and node renderers of r, for a
+// single call to Render at a time.
+func (r *Renderer) newInstance() *Renderer {
+	instance := &Renderer{config: r.config, maxKind: r.maxKind}
+	instance.initNodeRendererFuncs(r.nodeRendererFuncsTmp)
+	return instance
+}
+
+// initNodeRendererFuncs sets the node renderers of r, which are its own and those registered.
+func (r *Renderer) initNodeRendererFuncs(registered map[ast.NodeKind]renderer.NodeRendererFunc) {
+	r.nodeRendererFuncs = make([]nodeRenderer, r.maxKind+1)
+	// add default functions
+	// blocks
+	r.nodeRendererFuncs[ast.KindDocument] = r.chainRenderers(r.renderBlockSeparator, r.renderLinkDefinitions)
+	r.nodeRendererFuncs[ast.KindHeading] = r.chainRenderers(r.renderBlockSeparator, r.renderHeading)
+	r.nodeRendererFuncs[ast.KindBlockquote] = r.chainRenderers(r.renderBlockSeparator, r.renderBlockquote)
+	r.nodeRendererFuncs[ast.KindCodeBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderCodeBlock)
+	r.nodeRendererFuncs[ast.KindFencedCodeBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderFencedCodeBlock)
+	r.nodeRendererFuncs[ast.KindHTMLBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderHTMLBlock)
+	r.nodeRendererFuncs[ast.KindList] = r.chainRenderers(r.renderBlockSeparator, r.renderList)
+	r.nodeRendererFuncs[ast.KindListItem] = r.chainRenderers(r.renderBlockSeparator, r.renderListItem)
+	r.nodeRendererFuncs[ast.KindParagraph] = r.chainRenderers(r.renderBlockSeparator, r.renderWrapped)
+	r.nodeRendererFuncs[ast.KindTextBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderWrapped)
+	r.nodeRendererFuncs[ast.KindThematicBreak] = r.chainRenderers(r.renderBlockSeparator, r.renderThematicBreak)
+	r.nodeRendererFuncs[KindShortcodeBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderShortcodeBlock)
+	r.nodeRendererFuncs[KindLiquidBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderLiquidBlock)
+	r.nodeRendererFuncs[KindMathBlock] = r.chainRenderers(r.renderBlockSeparator, r.renderMathBlock)
+	r.nodeRendererFuncs[KindLinkReferenceDefinitions] = r.chainRenderers(r.renderBlockSeparator, r.renderLinkReferenceDefinitions)
+	r.nodeRendererFuncs[KindFrontMatter] = r.chainRenderers(r.renderBlockSeparator, r.renderFrontMatter)
+	r.nodeRendererFuncs[KindAdmonition] = r.chainRenderers(r.renderBlockSeparator, r.renderAdmonition)
+	r.nodeRendererFuncs[KindFencedDiv] = r.chainRenderers(r.renderBlockSeparator, r.renderFencedDiv)
+	r.nodeRendererFuncs[east.KindFootnoteList] = r.renderBlockSeparator
+	r.nodeRendererFuncs[east.KindFootnote] = r.chainRenderers(r.renderBlockSeparator, r.renderFootnote)
+	r.nodeRendererFuncs[east.KindTable] = r.transform(r.renderTable)
+
+	// inlines
+	r.nodeRendererFuncs[ast.KindAutoLink] = r.chainRenderers(r.renderNoBreak, r.renderAutoLink)
+	r.nodeRendererFuncs[ast.KindCodeSpan] = r.chainRenderers(r.renderNoBreak, r.renderCodeSpan)
+	r.nodeRendererFuncs[ast.KindEmphasis] = r.renderEmphasis
+	r.nodeRendererFuncs[east.KindStrikethrough] = r.renderStrikethrough
+	r.nodeRendererFuncs[ast.KindImage] = r.chainRenderers(r.renderNoBreak, r.renderImage)
+	r.nodeRendererFuncs[ast.KindLink] = r.chainRenderers(r.renderNoBreak, r.renderLink)
+	r.nodeRendererFuncs[ast.KindRawHTML] = r.chainRenderers(r.renderNoBreak, r.renderRawHTML)
+	r.nodeRendererFuncs[ast.KindText] = r.renderText
+	r.nodeRendererFuncs[east.KindTaskCheckBox] = r.renderTaskCheckBox
+	r.nodeRendererFuncs[KindShortcode] = r.renderShortcode
+	r.nodeRendererFuncs[KindLiquidTag] = r.renderLiquidTag
+	r.nodeRendererFuncs[KindAttributeList] = r.renderAttributeList
+	r.nodeRendererFuncs[KindSpan] = r.renderSpan
+	r.nodeRendererFuncs[KindAlertMarker] = r.renderAlertMarker
+	r.nodeRendererFuncs[east.KindFootnoteLink] = r.renderFootnoteLink
+	r.nodeRendererFuncs[east.KindFootnoteBacklink] = r.renderFootnoteBacklink
+	// TODO: add KindString
+	// r.nodeRendererFuncs[ast.KindString] = r.renderString
+
+	for kind, fun := range registered {
+		r.nodeRendererFuncs[kind] = r.transform(fun)
+	}
+	if r.config.ReviewComments {
+		for _, kind := range reviewedKinds {
+			if r.nodeRendererFuncs[kind] != nil {
+				r.nodeRendererFuncs[kind] = r.chainRenderers(r.renderReviewComment, r.nodeRendererFuncs[kind])
+			}
+		}
+	}
+}
+
+// render renders n to w with the state of r, which is reset first.
+func (r *Renderer) render(w io.Writer, source []byte, n ast.Node) error {
+	out := w
+	var output *bytes.Buffer
+	if r.config.Validate || r.config.OutputTemplate != nil {
+		// Nothing is written until the output is known to be valid and passed through the template
+		output = &bytes.Buffer{}
+		w = output
+	}
+	r.rc = newRenderContext(w, source, r.config)
+	r.rc.rawKinds = rawKindSet(r.config.RawKinds)
+	if r.config.CollectMetrics {
+		r.rc.metrics = newMetrics()
+	}
+	defer r.rc.release()
+	err := r.walk(n)
+	if r.rc.metrics != nil && output == nil {
+		r.rc.metrics.BytesWritten = r.rc.writer.written
+	}
+	if err == nil && output != nil && r.config.Validate {
+		err = r.validate(n, output.Bytes())
+	}
+	if err == nil && output != nil {
+		counted := &countingWriter{Writer: out}
+		if r.config.OutputTemplate != nil {
+			err = r.config.OutputTemplate.execute(counted, output.Bytes())
+		} else {
+			_, err = counted.Write(output.Bytes())
+		}
+		if r.rc.metrics != nil {
+			r.rc.metrics.BytesWritten = counted.written
+		}
+	}
+	return err
+}
+
+// walk renders n and its descendants to the current writer.
+func (r *Renderer) walk(n ast.Node) error {
 	return ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		return r.nodeRendererFuncs[n.Kind()](n, entering), r.rc.writer.Err()
+		if r.isOmitted(n) {
+			return ast.WalkSkipChildren, nil
+		}
+		if entering && r.rc.metrics != nil {
+			r.rc.metrics.NodeCounts[n.Kind()]++
+		}
+		kind := n.Kind()
+		if r.rc.rawKinds[kind] {
+			return r.renderRaw(n, entering), r.rc.writer.Err()
+		}
+		if int(kind) >= len(r.nodeRendererFuncs) || r.nodeRendererFuncs[kind] == nil {
+			return ast.WalkStop, fmt.Errorf("no renderer for %s nodes, which WithRawKinds can write verbatim", kind)
+		}
+		status := r.nodeRendererFuncs[kind](n, entering)
+		if r.rc.err != nil {
+			return ast.WalkStop, r.rc.err
+		}
+		return status, r.rc.writer.Err()
 	})
 }
 
-func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
-	reg.Register(east.KindTable, r.renderTable)
-	reg.Register(east.KindTableHeader, r.renderTableHeader)
-	reg.Register(east.KindTableRow, r.renderTableRow)
-	reg.Register(east.KindTableCell, r.renderTableCell)
+// Document pairs a markdown source with the AST parsed from it.
+type Document struct {
+	Source []byte
+	Node   ast.Node
 }
 
-// transform wraps a renderer.NodeRendererFunc to match the nodeRenderer function signature
+// RenderAll renders each document to w in order, writing separator between consecutive documents.
+// Writer state is pooled, so rendering a corpus this way avoids reallocating buffers per document.
+func (r *Renderer) RenderAll(w io.Writer, docs []Document, separator []byte) error {
+	for i, doc := range docs {
+		if i > 0 {
+			if _, err := w.Write(separator); err != nil {
+				return err
+			}
+		}
+		if err := r.Render(w, doc.Source, doc.Node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.RegisterFuncs. Renderers render the nodes of the
+// extensions they know about themselves, so there's nothing to register.
+func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {}
+
+// transform wraps a renderer.NodeRendererFunc to match the nodeRenderer function signature. Block
+// nodes are separated from their siblings like the blocks rendered by the Renderer itself. The
+// first error of fn is kept in the render context for walk to return.
 func (r *Renderer) transform(fn renderer.NodeRendererFunc) nodeRenderer {
 	return func(n ast.Node, entering bool) ast.WalkStatus {
-		status, _ := fn(r.rc.writer, r.rc.source, n, entering)
+		if n.Type() == ast.TypeBlock && entering {
+			r.renderBlockSeparator(n, entering)
+		}
+		status, err := fn(r.rc.writer, r.rc.source, n, entering)
+		if err != nil && r.rc.err == nil {
+			r.rc.err = err
+		}
+		if n.Type() == ast.TypeBlock && !entering {
+			r.renderBlockSeparator(n, entering)
+		}
 		return status
 	}
 }
@@ -142,12 +321,47 @@ func (r *Renderer) chainRenderers(renderers ...nodeRenderer) nodeRenderer {
 	}
 }
 
+// isOmitted returns true if node and its children are left out of the rendered output.
+func (r *Renderer) isOmitted(node ast.Node) bool {
+	if r.config.NodeFilter != nil && !r.config.NodeFilter(node) {
+		return true
+	}
+	// The parser leaves an empty text block in place of paragraphs of link reference definitions
+	if node.Kind() == ast.KindTextBlock && !node.HasChildren() && node.Lines().Len() == 0 {
+		return true
+	}
+	return r.config.HTMLComments == HTMLCommentsStrip && isHTMLComment(node, r.rc.source)
+}
+
+// previousSibling returns the closest previous sibling of node that is rendered, or nil.
+func (r *Renderer) previousSibling(node ast.Node) ast.Node {
+	prev := node.PreviousSibling()
+	for prev != nil && r.isOmitted(prev) {
+		prev = prev.PreviousSibling()
+	}
+	return prev
+}
+
+// isHTMLComment returns true if node is an HTML block or inline HTML consisting of a comment.
+func isHTMLComment(node ast.Node, source []byte) bool {
+	switch n := node.(type) {
+	case *ast.HTMLBlock:
+		return n.HTMLBlockType == ast.HTMLBlockType2
+	case *ast.RawHTML:
+		return bytes.HasPrefix(n.Segments.Value(source), []byte("<!--"))
+	}
+	return false
+}
+
 func (r *Renderer) renderBlockSeparator(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		// Add blank previous line if applicable
-		if node.PreviousSibling() != nil && node.HasBlankPreviousLines() {
+		if r.previousSibling(node) != nil && (r.hasBlankPreviousLines(node) || r.requiresBlankLine(node) ||
+			r.isBlankInQuote(node) || r.isBlankLineForced(node)) {
 			r.rc.writer.EndLine()
 		}
+		// Text in a new block doesn't join to text before it
+		r.rc.lastRune = 0
 	} else {
 		// Flush line buffer to complete line written by previous block
 		r.rc.writer.FlushLine()
@@ -155,20 +369,218 @@ func (r *Renderer) renderBlockSeparator(node ast.Node, entering bool) ast.WalkSt
 	return ast.WalkContinue
 }
 
+// hasBlankPreviousLines returns true if node is preceded by blank lines. After HTML blocks ended by a
+// closing line, such as comments, the parser's record of blank lines is unreliable, and tables and
+// the nodes of unknown extensions written verbatim may not keep the record of the paragraph they're
+// parsed from, so the source is checked instead.
+func (r *Renderer) hasBlankPreviousLines(node ast.Node) bool {
+	// Footnote lists are made by the parser, which only records blank lines for the footnotes in them
+	if node.Kind() == east.KindFootnoteList && node.HasChildren() {
+		return node.FirstChild().HasBlankPreviousLines()
+	}
+	prev, ok := r.previousSibling(node).(*ast.HTMLBlock)
+	if (ok && prev.HasClosure()) || node.Kind() == east.KindTable || r.rc.rawKinds[node.Kind()] {
+		if blank, ok := r.isBlankInSource(node); ok {
+			return blank
+		}
+	}
+	return node.HasBlankPreviousLines()
+}
+
+// requiresBlankLine returns true if node must be separated from its previous sibling by a blank line
+// to avoid being parsed as a continuation of it. The parser doesn't always record blank lines inside
+// container blocks, e.g. a line containing only ">" between two paragraphs of a blockquote, and nodes
+// moved by AST transformations may not have blank lines recorded at all.
+func (r *Renderer) requiresBlankLine(node ast.Node) bool {
+	switch prev := r.previousSibling(node).(type) {
+	case *ast.Paragraph:
+	case *ast.Blockquote, *Admonition:
+		// Paragraphs would be read as lazy continuation lines, and blockquotes or admonitions would be
+		// merged
+		return node.Kind() == ast.KindParagraph || node.Kind() == prev.Kind()
+	case *ast.HTMLBlock:
+		// These HTML blocks only end at a blank line
+		return prev.HTMLBlockType == ast.HTMLBlockType6 || prev.HTMLBlockType == ast.HTMLBlockType7
+	case *east.Table:
+		// Paragraphs and tables would be read as more rows of the table
+		return node.Kind() == ast.KindParagraph || node.Kind() == east.KindTable
+	default:
+		return false
+	}
+	switch n := node.(type) {
+	case *ast.Paragraph, *ast.CodeBlock, *Admonition, *FencedDiv:
+		return true
+	case *east.Table:
+		// The last line of the paragraph would be read as the table's header row
+		return true
+	case *ast.HTMLBlock:
+		// Only this type of HTML block can't interrupt a paragraph
+		return n.HTMLBlockType == ast.HTMLBlockType7
+	case *ast.ThematicBreak:
+		// A line of dashes after a paragraph would be read as a setext heading underline
+		return r.thematicBreak(n)[0] == '-'
+	case *ast.List:
+		// Lists starting with an empty item can't interrupt a paragraph
+		if !n.FirstChild().HasChildren() && r.config.EmptyListItemStyle == EmptyListItemStyleBare {
+			return true
+		}
+		// Only ordered lists starting with 1 can interrupt a paragraph
+		return n.IsOrdered() && n.Start != 1 && r.config.ListNumbering != ListNumberingFromOne
+	}
+	return false
+}
+
+// isBlankLineForced returns true if the BlankLines policy separates node from its previous sibling
+// by a blank line. Blocks in list items are left as they are, since a blank line would make a tight
+// list loose.
+func (r *Renderer) isBlankLineForced(node ast.Node) bool {
+	if r.config.BlankLines != BlankLinesAround || node.Parent() == nil || node.Parent().Kind() == ast.KindListItem {
+		return false
+	}
+	return isSurroundedByBlankLines(node) || isSurroundedByBlankLines(r.previousSibling(node))
+}
+
+// isSurroundedByBlankLines returns true if node is of a kind that BlankLinesAround separates from
+// its siblings.
+func isSurroundedByBlankLines(node ast.Node) bool {
+	switch node.Kind() {
+	case ast.KindHeading, ast.KindList, ast.KindCodeBlock, ast.KindFencedCodeBlock:
+		return true
+	}
+	return false
+}
+
+// isBlankInQuote returns true if node is inside a blockquote and is separated from its previous
+// sibling by a blank line in the source, e.g. a line containing only ">".
+func (r *Renderer) isBlankInQuote(node ast.Node) bool {
+	inQuote := false
+	for p := node.Parent(); p != nil && !inQuote; p = p.Parent() {
+		inQuote = p.Kind() == ast.KindBlockquote
+	}
+	if !inQuote {
+		return false
+	}
+	blank, _ := r.isBlankInSource(node)
+	return blank
+}
+
+// isBlankInSource returns true if node is separated from its previous sibling by a blank line in the
+// source, or false if the source positions of either node are unknown.
+func (r *Renderer) isBlankInSource(node ast.Node) (blank bool, ok bool) {
+	prevStop, ok := sourceBound(r.previousSibling(node), false)
+	if !ok {
+		return false, false
+	}
+	start, ok := sourceBound(node, true)
+	if !ok || start < prevStop {
+		return false, false
+	}
+	// The first and last lines between the nodes belong to the nodes themselves, unless the previous
+	// node's lines include their line ending
+	lines := bytes.Split(r.rc.source[prevStop:start], []byte{lineDelim})
+	first := 1
+	if prevStop > 0 && r.rc.source[prevStop-1] == lineDelim {
+		first = 0
+	}
+	for i := first; i < len(lines)-1; i++ {
+		if len(bytes.Trim(lines[i], " \t>")) == 0 {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// sourceBound returns the start of the first line or the stop of the last line of a block node, by
+// descending into its first or last child block until one with lines or inlines is found.
+func sourceBound(node ast.Node, first bool) (int, bool) {
+	for node != nil && node.Type() == ast.TypeBlock {
+		if html, ok := node.(*ast.HTMLBlock); ok && html.HasClosure() && !first {
+			return html.ClosureLine.Stop, true
+		}
+		if lines := node.Lines(); lines.Len() > 0 {
+			if first {
+				return lines.At(0).Start, true
+			}
+			return lines.At(lines.Len() - 1).Stop, true
+		}
+		if first {
+			node = node.FirstChild()
+		} else {
+			node = node.LastChild()
+		}
+	}
+	// Blocks such as table cells hold inlines instead of lines
+	if node != nil {
+		start, stop, ok := sourceRange(node)
+		if first {
+			return start, ok
+		}
+		return stop, ok
+	}
+	return 0, false
+}
+
 func (r *Renderer) renderAutoLink(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.AutoLink)
 	if entering {
-		r.rc.writer.WriteBytes([]byte("<"))
+		// The label is the link as written in the source. Unlike URL, it never has a protocol added, so
+		// emails and www. links are written as-is.
+		label := n.Label(r.rc.source)
+		// Autolinks found by extensions like Linkify weren't surrounded by angle brackets
+		bracketed := true
+		if start, ok := sourceOffset(r.rc.source, label); ok {
+			bracketed = start > 0 && r.rc.source[start-1] == '<'
+		}
 		// Set skipTranslation to true only for the URL part
 		r.rc.skipTranslation = true
-		r.rc.writer.WriteBytes(n.URL(r.rc.source))
+		if destination, title, changed := r.transformLink(n.URL(r.rc.source), nil, false); changed {
+			if len(title) == 0 && isAutoLink(destination, n.AutoLinkType == ast.AutoLinkEmail) {
+				r.rc.writer.WriteBytes([]byte("<"))
+				r.rc.writer.WriteBytes(destination)
+				r.rc.writer.WriteBytes([]byte(">"))
+			} else {
+				r.rc.writer.WriteBytes([]byte("["))
+				r.rc.writer.WriteBytes(escapeInline(label, r.config.HTMLEscaping))
+				r.renderLinkDestination(destination, nil, title)
+			}
+			return ast.WalkContinue
+		}
+		if bracketed {
+			r.rc.writer.WriteBytes([]byte("<"))
+		}
+		r.rc.writer.WriteBytes(label)
+		if bracketed {
+			r.rc.writer.WriteBytes([]byte(">"))
+		}
 	} else {
-		r.rc.writer.WriteBytes([]byte(">"))
 		r.rc.skipTranslation = false
 	}
 	return ast.WalkContinue
 }
 
+// transformLink returns the destination and title of a link as rewritten by the LinkTransformer, and
+// whether either was changed.
+func (r *Renderer) transformLink(destination, title []byte, isImage bool) ([]byte, []byte, bool) {
+	if r.config.LinkTransformer == nil {
+		return destination, title, false
+	}
+	newDestination, newTitle := r.config.LinkTransformer(string(destination), string(title), isImage)
+	changed := newDestination != string(destination) || newTitle != string(title)
+	return []byte(newDestination), []byte(newTitle), changed
+}
+
+// sourceOffset returns the offset of value within source, if value is a slice of source.
+func sourceOffset(source, value []byte) (int, bool) {
+	if len(value) == 0 || cap(value) > cap(source) {
+		return 0, false
+	}
+	offset := cap(source) - cap(value)
+	if offset >= len(source) || &source[offset] != &value[0] {
+		return 0, false
+	}
+	return offset, true
+}
+
 func (r *Renderer) renderBlockquote(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.rc.writer.PushPrefix([]byte("> "))
@@ -180,6 +592,10 @@ func (r *Renderer) renderBlockquote(node ast.Node, entering bool) ast.WalkStatus
 
 func (r *Renderer) renderHeading(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.Heading)
+	// Generated ids are made from the rendered text of the heading
+	if entering && r.config.HeadingIDs == HeadingIDsGenerate {
+		r.rc.headingText = &strings.Builder{}
+	}
 	// Empty headings or headings above level 2 can only be ATX
 	if !n.HasChildren() || n.Level > 2 {
 		return r.renderATXHeading(n, entering)
@@ -203,6 +619,7 @@ func (r *Renderer) renderATXHeading(node *ast.Heading, entering bool) ast.WalkSt
 			r.rc.writer.WriteBytes([]byte(" "))
 		}
 	} else {
+		r.writeHeadingAttributes(node)
 		if r.config.HeadingStyle == HeadingStyleATXSurround {
 			r.rc.writer.WriteBytes([]byte(" "))
 			r.rc.writer.WriteBytes(bytes.Repeat([]byte("#"), node.Level))
@@ -213,42 +630,192 @@ func (r *Renderer) renderATXHeading(node *ast.Heading, entering bool) ast.WalkSt
 
 func (r *Renderer) renderSetextHeading(node *ast.Heading, entering bool) ast.WalkStatus {
 	if entering {
+		r.rc.writer.StartMeasure()
 		return ast.WalkContinue
 	}
 	underlineChar := [...][]byte{[]byte(""), []byte("="), []byte("-")}[node.Level]
 	underlineWidth := 3
 	if r.config.HeadingStyle == HeadingStyleFullWidthSetext {
-		lines := node.Lines()
-		for i := 0; i < lines.Len(); i++ {
-			line := lines.At(i)
-			lineWidth := line.Len()
-
-			if lineWidth > underlineWidth {
-				underlineWidth = lineWidth
-			}
-		}
+		// Measure the rendered heading, which may differ from the source after transformation
+		underlineWidth = max(underlineWidth, r.rc.writer.Measure())
 	}
+	r.writeHeadingAttributes(node)
 	r.rc.writer.WriteBytes([]byte("\n"))
 	r.rc.writer.WriteBytes(bytes.Repeat(underlineChar, underlineWidth))
 	return ast.WalkContinue
 }
 
+// writeHeadingAttributes writes the attribute list of node after its text, if it has one.
+func (r *Renderer) writeHeadingAttributes(node *ast.Heading) {
+	text := ""
+	if r.rc.headingText != nil {
+		text = r.rc.headingText.String()
+		r.rc.headingText = nil
+	}
+	if attributes := r.headingAttributes(node, text); attributes != nil {
+		r.rc.writer.WriteBytes([]byte(" "))
+		r.rc.writer.WriteBytes(attributes)
+	}
+}
+
+// renderWrapped wraps the text of paragraphs at the configured line width.
+func (r *Renderer) renderWrapped(node ast.Node, entering bool) ast.WalkStatus {
+	r.rc.writer.SetWrap(entering)
+	return ast.WalkContinue
+}
+
+// renderNoBreak keeps lines from being wrapped within inline nodes that can't span lines, or would be
+// harder to read if they did, such as code spans and links.
+func (r *Renderer) renderNoBreak(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.rc.writer.BeginNoBreak()
+	} else {
+		r.rc.writer.EndNoBreak()
+	}
+	return ast.WalkContinue
+}
+
 func (r *Renderer) renderThematicBreak(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		breakChars := []byte{'-', '*', '_'}
-		breakChar := breakChars[r.config.ThematicBreakStyle : r.config.ThematicBreakStyle+1]
-		breakLen := int(max(r.config.ThematicBreakLength, ThematicBreakLengthMinimum))
-		r.rc.writer.WriteBytes(bytes.Repeat(breakChar, breakLen))
+		r.rc.writer.WriteBytes(r.thematicBreak(node))
 	}
 	return ast.WalkContinue
 }
 
+// thematicBreak returns the thematic break to write for node, which is the one written in the source
+// in StyleModePreserve, or the configured style otherwise.
+func (r *Renderer) thematicBreak(node ast.Node) []byte {
+	if r.config.StyleMode == StyleModePreserve {
+		if thematicBreak, ok := sourceThematicBreak(node, r.rc.source); ok {
+			return thematicBreak
+		}
+	}
+	breakChars := []byte{'-', '*', '_'}
+	breakChar := breakChars[r.config.ThematicBreakStyle : r.config.ThematicBreakStyle+1]
+	// A break on the same line as a list marker made of the same character would be read as a break
+	// in place of the list item. Underscores are never list markers.
+	depth := len(r.rc.lists) - 1
+	for n := node; n.PreviousSibling() == nil && depth >= 0; n = n.Parent() {
+		if _, ok := n.Parent().(*ast.ListItem); !ok {
+			break
+		}
+		if r.rc.lists[depth].marker == breakChar[0] {
+			breakChar = []byte{'_'}
+		}
+		n, depth = n.Parent(), depth-1
+	}
+	breakLen := int(max(r.config.ThematicBreakLength, ThematicBreakLengthMinimum))
+	return bytes.Repeat(breakChar, breakLen)
+}
+
+// sourceThematicBreak returns the thematic break as written in the source. Thematic breaks have no
+// lines, so the source is searched between the blocks before and after it, and only used if each
+// thematic break there is accounted for.
+func sourceThematicBreak(node ast.Node, source []byte) ([]byte, bool) {
+	root := node
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	start, stop := 0, len(source)
+	// count is the number of thematic breaks since start, and index is that of node
+	count, index := 0, -1
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		if n.Kind() == ast.KindThematicBreak {
+			if n == node {
+				index = count
+			}
+			count++
+			return ast.WalkContinue, nil
+		}
+		if n.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		if index >= 0 {
+			stop = n.Lines().At(0).Start
+			return ast.WalkStop, nil
+		}
+		start, _ = sourceBound(n, false)
+		count = 0
+		return ast.WalkContinue, nil
+	})
+	if index < 0 || start > stop {
+		return nil, false
+	}
+	var candidates [][]byte
+	for start <= stop {
+		// Slice the lines from source so the thematic break can be located within it
+		end := bytes.IndexByte(source[start:stop], lineDelim)
+		if end < 0 {
+			end = stop - start
+		}
+		if b := thematicBreakSuffix(source[start : start+end]); b != nil {
+			candidates = append(candidates, b)
+		}
+		start += end + 1
+	}
+	if len(candidates) != count {
+		return nil, false
+	}
+	thematicBreak := candidates[index]
+	// The markers of list items starting with the thematic break are on the same line
+	for n := node; n.PreviousSibling() == nil; n = n.Parent() {
+		item, ok := n.Parent().(*ast.ListItem)
+		if !ok {
+			break
+		}
+		if list, ok := item.Parent().(*ast.List); ok && list.Marker == thematicBreak[0] {
+			thematicBreak = bytes.TrimLeft(thematicBreak[1:], " \t")
+		}
+	}
+	if bytes.Count(thematicBreak, thematicBreak[:1]) < 3 {
+		return nil, false
+	}
+	return thematicBreak, true
+}
+
+// thematicBreakSuffix returns the characters at the end of line that form a thematic break, or nil
+// if there are none. Container markers such as ">" may precede them.
+func thematicBreakSuffix(line []byte) []byte {
+	line = bytes.TrimRight(line, " \t\r")
+	if len(line) == 0 || !bytes.ContainsAny(line[len(line)-1:], "-*_") {
+		return nil
+	}
+	c := line[len(line)-1]
+	start := len(line)
+	for start > 0 && (line[start-1] == c || line[start-1] == ' ' || line[start-1] == '\t') {
+		start--
+	}
+	b := bytes.TrimLeft(line[start:], " \t")
+	if bytes.Count(b, []byte{c}) < 3 {
+		return nil
+	}
+	return b
+}
+
 func (r *Renderer) renderCodeBlock(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.rc.writer.PushPrefix(r.config.Bytes())
 		// Skip translation for code block content
 		r.rc.skipTranslation = true
-		r.renderLines(node, entering)
+		if r.config.StyleMode == StyleModePreserve {
+			if indents, ok := sourceCodeIndents(node.Lines(), r.rc.source); ok {
+				// Each line carries its own indentation instead of a prefix
+				r.rc.writer.PushPrefix(nil)
+				r.rc.writer.SetVerbatim(true)
+				for i := 0; i < node.Lines().Len(); i++ {
+					segment := node.Lines().At(i)
+					r.rc.writer.WriteBytes(indents[i])
+					r.rc.writer.WriteBytes(segment.Value(r.rc.source))
+					r.rc.writer.FlushLine()
+				}
+				r.rc.writer.SetVerbatim(false)
+				return ast.WalkContinue
+			}
+		}
+		r.rc.writer.PushPrefix(r.config.Bytes())
+		r.renderVerbatimLines(node)
 	} else {
 		r.rc.writer.PopPrefix()
 		r.rc.skipTranslation = false
@@ -256,26 +823,175 @@ func (r *Renderer) renderCodeBlock(node ast.Node, entering bool) ast.WalkStatus
 	return ast.WalkContinue
 }
 
+// sourceCodeIndents returns the indentation of each line of an indented code block in the source, or
+// false if it can't be reproduced exactly, such as when a tab spans the indentation and content.
+// Blank lines have no indentation.
+func sourceCodeIndents(lines *text.Segments, source []byte) ([][]byte, bool) {
+	indents := make([][]byte, lines.Len())
+	for i := range indents {
+		segment := lines.At(i)
+		if len(bytes.TrimRight(segment.Value(source), "\r\n")) == 0 {
+			continue
+		}
+		if segment.Padding > 0 {
+			return nil, false
+		}
+		start := segment.Start
+		switch {
+		case start > 0 && source[start-1] == '\t':
+			indents[i] = source[start-1 : start]
+		case start >= 4 && bytes.Equal(source[start-4:start], []byte("    ")):
+			indents[i] = source[start-4 : start]
+		default:
+			return nil, false
+		}
+	}
+	return indents, true
+}
+
 func (r *Renderer) renderFencedCodeBlock(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.FencedCodeBlock)
-	r.rc.writer.WriteBytes([]byte("```"))
 	if entering {
 		r.rc.skipTranslation = true
+		opening := codeFence(n, r.rc.source)
+		closing := opening
+		indent := 0
+		// Reuse the source fences if content can't close them early
+		if r.config.StyleMode == StyleModePreserve {
+			if o, c, i, ok := sourceCodeFences(n, r.rc.source); ok && isValidCodeFence(n, r.rc.source, bytes.TrimRight(o, " \t")) {
+				opening, closing, indent = o, c, i
+			}
+		}
+		r.rc.codeFenceContext = codeFenceContext{closing: closing, indented: indent > 0}
+		if indent > 0 {
+			r.rc.writer.PushPrefix(bytes.Repeat([]byte{' '}, indent))
+		}
+		r.rc.writer.WriteBytes(opening)
 		if info := n.Info; info != nil {
+			// The info string is written byte for byte, since highlighters read attributes such as
+			// {hl_lines=[2,3] linenos=true} from it that unescaping or normalizing could change
 			r.rc.writer.WriteBytes(info.Value(r.rc.source))
 		}
 		r.rc.writer.FlushLine()
-		r.renderLines(node, entering)
+		r.renderVerbatimLines(node)
 	} else {
+		r.rc.writer.WriteBytes(r.rc.codeFenceContext.closing)
+		if r.rc.codeFenceContext.indented {
+			// Flush before removing the indentation so the closing fence is indented too
+			r.rc.writer.FlushLine()
+			r.rc.writer.PopPrefix()
+		}
 		r.rc.skipTranslation = false
 	}
 	return ast.WalkContinue
 }
 
+// codeFence returns the shortest fence that no line of the code block's content can close.
+func codeFence(n *ast.FencedCodeBlock, source []byte) []byte {
+	fenceChar := byte('`')
+	// Info strings of backtick fences can't contain backticks
+	if n.Info != nil && bytes.IndexByte(n.Info.Value(source), '`') >= 0 {
+		fenceChar = '~'
+	}
+	length := 3
+	for !isValidCodeFence(n, source, bytes.Repeat([]byte{fenceChar}, length)) {
+		length++
+	}
+	return bytes.Repeat([]byte{fenceChar}, length)
+}
+
+// isValidCodeFence returns true if none of the code block's content lines would close fence.
+func isValidCodeFence(n *ast.FencedCodeBlock, source []byte, fence []byte) bool {
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		line := segment.Value(source)
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			continue
+		}
+		run := len(trimmed) - len(bytes.TrimLeft(trimmed, string(fence[:1])))
+		if run >= len(fence) && len(bytes.TrimSpace(trimmed[run:])) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceCodeFences returns the opening and closing fences of a fenced code block in the source, and
+// the indentation of the opening fence if it starts its line.
+func sourceCodeFences(n *ast.FencedCodeBlock, source []byte) (opening, closing []byte, indent int, ok bool) {
+	lines := n.Lines()
+	// Find the end of the opening fence
+	var end int
+	switch {
+	case n.Info != nil:
+		end = n.Info.Segment.Start
+	case lines.Len() > 0:
+		// The opening fence is on the line before the first line of content
+		end = lines.At(0).Start
+		for end > 0 && source[end-1] != lineDelim {
+			end--
+		}
+		end--
+	default:
+		return nil, nil, 0, false
+	}
+	for end > 0 && (source[end-1] == ' ' || source[end-1] == '\t' || source[end-1] == '\r') {
+		end--
+	}
+	start := end
+	for start > 0 && (source[start-1] == '`' || source[start-1] == '~') && source[start-1] == source[end-1] {
+		start--
+	}
+	if end-start < 3 {
+		return nil, nil, 0, false
+	}
+	opening = source[start:end]
+	fence := opening
+	if n.Info != nil {
+		// Keep the spacing between the fence and the info string
+		opening = source[start:n.Info.Segment.Start]
+	}
+	for i := start; i > 0 && source[i-1] == ' '; i-- {
+		indent++
+	}
+	if start-indent > 0 && source[start-indent-1] != lineDelim {
+		// The fence follows a container marker, so the spaces aren't indentation
+		indent = 0
+	}
+
+	// The closing fence is on the line after the last line of content, if any
+	closing = fence
+	pos := end
+	if lines.Len() > 0 {
+		pos = lines.At(lines.Len() - 1).Stop
+	} else if i := bytes.IndexByte(source[pos:], lineDelim); i >= 0 {
+		pos += i + 1
+	}
+	if pos > 0 && source[pos-1] != lineDelim {
+		if i := bytes.IndexByte(source[pos:], lineDelim); i >= 0 {
+			pos += i + 1
+		}
+	}
+	line := source[pos:]
+	if i := bytes.IndexByte(line, lineDelim); i >= 0 {
+		line = line[:i]
+	}
+	if i := bytes.Index(line, fence); i >= 0 {
+		run := bytes.TrimRight(line[i:], " \t\r")
+		if len(bytes.Trim(run, string(fence[:1]))) == 0 {
+			closing = run
+		}
+	}
+	return opening, closing, indent, true
+}
+
 func (r *Renderer) renderHTMLBlock(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.HTMLBlock)
 	if entering {
-		if r.config.TextTransformer != nil {
+		// Comments are never transformed
+		if r.config.TextTransformer != nil && n.HTMLBlockType != ast.HTMLBlockType2 {
 			// Collect all HTML block content into a single string
 			var htmlContent strings.Builder
 			lines := n.Lines()
@@ -291,20 +1007,24 @@ func (r *Renderer) renderHTMLBlock(node ast.Node, entering bool) ast.WalkStatus
 
 			// Send the entire HTML content to the TextTransformer
 			htmlStr := htmlContent.String()
-			if translation, ok := r.config.TextTransformer.Transform(TextTypeHTML, htmlStr); ok {
-				// Write the translated HTML directly
+			if translation, ok := r.transformText(TextTypeHTML, htmlStr); ok {
+				// Write the translated HTML directly. It replaces the closing line too.
 				r.rc.writer.WriteBytes([]byte(translation))
+				r.rc.htmlBlockTransformed = true
 				return ast.WalkContinue
 			}
 		}
 
 		// Fall back to default behavior if no transformation happened
 		r.rc.skipTranslation = true
-		r.renderLines(node, entering)
+		r.renderVerbatimLines(node)
 	} else {
-		if n.HasClosure() {
+		if n.HasClosure() && !r.rc.htmlBlockTransformed {
+			r.rc.writer.SetVerbatim(true)
 			r.rc.writer.WriteLine(n.ClosureLine.Value(r.rc.source))
+			r.rc.writer.SetVerbatim(false)
 		}
+		r.rc.htmlBlockTransformed = false
 		r.rc.skipTranslation = false
 	}
 	return ast.WalkContinue
@@ -313,9 +1033,14 @@ func (r *Renderer) renderHTMLBlock(node ast.Node, entering bool) ast.WalkStatus
 func (r *Renderer) renderList(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		n := node.(*ast.List)
+		num := n.Start
+		if r.config.ListNumbering == ListNumberingFromOne {
+			num = 1
+		}
 		r.rc.lists = append(r.rc.lists, listContext{
-			list: n,
-			num:  n.Start,
+			list:   n,
+			num:    num,
+			marker: r.listMarker(n),
 		})
 	} else {
 		r.rc.lists = r.rc.lists[:len(r.rc.lists)-1]
@@ -323,6 +1048,23 @@ func (r *Renderer) renderList(node ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
 
+// listMarker returns the marker to write for the items of list. Bullet lists use the configured
+// marker, unless the previous list would use it too, since adjacent lists with the same marker are
+// read as one list.
+func (r *Renderer) listMarker(list *ast.List) byte {
+	marker := r.config.BulletMarker.Marker()
+	if list.IsOrdered() || marker == 0 {
+		return list.Marker
+	}
+	if prev, ok := r.previousSibling(list).(*ast.List); ok && !prev.IsOrdered() && r.listMarker(prev) == marker {
+		if marker == '-' {
+			return '*'
+		}
+		return '-'
+	}
+	return marker
+}
+
 func (r *Renderer) renderListItem(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		var itemPrefix []byte
@@ -332,14 +1074,18 @@ func (r *Renderer) renderListItem(node ast.Node, entering bool) ast.WalkStatus {
 			itemPrefix = append(itemPrefix, []byte(fmt.Sprint(l.num))...)
 			r.rc.lists[len(r.rc.lists)-1].num += 1
 		}
-		itemPrefix = append(itemPrefix, l.list.Marker, ' ')
+		itemPrefix = append(itemPrefix, l.marker)
+		itemPrefix, indent := r.config.listItemPrefix(itemPrefix)
 		// Prefix the current line with the item prefix
 		r.rc.writer.PushPrefix(itemPrefix, 0, 0)
-		// Prefix subsequent lines with padding the same length as the item prefix
-		indentLen := int(max(r.config.NestedListLength, NestedListLengthMinimum))
-		indent := bytes.Repeat([]byte{' '}, indentLen)
-		r.rc.writer.PushPrefix(bytes.Repeat(indent, len(itemPrefix)), 1)
+		// Prefix subsequent lines with the indentation of the item's content
+		r.rc.writer.PushPrefix(bytes.Repeat([]byte{' '}, indent), 1)
 	} else {
+		// Empty items have no content to write the item prefix, so end the line explicitly
+		if !node.HasChildren() {
+			r.rc.writer.WriteBytes(r.config.EmptyListItemStyle.Placeholder())
+			r.rc.writer.EndLine()
+		}
 		r.rc.writer.PopPrefix()
 		r.rc.writer.PopPrefix()
 	}
@@ -350,7 +1096,8 @@ func (r *Renderer) renderListItem(node ast.Node, entering bool) ast.WalkStatus {
 func (r *Renderer) renderRawHTML(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.RawHTML)
 	if entering {
-		if r.config.TextTransformer != nil {
+		// Comments are never transformed
+		if r.config.TextTransformer != nil && !isHTMLComment(n, r.rc.source) {
 			// For RawHTML, we just process this single node
 			// We'll capture the complete HTML structure during translation step
 			// with a custom TextTransformer approach
@@ -363,7 +1110,7 @@ func (r *Renderer) renderRawHTML(node ast.Node, entering bool) ast.WalkStatus {
 
 			// Send the HTML content to the TextTransformer
 			htmlStr := htmlContent.String()
-			if translation, ok := r.config.TextTransformer.Transform(TextTypeHTML, htmlStr); ok {
+			if translation, ok := r.transformText(TextTypeHTML, htmlStr); ok {
 				// Write the translated HTML directly
 				r.rc.writer.WriteBytes([]byte(translation))
 				return ast.WalkContinue
@@ -381,7 +1128,9 @@ func (r *Renderer) renderText(node ast.Node, entering bool) ast.WalkStatus {
 
 	if entering {
 		text := n.Value(r.rc.source)
-		nextIsSibling := node.NextSibling() != nil && node.NextSibling().Kind() == ast.KindText
+		// Hard line breaks end the current run of text
+		nextIsSibling := node.NextSibling() != nil && node.NextSibling().Kind() == ast.KindText &&
+			!n.HardLineBreak()
 
 		// Initialize or append to text buffer in renderContext
 		if !r.rc.textBufferActive {
@@ -393,6 +1142,7 @@ func (r *Renderer) renderText(node ast.Node, entering bool) ast.WalkStatus {
 			}
 			r.rc.textBuffer.Write(text)
 			r.rc.textBufferActive = true
+			r.rc.textStart = n.Segment.Start
 			// Store this node's line break status
 			if n.SoftLineBreak() {
 				r.rc.pendingLineBreaks = append(r.rc.pendingLineBreaks, true)
@@ -422,27 +1172,83 @@ func (r *Renderer) renderText(node ast.Node, entering bool) ast.WalkStatus {
 		// If this is the last Text node in a sequence, process all accumulated text
 		if !nextIsSibling {
 			textStr := r.rc.textBuffer.String()
+			// deferredSpaces holds trailing whitespace to write once the following text is known
+			deferredSpaces := ""
+			transformed := false
 
 			// Check if we have a translation for this text
-			if r.config.TextTransformer != nil && !r.rc.skipTranslation {
-				trimmedText := strings.TrimSpace(textStr)
-
-				if translation, ok := r.config.TextTransformer.Transform(TextTypePlain, trimmedText); ok {
-					// Preserve the original leading and trailing spaces
+			// Whitespace-only text has nothing to translate
+			if (r.config.TextTransformer != nil || r.textHook != nil) && !r.rc.skipTranslation &&
+				strings.TrimSpace(textStr) != "" {
+				// The transformer receives text as it reads, without backslash escapes
+				trimmedText := string(util.UnescapePunctuations([]byte(strings.TrimSpace(textStr))))
+
+				if translation, ok := r.transformPlainText(TextSegment{
+					Start: r.rc.textStart + len(textStr) - len(strings.TrimLeftFunc(textStr, unicode.IsSpace)),
+					Stop:  n.Segment.Stop - len(textStr) + len(strings.TrimRightFunc(textStr, unicode.IsSpace)),
+					Text:  trimmedText,
+				}); ok {
+					// Re-derive the escapes needed for the translation to be read as plain text
+					translation = string(escapeInline([]byte(translation), r.config.HTMLEscaping))
+
+					// Preserve the original leading and trailing spaces, as the join policy allows
 					leadingSpaces := textStr[:len(textStr)-len(strings.TrimLeftFunc(textStr, unicode.IsSpace))]
 					trailingSpaces := textStr[len(strings.TrimRightFunc(textStr, unicode.IsSpace)):]
+					if first, _ := utf8.DecodeRuneInString(translation); !r.joinWithSpace(r.rc.lastRune, first) {
+						leadingSpaces = ""
+					}
+					if trailingSpaces != "" && r.config.InlineJoin != InlineJoinSpace && node.NextSibling() != nil {
+						deferredSpaces = trailingSpaces
+						trailingSpaces = ""
+					}
 
 					// Apply translation with preserved spaces
 					textStr = leadingSpaces + translation + trailingSpaces
+					transformed = true
 				}
 			}
 
-			// Write the accumulated text
-			r.rc.writer.WriteBytes([]byte(textStr))
+			if r.config.Whitespace != WhitespacePreserve && !r.rc.skipTranslation &&
+				(node.Parent() == nil || node.Parent().Kind() != ast.KindCodeSpan) {
+				textStr = collapseWhitespace(textStr, r.config.Whitespace == WhitespaceNormalize)
+			}
+			if r.config.CJKSpacing != CJKSpacingPreserve && !r.rc.skipTranslation &&
+				(node.Parent() == nil || node.Parent().Kind() != ast.KindCodeSpan) {
+				textStr = spaceCJK(textStr, r.config.CJKSpacing == CJKSpacingAdd)
+			}
+			if r.rc.headingText != nil {
+				r.rc.headingText.WriteString(textStr)
+			}
+
+			// Write the accumulated text, escaping anything that would start a new block
+			textBytes := []byte(textStr)
+			if r.rc.writer.Wrapping() {
+				// Soft line breaks are reflowed along with the spaces of the text
+				textBytes = bytes.ReplaceAll(textBytes, []byte{lineDelim}, []byte(" "))
+			}
+			if node.Parent() == nil || node.Parent().Kind() != ast.KindCodeSpan {
+				if !transformed && !r.rc.skipTranslation {
+					textBytes = r.escapeText(n, textBytes)
+				}
+				textBytes = escapeLineStarts(textBytes, r.rc.writer.AtLineStart() && !r.rc.inlineOnly)
+			}
+			r.resolveJoin(textBytes)
+			r.resolveEmphasis(textBytes)
+			r.rc.writer.WriteBytes(textBytes)
+			if deferredSpaces != "" {
+				r.deferJoin(textBytes, deferredSpaces)
+			}
+			if last, _ := utf8.DecodeLastRune(bytes.TrimRightFunc(textBytes, unicode.IsSpace)); last != utf8.RuneError {
+				r.rc.lastRune = last
+			}
 
 			// Handle final node's line break if needed
 			lastNodeHasLineBreak := len(r.rc.pendingLineBreaks) > 0 && r.rc.pendingLineBreaks[len(r.rc.pendingLineBreaks)-1]
-			if lastNodeHasLineBreak {
+			if n.HardLineBreak() {
+				r.renderHardLineBreak(n)
+			} else if lastNodeHasLineBreak && r.rc.writer.Wrapping() {
+				r.rc.writer.WriteBytes([]byte(" "))
+			} else if lastNodeHasLineBreak {
 				r.rc.writer.EndLine()
 			}
 
@@ -455,6 +1261,48 @@ func (r *Renderer) renderText(node ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
 
+// escapeText escapes text from the source ending with the Text node n as set by the TextEscaping of
+// the config, taking the characters around it into account.
+func (r *Renderer) escapeText(n *ast.Text, text []byte) []byte {
+	if r.config.TextEscaping == TextEscapingMinimal {
+		return text
+	}
+	before := rune(lineDelim)
+	if line, _, ok := r.rc.writer.Buffer(r.rc.writer.Position()); ok {
+		before = lastRune(line)
+	}
+	// The text ends the line if nothing follows it, and isn't known yet otherwise
+	after := rune(0)
+	if n.NextSibling() == nil || n.HardLineBreak() || n.SoftLineBreak() {
+		after = rune(lineDelim)
+	}
+	return escapeText(text, before, after, r.config.TextEscaping)
+}
+
+// renderHardLineBreak ends the line with a hard line break in the configured style, or the style of
+// the source in StyleModePreserve.
+func (r *Renderer) renderHardLineBreak(n *ast.Text) {
+	style := r.config.HardBreakStyle
+	if r.config.StyleMode == StyleModePreserve && n.Segment.Stop < len(r.rc.source) {
+		switch r.rc.source[n.Segment.Stop] {
+		case '\\':
+			style = HardBreakStyleBackslash
+		case ' ':
+			style = HardBreakStyleSpaces
+		}
+	}
+	if style == HardBreakStyleSpaces {
+		// Trailing spaces are trimmed from lines that aren't verbatim
+		r.rc.writer.SetVerbatim(true)
+		r.rc.writer.WriteBytes([]byte("  "))
+		r.rc.writer.EndLine()
+		r.rc.writer.SetVerbatim(false)
+		return
+	}
+	r.rc.writer.WriteBytes([]byte("\\"))
+	r.rc.writer.EndLine()
+}
+
 func (r *Renderer) renderSegments(segments *text.Segments, asLines bool) {
 	for i := 0; i < segments.Len(); i++ {
 		segment := segments.At(i)
@@ -474,23 +1322,73 @@ func (r *Renderer) renderLines(node ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
 
+// renderVerbatimLines renders the lines of node exactly as they are in the source, including
+// trailing whitespace.
+func (r *Renderer) renderVerbatimLines(node ast.Node) {
+	r.rc.writer.SetVerbatim(true)
+	r.renderLines(node, true)
+	r.rc.writer.SetVerbatim(false)
+}
+
+func (r *Renderer) renderTaskCheckBox(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.rc.writer.WriteBytes(r.taskCheckBox(node.(*east.TaskCheckBox)))
+	}
+	return ast.WalkContinue
+}
+
+// taskCheckBox returns the task list marker to write for node. Checked boxes are written with the x
+// in the configured case, or as in the source in StyleModePreserve.
+func (r *Renderer) taskCheckBox(node *east.TaskCheckBox) []byte {
+	if !node.IsChecked {
+		return []byte("[ ] ")
+	}
+	if r.config.StyleMode == StyleModePreserve {
+		if mark := sourceTaskCheckBox(node, r.rc.source); mark == 'x' || mark == 'X' {
+			return []byte{'[', mark, ']', ' '}
+		}
+	}
+	if r.config.TaskCheckBoxCase == TaskCheckBoxCaseUpper {
+		return []byte("[X] ")
+	}
+	return []byte("[x] ")
+}
+
+// sourceTaskCheckBox returns the character between the brackets of a task list marker in the source,
+// found before the text that follows it, or 0 if it can't be found.
+func sourceTaskCheckBox(node ast.Node, source []byte) byte {
+	next, ok := node.NextSibling().(*ast.Text)
+	if !ok {
+		return 0
+	}
+	pos := next.Segment.Start
+	for pos > 0 && source[pos-1] == ' ' || pos > 0 && source[pos-1] == '\t' {
+		pos--
+	}
+	if pos == 0 || source[pos-1] != ']' {
+		return 0
+	}
+	// Spaces are allowed inside the brackets
+	for pos--; pos > 0 && source[pos-1] == ' '; pos-- {
+	}
+	if pos == 0 {
+		return 0
+	}
+	return source[pos-1]
+}
+
 func (r *Renderer) renderLink(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.Link)
 	if entering {
-		r.rc.writer.WriteBytes([]byte("["))
 		// Text content should be translated, skipTranslation is false by default
+		r.rc.writer.WriteBytes([]byte("["))
 	} else {
-		// Only set skipTranslation when rendering the URL part
-		r.rc.skipTranslation = true
-		r.rc.writer.WriteBytes([]byte("]("))
-		r.rc.writer.WriteBytes(n.Destination)
-		if len(n.Title) > 0 {
-			r.rc.writer.WriteBytes([]byte(" \""))
-			r.rc.writer.WriteBytes(n.Title)
-			r.rc.writer.WriteBytes([]byte("\""))
+		destination, title, changed := r.transformLink(n.Destination, n.Title, false)
+		if label, reference, ok := r.sourceReference(n); ok && !changed {
+			r.renderLinkReference(label, reference)
+		} else {
+			r.renderLinkDestination(destination, nil, title)
 		}
-		r.rc.writer.WriteBytes([]byte(")"))
-		r.rc.skipTranslation = false
 	}
 	return ast.WalkContinue
 }
@@ -498,28 +1396,90 @@ func (r *Renderer) renderLink(node ast.Node, entering bool) ast.WalkStatus {
 func (r *Renderer) renderImage(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.Image)
 	if entering {
-		r.rc.writer.WriteBytes([]byte("!["))
 		// Alt text should be translated, skipTranslation is false by default
+		r.rc.writer.WriteBytes([]byte("!["))
 	} else {
-		// Only set skipTranslation when rendering the URL part
-		r.rc.skipTranslation = true
-		r.rc.writer.WriteBytes([]byte("]("))
-		r.rc.writer.WriteBytes(n.Destination)
-		if len(n.Title) > 0 {
-			r.rc.writer.WriteBytes([]byte(" \""))
-			// Temporarily disable skipTranslation to allow the title to be translated
-			r.rc.skipTranslation = false
-			r.rc.writer.WriteBytes(n.Title)
-			// Re-enable skipTranslation for the rest of the URL
-			r.rc.skipTranslation = true
-			r.rc.writer.WriteBytes([]byte("\""))
+		destination, title, changed := r.transformLink(n.Destination, n.Title, true)
+		if label, reference, ok := r.sourceReference(n); ok && !changed {
+			r.renderLinkReference(label, reference)
+			return ast.WalkContinue
 		}
-		r.rc.writer.WriteBytes([]byte(")"))
-		r.rc.skipTranslation = false
+		var size []byte
+		if r.config.ImageAttributes == ImageAttributesPreserve {
+			size = imageSizeSuffix(n)
+		}
+		r.renderLinkDestination(destination, size, title)
 	}
 	return ast.WalkContinue
 }
 
+// renderLinkDestination closes the text of a link or image and writes its destination, the size
+// suffix of an image, if any, and its title, or a reference to a definition of them in
+// LinkStyleReference.
+func (r *Renderer) renderLinkDestination(destination, size, title []byte) {
+	// Restore the previous state afterwards, since links and images can be nested
+	skipTranslation := r.rc.skipTranslation
+	r.rc.skipTranslation = true
+	// Definitions can't have image sizes or empty destinations
+	if r.config.LinkStyle == LinkStyleReference && len(size) == 0 && len(destination) > 0 {
+		r.rc.writer.WriteBytes([]byte("]["))
+		r.rc.writer.WriteBytes(r.linkReference(destination, title))
+		r.rc.writer.WriteBytes([]byte("]"))
+	} else {
+		r.rc.writer.WriteBytes([]byte("]("))
+		r.writeLinkTarget(destination, size, title)
+		r.rc.writer.WriteBytes([]byte(")"))
+	}
+	r.rc.skipTranslation = skipTranslation
+}
+
+// writeLinkTarget writes the destination of a link or image, the size suffix of an image, if any,
+// and its title.
+func (r *Renderer) writeLinkTarget(destination, size, title []byte) {
+	if needsAngleBrackets(destination) {
+		r.rc.writer.WriteBytes([]byte("<"))
+		for _, c := range destination {
+			if c == '<' || c == '>' {
+				r.rc.writer.WriteBytes([]byte("\\"))
+			}
+			r.rc.writer.WriteBytes([]byte{c})
+		}
+		r.rc.writer.WriteBytes([]byte(">"))
+	} else {
+		r.rc.writer.WriteBytes(destination)
+	}
+	if len(size) > 0 {
+		r.rc.writer.WriteBytes([]byte(" "))
+		r.rc.writer.WriteBytes(size)
+	}
+	if len(title) > 0 {
+		r.rc.writer.WriteBytes([]byte(" \""))
+		r.rc.writer.WriteBytes(escapeLinkTitle(title))
+		r.rc.writer.WriteBytes([]byte("\""))
+	}
+}
+
+// needsAngleBrackets returns true if a link destination must be enclosed in angle brackets, because
+// it contains spaces or unbalanced parentheses.
+func needsAngleBrackets(destination []byte) bool {
+	depth := 0
+	for i, c := range destination {
+		switch {
+		case c == ' ' || c == '\t' || c == '<':
+			return true
+		// Escaped parentheses don't need to be balanced
+		case c == '(' && (i == 0 || destination[i-1] != '\\'):
+			depth++
+		case c == ')' && (i == 0 || destination[i-1] != '\\'):
+			depth--
+			if depth < 0 {
+				return true
+			}
+		}
+	}
+	return depth != 0
+}
+
 func (r *Renderer) renderCodeSpan(node ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.rc.skipTranslation = true
@@ -568,11 +1528,21 @@ func (r *Renderer) renderCodeSpan(node ast.Node, entering bool) ast.WalkStatus {
 				break
 			}
 		}
-		r.rc.writer.WriteBytes(bytes.Repeat([]byte("`"), r.rc.codeSpanContext.backtickLength))
-
 		// Check if the code span needs to be padded with spaces
-		if beginsWithSpace && endsWithSpace && !isOnlySpace || beginsWithBackTick || endsWithBackTick {
-			r.rc.codeSpanContext.padSpace = true
+		r.rc.codeSpanContext.padSpace = beginsWithSpace && endsWithSpace && !isOnlySpace ||
+			beginsWithBackTick || endsWithBackTick
+
+		// Reuse the source delimiters if they can still contain the span
+		if r.config.StyleMode == StyleModePreserve {
+			length, padSpace, ok := sourceCodeSpanDelimiter(node, r.rc.source)
+			if ok && !slices.Contains(backtickLengths, length) && (padSpace || !r.rc.codeSpanContext.padSpace) {
+				r.rc.codeSpanContext.backtickLength = length
+				r.rc.codeSpanContext.padSpace = padSpace
+			}
+		}
+
+		r.rc.writer.WriteBytes(bytes.Repeat([]byte("`"), r.rc.codeSpanContext.backtickLength))
+		if r.rc.codeSpanContext.padSpace {
 			r.rc.writer.WriteBytes([]byte(" "))
 		}
 	} else {
@@ -586,76 +1556,186 @@ func (r *Renderer) renderCodeSpan(node ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
 
+// sourceCodeSpanDelimiter returns the number of backticks that opened a code span in the source, and
+// whether its content was padded by a space that the parser stripped.
+func sourceCodeSpanDelimiter(node ast.Node, source []byte) (length int, padSpace bool, ok bool) {
+	first, isText := node.FirstChild().(*ast.Text)
+	if !isText {
+		return 0, false, false
+	}
+	pos := first.Segment.Start
+	if pos > 0 && (source[pos-1] == ' ' || source[pos-1] == lineDelim) {
+		padSpace = true
+		pos--
+	}
+	for pos > 0 && source[pos-1] == '`' {
+		length++
+		pos--
+	}
+	return length, padSpace, length > 0
+}
+
 func (r *Renderer) renderEmphasis(node ast.Node, entering bool) ast.WalkStatus {
 	n := node.(*ast.Emphasis)
-	r.rc.writer.WriteBytes(bytes.Repeat([]byte{'*'}, n.Level))
+	delimiter := []byte{r.emphasisDelimiter(n)}
+	if entering {
+		r.rc.emphasis = append(r.rc.emphasis, r.rc.writer.Position())
+		r.rc.writer.WriteBytes(bytes.Repeat(delimiter, n.Level))
+		return ast.WalkContinue
+	}
+	delimiters := emphasisDelimiters{
+		opening: r.rc.emphasis[len(r.rc.emphasis)-1],
+		closing: r.rc.writer.Position(),
+		level:   n.Level,
+		char:    delimiter[0],
+	}
+	r.rc.emphasis = r.rc.emphasis[:len(r.rc.emphasis)-1]
+	r.rc.writer.WriteBytes(bytes.Repeat(delimiter, n.Level))
+	r.checkEmphasis(delimiters)
 	return ast.WalkContinue
 }
 
-// Table rendering functions
+func (r *Renderer) renderStrikethrough(node ast.Node, entering bool) ast.WalkStatus {
+	r.rc.writer.WriteBytes([]byte("~~"))
+	r.rc.lastRune = '~'
+	return ast.WalkContinue
+}
+
+// renderTable renders a table in a single pass over its cells, since the width of each column depends
+// on the rendered content of every cell in it.
 func (r *Renderer) renderTable(
 	w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
-	// Tables are rendered as markdown tables with | separators
 	if !entering {
-		// End the table with a newline
-		// r.rc.writer.EndLine()
+		return ast.WalkContinue, nil
+	}
+	table := n.(*east.Table)
+	rows, err := r.renderTableCells(table)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	// Measure each column, starting from the minimum delimiter width
+	widths := make([]int, len(table.Alignments))
+	for i := range widths {
+		widths[i] = 3
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], displayWidth(cell))
+			}
+		}
 	}
-	return ast.WalkContinue, nil
-}
-
-func (r *Renderer) renderTableHeader(
-	w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
-		r.rc.writer.WriteBytes([]byte("|"))
-	} else {
-		// After rendering all header cells, add the separator row
-		r.rc.writer.EndLine()
 
-		tableNode := n.Parent()
-		alignments := tableNode.(*east.Table).Alignments
+	for i, row := range rows {
+		if r.config.TableStyle == TableStylePretty {
+			for j, cell := range row {
+				row[j] = padTableCell(cell, widths[j], table.Alignments[j])
+			}
+		}
+		r.writeTableRow(row)
+		if i == 0 {
+			r.writeTableDelimiterRow(table.Alignments, widths)
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
 
-		r.rc.writer.WriteByte('|')
-		for _, alignment := range alignments {
-			r.rc.writer.WriteByte(' ')
-			switch alignment {
-			case east.AlignLeft:
-				r.rc.writer.WriteBytes([]byte(":----- "))
-			case east.AlignRight:
-				r.rc.writer.WriteBytes([]byte("-----: "))
-			case east.AlignCenter:
-				r.rc.writer.WriteBytes([]byte(":----: "))
-			default:
-				r.rc.writer.WriteBytes([]byte("----- "))
+// renderTableCells renders the content of each cell of the table, with the header row first.
+func (r *Renderer) renderTableCells(table *east.Table) ([][][]byte, error) {
+	var rows [][][]byte
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells [][]byte
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			content, err := r.renderInlines(cell)
+			if err != nil {
+				return nil, err
 			}
-			r.rc.writer.WriteByte('|')
+			cells = append(cells, escapeTableCell(content))
 		}
-		r.rc.writer.EndLine()
+		// Every row has a cell per column, even if the AST leaves trailing cells out
+		for len(cells) < len(table.Alignments) {
+			cells = append(cells, nil)
+		}
+		cells = cells[:len(table.Alignments)]
+		if isTableDelimiterRow(cells) {
+			// Keep the row from being read as the table's delimiter row
+			for i, cell := range cells {
+				cells[i] = append([]byte{'\\'}, cell...)
+			}
+		}
+		rows = append(rows, cells)
 	}
-	return ast.WalkContinue, nil
+	return rows, nil
 }
 
-func (r *Renderer) renderTableRow(
-	w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
-		// Start the row with a pipe
-		r.rc.writer.WriteByte('|')
-	} else {
-		// End the row with a pipe and a newline
-		r.rc.writer.EndLine()
+// renderInlines renders the children of node on their own, returning the rendered bytes.
+func (r *Renderer) renderInlines(node ast.Node) ([]byte, error) {
+	writer, inlineOnly, lastRune := r.rc.writer, r.rc.inlineOnly, r.rc.lastRune
+	defer func() { r.rc.writer, r.rc.inlineOnly, r.rc.lastRune = writer, inlineOnly, lastRune }()
+	buf := bytes.Buffer{}
+	r.rc.writer = newMarkdownWriter(&buf, r.config)
+	r.rc.inlineOnly = true
+	r.rc.lastRune = 0
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		if err := r.walk(c); err != nil {
+			return nil, err
+		}
 	}
-	return ast.WalkContinue, nil
+	r.rc.writer.FlushLine()
+	return bytes.TrimSuffix(buf.Bytes(), []byte{lineDelim}), r.rc.writer.Err()
 }
 
-func (r *Renderer) renderTableCell(
-	w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
-		// Add a space after the pipe for readability
+// padTableCell pads a rendered table cell with spaces to the display width of its column, on the side
+// opposite its alignment, or on both sides if it's centered.
+func padTableCell(cell []byte, width int, alignment east.Alignment) []byte {
+	padding := width - displayWidth(cell)
+	if padding <= 0 {
+		return cell
+	}
+	left := 0
+	switch alignment {
+	case east.AlignRight:
+		left = padding
+	case east.AlignCenter:
+		left = padding / 2
+	}
+	padded := make([]byte, 0, len(cell)+padding)
+	padded = append(padded, bytes.Repeat([]byte{' '}, left)...)
+	padded = append(padded, cell...)
+	return append(padded, bytes.Repeat([]byte{' '}, padding-left)...)
+}
+
+// writeTableRow writes a row of rendered table cells.
+func (r *Renderer) writeTableRow(cells [][]byte) {
+	r.rc.writer.WriteByte('|')
+	for _, cell := range cells {
 		r.rc.writer.WriteByte(' ')
-	} else {
-		// Add a space and pipe after each cell
+		r.rc.writer.WriteBytes(cell)
 		r.rc.writer.WriteBytes([]byte(" |"))
 	}
-	return ast.WalkContinue, nil
+	r.rc.writer.EndLine()
+}
+
+// writeTableDelimiterRow writes the delimiter row separating the table header from its body, with
+// each column's delimiter as wide as the column.
+func (r *Renderer) writeTableDelimiterRow(alignments []east.Alignment, widths []int) {
+	r.rc.writer.WriteByte('|')
+	for i, alignment := range alignments {
+		delimiter := bytes.Repeat([]byte{'-'}, widths[i])
+		switch alignment {
+		case east.AlignLeft:
+			delimiter[0] = ':'
+		case east.AlignRight:
+			delimiter[len(delimiter)-1] = ':'
+		case east.AlignCenter:
+			delimiter[0] = ':'
+			delimiter[len(delimiter)-1] = ':'
+		}
+		r.rc.writer.WriteByte(' ')
+		r.rc.writer.WriteBytes(delimiter)
+		r.rc.writer.WriteBytes([]byte(" |"))
+	}
+	r.rc.writer.EndLine()
 }
 
 type renderContext struct {
@@ -665,17 +1745,57 @@ type renderContext struct {
 	// listMarkers is the marker character used for the current list
 	lists           []listContext
 	codeSpanContext codeSpanContext
+	// codeFenceContext holds state about the fenced code block being rendered
+	codeFenceContext codeFenceContext
 	// skipTranslation indicates whether we're inside a node type that shouldn't be translated
 	skipTranslation bool
+	// lastRune is the last non-space character of the most recently written text
+	lastRune rune
+	// pendingJoin holds whitespace whose rendering depends on the text that follows
+	pendingJoin *pendingJoin
+	// emphasis holds the positions of the opening delimiters of the emphasis being rendered
+	emphasis []writerPosition
+	// pendingEmphasis holds emphasis whose closing delimiter is checked once the following text is
+	// known
+	pendingEmphasis *emphasisDelimiters
+	// htmlBlockTransformed indicates the HTML block being rendered was replaced by the transformer
+	htmlBlockTransformed bool
+	// inlineOnly indicates inline content is being rendered on its own, so the start of the output
+	// isn't the start of a block
+	inlineOnly bool
 	// Text accumulation fields
 	textBuffer        *bytes.Buffer
 	textBufferActive  bool
 	pendingLineBreaks []bool
+	// textStart is the source position of the text being accumulated
+	textStart int
+	// metrics collects render metrics if non-nil
+	metrics *Metrics
+	// transformer is the TextTransformer for the TargetLanguage
+	transformer TextTransformer
+	// footnoteRefs maps the indexes of the document's footnotes to their labels once looked up
+	footnoteRefs map[int][]byte
+	// rawKinds holds the kinds of nodes written as their source verbatim
+	rawKinds map[ast.NodeKind]bool
+	// textChanged indicates the TextTransformer changed text of the block being reviewed
+	textChanged bool
+	// headingText collects the rendered text of the heading being rendered, to generate its id from
+	headingText *strings.Builder
+	// headingSlugs holds the ids generated, for uniqueSlug
+	headingSlugs map[string]int
+	// linkLabels maps the destinations and titles of links written as references to their labels
+	linkLabels map[string][]byte
+	// linkDefinitions holds the definitions of the links written as references, in order
+	linkDefinitions []linkDefinition
+	// err is the first error of a renderer.NodeRendererFunc, which stops the walk
+	err error
 }
 
 type listContext struct {
 	list *ast.List
 	num  int
+	// marker is the marker written for the list's items
+	marker byte
 }
 
 // codeSpanContext holds state about how the current codespan should be rendererd.
@@ -686,10 +1806,41 @@ type codeSpanContext struct {
 	padSpace bool
 }
 
+// writerPool holds markdownWriters that can be reused between renders.
+var writerPool = sync.Pool{
+	New: func() any {
+		return &markdownWriter{buf: &bytes.Buffer{}}
+	},
+}
+
+// codeFenceContext holds state about how the current fenced code block should be rendered.
+type codeFenceContext struct {
+	// closing is the closing fence
+	closing []byte
+	// indented is true if the code block's lines are indented
+	indented bool
+}
+
 // newRenderContext returns a new renderContext object
 func newRenderContext(writer io.Writer, source []byte, config *Config) renderContext {
+	w := writerPool.Get().(*markdownWriter)
+	w.config = config
+	w.Reset(writer)
+	transformer := config.TextTransformer
+	if t, ok := transformer.(LanguageTransformer); ok && config.TargetLanguage != "" {
+		transformer = t.ForLanguage(string(config.TargetLanguage))
+	}
 	return renderContext{
-		writer: newMarkdownWriter(writer, config),
-		source: source,
+		writer:      w,
+		source:      source,
+		transformer: transformer,
+	}
+}
+
+// release returns the context's writer to the pool. The context must not be written to afterwards.
+func (rc *renderContext) release() {
+	if rc.writer != nil {
+		writerPool.Put(rc.writer)
+		rc.writer = nil
 	}
 }
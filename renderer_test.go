@@ -3,6 +3,7 @@ package markdown
 import (
 	"bytes"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/rhysd/go-fakeio"
@@ -10,6 +11,7 @@ import (
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 	"github.com/yuin/goldmark/util"
@@ -115,6 +117,24 @@ func TestRenderedOutput(t *testing.T) {
 			"Foo Bar\n---",
 			"Foo Bar\n-------\n",
 		},
+		{
+			"Full width setext heading with wide characters",
+			[]Option{WithHeadingStyle(HeadingStyleFullWidthSetext)},
+			"标题 Title\n---",
+			"标题 Title\n----------\n",
+		},
+		{
+			"Full width setext heading with inline markup",
+			[]Option{WithHeadingStyle(HeadingStyleFullWidthSetext)},
+			"Foo *Bar*\n===",
+			"Foo *Bar*\n=========\n",
+		},
+		{
+			"Full width multiline setext heading in list",
+			[]Option{WithHeadingStyle(HeadingStyleFullWidthSetext)},
+			"- Foo\n  Barbaz\n  ---",
+			"- Foo\n  Barbaz\n  ------\n",
+		},
 		{
 			"ATX heading with closing sequence",
 			[]Option{WithHeadingStyle(HeadingStyleATXSurround)},
@@ -167,6 +187,42 @@ func TestRenderedOutput(t *testing.T) {
 			"> one\n> > two\n> > > three\n\n> one again",
 			"> one\n> > two\n> > > three\n\n> one again\n",
 		},
+		{
+			"Blockquote with multiple paragraphs",
+			[]Option{},
+			"> one\n>\n> two",
+			"> one\n>\n> two\n",
+		},
+		{
+			"Blockquote containing loose list",
+			[]Option{},
+			"> intro\n>\n> - a\n>\n>   more a\n> - b",
+			"> intro\n>\n> - a\n>\n>   more a\n> - b\n",
+		},
+		{
+			"Blockquote paragraph followed by code block",
+			[]Option{},
+			"> para\n>\n>     code",
+			"> para\n>\n>     code\n",
+		},
+		{
+			"Nested blockquote followed by shallower paragraph",
+			[]Option{},
+			">> deep\n>\n> shallow",
+			"> > deep\n>\n> shallow\n",
+		},
+		{
+			"Nested blockquote containing list and code block",
+			[]Option{},
+			"> a\n>\n>> b\n>>\n>> - x\n>>\n>>       code\n>\n> c",
+			"> a\n>\n> > b\n> >\n> > - x\n> >\n> >       code\n>\n> c\n",
+		},
+		{
+			"Nested blockquote containing fenced code block",
+			[]Option{},
+			"> > ```\n> > f\n> >\n> > g\n> > ```",
+			"> > ```\n> > f\n> >\n> > g\n> > ```\n",
+		},
 		// Code Block
 		{
 			"Space indented code block",
@@ -180,6 +236,24 @@ func TestRenderedOutput(t *testing.T) {
 			"    foo",
 			"\tfoo\n",
 		},
+		{
+			"Code block with trailing whitespace and tabs",
+			[]Option{},
+			"    x  \n    \ty\n\n    z",
+			"    x  \n    \ty\n\n    z\n",
+		},
+		{
+			"Preserved code block indentation",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"\tfoo\n    bar\n\n\t\tbaz",
+			"\tfoo\n    bar\n\n\t\tbaz\n",
+		},
+		{
+			"Preserved code block indentation in list",
+			[]Option{WithStyleMode(StyleModePreserve), WithIndentStyle(IndentStyleTabs)},
+			"- a\n\n      code\n       more",
+			"- a\n\n      code\n       more\n",
+		},
 		{
 			"Multiline code block",
 			[]Option{WithIndentStyle(IndentStyleSpaces)},
@@ -301,6 +375,30 @@ func TestRenderedOutput(t *testing.T) {
 			"`foo``bar``",
 			"`foo`bar`\n",
 		},
+		{
+			"Padded code span followed by unpadded code span",
+			[]Option{},
+			"`` `a` `` then `b`",
+			"`` `a` `` then `b`\n",
+		},
+		{
+			"Preserved code span delimiters",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"``foo bar`` and ` baz ` and ```a `` b```",
+			"``foo bar`` and ` baz ` and ```a `` b```\n",
+		},
+		{
+			"Preserved code span padding",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"``  ``  `` and `  a  `",
+			"``  ``  `` and `  a  `\n",
+		},
+		{
+			"Preserved code span with required padding",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"`` `a` ``",
+			"`` `a` ``\n",
+		},
 		// Emphasis
 		{
 			"Emphasis",
@@ -308,6 +406,24 @@ func TestRenderedOutput(t *testing.T) {
 			"*emph*",
 			"*emph*\n",
 		},
+		{
+			"Emphasis nested in emphasis",
+			[]Option{},
+			"*_a_* **_a_** __*a*__",
+			"*_a_* **_a_** **_a_**\n",
+		},
+		{
+			"Strong nested in emphasis",
+			[]Option{},
+			"_**a**_ *__a__* ____a____",
+			"***a*** ***a*** ****a****\n",
+		},
+		{
+			"Adjacent emphasis",
+			[]Option{},
+			"*a*_b_*c* __a__**b**",
+			"*a*_b_*c* **a**__b__\n",
+		},
 		{
 			"Strong",
 			[]Option{},
@@ -357,6 +473,42 @@ func TestRenderedOutput(t *testing.T) {
 			"\\# foo \\*bar\\* \\__baz\\_\\_",
 			"\\# foo \\*bar\\* \\__baz\\_\\_\n",
 		},
+		{
+			"Hard line break with backslash",
+			[]Option{},
+			"foo\\\nbar",
+			"foo\\\nbar\n",
+		},
+		{
+			"Hard line break with trailing spaces",
+			[]Option{},
+			"foo  \nbar *baz*  \nqux",
+			"foo\\\nbar *baz*\\\nqux\n",
+		},
+		{
+			"Hard line break in list item",
+			[]Option{},
+			"- foo\\\n  bar",
+			"- foo\\\n  bar\n",
+		},
+		{
+			"Hard line break spaces style",
+			[]Option{WithHardBreakStyle(HardBreakStyleSpaces)},
+			"foo\\\nbar *baz*  \nqux",
+			"foo  \nbar *baz*  \nqux\n",
+		},
+		{
+			"Hard line break spaces style in blockquote",
+			[]Option{WithHardBreakStyle(HardBreakStyleSpaces)},
+			"> foo\\\n> bar",
+			"> foo  \n> bar\n",
+		},
+		{
+			"Hard line break preserved style",
+			[]Option{WithHardBreakStyle(HardBreakStyleSpaces), WithStyleMode(StyleModePreserve)},
+			"foo\\\nbar   \nqux",
+			"foo\\\nbar  \nqux\n",
+		},
 		// Thematic Break
 		{
 			"Thematic break default style",
@@ -389,6 +541,43 @@ func TestRenderedOutput(t *testing.T) {
 			"---",
 			"----------\n",
 		},
+		{
+			"Thematic break after paragraph",
+			[]Option{},
+			"a\n***",
+			"a\n\n---\n",
+		},
+		{
+			"Thematic break preserved",
+			[]Option{WithStyleMode(StyleModePreserve), WithThematicBreakStyle(ThematicBreakStyleUnderlined)},
+			"a\n\n- - -\n\n*****\n\nb",
+			"a\n\n- - -\n\n*****\n\nb\n",
+		},
+		{
+			"Thematic break preserved in containers",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"> * * *\n\n- a\n- ***\n\n* ___",
+			"> * * *\n\n- a\n- ***\n\n* ___\n",
+		},
+		{
+			"Thematic break preserved after paragraph",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"a\n***\n\nb\n- - -",
+			"a\n***\n\nb\n\n- - -\n",
+		},
+		{
+			"Adjacent thematic breaks preserved",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"***\n___",
+			"***\n___\n",
+		},
+		// Link
+		{
+			"Link title with quotes",
+			[]Option{},
+			"[a](/u (say \"hi\")) [b](/v \"a \\\" b\")",
+			"[a](/u \"say \\\"hi\\\"\") [b](/v \"a \\\" b\")\n",
+		},
 		// Fenced Code Block
 		{
 			"Fenced Code Block",
@@ -408,6 +597,66 @@ func TestRenderedOutput(t *testing.T) {
 			"```\n!@#$%^&*\\[],./;'()\n```",
 			"```\n!@#$%^&*\\[],./;'()\n```\n",
 		},
+		{
+			"Fenced Code Block with trailing whitespace and tabs",
+			[]Option{},
+			"```\na  \n\tb\t\n  \n\n```",
+			"```\na  \n\tb\t\n  \n\n```\n",
+		},
+		{
+			"Fenced Code Block containing fences",
+			[]Option{},
+			"~~~md\n```\ninner\n````\n~~~",
+			"`````md\n```\ninner\n````\n`````\n",
+		},
+		{
+			"Fenced Code Block with backticks in info",
+			[]Option{},
+			"~~~ a`b\nfoo\n~~~",
+			"~~~a`b\nfoo\n~~~\n",
+		},
+		{
+			"Fenced Code Block with highlighting attributes",
+			[]Option{},
+			"```go {hl_lines=[2,3] linenos=true}\nfoo\n```",
+			"```go {hl_lines=[2,3] linenos=true}\nfoo\n```\n",
+		},
+		{
+			"Fenced Code Block with quoted and escaped attributes",
+			[]Option{},
+			"```go  {hl_lines=[\"2-3\"] linenostart=199 title=\"a \\\"b\\\" &amp; c\"}  \nfoo\n```",
+			"```go  {hl_lines=[\"2-3\"] linenostart=199 title=\"a \\\"b\\\" &amp; c\"}\nfoo\n```\n",
+		},
+		{
+			"Preserved code fence with highlighting attributes",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"~~~  go {.numberLines startFrom=\"10\"}\nfoo\n~~~",
+			"~~~  go {.numberLines startFrom=\"10\"}\nfoo\n~~~\n",
+		},
+		{
+			"Preserved code fence",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"~~~~ go\nfoo\n~~~~~~",
+			"~~~~ go\nfoo\n~~~~~~\n",
+		},
+		{
+			"Preserved indented code fence",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"  ```\n  a\n b\n  ```\n\nc",
+			"  ```\n  a\n  b\n  ```\n\nc\n",
+		},
+		{
+			"Preserved code fence in blockquote",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"> ~~~\n> a\n> ~~~",
+			"> ~~~\n> a\n> ~~~\n",
+		},
+		{
+			"Preserved tilde code fence containing backticks",
+			[]Option{WithStyleMode(StyleModePreserve)},
+			"~~~\n```\n~~~",
+			"~~~\n```\n~~~\n",
+		},
 		// Raw HTML
 		{
 			"Raw HTML open tags",
@@ -495,6 +744,114 @@ func TestRenderedOutput(t *testing.T) {
 			"1. A1\n2. B1\n   - C2\n     1. D3\n     2. E3\n   - F2\n   - G2\n3. H1\n",
 			"1. A1\n2. B1\n      - C2\n          1. D3\n          2. E3\n      - F2\n      - G2\n3. H1\n",
 		},
+		{
+			"Nested list length with wide markers",
+			[]Option{WithNestedListLength(2)},
+			"9. A1\n10. B1\n    - C2\n\n    D1\n",
+			"9. A1\n10. B1\n       - C2\n\n       D1\n",
+		},
+		{
+			"List indent width",
+			[]Option{WithListIndentWidth(4)},
+			"- A1\n  - B2\n\n    more B2\n\n9. C1\n10. D1\n    ```\n    code\n    ```\n",
+			"-   A1\n    -   B2\n\n        more B2\n\n9.  C1\n10. D1\n    ```\n    code\n    ```\n",
+		},
+		{
+			"List indent width beyond code indentation",
+			[]Option{WithListIndentWidth(8)},
+			"- A1\n  - B2\n",
+			"-    A1\n     -    B2\n",
+		},
+		{
+			"List item with multiple paragraphs",
+			[]Option{},
+			"- one\n\n  more one\n\n- two",
+			"- one\n\n  more one\n\n- two\n",
+		},
+		{
+			"Ordered list item with multiple paragraphs",
+			[]Option{},
+			"1. one\n\n   more one\n2. two",
+			"1. one\n\n   more one\n2. two\n",
+		},
+		{
+			"Ordered list start number",
+			[]Option{},
+			"3. A1\n4. B1\n   - C2",
+			"3. A1\n4. B1\n   - C2\n",
+		},
+		{
+			"Ordered list renumbered from one",
+			[]Option{WithListNumbering(ListNumberingFromOne)},
+			"3. A1\n4. B1\n   1) C2\n   2) D2",
+			"1. A1\n2. B1\n   1) C2\n   2) D2\n",
+		},
+		{
+			"Bullet marker",
+			[]Option{WithBulletMarker(BulletMarkerAsterisk)},
+			"- A1\n- B1\n  + C2\n\n1. D1",
+			"* A1\n* B1\n  * C2\n\n1. D1\n",
+		},
+		{
+			"Bullet marker of adjacent lists",
+			[]Option{WithBulletMarker(BulletMarkerDash)},
+			"- A1\n+ B1\n* C1\n\n  - D2\n  + E2",
+			"- A1\n* B1\n- C1\n\n  - D2\n  * E2\n",
+		},
+		{
+			"Thematic break in list item with the same marker",
+			[]Option{},
+			"- ***\n- A1\n\n1. - ***\n\n* ---",
+			"- ___\n- A1\n\n1. - ___\n\n* ---\n",
+		},
+		{
+			"Empty list items",
+			[]Option{},
+			"- a\n-\n- b\n\n1. x\n2.\n3. y",
+			"- a\n-\n- b\n\n1. x\n2.\n3. y\n",
+		},
+		{
+			"Nested empty list item",
+			[]Option{},
+			"> - a\n>   - b\n>   -",
+			"> - a\n>   - b\n>   -\n",
+		},
+		{
+			"Empty list item placeholder",
+			[]Option{WithEmptyListItemStyle(EmptyListItemStyleNBSP)},
+			"- a\n-",
+			"- a\n- &nbsp;\n",
+		},
+		{
+			"List item with fenced code block",
+			[]Option{},
+			"- item\n\n  ```go\n  a\n\n  b\n  ```\n- ```\n  code\n  ```",
+			"- item\n\n  ```go\n  a\n\n  b\n  ```\n- ```\n  code\n  ```\n",
+		},
+		{
+			"List item with blockquote",
+			[]Option{},
+			"- item\n\n  > quote\n  > more\n- > q\n  > r",
+			"- item\n\n  > quote\n  > more\n- > q\n  > r\n",
+		},
+		{
+			"List item with indented code block",
+			[]Option{},
+			"- item\n\n      code\n-     code\n  more",
+			"- item\n\n      code\n-     code\n  more\n",
+		},
+		{
+			"List item with heading",
+			[]Option{},
+			"- # h\n  para",
+			"- # h\n  para\n",
+		},
+		{
+			"Wide ordered list marker continuation",
+			[]Option{},
+			"10. ten\n\n    para\n\n    ```\n    code\n    ```",
+			"10. ten\n\n    para\n\n    ```\n    code\n    ```\n",
+		},
 		// Block separators
 		{
 			"ATX heading block separator",
@@ -577,6 +934,24 @@ func TestRenderedOutput(t *testing.T) {
 			"[link](/uri \"title\")",
 			"[link](/uri \"title\")\n",
 		},
+		{
+			"Link destination with spaces",
+			[]Option{},
+			"[link](<my page.md> \"title\")",
+			"[link](<my page.md> \"title\")\n",
+		},
+		{
+			"Link destination with unbalanced parentheses",
+			[]Option{},
+			"[link](<a)b>)",
+			"[link](<a)b>)\n",
+		},
+		{
+			"Link destination with balanced parentheses",
+			[]Option{},
+			"[link](a(b)c)",
+			"[link](a(b)c)\n",
+		},
 		// Images
 		{
 			"Empty image",
@@ -596,6 +971,24 @@ func TestRenderedOutput(t *testing.T) {
 			"![image](/uri \"title\")",
 			"![image](/uri \"title\")\n",
 		},
+		{
+			"Image inside link",
+			[]Option{},
+			"[![alt](img.png \"img\")](https://target \"link\")",
+			"[![alt](img.png \"img\")](https://target \"link\")\n",
+		},
+		{
+			"Badges",
+			[]Option{},
+			"[![a](a.svg)](a) [![b](b.svg)](b)",
+			"[![a](a.svg)](a) [![b](b.svg)](b)\n",
+		},
+		{
+			"Image and text inside link",
+			[]Option{},
+			"[see ![icon](i.png) here](/uri)",
+			"[see ![icon](i.png) here](/uri)\n",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -613,3 +1006,383 @@ func TestRenderedOutput(t *testing.T) {
 		})
 	}
 }
+
+// TestRenderAll tests rendering multiple documents to a single writer
+func TestRenderAll(t *testing.T) {
+	assert := assert.New(t)
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+	sources := []string{"Foo\n===", "- bar\n- baz", "`qux`"}
+	docs := make([]Document, len(sources))
+	for i, source := range sources {
+		docs[i] = Document{
+			Source: []byte(source),
+			Node:   md.Parser().Parse(text.NewReader([]byte(source))),
+		}
+	}
+
+	buf := bytes.Buffer{}
+	err := NewRenderer().RenderAll(&buf, docs, []byte("\n---\n"))
+	assert.NoError(err)
+	assert.Equal("# Foo\n\n---\n- bar\n- baz\n\n---\n`qux`\n", buf.String())
+
+	err = NewRenderer().RenderAll(&errorWriter{err: fmt.Errorf("RenderAll")}, docs, []byte("\n"))
+	assert.Error(err)
+}
+
+// TestRenderConcurrent tests that a Renderer can render documents from multiple goroutines at once
+func TestRenderConcurrent(t *testing.T) {
+	r := NewRenderer(WithMetrics(true), WithTextTransformer(MapTransformer{"Foo": "Bar"}))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.GFM, r))
+	sources := map[string]string{
+		"# Foo\n\n- *a*\n- **b**\n":               "# Bar\n\n- *a*\n- **b**\n",
+		"| Foo | b |\n| --- | --- |\n| c | d |\n": "| Bar | b |\n| --- | --- |\n| c | d |\n",
+		"> Foo\n>\n> ```go\n> x\n> ```\n":         "> Bar\n>\n> ```go\n> x\n> ```\n",
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		for source, expected := range sources {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				buf := bytes.Buffer{}
+				assert.NoError(t, md.Convert([]byte(source), &buf))
+				assert.Equal(t, expected, buf.String())
+			}()
+		}
+	}
+	wg.Wait()
+	assert.NotZero(t, r.Metrics().BytesWritten)
+}
+
+// TestListNumberingAfterEdits tests that ordered lists are numbered consistently after their AST has
+// been modified
+func TestListNumberingAfterEdits(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("Intro\n\n3. A\n4. B\n5. C\n")
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+	doc := md.Parser().Parse(text.NewReader(source))
+	list := doc.FirstChild().NextSibling()
+	// Remove the second item, then the blank line before the list
+	list.RemoveChild(list, list.FirstChild().NextSibling())
+	list.SetBlankPreviousLines(false)
+
+	buf := bytes.Buffer{}
+	assert.NoError(md.Renderer().Render(&buf, source, doc))
+	assert.Equal("Intro\n\n3. A\n4. C\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(NewRenderer(WithListNumbering(ListNumberingFromOne)).Render(&buf, source, doc))
+	assert.Equal("Intro\n1. A\n2. C\n", buf.String())
+}
+
+// TestEmptyListItemAfterParagraph tests that a list beginning with an empty item isn't read as a
+// setext heading underline
+func TestEmptyListItemAfterParagraph(t *testing.T) {
+	source := []byte("Intro\n\n- a\n")
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+	doc := md.Parser().Parse(text.NewReader(source))
+	list := doc.FirstChild().NextSibling()
+	list.FirstChild().RemoveChildren(list.FirstChild())
+	list.SetBlankPreviousLines(false)
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Renderer().Render(&buf, source, doc))
+	assert.Equal(t, "Intro\n\n-\n", buf.String())
+}
+
+// TestBlockquoteSeparationAfterEdits tests that blockquotes stay separate from following blocks when
+// the AST no longer records the blank lines between them
+func TestBlockquoteSeparationAfterEdits(t *testing.T) {
+	source := []byte("> > a\n>\n> b\n\n> c\n")
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+	doc := md.Parser().Parse(text.NewReader(source))
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		n.SetBlankPreviousLines(false)
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			c.SetBlankPreviousLines(false)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Renderer().Render(&buf, source, doc))
+	assert.Equal(t, "> > a\n>\n> b\n\n> c\n", buf.String())
+}
+
+// TestPreservedCodeSpanAfterEdits tests that preserved code span delimiters are replaced if they
+// can no longer contain the code span
+func TestPreservedCodeSpanAfterEdits(t *testing.T) {
+	source := []byte("`foo`")
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithStyleMode(StyleModePreserve))))
+	doc := md.Parser().Parse(text.NewReader(source))
+	codeSpan := doc.FirstChild().FirstChild()
+	// Append a single backtick to the code span's content
+	codeSpan.AppendChild(codeSpan, ast.NewTextSegment(text.NewSegment(0, 1)))
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Renderer().Render(&buf, source, doc))
+	assert.Equal(t, "`` foo` ``\n", buf.String())
+}
+
+// TestTableDelimiterRow tests that each column of the delimiter row is as wide as its widest cell
+func TestTableDelimiterRow(t *testing.T) {
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	source := "| a | Center | Right | 宽字符 |\n" +
+		"|:--|:-:|--:|---|\n" +
+		"| longer cell | b | `c` | d |\n"
+	expected := "| a | Center | Right | 宽字符 |\n" +
+		"| :---------- | :----: | ----: | ------ |\n" +
+		"| longer cell | b | `c` | d |\n"
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestTableStylePretty tests that table cells are padded to align their columns, as measured after
+// translation
+func TestTableStylePretty(t *testing.T) {
+	r := NewRenderer(WithTableStyle(TableStylePretty), WithTextTransformer(MapTransformer{"b": "translated"}))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	source := "| a | Center | Right | 宽字符 |\n" +
+		"|:--|:-:|--:|---|\n" +
+		"| longer cell | b | `c` | d |\n"
+	expected := "| a           |   Center   | Right | 宽字符 |\n" +
+		"| :---------- | :--------: | ----: | ------ |\n" +
+		"| longer cell | translated |   `c` | d      |\n"
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestTableCellEscapes tests that table cell content can't end a cell or turn a row into a delimiter
+// row
+func TestTableCellEscapes(t *testing.T) {
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	source := "| a \\| b | `c \\| d` |\n" +
+		"|---|---|\n" +
+		"| -- | --- |\n" +
+		"| - | :-: |\n" +
+		"| -- | x |\n"
+	expected := "| a \\| b | `c \\| d` |\n" +
+		"| ------ | -------- |\n" +
+		"| \\-- | \\--- |\n" +
+		"| \\- | \\:-: |\n" +
+		"| -- | x |\n"
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, md.Convert([]byte(source), &buf))
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestTableMissingCells tests that rows keep a cell per column when the AST leaves cells out
+func TestTableMissingCells(t *testing.T) {
+	assert := assert.New(t)
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	source := []byte("| a | b | c |\n|---|---|---|\n| x | y | z |\n| u | v | w |\n")
+	doc := md.Parser().Parse(text.NewReader(source))
+	table := doc.FirstChild()
+	row := table.FirstChild().NextSibling()
+	row.RemoveChild(row, row.LastChild())
+	row.RemoveChild(row, row.LastChild())
+	row = row.NextSibling()
+	row.RemoveChild(row, row.FirstChild())
+	row.AppendChild(row, east.NewTableCell())
+
+	buf := bytes.Buffer{}
+	assert.NoError(md.Renderer().Render(&buf, source, doc))
+	assert.Equal("| a | b | c |\n| --- | --- | --- |\n| x |  |  |\n| v | w |  |\n", buf.String())
+
+	// The reparsed table has the same number of cells in each row
+	reparsed := md.Parser().Parse(text.NewReader(buf.Bytes()))
+	for row := reparsed.FirstChild().FirstChild(); row != nil; row = row.NextSibling() {
+		assert.Equal(3, row.ChildCount())
+	}
+}
+
+func TestHTMLBlockTypes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		blockType ast.HTMLBlockType
+		source    string
+	}{
+		{"Type 1 with blank line", ast.HTMLBlockType1, "<pre>\nx\n\n</pre>\nafter\n"},
+		{"Type 1 on one line", ast.HTMLBlockType1, "<style>p{}</style>\nafter\n"},
+		{"Type 2 with blank line", ast.HTMLBlockType2, "<!--\ncomment\n\n-->\n\nafter\n"},
+		{"Type 3 with blank line", ast.HTMLBlockType3, "<?php\necho 1;\n\n?>\nafter\n"},
+		{"Type 4", ast.HTMLBlockType4, "<!DOCTYPE html>\nafter\n"},
+		{"Type 5 with blank line", ast.HTMLBlockType5, "<![CDATA[\nx\n\n]]>\nafter\n"},
+		{"Type 6", ast.HTMLBlockType6, "<div>\n  a\n</div>\n\nafter\n"},
+		{"Type 7", ast.HTMLBlockType7, "<custom-tag>\na\n\nafter\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+			buf := bytes.Buffer{}
+			assert.NoError(md.Convert([]byte(tc.source), &buf))
+			assert.Equal(tc.source, buf.String())
+
+			doc := md.Parser().Parse(text.NewReader(buf.Bytes()))
+			html, ok := doc.FirstChild().(*ast.HTMLBlock)
+			if assert.True(ok) {
+				assert.Equal(tc.blockType, html.HTMLBlockType)
+			}
+			assert.Equal(2, doc.ChildCount())
+		})
+	}
+}
+
+func TestHTMLBlockSeparationAfterEdits(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+	}{
+		{"Paragraph after type 6 block", "<div>\n\na\n"},
+		{"Type 7 block after paragraph", "a\n\n<custom-tag>\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+			source := []byte(tc.source)
+			doc := md.Parser().Parse(text.NewReader(source))
+			// Blocks inserted by an AST edit have no blank lines recorded before them
+			doc.LastChild().SetBlankPreviousLines(false)
+
+			buf := bytes.Buffer{}
+			assert.NoError(md.Renderer().Render(&buf, source, doc))
+			assert.Equal(tc.source, buf.String())
+		})
+	}
+}
+
+// TestTableSeparation tests that tables are separated from the blocks around them, so they aren't
+// merged with paragraphs, HTML blocks or each other
+func TestTableSeparation(t *testing.T) {
+	assert := assert.New(t)
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	source := []byte("# Heading\n\n| a |\n|---|\n\n| b |\n|---|\n\n<div>\n</div>\n\n| c |\n|---|\n\ntext\n")
+	doc := md.Parser().Parse(text.NewReader(source))
+	buf := bytes.Buffer{}
+	assert.NoError(md.Renderer().Render(&buf, source, doc))
+	assert.Equal("# Heading\n\n| a |\n| --- |\n\n| b |\n| --- |\n\n<div>\n</div>\n\n| c |\n| --- |\n\ntext\n",
+		buf.String())
+
+	// Blocks moved next to a table without blank lines are separated too
+	paragraph := doc.LastChild()
+	doc.RemoveChild(doc, paragraph)
+	paragraph.SetBlankPreviousLines(false)
+	doc.InsertBefore(doc, doc.FirstChild().NextSibling(), paragraph)
+	buf.Reset()
+	assert.NoError(md.Renderer().Render(&buf, source, doc))
+	assert.Equal("# Heading\ntext\n\n| a |\n| --- |\n\n| b |\n| --- |\n\n<div>\n</div>\n\n| c |\n| --- |\n",
+		buf.String())
+}
+
+// TestStrikethrough tests that the nodes of goldmark's Strikethrough extension are written as
+// markdown rather than by the extension's HTML renderer
+func TestStrikethrough(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"Inline", "a ~~b~~ c ~~*d*~~ *~~e~~* x~~y~~z\n", "a ~~b~~ c ~~*d*~~ *~~e~~* x~~y~~z\n"},
+		{"Single tilde", "a ~b~ c\n", "a ~~b~~ c\n"},
+		{"Across lines", "~~a\nb~~\n", "~~a\nb~~\n"},
+		{"In table", "| ~~a~~ |\n|---|\n| ~~b~~ |\n", "| ~~a~~ |\n| ----- |\n| ~~b~~ |\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer()
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.GFM, r))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+// TestTableInContainers tests that every row of a table nested in blockquotes, list items, footnotes
+// and admonitions has the prefixes of its containers, so it's read back as a table in the same place
+func TestTableInContainers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Blockquote",
+			"> | a | b |\n> |---|--:|\n> | 1 | 2 |\n",
+			"> | a | b |\n> | --- | --: |\n> | 1 | 2 |\n",
+		},
+		{
+			"Nested blockquote",
+			"> > | a |\n> > |---|\n> > | x \\| y |\n",
+			"> > | a |\n> > | ------ |\n> > | x \\| y |\n",
+		},
+		{
+			"Blockquote after paragraph",
+			"> text\n> | a |\n> |---|\n> | 1 |\n",
+			"> text\n>\n> | a |\n> | --- |\n> | 1 |\n",
+		},
+		{
+			"List item",
+			"- item\n\n  | a |\n  |---|\n  | 1 |\n\n  after\n- next\n",
+			"- item\n\n  | a |\n  | --- |\n  | 1 |\n\n  after\n- next\n",
+		},
+		{
+			"First block of ordered list item",
+			"10. | a |\n    |---|\n    | 1 |\n",
+			"10. | a |\n    | --- |\n    | 1 |\n",
+		},
+		{
+			"Nested list item",
+			"- a\n  - | a |\n    |---|\n    | 1 |\n",
+			"- a\n  - | a |\n    | --- |\n    | 1 |\n",
+		},
+		{
+			"List item in blockquote",
+			"> 1. | a |\n>    |---|\n>    | 1 |\n>\n> 2. b\n",
+			"> 1. | a |\n>    | --- |\n>    | 1 |\n>\n> 2. b\n",
+		},
+		{
+			"Blockquote in list item",
+			"1. item\n\n   > | a |\n   > |---|\n   > | 1 |\n",
+			"1. item\n\n   > | a |\n   > | --- |\n   > | 1 |\n",
+		},
+		{
+			"Footnote",
+			"Text[^1].\n\n[^1]: Note\n\n    | a |\n    |---|\n    | 1 |\n",
+			"Text[^1].\n\n[^1]: Note\n\n    | a |\n    | --- |\n    | 1 |\n",
+		},
+		{
+			"Admonition",
+			"!!! note\n    | a |\n    |---|\n    | 1 |\n",
+			"!!! note\n    | a |\n    | --- |\n    | 1 |\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewRenderer(WithDialect(DialectMkDocs))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			assert.NoError(md.Convert([]byte(tc.source), &buf))
+			assert.Equal(tc.expected, buf.String())
+
+			// The output is stable
+			source := buf.String()
+			buf.Reset()
+			assert.NoError(md.Convert([]byte(source), &buf))
+			assert.Equal(tc.expected, buf.String())
+			doc := md.Parser().Parse(text.NewReader(buf.Bytes()))
+			assert.Len(FindAll(doc, func(n ast.Node) bool { return n.Kind() == east.KindTable }), 1)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// RenderChange is a construct that was written differently from the source by the renderer.
+type RenderChange struct {
+	// Category is the kind of change, such as "escape" or "fence"
+	Category string
+	// Message describes the change
+	Message string
+	// Position is where the construct is in the source
+	Position SourcePosition
+}
+
+func (c RenderChange) String() string {
+	return fmt.Sprintf("%s: %s (%s)", c.Position, c.Message, c.Category)
+}
+
+// RenderWithReport parses and renders source with md, and returns the output along with the
+// constructs that were normalized or changed, in document order. The output is parsed again to find
+// the changes, so changes to the structure of the document are reported too, with category
+// "structure".
+func RenderWithReport(md goldmark.Markdown, source []byte) ([]byte, []RenderChange, error) {
+	doc := md.Parser().Parse(text.NewReader(source))
+	buf := bytes.Buffer{}
+	if err := md.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, nil, err
+	}
+	output := buf.Bytes()
+	rendered := md.Parser().Parse(text.NewReader(output))
+
+	var changes []RenderChange
+	if differences := DiffAST(source, doc, output, rendered); len(differences) > 0 {
+		// The constructs can't be matched up once the structure differs
+		for _, d := range differences {
+			changes = append(changes, RenderChange{
+				Category: "structure",
+				Message:  fmt.Sprintf("%s: %s", d.Path, d.Description),
+				Position: d.A,
+			})
+		}
+		return output, changes, nil
+	}
+	r := changeReporter{source: source, output: output}
+	r.compare(diffNode{node: doc}, diffNode{node: rendered})
+	return output, r.changes, nil
+}
+
+// changeReporter holds the state of RenderWithReport.
+type changeReporter struct {
+	source, output []byte
+	changes        []RenderChange
+}
+
+// report records a change to the construct of a.
+func (r *changeReporter) report(category, message string, a ast.Node) {
+	r.reportAt(category, message, sourcePosition(r.source, a))
+}
+
+// reportAt records a change to the construct at position.
+func (r *changeReporter) reportAt(category, message string, position SourcePosition) {
+	r.changes = append(r.changes, RenderChange{
+		Category: category,
+		Message:  message,
+		Position: position,
+	})
+}
+
+// markupPosition returns the position of markup, which is a slice of the source, falling back to the
+// position of n.
+func (r *changeReporter) markupPosition(markup []byte, n ast.Node) SourcePosition {
+	if start, ok := sourceOffset(r.source, markup); ok {
+		return offsetPosition(r.source, start)
+	}
+	return sourcePosition(r.source, n)
+}
+
+// compare reports the differences between the source and output of a and b, which are equivalent
+// nodes, along with their children.
+func (r *changeReporter) compare(a, b diffNode) {
+	if a.text != nil {
+		r.compareText(a.node, b.node)
+		return
+	}
+	switch a := a.node.(type) {
+	case *ast.Heading:
+		before, okBefore := sourceHeadingStyle(r.source, a)
+		after, okAfter := sourceHeadingStyle(r.output, b.node.(*ast.Heading))
+		if okBefore && okAfter && before != after {
+			r.report("heading", fmt.Sprintf("%s heading written as %s heading", before, after), a)
+		}
+	case *ast.List:
+		if after := b.node.(*ast.List).Marker; a.Marker != after {
+			r.report("list-marker", fmt.Sprintf("list marker %q written as %q", a.Marker, after), a)
+		}
+	case *ast.ThematicBreak:
+		before, okBefore := sourceThematicBreak(a, r.source)
+		after, okAfter := sourceThematicBreak(b.node, r.output)
+		if okBefore && okAfter && !bytes.Equal(before, after) {
+			r.reportAt("thematic-break", fmt.Sprintf("thematic break %q written as %q", before, after),
+				r.markupPosition(before, a))
+		}
+	case *ast.FencedCodeBlock:
+		before, _, _, okBefore := sourceCodeFences(a, r.source)
+		after, _, _, okAfter := sourceCodeFences(b.node.(*ast.FencedCodeBlock), r.output)
+		before, after = bytes.TrimRight(before, " \t"), bytes.TrimRight(after, " \t")
+		if okBefore && okAfter && !bytes.Equal(before, after) {
+			r.reportAt("fence", fmt.Sprintf("code fence %q written as %q", before, after), r.markupPosition(before, a))
+		}
+	case *ast.CodeSpan:
+		return
+	}
+
+	aChildren, bChildren := diffChildren(r.source, a.node), diffChildren(r.output, b.node)
+	for i := range aChildren {
+		r.compare(aChildren[i], bChildren[i])
+	}
+}
+
+// compareText reports differences in how the run of text starting at a is written, which has the
+// same value as the run starting at b.
+func (r *changeReporter) compareText(a, b ast.Node) {
+	before, after := rawText(r.source, a), rawText(r.output, b)
+	if before == after {
+		return
+	}
+	switch {
+	case strings.ReplaceAll(after, "\\\n", "  \n") == before:
+		r.report("line-break", fmt.Sprintf("hard line breaks in %q written with backslashes", before), a)
+	case strings.Count(after, `\`) > strings.Count(before, `\`):
+		r.report("escape", fmt.Sprintf("text %q written with escapes as %q", before, after), a)
+	case strings.Count(after, `\`) < strings.Count(before, `\`):
+		r.report("escape", fmt.Sprintf("text %q written without escapes as %q", before, after), a)
+	case strings.Count(after, "&") < strings.Count(before, "&"):
+		r.report("entity", fmt.Sprintf("text %q written with references decoded as %q", before, after), a)
+	default:
+		r.report("text", fmt.Sprintf("text %q written as %q", before, after), a)
+	}
+}
+
+// rawText returns the run of text starting at n as written in source, with hard line breaks in the
+// form they're written.
+func rawText(source []byte, n ast.Node) string {
+	raw := strings.Builder{}
+	for ; n != nil && isTextNode(n); n = n.NextSibling() {
+		t, ok := n.(*ast.Text)
+		if !ok {
+			raw.WriteString(unescapedText(source, n))
+			continue
+		}
+		raw.Write(t.Segment.Value(source))
+		switch {
+		case t.HardLineBreak() && t.Segment.Stop < len(source) && source[t.Segment.Stop] == '\\':
+			raw.WriteString("\\\n")
+		case t.HardLineBreak():
+			raw.WriteString("  \n")
+		case t.SoftLineBreak():
+			raw.WriteString("\n")
+		}
+	}
+	return raw.String()
+}
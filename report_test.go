@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+func TestRenderWithReport(t *testing.T) {
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer()))
+	source := []byte("Title\n===\n\n* a *b* 1\\. c\n* d  \n  e\n\n___\n\n~~~\ncode\n~~~")
+	output, changes, err := RenderWithReport(md, source)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Title\n\n* a *b* 1\\. c\n* d\\\n  e\n\n---\n\n```\ncode\n```\n", string(output))
+
+	var actual []string
+	for _, c := range changes {
+		actual = append(actual, c.String())
+	}
+	assert.Equal(t, []string{
+		"1:1: setext heading written as ATX heading (heading)",
+		`5:3: hard line breaks in "d  \ne" written with backslashes (line-break)`,
+		`8:1: thematic break "___" written as "---" (thematic-break)`,
+		"10:1: code fence \"~~~\" written as \"```\" (fence)",
+	}, actual)
+}
+
+func TestRenderWithReportStructure(t *testing.T) {
+	md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithTextTransformer(MapTransformer{"a": "# a"}))))
+	_, changes, err := RenderWithReport(md, []byte("a\n"))
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "structure", changes[0].Category)
+	}
+}
@@ -0,0 +1,52 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// reviewedKinds are the kinds of blocks followed by a review comment with WithReviewComments. Tables
+// are reviewed as a whole, since comments can't be written between their rows.
+var reviewedKinds = []ast.NodeKind{ast.KindParagraph, ast.KindTextBlock, ast.KindHeading, east.KindTable}
+
+// renderReviewComment writes an HTML comment with the source of node after it if the TextTransformer
+// changed its text. A comment of a single line is written on that line:
+//
+//	Hallo Welt.
+//	<!-- original: Hello world. -->
+func (r *Renderer) renderReviewComment(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.rc.textChanged = false
+		return ast.WalkContinue
+	}
+	if !r.rc.textChanged {
+		return ast.WalkContinue
+	}
+	r.rc.textChanged = false
+	var lines [][]byte
+	for _, line := range rawBlockLines(node, r.rc.source) {
+		// The comment would end at the first "-->"
+		line = bytes.ReplaceAll(bytes.TrimRight(line, "\r\n"), []byte("-->"), []byte("--&gt;"))
+		lines = append(lines, line)
+	}
+	r.rc.writer.SetVerbatim(true)
+	r.rc.writer.FlushLine()
+	if len(lines) == 1 {
+		r.rc.writer.WriteBytes([]byte("<!-- original: "))
+		r.rc.writer.WriteBytes(lines[0])
+		r.rc.writer.WriteBytes([]byte(" -->"))
+	} else {
+		r.rc.writer.WriteBytes([]byte("<!-- original:"))
+		r.rc.writer.EndLine()
+		for _, line := range lines {
+			r.rc.writer.WriteBytes(line)
+			r.rc.writer.EndLine()
+		}
+		r.rc.writer.WriteBytes([]byte("-->"))
+	}
+	r.rc.writer.FlushLine()
+	r.rc.writer.SetVerbatim(false)
+	return ast.WalkContinue
+}
@@ -0,0 +1,67 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestReviewComments(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Heading",
+			"# Title\n\nUnchanged.\n",
+			"# Titel\n<!-- original: # Title -->\n\nUnchanged.\n",
+		},
+		{
+			"Multiple lines",
+			"Hello world.\nSecond --> line.\n",
+			"Hallo Welt.\nZweite --> Zeile.\n<!-- original:\nHello world.\nSecond --&gt; line.\n-->\n",
+		},
+		{
+			"Setext heading",
+			"Title\n=====\n",
+			"# Titel\n<!-- original:\nTitle\n=====\n-->\n",
+		},
+		{
+			"Containers",
+			"> Quoted\n\n- Item\n- Unchanged.\n",
+			"> Zitiert\n> <!-- original: Quoted -->\n\n- Eintrag\n  <!-- original: Item -->\n- Unchanged.\n",
+		},
+		{
+			"Table",
+			"| Item |\n|---|\n| x |\n",
+			"| Eintrag |\n| ------- |\n| x |\n<!-- original:\n| Item |\n|---|\n| x |\n-->\n",
+		},
+	}
+	translations := MapTransformer{
+		"Title":                          "Titel",
+		"Hello world.\nSecond --> line.": "Hallo Welt.\nZweite --> Zeile.",
+		"Quoted":                         "Zitiert",
+		"Item":                           "Eintrag",
+		"Unchanged.":                     "Unchanged.",
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithTextTransformer(translations), WithReviewComments(true))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+
+			// The comments are kept when the output is formatted again
+			r = NewRenderer()
+			md = goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			formatted := bytes.Buffer{}
+			require.NoError(t, md.Convert(buf.Bytes(), &formatted))
+			assert.Equal(t, tc.expected, formatted.String())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package roundtrip
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/yuin/goldmark"
+)
+
+// Conformance baselines are the number of examples known to round trip. Raise them as fidelity
+// improves; the tests fail if fewer examples pass than recorded here.
+const (
+	specBaseline     = 556
+	gfmTableBaseline = 13
+)
+
+// example is a markdown example from a conformance suite.
+type example struct {
+	Markdown string `json:"markdown"`
+	Example  int    `json:"example"`
+	Section  string `json:"section"`
+}
+
+// TestCommonMarkConformance round trips the CommonMark spec examples.
+func TestCommonMarkConformance(t *testing.T) {
+	data, err := os.ReadFile("testdata/spec.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var examples []example
+	if err := json.Unmarshal(data, &examples); err != nil {
+		t.Fatal(err)
+	}
+	checkConformance(t, New(), examples, specBaseline)
+}
+
+// TestGFMTableConformance round trips the GitHub Flavored Markdown table examples.
+func TestGFMTableConformance(t *testing.T) {
+	examples, err := readExamples("testdata/gfm_table.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := markdown.NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	checkConformance(t, md, examples, gfmTableBaseline)
+}
+
+// checkConformance round trips each example, logging the conformance percentage and failing if
+// fewer than baseline examples pass.
+func checkConformance(t *testing.T, md goldmark.Markdown, examples []example, baseline int) {
+	passed := 0
+	for _, e := range examples {
+		if err := checkExample(md, e); err != nil {
+			if testing.Verbose() {
+				t.Logf("example %d (%s): %v", e.Example, e.Section, err)
+			}
+			continue
+		}
+		passed++
+	}
+	t.Logf("conformance: %d/%d examples (%.1f%%)", passed, len(examples),
+		100*float64(passed)/float64(len(examples)))
+	if passed < baseline {
+		t.Errorf("conformance regressed: %d examples passed, baseline is %d", passed, baseline)
+	}
+}
+
+// checkExample round trips a single example, reporting a panic as an error.
+func checkExample(md goldmark.Markdown, e example) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	_, err = Check(md, []byte(e.Markdown))
+	return err
+}
+
+// readExamples reads examples from a goldmark test case file, where each case is a number line,
+// then the markdown and expected HTML each preceded by a "//- - -" line, and cases are separated by
+// "//= = =" lines.
+func readExamples(filename string) ([]example, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var examples []example
+	var current *example
+	part := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "//= = ="):
+			current, part = nil, 0
+		case strings.HasPrefix(line, "//- - -"):
+			part++
+		case current == nil && strings.TrimSpace(line) != "":
+			number, section, _ := strings.Cut(line, ":")
+			examples = append(examples, example{Section: strings.TrimSpace(section)})
+			current = &examples[len(examples)-1]
+			_, _ = fmt.Sscan(number, &current.Example)
+		case current != nil && part == 1:
+			current.Markdown += line + "\n"
+		}
+	}
+	return examples, scanner.Err()
+}
@@ -0,0 +1,184 @@
+// Package roundtrip provides helpers for verifying that markdown documents survive a
+// parse→render→parse cycle through the markdown renderer without changing their AST.
+package roundtrip
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// New returns a goldmark.Markdown that renders markdown with the given options.
+func New(options ...markdown.Option) goldmark.Markdown {
+	return goldmark.New(goldmark.WithRenderer(markdown.NewRenderer(options...)))
+}
+
+// Check parses source with md, renders it with md's renderer, then parses the rendered output and
+// compares both ASTs. It returns the rendered output along with an error describing the first
+// structural difference, if any.
+func Check(md goldmark.Markdown, source []byte) ([]byte, error) {
+	doc := md.Parser().Parse(text.NewReader(source))
+	before := Dump(source, doc)
+
+	buf := bytes.Buffer{}
+	if err := md.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+	output := buf.Bytes()
+
+	after := Dump(output, md.Parser().Parse(text.NewReader(output)))
+	if err := compare(before, after); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// AssertRoundTrip fails the test if source does not survive a round trip through md.
+func AssertRoundTrip(t testing.TB, md goldmark.Markdown, source []byte) {
+	t.Helper()
+	output, err := Check(md, source)
+	if err != nil {
+		t.Errorf("round trip of %q rendered %q: %v", source, output, err)
+	}
+}
+
+// compare returns an error describing the first line that differs between two AST dumps.
+func compare(before, after string) error {
+	beforeLines := strings.Split(before, "\n")
+	afterLines := strings.Split(after, "\n")
+	for i := 0; i < max(len(beforeLines), len(afterLines)); i++ {
+		var b, a string
+		if i < len(beforeLines) {
+			b = beforeLines[i]
+		}
+		if i < len(afterLines) {
+			a = afterLines[i]
+		}
+		if a != b {
+			return fmt.Errorf("AST differs at node %d: expected %q, got %q", i, b, a)
+		}
+	}
+	return nil
+}
+
+// Dump returns a canonical description of the AST rooted at n, one node per line. Only properties
+// that affect the meaning of the document are included, and adjacent text nodes are merged, so two
+// documents with equal dumps are equivalent even if their source formatting differs.
+func Dump(source []byte, n ast.Node) string {
+	buf := strings.Builder{}
+	dumpNode(&buf, source, n, 0)
+	return buf.String()
+}
+
+func dumpNode(buf *strings.Builder, source []byte, n ast.Node, depth int) {
+	buf.WriteString(strings.Repeat("  ", depth))
+	buf.WriteString(n.Kind().String())
+	buf.WriteString(describe(source, n))
+	buf.WriteByte('\n')
+	if isLeafBlock(n) {
+		return
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() == ast.KindText || c.Kind() == ast.KindString {
+			// Merge runs of text into a single line
+			run := strings.Builder{}
+			for ; c != nil && (c.Kind() == ast.KindText || c.Kind() == ast.KindString); c = c.NextSibling() {
+				run.WriteString(textValue(source, c))
+			}
+			fmt.Fprintf(buf, "%sText %q\n", strings.Repeat("  ", depth+1), run.String())
+			if c == nil {
+				break
+			}
+		}
+		dumpNode(buf, source, c, depth+1)
+	}
+}
+
+// isLeafBlock returns true for nodes whose children are fully described by describe.
+func isLeafBlock(n ast.Node) bool {
+	return n.Kind() == ast.KindCodeSpan
+}
+
+// describe returns the meaningful properties of a node.
+func describe(source []byte, n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Heading:
+		return fmt.Sprintf(" level=%d", n.Level)
+	case *ast.List:
+		if n.IsOrdered() {
+			return fmt.Sprintf(" ordered start=%d tight=%t", n.Start, n.IsTight)
+		}
+		return fmt.Sprintf(" tight=%t", n.IsTight)
+	case *ast.Emphasis:
+		return fmt.Sprintf(" level=%d", n.Level)
+	case *ast.Link:
+		// Titles keep their escapes, which depend on how the title is quoted
+		return fmt.Sprintf(" destination=%q title=%q", n.Destination, util.UnescapePunctuations(n.Title))
+	case *ast.Image:
+		return fmt.Sprintf(" destination=%q title=%q", n.Destination, util.UnescapePunctuations(n.Title))
+	case *ast.AutoLink:
+		return fmt.Sprintf(" type=%d url=%q", n.AutoLinkType, n.URL(source))
+	case *ast.CodeSpan:
+		content := strings.Builder{}
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if t, ok := c.(*ast.Text); ok {
+				content.Write(t.Segment.Value(source))
+			}
+		}
+		return fmt.Sprintf(" %q", content.String())
+	case *ast.FencedCodeBlock:
+		var info []byte
+		if n.Info != nil {
+			info = n.Info.Value(source)
+		}
+		return fmt.Sprintf(" info=%q %q", info, n.Lines().Value(source))
+	case *ast.CodeBlock:
+		return fmt.Sprintf(" %q", n.Lines().Value(source))
+	case *ast.HTMLBlock:
+		content := n.Lines().Value(source)
+		if n.HasClosure() {
+			content = append(content, n.ClosureLine.Value(source)...)
+		}
+		return fmt.Sprintf(" type=%d %q", n.HTMLBlockType, content)
+	case *ast.RawHTML:
+		return fmt.Sprintf(" %q", n.Segments.Value(source))
+	case *east.Table:
+		return fmt.Sprintf(" alignments=%v", n.Alignments)
+	case *east.TableCell:
+		return fmt.Sprintf(" alignment=%v", n.Alignment)
+	case *east.TaskCheckBox:
+		return fmt.Sprintf(" checked=%t", n.IsChecked)
+	}
+	return ""
+}
+
+// textValue returns the unescaped value of a Text or String node, followed by a marker for any
+// line break.
+func textValue(source []byte, n ast.Node) string {
+	var value []byte
+	switch n := n.(type) {
+	case *ast.Text:
+		value = n.Value(source)
+		if !n.IsRaw() {
+			value = util.UnescapePunctuations(value)
+			value = util.ResolveNumericReferences(value)
+			value = util.ResolveEntityNames(value)
+		}
+		if n.HardLineBreak() {
+			value = append(value, "<br>\n"...)
+		} else if n.SoftLineBreak() {
+			value = append(value, '\n')
+		}
+	case *ast.String:
+		value = n.Value
+	}
+	return string(value)
+}
@@ -0,0 +1,90 @@
+package roundtrip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+var seeds = []string{
+	"",
+	"# Heading\n\nParagraph with *emphasis*, **strong** and `code`.",
+	"Setext\n===\n\n- a\n- b\n  - c\n\n1. one\n2. two",
+	"> quote\n> > nested\n\n---\n\n    indented code",
+	"```go\nfunc main() {}\n```",
+	"[link](/uri \"title\") ![image](/img.png) <https://example.com>",
+	"<div>\nhtml\n</div>\n\ninline <b>html</b>",
+	"\\*not emphasis\\*",
+	"> one\n>\n> two\n>\n> - a\n>\n>   b",
+	"- a\n-\n- b\n\n1. x\n2.",
+	">> deep\n>\n> shallow\n\n> a\n>\n>> - x\n>>\n>>       code\n>\n> c",
+	"*_a_* **_a_** ***a*** *a*_b_*c* **a**__b__ *_a_ b* ____a____",
+	"```\na  \n\tb\t\n  \n\n```\n\n    x  \n    \ty\n\n> ```\n> c \n>\n> ```\n\n- a\n\n  \t\tb \n",
+}
+
+// TestCheck tests that the seed documents round trip without AST changes
+func TestCheck(t *testing.T) {
+	md := New()
+	for _, seed := range seeds {
+		AssertRoundTrip(t, md, []byte(seed))
+	}
+}
+
+// TestCheckTables tests that tables round trip without AST changes
+func TestCheckTables(t *testing.T) {
+	r := markdown.NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	for _, source := range []string{
+		"| a | b |\n|:--|--:|\n| c | d |",
+		"| a \\| b | `c \\| d` |\n|---|---|\n| -- | --- |\n| - | :-: |\n| [x](/a\\|b) | e |",
+	} {
+		AssertRoundTrip(t, md, []byte(source))
+	}
+}
+
+// TestCheckWrapping tests that wrapped paragraphs round trip without starting new blocks. Wrapping
+// turns spaces into line breaks, so they're compared as spaces.
+func TestCheckWrapping(t *testing.T) {
+	md := New(markdown.WithLineWidth(5))
+	for _, source := range []string{
+		"aaaa ==== bbb",
+		"aaaa --- bbb",
+		"aaaa ``` bbb",
+		"aaaa ~~~ bbb",
+		"aaaa *** bbb",
+		"aaaa ====",
+	} {
+		output, _ := Check(md, []byte(source))
+		before := Dump([]byte(source), md.Parser().Parse(textReader(source)))
+		after := Dump(output, md.Parser().Parse(text.NewReader(output)))
+		assert.Equal(t, before, strings.ReplaceAll(after, `\n`, " "), "%q rendered %q", source, output)
+	}
+}
+
+// TestCheckDetectsDifferences tests that Check reports documents whose AST changed
+func TestCheckDetectsDifferences(t *testing.T) {
+	assert := assert.New(t)
+	before := Dump([]byte("*a*"), New().Parser().Parse(textReader("*a*")))
+	after := Dump([]byte("**a**"), New().Parser().Parse(textReader("**a**")))
+	assert.Error(compare(before, after))
+	assert.NoError(compare(before, before))
+}
+
+// FuzzRoundTrip verifies that arbitrary documents round trip without AST changes.
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	md := New()
+	f.Fuzz(func(t *testing.T, source string) {
+		AssertRoundTrip(t, md, []byte(source))
+	})
+}
+
+func textReader(source string) text.Reader {
+	return text.NewReader([]byte(source))
+}
@@ -0,0 +1,240 @@
+// Package server formats markdown for long-running clients, such as editors and language servers,
+// over a line-delimited JSON protocol on standard input and output or over HTTP, so they don't pay
+// the startup cost of a formatter process per buffer.
+//
+// Each request is a JSON object holding the source to format and the options to format it with:
+//
+//	{"id": 1, "source": "Title\n===\n", "options": {"heading": "atx"}}
+//
+// and each response is a JSON object holding the formatted source, or the error that prevented
+// formatting it, along with the id of the request, if any:
+//
+//	{"id": 1, "formatted": "# Title\n"}
+//
+// A request can also limit formatting to the blocks intersecting a range of lines, with a "lines"
+// object holding the "start" and "end" lines, as markdown.FormatRange does.
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	markdown "github.com/teekennedy/goldmark-markdown"
+)
+
+var (
+	// HeadingStyles are the heading styles by name.
+	HeadingStyles = markdown.HeadingStyleNames
+	// BulletMarkers are the bullet markers by name.
+	BulletMarkers = markdown.BulletMarkerNames
+	// ThematicBreakStyles are the thematic break styles by name.
+	ThematicBreakStyles = markdown.ThematicBreakStyleNames
+	// IndentStyles are the indent styles by name.
+	IndentStyles = markdown.IndentStyleNames
+	// ListNumberings are the list numberings by name.
+	ListNumberings = markdown.ListNumberingNames
+)
+
+// Options are the formatting options of a request, by the names of their values in HeadingStyles,
+// BulletMarkers, ThematicBreakStyles, IndentStyles and ListNumberings. Empty options are left to the
+// defaults of the Server.
+type Options struct {
+	Heading   string `json:"heading,omitempty"`
+	Bullet    string `json:"bullet,omitempty"`
+	Break     string `json:"break,omitempty"`
+	Indent    string `json:"indent,omitempty"`
+	Numbering string `json:"numbering,omitempty"`
+	// Width is the column that paragraphs are wrapped at, if set
+	Width int `json:"width,omitempty"`
+	// Preserve reuses the syntax of the source where it's valid, if set
+	Preserve *bool `json:"preserve,omitempty"`
+}
+
+// merge returns o with its empty options set to those of defaults.
+func (o Options) merge(defaults Options) Options {
+	for _, option := range []struct{ value, fallback *string }{
+		{&o.Heading, &defaults.Heading},
+		{&o.Bullet, &defaults.Bullet},
+		{&o.Break, &defaults.Break},
+		{&o.Indent, &defaults.Indent},
+		{&o.Numbering, &defaults.Numbering},
+	} {
+		if *option.value == "" {
+			*option.value = *option.fallback
+		}
+	}
+	if o.Width == 0 {
+		o.Width = defaults.Width
+	}
+	if o.Preserve == nil {
+		o.Preserve = defaults.Preserve
+	}
+	return o
+}
+
+// MarkdownOptions returns the renderer options named by o, or an error for the first unknown name.
+func (o Options) MarkdownOptions() ([]markdown.Option, error) {
+	values := map[string]string{}
+	for name, value := range map[string]string{
+		"heading":   o.Heading,
+		"bullet":    o.Bullet,
+		"break":     o.Break,
+		"indent":    o.Indent,
+		"numbering": o.Numbering,
+	} {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	if o.Width != 0 {
+		values["width"] = strconv.Itoa(o.Width)
+	}
+	if o.Preserve != nil && *o.Preserve {
+		values["preserve"] = "true"
+	}
+	return markdown.NamedOptions(values)
+}
+
+// Choices returns the names of values as a sorted, comma separated list.
+func Choices[T any](values map[string]T) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Request is a request to format markdown source.
+type Request struct {
+	// ID is returned in the response unchanged, to match responses to requests
+	ID json.RawMessage `json:"id,omitempty"`
+	// Source is the markdown to format
+	Source string `json:"source"`
+	// Options override the defaults of the Server for this request
+	Options Options `json:"options,omitempty"`
+	// Lines limits formatting to the blocks intersecting a range of lines, if set
+	Lines *LineRange `json:"lines,omitempty"`
+}
+
+// LineRange is a range of lines, numbered from 1 and inclusive, such as the selection of an editor.
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Response is the result of a Request.
+type Response struct {
+	// ID is the ID of the request
+	ID json.RawMessage `json:"id,omitempty"`
+	// Formatted is the formatted source, if there's no error
+	Formatted string `json:"formatted"`
+	// Error describes why the source couldn't be formatted
+	Error string `json:"error,omitempty"`
+}
+
+// DefaultMaxRequestBytes is the size limit of the body of HTTP requests of a Server that leaves
+// MaxRequestBytes zero.
+const DefaultMaxRequestBytes = 16 << 20
+
+// Server formats the markdown of requests. It implements http.Handler, accepting a Request as the
+// JSON body of a POST request.
+type Server struct {
+	// Defaults are the options of requests that leave them empty
+	Defaults Options
+	// MaxRequestBytes limits the size of the body of HTTP requests, DefaultMaxRequestBytes if zero
+	MaxRequestBytes int64
+}
+
+var _ http.Handler = &Server{}
+
+// Format formats the source of req.
+func (s *Server) Format(req Request) Response {
+	resp := Response{ID: req.ID}
+	options, err := req.Options.merge(s.Defaults).MarkdownOptions()
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	var formatted []byte
+	if req.Lines != nil {
+		formatted, err = markdown.FormatRange([]byte(req.Source), req.Lines.Start, req.Lines.End, options...)
+	} else {
+		formatted, err = markdown.Format([]byte(req.Source), options...)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	resp.Formatted = string(formatted)
+	return resp
+}
+
+// ServeStdio reads requests from r, one JSON object per line, and writes a response for each to w,
+// one JSON object per line, until r is exhausted. Malformed requests get a response with an error.
+func (s *Server) ServeStdio(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	// Requests hold whole documents
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+		req := Request{}
+		var resp Response
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp = Response{Error: "invalid request: " + err.Error()}
+		} else {
+			resp = s.Format(req)
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ServeHTTP implements http.Handler.ServeHTTP, formatting the Request in the body of a POST request.
+// Requests that can't be decoded or formatted get a 400 status along with the response, and those
+// with a body larger than MaxRequestBytes a 413 status.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := s.MaxRequestBytes
+	if limit == 0 {
+		limit = DefaultMaxRequestBytes
+	}
+	body := http.MaxBytesReader(w, r.Body, limit)
+	req := Request{}
+	var resp Response
+	status := http.StatusBadRequest
+	var tooLarge *http.MaxBytesError
+	if err := json.NewDecoder(body).Decode(&req); errors.As(err, &tooLarge) {
+		resp = Response{Error: fmt.Sprintf("invalid request: body larger than %d bytes", limit)}
+		status = http.StatusRequestEntityTooLarge
+	} else if err != nil {
+		resp = Response{Error: "invalid request: " + err.Error()}
+	} else {
+		resp = s.Format(req)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Error != "" {
+		w.WriteHeader(status)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(resp)
+}
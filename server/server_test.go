@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	preserve, normalize := true, false
+	srv := &Server{Defaults: Options{Heading: "setext", Preserve: &preserve}}
+	testCases := []struct {
+		name     string
+		request  Request
+		expected Response
+	}{
+		{
+			"Defaults",
+			Request{Source: "# Title\n\n***\n"},
+			Response{Formatted: "Title\n===\n\n***\n"},
+		},
+		{
+			"Overridden options",
+			Request{ID: []byte(`"a"`), Source: "# Title\n\n* a\n\n***\n", Options: Options{Heading: "atx", Bullet: "-", Break: "_", Preserve: &normalize}},
+			Response{ID: []byte(`"a"`), Formatted: "# Title\n\n- a\n\n___\n"},
+		},
+		{
+			"Front matter",
+			Request{Source: "---\ntitle: x\n---\n# Title\n"},
+			Response{Formatted: "---\ntitle: x\n---\nTitle\n===\n"},
+		},
+		{
+			"Line range",
+			Request{Source: "# One\n\n# Two\n", Lines: &LineRange{Start: 3, End: 3}},
+			Response{Formatted: "# One\n\nTwo\n===\n"},
+		},
+		{
+			"Invalid line range",
+			Request{Source: "# One\n", Lines: &LineRange{Start: 0, End: 1}},
+			Response{Error: "invalid line range 0-1"},
+		},
+		{
+			"Invalid option",
+			Request{ID: []byte("2"), Source: "# Title\n", Options: Options{Indent: "tab"}},
+			Response{ID: []byte("2"), Error: `invalid indent "tab": must be one of spaces, tabs`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, srv.Format(tc.request))
+		})
+	}
+}
+
+func TestServeStdio(t *testing.T) {
+	input := strings.Join([]string{
+		`{"id": 1, "source": "Title\n===\n"}`,
+		``,
+		`{"id": "two", "source": "* <a>\n", "options": {"bullet": "+"}}`,
+		`not json`,
+		`{"source": "text", "options": {"numbering": "zero"}}`,
+	}, "\n")
+	output := bytes.Buffer{}
+	require.NoError(t, (&Server{}).ServeStdio(strings.NewReader(input), &output))
+	assert.Equal(t, strings.Join([]string{
+		`{"id":1,"formatted":"# Title\n"}`,
+		`{"id":"two","formatted":"+ <a>\n"}`,
+		`{"formatted":"","error":"invalid request: invalid character 'o' in literal null (expecting 'u')"}`,
+		`{"formatted":"","error":"invalid numbering \"zero\": must be one of one, start"}`,
+	}, "\n")+"\n", output.String())
+}
+
+func TestServeHTTP(t *testing.T) {
+	srv := httptest.NewServer(&Server{Defaults: Options{Break: "*"}, MaxRequestBytes: 64})
+	defer srv.Close()
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		status   int
+		expected string
+	}{
+		{"Format", http.MethodPost, `{"source": "---\n"}`, http.StatusOK, `{"formatted":"***\n"}` + "\n"},
+		{"Invalid option", http.MethodPost, `{"source": "", "options": {"heading": "h1"}}`, http.StatusBadRequest, `{"formatted":"","error":"invalid heading \"h1\": must be one of atx, atx-surround, full-width-setext, setext"}` + "\n"},
+		{"Invalid request", http.MethodPost, `[]`, http.StatusBadRequest, `{"formatted":"","error":"invalid request: json: cannot unmarshal array into Go value of type server.Request"}` + "\n"},
+		{"Too large", http.MethodPost, `{"source": "` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, `{"formatted":"","error":"invalid request: body larger than 64 bytes"}` + "\n"},
+		{"Wrong method", http.MethodGet, ``, http.StatusMethodNotAllowed, "method not allowed\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, srv.URL, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body := bytes.Buffer{}
+			_, err = body.ReadFrom(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.expected, body.String())
+		})
+	}
+}
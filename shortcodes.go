@@ -0,0 +1,322 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindShortcode is the NodeKind of Shortcode nodes.
+var KindShortcode = ast.NewNodeKind("Shortcode")
+
+// Shortcode is a Hugo shortcode within a paragraph, such as {{< ref "page.md" >}}.
+type Shortcode struct {
+	ast.BaseInline
+	// Segment is the shortcode in the source, from "{{" to "}}"
+	Segment text.Segment
+	// Arguments are the positions of the values of its double quoted arguments in the source,
+	// without the quotes
+	Arguments []text.Segment
+}
+
+// Kind implements ast.Node.Kind
+func (n *Shortcode) Kind() ast.NodeKind {
+	return KindShortcode
+}
+
+// Dump implements ast.Node.Dump
+func (n *Shortcode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Segment": string(n.Segment.Value(source))}, nil)
+}
+
+// KindShortcodeBlock is the NodeKind of ShortcodeBlock nodes.
+var KindShortcodeBlock = ast.NewNodeKind("ShortcodeBlock")
+
+// ShortcodeBlock is a Hugo shortcode on a line of its own. The lines of a paired {{< >}} shortcode
+// include its content and closing shortcode, since Hugo doesn't render the content as markdown.
+type ShortcodeBlock struct {
+	ast.BaseBlock
+	// Arguments are the positions of the values of the double quoted arguments of the opening
+	// shortcode in the source, without the quotes
+	Arguments []text.Segment
+	// closing matches the line of the closing shortcode while the block is parsed, or is nil if the
+	// block ends
+	closing *regexp.Regexp
+}
+
+// Kind implements ast.Node.Kind
+func (n *ShortcodeBlock) Kind() ast.NodeKind {
+	return KindShortcodeBlock
+}
+
+// IsRaw implements ast.Node.IsRaw
+func (n *ShortcodeBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump
+func (n *ShortcodeBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// shortcode holds what scanShortcode found of a shortcode.
+type shortcode struct {
+	// length is the number of bytes from "{{" to "}}"
+	length int
+	// delimiter is '<' or '%'
+	delimiter byte
+	name      []byte
+	// closing is set for closing shortcodes such as {{< /name >}}
+	closing bool
+	// standalone is set for self-closing shortcodes and comments, which can't be paired
+	standalone bool
+	// arguments are the offsets of the values of double quoted arguments
+	arguments [][2]int
+}
+
+// scanShortcode returns the Hugo shortcode at the start of line, if any.
+func scanShortcode(line []byte) (shortcode, bool) {
+	if len(line) < 3 || line[0] != '{' || line[1] != '{' || (line[2] != '<' && line[2] != '%') {
+		return shortcode{}, false
+	}
+	sc := shortcode{delimiter: line[2]}
+	end := []byte("%}}")
+	if sc.delimiter == '<' {
+		end = []byte(">}}")
+	}
+	i := util.TrimLeftSpaceLength(line[3:]) + 3
+	// Comments, such as {{</* figure */>}}, show a shortcode without running it
+	if bytes.HasPrefix(line[i:], []byte("/*")) {
+		closing := bytes.Index(line[i+2:], []byte("*/"))
+		if closing < 0 {
+			return shortcode{}, false
+		}
+		j := i + 2 + closing + 2
+		j += util.TrimLeftSpaceLength(line[j:])
+		if !bytes.HasPrefix(line[j:], end) {
+			return shortcode{}, false
+		}
+		sc.length, sc.standalone = j+len(end), true
+		return sc, true
+	}
+	if i < len(line) && line[i] == '/' {
+		sc.closing = true
+		i++
+		i += util.TrimLeftSpaceLength(line[i:])
+	}
+	nameStart := i
+	for i < len(line) && !util.IsSpace(line[i]) && !bytes.HasPrefix(line[i:], end) && line[i] != '/' {
+		i++
+	}
+	if i == nameStart {
+		return shortcode{}, false
+	}
+	sc.name = line[nameStart:i]
+	for {
+		i += util.TrimLeftSpaceLength(line[i:])
+		switch {
+		case i >= len(line) || line[i] == '\n':
+			return shortcode{}, false
+		case bytes.HasPrefix(line[i:], end):
+			sc.length = i + len(end)
+			return sc, true
+		case line[i] == '/' && bytes.HasPrefix(line[i+1:], end):
+			sc.length, sc.standalone = i+1+len(end), true
+			return sc, true
+		case line[i] == '"':
+			start := i + 1
+			for i = start; i < len(line) && line[i] != '"' && line[i] != '\n'; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(line) || line[i] != '"' {
+				return shortcode{}, false
+			}
+			sc.arguments = append(sc.arguments, [2]int{start, i})
+			i++
+		case line[i] == '`':
+			closing := bytes.IndexByte(line[i+1:], '`')
+			if closing < 0 {
+				return shortcode{}, false
+			}
+			i += closing + 2
+		default:
+			// Bare values and argument names, which may be followed by a quoted value
+			for i < len(line) && !util.IsSpace(line[i]) && line[i] != '"' && line[i] != '`' &&
+				!bytes.HasPrefix(line[i:], end) {
+				i++
+			}
+		}
+	}
+}
+
+// argumentSegments returns the positions of the arguments of sc, found at offset in the source.
+func (sc shortcode) argumentSegments(offset int) []text.Segment {
+	segments := make([]text.Segment, 0, len(sc.arguments))
+	for _, arg := range sc.arguments {
+		segments = append(segments, text.NewSegment(offset+arg[0], offset+arg[1]))
+	}
+	return segments
+}
+
+type shortcodeParser struct{}
+
+// NewShortcodeParser returns a parser.InlineParser that parses Hugo shortcodes into Shortcode nodes.
+func NewShortcodeParser() parser.InlineParser {
+	return &shortcodeParser{}
+}
+
+// Trigger implements parser.InlineParser.Trigger
+func (p *shortcodeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Parse implements parser.InlineParser.Parse
+func (p *shortcodeParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	sc, ok := scanShortcode(line)
+	if !ok {
+		return nil
+	}
+	block.Advance(sc.length)
+	return &Shortcode{
+		Segment:   segment.WithStop(segment.Start + sc.length),
+		Arguments: sc.argumentSegments(segment.Start),
+	}
+}
+
+type shortcodeBlockParser struct{}
+
+// NewShortcodeBlockParser returns a parser.BlockParser that parses Hugo shortcodes on lines of their
+// own into ShortcodeBlock nodes. A {{< >}} shortcode with a closing shortcode on a later line of its
+// own takes in the lines up to it.
+func NewShortcodeBlockParser() parser.BlockParser {
+	return &shortcodeBlockParser{}
+}
+
+// Trigger implements parser.BlockParser.Trigger
+func (p *shortcodeBlockParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Open implements parser.BlockParser.Open
+func (p *shortcodeBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	sc, ok := scanShortcode(line[pos:])
+	if !ok || !util.IsBlank(line[pos+sc.length:]) {
+		return nil, parser.NoChildren
+	}
+	node := &ShortcodeBlock{Arguments: sc.argumentSegments(segment.Start + pos)}
+	node.Lines().Append(segment.WithStart(segment.Start + pos))
+	reader.Advance(segment.Len() - 1)
+
+	if sc.delimiter == '<' && !sc.closing && !sc.standalone {
+		closing := `[ \t]*\{\{<\s*/\s*` + regexp.QuoteMeta(string(sc.name)) + `\s*>\}\}[ \t]*`
+		// Only take in lines if the shortcode is closed, or the rest of the document would be
+		if regexp.MustCompile(`(?m)^` + closing + `\r?$`).Match(reader.Source()[segment.Stop:]) {
+			node.closing = regexp.MustCompile(`^` + closing + `\r?\n?$`)
+		}
+	}
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.Continue
+func (p *shortcodeBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*ShortcodeBlock)
+	if n.closing == nil {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	if n.closing.Match(line) {
+		n.closing = nil
+	}
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.Close
+func (p *shortcodeBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	node.(*ShortcodeBlock).closing = nil
+}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph. Shortcodes on a line of
+// their own within a paragraph stay inline, like links.
+func (p *shortcodeBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine
+func (p *shortcodeBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func (r *Renderer) renderShortcode(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := node.(*Shortcode)
+		r.writeShortcode(n.Segment.Value(r.rc.source), n.Segment.Start, n.Arguments)
+		if last := n.Segment.Value(r.rc.source); len(last) > 0 {
+			r.rc.lastRune = rune(last[len(last)-1])
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *Renderer) renderShortcodeBlock(node ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := node.(*ShortcodeBlock)
+		r.rc.writer.SetVerbatim(true)
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			value := line.Value(r.rc.source)
+			if i == 0 {
+				r.writeShortcode(value, line.Start, n.Arguments)
+			} else {
+				r.rc.writer.WriteBytes(value)
+			}
+			r.rc.writer.FlushLine()
+		}
+		r.rc.writer.SetVerbatim(false)
+	}
+	return ast.WalkContinue
+}
+
+// writeShortcode writes the shortcode source found at offset, with its double quoted arguments
+// translated if configured.
+func (r *Renderer) writeShortcode(source []byte, offset int, arguments []text.Segment) {
+	if r.config.HugoShortcodes != HugoShortcodesTranslateArguments || r.rc.skipTranslation ||
+		(r.config.TextTransformer == nil && r.textHook == nil) {
+		r.rc.writer.WriteBytes(bytes.TrimRight(source, "\r\n"))
+		return
+	}
+	written := offset
+	for _, arg := range arguments {
+		// Quotes are the only escapes in quoted arguments
+		value := bytes.ReplaceAll(arg.Value(r.rc.source), []byte(`\"`), []byte(`"`))
+		if len(bytes.TrimSpace(value)) == 0 {
+			continue
+		}
+		translation, ok := r.transformPlainText(TextSegment{Start: arg.Start, Stop: arg.Stop, Text: string(value)})
+		if !ok {
+			continue
+		}
+		r.rc.writer.WriteBytes(r.rc.source[written:arg.Start])
+		r.rc.writer.WriteBytes([]byte(strings.ReplaceAll(translation, `"`, `\"`)))
+		written = arg.Stop
+	}
+	r.rc.writer.WriteBytes(bytes.TrimRight(r.rc.source[written:offset+len(source)], "\r\n"))
+}
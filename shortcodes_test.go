@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestHugoShortcodes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Inline",
+			"See {{< figure src=\"a_b.png\" title=\"A *b* [x]\" >}} and {{% ref `c_d.md` %}}.\n",
+			"See {{< figure src=\"a_b.png\" title=\"A *b* [x]\" >}} and {{% ref `c_d.md` %}}.\n",
+		},
+		{
+			"Markdown content",
+			"{{% note %}}\nSome __text__\n{{% /note %}}\n",
+			"{{% note %}}\nSome **text**\n{{% /note %}}\n",
+		},
+		{
+			"Raw content",
+			"{{< highlight go >}}\n* a_b\n\n  {{< /highlightx >}}\n{{< /highlight >}}\nAfter __text__\n",
+			"{{< highlight go >}}\n* a_b\n\n  {{< /highlightx >}}\n{{< /highlight >}}\nAfter **text**\n",
+		},
+		{
+			"Unclosed",
+			"{{< figure src=\"a.png\" >}}\n* a\n",
+			"{{< figure src=\"a.png\" >}}\n* a\n",
+		},
+		{
+			"Self-closing and comments",
+			"{{< figure src=\"a.png\" />}}\n\n{{</* highlight */>}}\n\n* a\n",
+			"{{< figure src=\"a.png\" />}}\n\n{{</* highlight */>}}\n\n* a\n",
+		},
+		{
+			"In a list",
+			"* Item\n\n  {{< tabs >}}\n  * a_b\n  {{< /tabs >}}\n",
+			"* Item\n\n  {{< tabs >}}\n  * a_b\n  {{< /tabs >}}\n",
+		},
+		{
+			"Not shortcodes",
+			"{{ .Title }} and {{< a_b\n",
+			"{{ .Title }} and {{< a_b\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithHugoShortcodes(HugoShortcodesPreserve))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestHugoShortcodesNone(t *testing.T) {
+	source := []byte("{{< figure title=\"A _b_\" >}}\n")
+	r := NewRenderer()
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	doc := md.Parser().Parse(text.NewReader(source))
+	assert.Empty(t, FindAll(doc, func(n ast.Node) bool {
+		return n.Kind() == KindShortcode || n.Kind() == KindShortcodeBlock
+	}))
+}
+
+func TestHugoShortcodesTranslation(t *testing.T) {
+	source := "Go to {{< ref \"about.md\" >}} now.\n\n{{< figure src=\"a.png\" title=\"A \\\"cat\\\"\" alt=\"\" >}}\n\n{{< highlight >}}\ntext\n{{< /highlight >}}\n"
+	translations := MapTransformer{
+		"Go to":     "Aller à",
+		"now.":      "maintenant.",
+		"about.md":  "a-propos.md",
+		"A \"cat\"": "Un \"chat\"",
+		"text":      "texte",
+	}
+	testCases := []struct {
+		name       string
+		shortcodes HugoShortcodes
+		expected   string
+	}{
+		{
+			"Preserve",
+			HugoShortcodesPreserve,
+			"Aller à {{< ref \"about.md\" >}} maintenant.\n\n{{< figure src=\"a.png\" title=\"A \\\"cat\\\"\" alt=\"\" >}}\n\n{{< highlight >}}\ntext\n{{< /highlight >}}\n",
+		},
+		{
+			"Translate arguments",
+			HugoShortcodesTranslateArguments,
+			"Aller à {{< ref \"a-propos.md\" >}} maintenant.\n\n{{< figure src=\"a.png\" title=\"Un \\\"chat\\\"\" alt=\"\" >}}\n\n{{< highlight >}}\ntext\n{{< /highlight >}}\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithHugoShortcodes(tc.shortcodes), WithTextTransformer(translations))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+			buf := bytes.Buffer{}
+			require.NoError(t, md.Convert([]byte(source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	// The arguments are text segments that can be edited
+	r := NewRenderer(WithHugoShortcodes(HugoShortcodesTranslateArguments))
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	doc := md.Parser().Parse(text.NewReader([]byte(source)))
+	segments, err := r.TextSegments([]byte(source), doc)
+	require.NoError(t, err)
+	texts := []string{}
+	for _, segment := range segments {
+		texts = append(texts, segment.Text)
+	}
+	assert.Equal(t, "Go to|about.md|now.|a.png|A \"cat\"", strings.Join(texts, "|"))
+}
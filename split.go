@@ -0,0 +1,114 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// SplitByHeading splits source into sections, each starting at a heading of level or lower outside
+// of container blocks, and returns each section as a separate document rendered by a Renderer
+// configured with options. Content before the first heading is returned as a section of its own.
+// YAML or TOML front matter, which goldmark doesn't parse, is kept verbatim at the start of the first
+// section, so the sections can be joined back into a document.
+func SplitByHeading(source []byte, level int, options ...Option) ([][]byte, error) {
+	frontMatter, body := splitFrontMatter(source)
+	r := NewRenderer(options...)
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	doc := md.Parser().Parse(text.NewReader(body))
+
+	var sections []*ast.Document
+	for c := doc.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		if h, ok := c.(*ast.Heading); (ok && h.Level <= level) || len(sections) == 0 {
+			sections = append(sections, ast.NewDocument())
+		}
+		section := sections[len(sections)-1]
+		doc.RemoveChild(doc, c)
+		section.AppendChild(section, c)
+		c = next
+	}
+
+	rendered := make([][]byte, 0, max(len(sections), 1))
+	for _, section := range sections {
+		buf := bytes.Buffer{}
+		if err := md.Renderer().Render(&buf, body, section); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, buf.Bytes())
+	}
+	if len(frontMatter) > 0 {
+		if len(rendered) == 0 {
+			rendered = append(rendered, nil)
+		}
+		rendered[0] = append(append([]byte{}, frontMatter...), rendered[0]...)
+	}
+	return rendered, nil
+}
+
+// splitFrontMatter returns the YAML or TOML front matter at the start of source, including its
+// delimiter lines, and the rest of source. The front matter is empty if there's none.
+func splitFrontMatter(source []byte) (frontMatter, body []byte) {
+	firstLine, rest, found := bytes.Cut(source, []byte{lineDelim})
+	delimiter := bytes.TrimRight(firstLine, " \t\r")
+	if !found || (string(delimiter) != "---" && string(delimiter) != "+++") {
+		return nil, source
+	}
+	for offset := len(firstLine) + 1; len(rest) > 0; {
+		line, next, _ := bytes.Cut(rest, []byte{lineDelim})
+		closing := bytes.TrimRight(line, " \t\r")
+		// YAML documents can also be ended by "..."
+		if bytes.Equal(closing, delimiter) || (string(delimiter) == "---" && string(closing) == "...") {
+			end := min(offset+len(line)+1, len(source))
+			return source[:end], source[end:]
+		}
+		offset += len(line) + 1
+		rest = next
+	}
+	return nil, source
+}
+
+// ConcatSource is a document merged by Concat.
+type ConcatSource struct {
+	// Source is the markdown source of the document
+	Source []byte
+	// HeadingOffset is added to the level of each heading of the document, which is kept between 1
+	// and 6
+	HeadingOffset int
+}
+
+// Concat merges the documents of sources into one, rendering each with a Renderer configured with
+// options and separating them by a blank line. The front matter of the first document is kept at the
+// start, and that of the others is left out.
+func Concat(sources []ConcatSource, options ...Option) ([]byte, error) {
+	r := NewRenderer(options...)
+	md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(r))
+	merged := bytes.Buffer{}
+	separate := false
+	for i, s := range sources {
+		frontMatter, body := splitFrontMatter(s.Source)
+		if i == 0 {
+			merged.Write(frontMatter)
+		}
+		doc := md.Parser().Parse(text.NewReader(body))
+		for _, n := range FindAll(doc, func(n ast.Node) bool { return n.Kind() == ast.KindHeading }) {
+			heading := n.(*ast.Heading)
+			heading.Level = min(max(heading.Level+s.HeadingOffset, 1), 6)
+		}
+		rendered := bytes.Buffer{}
+		if err := md.Renderer().Render(&rendered, body, doc); err != nil {
+			return nil, err
+		}
+		if rendered.Len() == 0 {
+			continue
+		}
+		if separate {
+			merged.WriteByte(lineDelim)
+		}
+		merged.Write(rendered.Bytes())
+		separate = true
+	}
+	return merged.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitByHeading(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		level    int
+		options  []Option
+		expected []string
+	}{
+		{
+			"Sections",
+			"Intro\n\n# One\n\nText\n\n## Nested\n\n* item\n\n# Two\n\n> # Quoted\n",
+			1,
+			[]Option{},
+			[]string{"Intro\n", "# One\n\nText\n\n## Nested\n\n* item\n", "# Two\n\n> # Quoted\n"},
+		},
+		{
+			"Deeper level",
+			"# One\n\nText\n\n## Nested\n\nMore\n",
+			2,
+			[]Option{},
+			[]string{"# One\n\nText\n", "## Nested\n\nMore\n"},
+		},
+		{
+			"Renderer options",
+			"One\n===\n\n| a |\n|---|\n\nTwo\n===\n",
+			1,
+			[]Option{WithHeadingStyle(HeadingStyleSetext)},
+			[]string{"One\n===\n\n| a |\n| --- |\n", "Two\n===\n"},
+		},
+		{
+			"YAML front matter",
+			"---\ntitle: Doc\n---\n# One\n\n# Two\n",
+			1,
+			[]Option{},
+			[]string{"---\ntitle: Doc\n---\n# One\n", "# Two\n"},
+		},
+		{
+			"TOML front matter only",
+			"+++\ntitle = \"Doc\"\n+++\n",
+			1,
+			[]Option{},
+			[]string{"+++\ntitle = \"Doc\"\n+++\n"},
+		},
+		{
+			"Unclosed front matter",
+			"---\n# One\n",
+			1,
+			[]Option{},
+			[]string{"---\n", "# One\n"},
+		},
+		{
+			"Empty document",
+			"",
+			1,
+			[]Option{},
+			[]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sections, err := SplitByHeading([]byte(tc.source), tc.level, tc.options...)
+			assert.NoError(t, err)
+			actual := []string{}
+			for _, section := range sections {
+				actual = append(actual, string(section))
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestConcat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sources  []ConcatSource
+		options  []Option
+		expected string
+	}{
+		{
+			"Heading offsets",
+			[]ConcatSource{
+				{Source: []byte("# Book\n")},
+				{Source: []byte("# Chapter\n\n## Section\nText\n\n\n"), HeadingOffset: 1},
+				{Source: []byte("###### Deep\n\n# Up\n"), HeadingOffset: -1},
+				{Source: []byte("## Clamped\n"), HeadingOffset: 5},
+			},
+			[]Option{},
+			"# Book\n\n## Chapter\n\n### Section\nText\n\n##### Deep\n\n# Up\n\n###### Clamped\n",
+		},
+		{
+			"Renderer options",
+			[]ConcatSource{
+				{Source: []byte("Title\n=====\n\n* a\n")},
+				{Source: []byte("Part\n====\n"), HeadingOffset: 1},
+			},
+			[]Option{WithHeadingStyle(HeadingStyleSetext)},
+			"Title\n===\n\n* a\n\nPart\n---\n",
+		},
+		{
+			"Front matter",
+			[]ConcatSource{
+				{Source: []byte("---\ntitle: Book\n---\n# One\n")},
+				{Source: []byte("---\ntitle: Two\n---\n")},
+				{Source: []byte("---\ntitle: Three\n---\n# Three\n")},
+			},
+			[]Option{},
+			"---\ntitle: Book\n---\n# One\n\n# Three\n",
+		},
+		{
+			"Empty documents",
+			[]ConcatSource{{Source: []byte("")}, {Source: []byte("Text\n")}, {Source: []byte("\n")}},
+			[]Option{},
+			"Text\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := Concat(tc.sources, tc.options...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(merged))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// DocumentStats holds statistics about the content of a document, returned by Stats.
+type DocumentStats struct {
+	// NodeCounts is the number of nodes of each kind, keyed by kind name such as "Paragraph"
+	NodeCounts map[string]int
+	// Outline holds the headings of the document in order
+	Outline []OutlineHeading
+	// Links is the number of links, including autolinks
+	Links int
+	// Images is the number of images
+	Images int
+	// Words is the number of whitespace separated words in the text of the document, excluding code
+	// and URLs
+	Words int
+}
+
+// OutlineHeading is a heading in the outline of a document.
+type OutlineHeading struct {
+	// Level is the heading level, from 1 to 6
+	Level int
+	// Text is the plain text of the heading
+	Text string
+}
+
+// Stats returns statistics about the document doc parsed from source.
+func Stats(source []byte, doc ast.Node) DocumentStats {
+	stats := DocumentStats{NodeCounts: map[string]int{}}
+	text := strings.Builder{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				// Words don't continue across blocks
+				text.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+		stats.NodeCounts[n.Kind().String()]++
+		switch n := n.(type) {
+		case *ast.Heading:
+			stats.Outline = append(stats.Outline, OutlineHeading{Level: n.Level, Text: plainText(source, n)})
+		case *ast.Link, *ast.AutoLink:
+			stats.Links++
+		case *ast.Image:
+			stats.Images++
+		case *ast.CodeSpan:
+			// Count the nodes in code spans, but not their words
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				stats.NodeCounts[c.Kind().String()]++
+			}
+			text.WriteByte(' ')
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			// Tags such as <br> separate words
+			text.WriteByte(' ')
+		case *ast.Text:
+			text.WriteString(unescapedText(source, n))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				text.WriteByte(' ')
+			}
+		case *ast.String:
+			text.WriteString(unescapedText(source, n))
+		}
+		return ast.WalkContinue, nil
+	})
+	stats.Words = countWords(text.String())
+	return stats
+}
+
+// countWords returns the number of whitespace separated words in text.
+func countWords(text string) int {
+	words := 0
+	for _, word := range strings.Fields(text) {
+		// Punctuation on its own isn't a word
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0 {
+			words++
+		}
+	}
+	return words
+}
+
+// plainText returns the text of the descendants of n, without markup.
+func plainText(source []byte, n ast.Node) string {
+	text := strings.Builder{}
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && isTextNode(n) {
+			text.WriteString(unescapedText(source, n))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(text.String())
+}
+
+// ProseCount is the amount of translatable text in a document, returned by CountProse.
+type ProseCount struct {
+	// Texts is the number of texts passed to the TextTransformer
+	Texts int
+	// Words is the number of whitespace separated words in the texts
+	Words int
+	// Characters is the number of characters in the texts, counting each rune once
+	Characters int
+}
+
+// CountProse counts the plain text that r would pass to its TextTransformer when rendering the
+// document doc parsed from source, which excludes code, URLs and HTML. The document is rendered with
+// the configuration of r, but with a TextTransformer that records its input instead, so the counts are
+// of the texts a translator would be asked to translate, e.g. to estimate the cost of translating the
+// document.
+func (r *Renderer) CountProse(source []byte, doc ast.Node) (ProseCount, error) {
+	count := ProseCount{}
+	counter := NewRenderer()
+	*counter.config = *r.config
+	counter.config.TextTransformer = proseCounter{&count}
+	counter.config.CollectMetrics = false
+	if err := counter.Render(io.Discard, source, doc); err != nil {
+		return ProseCount{}, err
+	}
+	return count, nil
+}
+
+// proseCounter is a TextTransformer that counts the plain text it receives without transforming it.
+type proseCounter struct {
+	count *ProseCount
+}
+
+func (c proseCounter) Transform(textType TextType, text string) (string, bool) {
+	if textType == TextTypePlain {
+		c.count.Texts++
+		c.count.Words += countWords(text)
+		c.count.Characters += utf8.RuneCountInString(text)
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package markdown
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestStats(t *testing.T) {
+	source := []byte("# The *Title*\n\nSome text with a [link](/url) and\nan ![image](i.png) `code span`.\n\n" +
+		"## Section `two`\n\n- one<br>two\n- <https://example.com>\n\n```\nnot counted\n```\n")
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+	stats := Stats(source, doc)
+
+	assert := assert.New(t)
+	assert.Equal([]OutlineHeading{{Level: 1, Text: "The Title"}, {Level: 2, Text: "Section two"}}, stats.Outline)
+	assert.Equal(2, stats.Links)
+	assert.Equal(1, stats.Images)
+	// "The Title", "Some text with a link and an image", "Section" and "one two"
+	assert.Equal(13, stats.Words)
+	assert.Equal(2, stats.NodeCounts["Heading"])
+	assert.Equal(2, stats.NodeCounts["CodeSpan"])
+	assert.Equal(1, stats.NodeCounts["FencedCodeBlock"])
+	assert.Equal(2, stats.NodeCounts["ListItem"])
+}
+
+func TestCountProse(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("# The *Title*\n\nSome text with a [link](/url \"title\") and\nan ![image](i.png) `code span` " +
+		"<https://example.com> <b>bold</b>.\n\n- 一 二三\n\n| a | b |\n|---|---|\n| c | `d` |\n\n" +
+		"<div>\nhtml\n</div>\n\n```\nnot counted\n```\n")
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	doc := md.Parser().Parse(text.NewReader(source))
+
+	recorded := recordingTransformer{}
+	r := NewRenderer(WithTextTransformer(&recorded))
+	count, err := r.CountProse(source, doc)
+	assert.NoError(err)
+	assert.Equal(ProseCount{Texts: 12, Words: 16, Characters: 51}, count)
+	// The renderer's own TextTransformer isn't called
+	assert.Empty(recorded.calls)
+
+	// The counts match the plain text the TextTransformer receives when rendering
+	r.RegisterFuncs(r)
+	assert.NoError(r.Render(io.Discard, source, doc))
+	assert.Equal([]string{"The", "Title", "Some text with a", "link", "and\nan", "image", "<b>", "bold", "</b>",
+		".", "一 二三", "a", "b", "c", "<div>\nhtml\n</div>\n"}, recorded.calls)
+}
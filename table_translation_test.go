@@ -27,7 +27,7 @@ func TestTableTranslation(t *testing.T) {
 				"Cell 2":   "单元格 2",
 			},
 			expected: "| 标题 1 | 标题 2 |\n" +
-				"| ----- | ----- |\n" +
+				"| -------- | -------- |\n" +
 				"| 单元格 1 | 单元格 2 |\n",
 		},
 		{
@@ -44,7 +44,7 @@ func TestTableTranslation(t *testing.T) {
 				"3":      "三",
 			},
 			expected: "| 左对齐 | 居中 | 右对齐 |\n" +
-				"| :----- | :----: | -----: |\n" +
+				"| :----- | :--: | -----: |\n" +
 				"| 一 | 二 | 三 |\n",
 		},
 		{
@@ -59,7 +59,7 @@ func TestTableTranslation(t *testing.T) {
 				"Link":   "链接",
 			},
 			expected: "| *斜体* | **粗体** |\n" +
-				"| ----- | ----- |\n" +
+				"| ------ | -------------------------- |\n" +
 				"| `Code` | [链接](http://example.com) |\n",
 		},
 		{
@@ -74,9 +74,21 @@ func TestTableTranslation(t *testing.T) {
 				// NotTranslated not in translations map
 			},
 			expected: "| 标题 1 | 标题 2 |\n" +
-				"| ----- | ----- |\n" +
+				"| -------- | ------------- |\n" +
 				"| 单元格 1 | NotTranslated |\n",
 		},
+		{
+			name: "translation containing a pipe",
+			source: "| Header |\n" +
+				"|--------|\n" +
+				"| Cell   |",
+			translations: map[string]string{
+				"Cell": "是 | 否",
+			},
+			expected: "| Header |\n" +
+				"| -------- |\n" +
+				"| 是 \\| 否 |\n",
+		},
 		{
 			name: "multi-row table translation",
 			source: "| Header 1 | Header 2 |\n" +
@@ -92,7 +104,7 @@ func TestTableTranslation(t *testing.T) {
 				"Row 2 Cell 2": "第2行单元格2",
 			},
 			expected: "| 标题 1 | 标题 2 |\n" +
-				"| ----- | ----- |\n" +
+				"| ------------ | ------------ |\n" +
 				"| 第1行单元格1 | 第1行单元格2 |\n" +
 				"| 第2行单元格1 | 第2行单元格2 |\n",
 		},
@@ -0,0 +1,25 @@
+package markdown
+
+import (
+	"io"
+	"time"
+)
+
+// OutputTemplateData is the data that the template of WithOutputTemplate is executed with.
+type OutputTemplateData struct {
+	// Content is the rendered markdown
+	Content string
+	// Date is the time of rendering
+	Date time.Time
+	// Vars are the variables given to WithOutputTemplate
+	Vars map[string]string
+}
+
+// execute writes the output of the template executed with content to w.
+func (t *OutputTemplate) execute(w io.Writer, content []byte) error {
+	return t.Template.Execute(w, OutputTemplateData{
+		Content: string(content),
+		Date:    time.Now(),
+		Vars:    t.Vars,
+	})
+}
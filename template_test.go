@@ -0,0 +1,29 @@
+package markdown
+
+import (
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("banner").Parse(
+		"> This page was machine translated from {{.Vars.source}} to {{.Vars.lang}}.\n\n{{.Content}}"))
+	formatted, err := Format([]byte("---\ntitle: Hello\n---\nHello\n=====\n"),
+		WithTextTransformer(MapTransformer{"Hello": "Bonjour"}),
+		WithOutputTemplate(tmpl, map[string]string{"lang": "fr", "source": "index.md"}))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Hello\n---\n> This page was machine translated from index.md to fr.\n\n# Bonjour\n", string(formatted))
+
+	tmpl = template.Must(template.New("date").Parse("{{.Date.Year}}"))
+	formatted, err = Format([]byte("Text\n"), WithOutputTemplate(tmpl, nil))
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().Format("2006"), string(formatted))
+
+	tmpl = template.Must(template.New("missing").Option("missingkey=error").Parse("{{.Vars.lang}}"))
+	_, err = Format([]byte("Text\n"), WithOutputTemplate(tmpl, map[string]string{}))
+	assert.Error(t, err)
+}
@@ -207,8 +207,9 @@ def hello_world():
 - ![示例图片](https://example.com/image.jpg)
 
 ## 表格
+
 | 标题 1 | 标题 2 |
-| ----- | ----- |
+| -------- | -------- |
 | 单元格 1 | 单元格 2 |
 | 单元格 3 | 单元格 4 |
 
@@ -105,6 +105,25 @@ func TestTranslations(t *testing.T) {
 				"### 结论\n\n" +
 				"最后的段落包含一些重要信息。\n",
 		},
+		{
+			name:   "image inside link translation",
+			source: "[![Build status](badge.svg)](https://ci) [see ![icon](i.png) here](/uri)",
+			translations: map[string]string{
+				"Build status": "构建状态",
+				"see":          "查看",
+				"icon":         "图标",
+				"here":         "这里",
+				"https://ci":   "wrong",
+				"badge.svg":    "wrong",
+			},
+			expected: "[![构建状态](badge.svg)](https://ci) [查看 ![图标](i.png) 这里](/uri)\n",
+		},
+		{
+			name:         "hard line break separates translations",
+			source:       "Hello  \nworld",
+			translations: map[string]string{"Hello": "你好", "world": "世界"},
+			expected:     "你好\\\n世界\n",
+		},
 		{
 			name:         "no translations",
 			source:       "Hello world",
@@ -206,3 +225,53 @@ func TestRenderText(t *testing.T) {
 		})
 	}
 }
+
+// TestTranslatedSetextUnderline tests that full-width setext underlines match the translated heading
+func TestTranslatedSetextUnderline(t *testing.T) {
+	renderer := NewRenderer(
+		WithHeadingStyle(HeadingStyleFullWidthSetext),
+		WithTextTransformer(MapTransformer(map[string]string{"Hi": "Hello world"})),
+	)
+	var buf bytes.Buffer
+	if err := goldmark.New(goldmark.WithRenderer(renderer)).Convert([]byte("# Hi"), &buf); err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if expected := "Hello world\n===========\n"; buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
+
+// TestInlineJoin tests how translated text is joined to neighboring inline nodes
+func TestInlineJoin(t *testing.T) {
+	translations := MapTransformer(map[string]string{
+		"This is": "这是",
+		"bold":    "粗体",
+		"text.":   "文本。",
+		"see":     "参见",
+		"link":    "link",
+		"now":     "现在",
+	})
+	source := "This is **bold** text.\n\nsee [link](/uri) now"
+	tests := []struct {
+		name     string
+		join     InlineJoin
+		expected string
+	}{
+		{"space", InlineJoinSpace, "这是 **粗体** 文本。\n\n参见 [link](/uri) 现在\n"},
+		{"none", InlineJoinNone, "这是**粗体**文本。\n\n参见[link](/uri)现在\n"},
+		{"smart", InlineJoinSmart, "这是**粗体**文本。\n\n参见 [link](/uri) 现在\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := NewRenderer(WithTextTransformer(translations), WithInlineJoin(tt.join))
+			var buf bytes.Buffer
+			if err := goldmark.New(goldmark.WithRenderer(renderer)).Convert([]byte(source), &buf); err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package markdown
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
@@ -12,31 +16,453 @@ import (
 	"github.com/yuin/goldmark/text"
 )
 
+// PrintASTOption configures PrintAST.
+type PrintASTOption func(*printASTConfig)
+
+// ASTColor is an enum expressing whether PrintAST colors its output.
+type ASTColor int
+
+const (
+	// ASTColorAuto colors the output if it's written to a terminal and the NO_COLOR environment
+	// variable isn't set. This is the default and zero value.
+	ASTColorAuto ASTColor = iota
+	// ASTColorAlways colors the output with ANSI escape codes.
+	ASTColorAlways
+	// ASTColorNever never colors the output.
+	ASTColorNever
+)
+
+// ANSI escape codes for the parts of PrintAST output
+const (
+	astColorKind      = "\x1b[1;34m"
+	astColorText      = "\x1b[32m"
+	astColorAttribute = "\x1b[33m"
+	astColorPosition  = "\x1b[2m"
+	astColorReset     = "\x1b[0m"
+)
+
+// printASTConfig holds the configuration of PrintAST.
+type printASTConfig struct {
+	// positions shows the source range of each node
+	positions bool
+	// color is whether to color the output
+	color ASTColor
+	// include and exclude are the kinds of nodes to print or skip. All kinds are included if include
+	// is empty.
+	include, exclude []ast.NodeKind
+	// maxDepth is the depth of the deepest nodes printed, or 0 for no limit
+	maxDepth int
+	// maxText is the number of characters of text printed for each node or line, or 0 for no limit
+	maxText int
+	// markdown parses the source in PrintASTFromMarkdown
+	markdown goldmark.Markdown
+	// describers describe nodes by kind, in addition to those registered with RegisterASTDescriber
+	describers map[ast.NodeKind]ASTDescriber
+}
+
+// ASTDescriber returns the details of a node printed by PrintAST after its kind, such as "Level=1",
+// for kinds of nodes added by extensions.
+type ASTDescriber func(source []byte, n ast.Node) string
+
+// astDescribers holds the describers registered with RegisterASTDescriber.
+var astDescribers = struct {
+	sync.RWMutex
+	kinds map[ast.NodeKind]ASTDescriber
+}{kinds: map[ast.NodeKind]ASTDescriber{}}
+
+// RegisterASTDescriber registers describer for nodes of kind in all calls to PrintAST, replacing the
+// details PrintAST prints for the kind, if any. Extensions can use it to describe the nodes they add.
+// Registering a nil describer restores the default details.
+func RegisterASTDescriber(kind ast.NodeKind, describer ASTDescriber) {
+	astDescribers.Lock()
+	defer astDescribers.Unlock()
+	astDescribers.kinds[kind] = describer
+}
+
+// describer returns the describer of nodes of kind, or nil if PrintAST describes them itself.
+func (c *printASTConfig) describer(kind ast.NodeKind) ASTDescriber {
+	if describer, ok := c.describers[kind]; ok {
+		return describer
+	}
+	astDescribers.RLock()
+	defer astDescribers.RUnlock()
+	return astDescribers.kinds[kind]
+}
+
+// truncate returns text shortened to the maximum text length, keeping any final newline.
+func (c *printASTConfig) truncate(text []byte) string {
+	s := string(text)
+	if c.maxText <= 0 {
+		return s
+	}
+	s, newline := strings.CutSuffix(s, "\n")
+	if runes := []rune(s); len(runes) > c.maxText {
+		s = string(runes[:c.maxText]) + "…"
+	}
+	if newline {
+		s += "\n"
+	}
+	return s
+}
+
+// shows returns true if nodes of kind are printed.
+func (c *printASTConfig) shows(kind ast.NodeKind) bool {
+	return (len(c.include) == 0 || slices.Contains(c.include, kind)) && !slices.Contains(c.exclude, kind)
+}
+
+// paint returns s in the given color if the output is colored.
+func (c *printASTConfig) paint(color, s string) string {
+	if c.color != ASTColorAlways {
+		return s
+	}
+	return color + s + astColorReset
+}
+
+// fprintf writes formatted output in the given color if the output is colored.
+func (c *printASTConfig) fprintf(w io.Writer, color, format string, args ...any) {
+	fmt.Fprint(w, c.paint(color, fmt.Sprintf(format, args...)))
+}
+
+// isTerminal returns true if w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// WithASTPositions shows the source range of each node in PrintAST, as byte offsets followed by
+// line:column positions, for nodes whose range is known.
+func WithASTPositions() PrintASTOption {
+	return func(c *printASTConfig) {
+		c.positions = true
+	}
+}
+
+// WithASTColor sets whether PrintAST colors node kinds, text and attributes.
+func WithASTColor(color ASTColor) PrintASTOption {
+	return func(c *printASTConfig) {
+		c.color = color
+	}
+}
+
+// WithASTKinds prints only nodes of the given kinds in PrintAST. The descendants of other nodes are
+// still printed, in their place.
+func WithASTKinds(kinds ...ast.NodeKind) PrintASTOption {
+	return func(c *printASTConfig) {
+		c.include = append(c.include, kinds...)
+	}
+}
+
+// WithoutASTKinds skips nodes of the given kinds in PrintAST. Their descendants are still printed, in
+// their place.
+func WithoutASTKinds(kinds ...ast.NodeKind) PrintASTOption {
+	return func(c *printASTConfig) {
+		c.exclude = append(c.exclude, kinds...)
+	}
+}
+
+// WithASTMaxDepth limits PrintAST to nodes at most depth levels below the root. Nodes at the limit
+// show how many children they have instead.
+func WithASTMaxDepth(depth int) PrintASTOption {
+	return func(c *printASTConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// WithASTMaxText limits the text printed by PrintAST for each node or line of content to length
+// characters, marking where it was cut with an ellipsis.
+func WithASTMaxText(length int) PrintASTOption {
+	return func(c *printASTConfig) {
+		c.maxText = length
+	}
+}
+
+// WithASTMarkdown sets the goldmark.Markdown used by PrintASTFromMarkdown to parse the source, so the
+// printed AST matches that of a pipeline with other extensions or parser options. By default, only
+// the Table extension is enabled.
+func WithASTMarkdown(md goldmark.Markdown) PrintASTOption {
+	return func(c *printASTConfig) {
+		c.markdown = md
+	}
+}
+
+// WithASTDescriber describes nodes of kind with describer in PrintAST, taking precedence over any
+// describer registered with RegisterASTDescriber. The details it returns are printed in brackets
+// after the kind, unless they're empty.
+func WithASTDescriber(kind ast.NodeKind, describer ASTDescriber) PrintASTOption {
+	return func(c *printASTConfig) {
+		if c.describers == nil {
+			c.describers = map[ast.NodeKind]ASTDescriber{}
+		}
+		c.describers[kind] = describer
+	}
+}
+
 // PrintAST prints the AST structure of a Markdown document to the specified writer
-func PrintAST(w io.Writer, source []byte, n ast.Node) error {
+func PrintAST(w io.Writer, source []byte, n ast.Node, options ...PrintASTOption) error {
+	config := printASTConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	if config.color == ASTColorAuto {
+		config.color = ASTColorNever
+		if _, noColor := os.LookupEnv("NO_COLOR"); !noColor && isTerminal(w) {
+			config.color = ASTColorAlways
+		}
+	}
 	_, err := fmt.Fprintln(w, "AST Tree:")
 	if err != nil {
 		return err
 	}
-	return printASTNode(w, source, n, 0, "")
+	return printASTNode(w, source, n, 0, "", &config)
 }
 
 // PrintASTFromMarkdown parses the markdown text into an AST and prints its structure
-func PrintASTFromMarkdown(w io.Writer, source []byte) error {
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.Table,
-		),
-	)
+func PrintASTFromMarkdown(w io.Writer, source []byte, options ...PrintASTOption) error {
+	config := printASTConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	md := config.markdown
+	if md == nil {
+		md = goldmark.New(
+			goldmark.WithExtensions(
+				extension.Table,
+			),
+		)
+	}
 	parser := md.Parser()
 	reader := text.NewReader(source)
 	doc := parser.Parse(reader)
 
-	return PrintAST(w, source, doc)
+	return PrintAST(w, source, doc, options...)
+}
+
+// ASTNode is the JSON form of an AST node written by PrintASTJSON.
+type ASTNode struct {
+	// Kind is the kind of the node, such as "Paragraph"
+	Kind string `json:"kind"`
+	// Properties holds the fields of the node, such as the level of a heading
+	Properties map[string]any `json:"properties,omitempty"`
+	// Attributes holds the HTML attributes set on the node
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// Text is the text of text nodes, and the content of code and HTML blocks
+	Text string `json:"text,omitempty"`
+	// Source is the range of the source covered by the content of the node, if known
+	Source *SourceRange `json:"source,omitempty"`
+	// Children are the child nodes in order
+	Children []ASTNode `json:"children,omitempty"`
+}
+
+// SourceRange is a range of byte offsets into the source, with Stop being exclusive.
+type SourceRange struct {
+	Start int `json:"start"`
+	Stop  int `json:"stop"`
+}
+
+// PrintASTJSON writes the AST of a Markdown document to the specified writer as indented JSON, for
+// tools that consume the tree rather than reading it. UnmarshalASTJSON reads it back.
+func PrintASTJSON(w io.Writer, source []byte, n ast.Node) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(newASTNode(source, n))
+}
+
+// newASTNode returns the JSON form of n and its children.
+func newASTNode(source []byte, n ast.Node) ASTNode {
+	node := ASTNode{
+		Kind:       n.Kind().String(),
+		Properties: map[string]any{},
+		Text:       nodeText(source, n),
+	}
+	for _, attr := range n.Attributes() {
+		value := attr.Value
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		if node.Attributes == nil {
+			node.Attributes = map[string]any{}
+		}
+		node.Attributes[string(attr.Name)] = value
+	}
+
+	switch n := n.(type) {
+	case *ast.Text:
+		if n.SoftLineBreak() {
+			node.Properties["softLineBreak"] = true
+		}
+		if n.HardLineBreak() {
+			node.Properties["hardLineBreak"] = true
+		}
+	case *ast.Link:
+		node.Properties["destination"] = string(n.Destination)
+		node.Properties["title"] = string(n.Title)
+	case *ast.Image:
+		node.Properties["destination"] = string(n.Destination)
+		node.Properties["title"] = string(n.Title)
+	case *ast.AutoLink:
+		node.Properties["url"] = string(n.URL(source))
+		node.Properties["label"] = string(n.Label(source))
+		if n.AutoLinkType == ast.AutoLinkEmail {
+			node.Properties["email"] = true
+		}
+		// Autolinks found by extensions like Linkify aren't surrounded by angle brackets
+		if start, ok := sourceOffset(source, n.Label(source)); ok && start > 0 && source[start-1] == '<' {
+			node.Properties["bracketed"] = true
+		}
+	case *ast.Heading:
+		node.Properties["level"] = n.Level
+	case *ast.Emphasis:
+		node.Properties["level"] = n.Level
+	case *ast.List:
+		node.Properties["marker"] = string(n.Marker)
+		node.Properties["tight"] = n.IsTight
+		if n.IsOrdered() {
+			node.Properties["start"] = n.Start
+		}
+	case *ast.ListItem:
+		node.Properties["offset"] = n.Offset
+	case *ast.FencedCodeBlock:
+		if n.Info != nil {
+			node.Properties["info"] = string(n.Info.Value(source))
+		}
+	case *ast.HTMLBlock:
+		node.Properties["type"] = int(n.HTMLBlockType)
+	case *east.Table:
+		alignments := make([]string, len(n.Alignments))
+		for i, alignment := range n.Alignments {
+			alignments[i] = alignment.String()
+		}
+		node.Properties["alignments"] = alignments
+	case *east.TableCell:
+		node.Properties["alignment"] = n.Alignment.String()
+	case *east.TaskCheckBox:
+		node.Properties["checked"] = n.IsChecked
+	}
+	if n.Type() == ast.TypeBlock && n.HasBlankPreviousLines() {
+		node.Properties["blankPreviousLines"] = true
+	}
+	if len(node.Properties) == 0 {
+		node.Properties = nil
+	}
+
+	if start, stop, ok := sourceRange(n); ok {
+		node.Source = &SourceRange{Start: start, Stop: stop}
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		node.Children = append(node.Children, newASTNode(source, c))
+	}
+	return node
+}
+
+// dotLabelLength is the number of characters of text shown in a node label by PrintASTDOT.
+const dotLabelLength = 20
+
+// dotEscaper escapes text for a quoted DOT string.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+// PrintASTDOT writes the AST of a Markdown document to the specified writer as a Graphviz DOT graph,
+// with each node labeled by its kind and the start of its text.
+func PrintASTDOT(w io.Writer, source []byte, n ast.Node) error {
+	var b strings.Builder
+	b.WriteString("digraph AST {\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	id := 0
+	var writeNode func(n ast.Node) int
+	writeNode = func(n ast.Node) int {
+		nodeID := id
+		id++
+		label := n.Kind().String()
+		if text := []rune(nodeText(source, n)); len(text) > 0 {
+			if len(text) > dotLabelLength {
+				text = append(text[:dotLabelLength], '…')
+			}
+			label += "\n" + string(text)
+		}
+		fmt.Fprintf(&b, "  n%d [label=\"%s\"];\n", nodeID, dotEscaper.Replace(label))
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			childID := writeNode(c)
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", nodeID, childID)
+		}
+		return nodeID
+	}
+	writeNode(n)
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// nodeText returns the text of text nodes, and the content of code and HTML blocks.
+func nodeText(source []byte, n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Text:
+		return string(n.Value(source))
+	case *ast.String:
+		return string(n.Value)
+	case *ast.RawHTML:
+		return string(n.Segments.Value(source))
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		return string(n.Lines().Value(source))
+	case *ast.HTMLBlock:
+		text := string(n.Lines().Value(source))
+		if n.HasClosure() {
+			text += string(n.ClosureLine.Value(source))
+		}
+		return text
+	}
+	return ""
+}
+
+// sourceRange returns the range of the source covered by the content of n, from its text or lines,
+// or those of its descendants.
+func sourceRange(n ast.Node) (start, stop int, ok bool) {
+	switch n := n.(type) {
+	case *ast.Text:
+		return n.Segment.Start, n.Segment.Stop, true
+	case *ast.RawHTML:
+		if n.Segments.Len() > 0 {
+			return n.Segments.At(0).Start, n.Segments.At(n.Segments.Len() - 1).Stop, true
+		}
+	}
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		lines := n.Lines()
+		stop = lines.At(lines.Len() - 1).Stop
+		if html, ok := n.(*ast.HTMLBlock); ok && html.HasClosure() {
+			stop = html.ClosureLine.Stop
+		}
+		return lines.At(0).Start, stop, true
+	}
+	// Use the first and last children that have a range
+	found := false
+	for c := n.FirstChild(); c != nil && !found; c = c.NextSibling() {
+		start, _, found = sourceRange(c)
+	}
+	if !found {
+		return 0, 0, false
+	}
+	for c := n.LastChild(); c != nil; c = c.PreviousSibling() {
+		if _, stop, ok = sourceRange(c); ok {
+			break
+		}
+	}
+	return start, stop, true
 }
 
 // printASTNode prints a single AST node and its children recursively with visual tree structure
-func printASTNode(w io.Writer, source []byte, n ast.Node, level int, prefix string) error {
+func printASTNode(w io.Writer, source []byte, n ast.Node, level int, prefix string, config *printASTConfig) error {
+	if !config.shows(n.Kind()) {
+		// Print the children in place of the node
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if err := printASTNode(w, source, c, level, prefix, config); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Create the appropriate prefix for this level
 	var currentPrefix string
 	if level > 0 {
@@ -54,82 +480,109 @@ func printASTNode(w io.Writer, source []byte, n ast.Node, level int, prefix stri
 		nodeName = nodeName[idx+1:]
 	}
 
-	fmt.Fprintf(w, "%s%s", prefix+currentPrefix, nodeName)
+	fmt.Fprintf(w, "%s%s", prefix+currentPrefix, config.paint(astColorKind, nodeName))
 
-	// Print additional attributes based on node type
-	switch n := n.(type) {
-	case *ast.Text:
-		fmt.Fprintf(w, " [%q]", n.Value(source))
-	case *ast.String:
-		fmt.Fprintf(w, " [%q]", n.Value)
-	case *ast.RawHTML:
-		fmt.Fprintf(w, " [HTML]")
-		// Print HTML content
-		if n.Segments.Len() > 0 {
-			fmt.Fprintf(w, " Content:")
-			for i := 0; i < n.Segments.Len(); i++ {
-				segment := n.Segments.At(i)
-				fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)), segment.Value(source))
-			}
+	if config.positions {
+		if start, stop, ok := sourceRange(n); ok && stop <= len(source) {
+			config.fprintf(w, astColorPosition, " @%d-%d (%s-%s)", start, stop, offsetPosition(source, start),
+				offsetPosition(source, stop))
 		}
-	case *ast.Link:
-		fmt.Fprintf(w, " [%s]", n.Destination)
-	case *ast.Image:
-		fmt.Fprintf(w, " [%s]", n.Destination)
-	case *ast.Heading:
-		fmt.Fprintf(w, " [Level=%d]", n.Level)
-	case *ast.ListItem:
-		fmt.Fprintf(w, " [%d]", n.Offset)
-	case *ast.List:
-		fmt.Fprintf(w, " [Tight=%t]", n.IsTight)
-		if n.IsOrdered() {
-			fmt.Fprintf(w, " [Ordered start=%d]", n.Start)
-		} else {
-			fmt.Fprintf(w, " [Bullet]")
+	}
+
+	// Print additional attributes based on node type, using the describer for the kind if any
+	if describer := config.describer(n.Kind()); describer != nil {
+		if details := describer(source, n); details != "" {
+			config.fprintf(w, astColorAttribute, " [%s]", details)
 		}
-	case *ast.CodeSpan:
-		fmt.Fprintf(w, " [Code]")
-	case *ast.Emphasis:
-		fmt.Fprintf(w, " [Level=%d]", n.Level)
-	case *ast.FencedCodeBlock:
-		if n.Info != nil {
-			fmt.Fprintf(w, " [Lang=%s]", n.Info.Value(source))
-		}
-		// Print code content
-		if n.Lines().Len() > 0 {
-			fmt.Fprintf(w, " Content:")
-			for i := 0; i < n.Lines().Len(); i++ {
-				line := n.Lines().At(i)
-				fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)), line.Value(source))
+	} else {
+		switch n := n.(type) {
+		case *ast.Text:
+			config.fprintf(w, astColorText, " [%q]", config.truncate(n.Value(source)))
+		case *ast.String:
+			config.fprintf(w, astColorText, " [%q]", config.truncate(n.Value))
+		case *ast.RawHTML:
+			config.fprintf(w, astColorAttribute, " [HTML]")
+			// Print HTML content
+			if n.Segments.Len() > 0 {
+				config.fprintf(w, astColorAttribute, " Content:")
+				for i := 0; i < n.Segments.Len(); i++ {
+					segment := n.Segments.At(i)
+					fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)),
+						config.paint(astColorText, config.truncate(segment.Value(source))))
+				}
 			}
-		}
-	case *ast.CodeBlock:
-		// Print code content
-		if n.Lines().Len() > 0 {
-			fmt.Fprintf(w, " Content:")
-			for i := 0; i < n.Lines().Len(); i++ {
-				line := n.Lines().At(i)
-				fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)), line.Value(source))
+		case *ast.Link:
+			config.fprintf(w, astColorAttribute, " [%s]", n.Destination)
+		case *ast.Image:
+			config.fprintf(w, astColorAttribute, " [%s]", n.Destination)
+		case *ast.Heading:
+			config.fprintf(w, astColorAttribute, " [Level=%d]", n.Level)
+		case *ast.ListItem:
+			config.fprintf(w, astColorAttribute, " [%d]", n.Offset)
+		case *ast.List:
+			config.fprintf(w, astColorAttribute, " [Tight=%t]", n.IsTight)
+			if n.IsOrdered() {
+				config.fprintf(w, astColorAttribute, " [Ordered start=%d]", n.Start)
+			} else {
+				config.fprintf(w, astColorAttribute, " [Bullet]")
 			}
-		}
-	case *east.Table:
-		fmt.Fprintf(w, " [Table]")
-	case *east.TableHeader:
-		fmt.Fprintf(w, " [Header Row]")
-	case *east.TableRow:
-		fmt.Fprintf(w, " [Row]")
-	case *east.TableCell:
-		fmt.Fprintf(w, " [Cell]")
-	case *ast.HTMLBlock:
-		fmt.Fprintf(w, " [HTMLBlock]")
-		// Print HTML block content
-		if n.Lines().Len() > 0 {
-			fmt.Fprintf(w, " Content:")
-			for i := 0; i < n.Lines().Len(); i++ {
-				line := n.Lines().At(i)
-				fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)), line.Value(source))
+		case *ast.CodeSpan:
+			config.fprintf(w, astColorAttribute, " [Code]")
+		case *ast.Emphasis:
+			config.fprintf(w, astColorAttribute, " [Level=%d]", n.Level)
+		case *ast.FencedCodeBlock:
+			if n.Info != nil {
+				config.fprintf(w, astColorAttribute, " [Lang=%s]", n.Info.Value(source))
+			}
+			// Print code content
+			if n.Lines().Len() > 0 {
+				config.fprintf(w, astColorAttribute, " Content:")
+				for i := 0; i < n.Lines().Len(); i++ {
+					line := n.Lines().At(i)
+					fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)),
+						config.paint(astColorText, config.truncate(line.Value(source))))
+				}
 			}
+		case *ast.CodeBlock:
+			// Print code content
+			if n.Lines().Len() > 0 {
+				config.fprintf(w, astColorAttribute, " Content:")
+				for i := 0; i < n.Lines().Len(); i++ {
+					line := n.Lines().At(i)
+					fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)),
+						config.paint(astColorText, config.truncate(line.Value(source))))
+				}
+			}
+		case *east.Table:
+			config.fprintf(w, astColorAttribute, " [Table]")
+		case *east.TableHeader:
+			config.fprintf(w, astColorAttribute, " [Header Row]")
+		case *east.TableRow:
+			config.fprintf(w, astColorAttribute, " [Row]")
+		case *east.TableCell:
+			config.fprintf(w, astColorAttribute, " [Cell]")
+		case *east.TaskCheckBox:
+			config.fprintf(w, astColorAttribute, " [Checked=%t]", n.IsChecked)
+		case *ast.HTMLBlock:
+			config.fprintf(w, astColorAttribute, " [HTMLBlock]")
+			// Print HTML block content
+			if n.Lines().Len() > 0 {
+				config.fprintf(w, astColorAttribute, " Content:")
+				for i := 0; i < n.Lines().Len(); i++ {
+					line := n.Lines().At(i)
+					fmt.Fprintf(w, "\n%s%s  |%s", prefix+currentPrefix, strings.Repeat(" ", len(nodeName)),
+						config.paint(astColorText, config.truncate(line.Value(source))))
+				}
+			}
+		}
+	}
+
+	if config.maxDepth > 0 && level >= config.maxDepth {
+		if count := n.ChildCount(); count > 0 {
+			config.fprintf(w, astColorAttribute, " [+%d children]", count)
 		}
+		fmt.Fprintln(w)
+		return nil
 	}
 
 	fmt.Fprintln(w)
@@ -159,7 +612,7 @@ func printASTNode(w io.Writer, source []byte, n ast.Node, level int, prefix stri
 			}
 		}
 
-		if err := printASTNode(w, source, c, level+1, newPrefix); err != nil {
+		if err := printASTNode(w, source, c, level+1, newPrefix, config); err != nil {
 			return err
 		}
 
@@ -0,0 +1,93 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ValidationError is returned by Render when WithValidation is enabled and the rendered markdown
+// doesn't parse into the constructs of the source in the configured Dialect, as with strikethrough,
+// which no dialect recognizes.
+type ValidationError struct {
+	// Dialect is the dialect the rendered markdown was parsed in
+	Dialect Dialect
+	// Missing is the number of nodes of each kind that the rendered markdown has fewer of than the
+	// source
+	Missing map[ast.NodeKind]int
+}
+
+// Error implements error.Error
+func (e *ValidationError) Error() string {
+	kinds := make([]ast.NodeKind, 0, len(e.Missing))
+	for kind := range e.Missing {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+	missing := make([]string, len(kinds))
+	for i, kind := range kinds {
+		missing[i] = fmt.Sprintf("%d %s", e.Missing[kind], kind)
+	}
+	dialect := fmt.Sprint(int(e.Dialect))
+	for name, d := range DialectNames {
+		if d == e.Dialect {
+			dialect = name
+		}
+	}
+	return fmt.Sprintf("rendered markdown loses %s when parsed in the %s dialect", strings.Join(missing, ", "), dialect)
+}
+
+// unvalidatedKinds are the kinds of nodes that aren't written as constructs of their own, so their
+// number may change without anything being lost.
+var unvalidatedKinds = map[ast.NodeKind]bool{
+	ast.KindDocument:          true,
+	ast.KindText:              true,
+	ast.KindString:            true,
+	east.KindFootnoteList:     true,
+	east.KindFootnoteBacklink: true,
+}
+
+// validate reparses output, the rendering of n, with a strict parser for the Dialect and returns a
+// *ValidationError if it has fewer nodes of any kind than n.
+func (r *Renderer) validate(n ast.Node, output []byte) error {
+	expected := r.countKinds(n, r.rc.source)
+	found := r.countKinds(dialectParser(r.config).Parse(text.NewReader(output)), output)
+	missing := map[ast.NodeKind]int{}
+	for kind, count := range expected {
+		if count > found[kind] {
+			missing[kind] = count - found[kind]
+		}
+	}
+	if len(missing) > 0 {
+		return &ValidationError{Dialect: r.config.Dialect, Missing: missing}
+	}
+	return nil
+}
+
+// countKinds returns the number of rendered nodes of each kind in the tree of n, parsed from source.
+func (r *Renderer) countKinds(n ast.Node, source []byte) map[ast.NodeKind]int {
+	counts := map[ast.NodeKind]int{}
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if r.config.HTMLComments == HTMLCommentsStrip && isHTMLComment(n, source) {
+			return ast.WalkSkipChildren, nil
+		}
+		if entering && !unvalidatedKinds[n.Kind()] {
+			counts[n.Kind()]++
+		}
+		return ast.WalkContinue, nil
+	})
+	return counts
+}
+
+// dialectParser returns a parser of CommonMark and nothing but the syntax extensions that c enables.
+func dialectParser(c *Config) parser.Parser {
+	r := NewRenderer(WithDialect(c.Dialect), WithHugoShortcodes(c.HugoShortcodes), WithLiquidTags(c.LiquidTags),
+		WithMathBlocks(c.MathBlocks), WithImageAttributes(c.ImageAttributes), WithLinkStyle(c.LinkStyle))
+	return goldmark.New(goldmark.WithExtensions(r)).Parser()
+}
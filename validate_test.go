@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+func TestValidation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dialect Dialect
+		source  string
+		missing map[ast.NodeKind]int
+	}{
+		{
+			"Valid",
+			DialectCommonMark,
+			"# Title\n\nSome *text*, `code` and <b>HTML</b>.\n\n| a | b |\n|---|---|\n| 1 | 2 |\n",
+			nil,
+		},
+		{
+			"Strikethrough",
+			DialectCommonMark,
+			"Some ~~old~~ text and ~~more~~.\n",
+			map[ast.NodeKind]int{east.KindStrikethrough: 2},
+		},
+		{
+			"Task list",
+			DialectMkDocs,
+			"* [x] done\n",
+			map[ast.NodeKind]int{east.KindTaskCheckBox: 1},
+		},
+		{
+			"Footnotes in CommonMark",
+			DialectCommonMark,
+			"Text[^1].\n\n[^1]: Note.\n",
+			map[ast.NodeKind]int{east.KindFootnoteLink: 1, east.KindFootnote: 1, ast.KindParagraph: 1},
+		},
+		{
+			"Footnotes in pandoc",
+			DialectPandoc,
+			"Text[^1].\n\n[^1]: Note.\n",
+			nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRenderer(WithDialect(tc.dialect), WithValidation(true))
+			md := goldmark.New(goldmark.WithRenderer(r), goldmark.WithExtensions(extension.GFM, extension.Footnote, r))
+			buf := bytes.Buffer{}
+			err := md.Convert([]byte(tc.source), &buf)
+			if tc.missing == nil {
+				require.NoError(t, err)
+				assert.NotEmpty(t, buf.String())
+				return
+			}
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr), "expected a ValidationError, got %v", err)
+			assert.Equal(t, tc.dialect, validationErr.Dialect)
+			assert.Equal(t, tc.missing, validationErr.Missing)
+			assert.Empty(t, buf.String(), "invalid output must not be written")
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{
+		Dialect: DialectCommonMark,
+		Missing: map[ast.NodeKind]int{east.KindTaskCheckBox: 1, east.KindStrikethrough: 2},
+	}
+	assert.Equal(t, "rendered markdown loses 2 Strikethrough, 1 TaskCheckBox when parsed in the commonmark dialect", err.Error())
+}
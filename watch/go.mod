@@ -0,0 +1,19 @@
+module github.com/teekennedy/goldmark-markdown/watch
+
+go 1.22
+
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/stretchr/testify v1.7.0
+	github.com/teekennedy/goldmark-markdown v0.0.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/yuin/goldmark v1.7.8 // indirect
+	golang.org/x/sys v0.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0 // indirect
+)
+
+replace github.com/teekennedy/goldmark-markdown => ..
@@ -0,0 +1,166 @@
+// Package watch renders markdown files again each time they change, for tools built on the markdown
+// renderer such as live previews of formatting or translations.
+//
+// Changes are found through the file system notifications of the platform, with fsnotify. As those
+// aren't recursive, each directory under the watched paths is watched, along with the directories
+// holding the watched files, so files replaced by editors are followed too. The package is a module
+// of its own, so that the renderer doesn't depend on fsnotify.
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	markdown "github.com/teekennedy/goldmark-markdown"
+	"github.com/teekennedy/goldmark-markdown/internal/walk"
+)
+
+// DefaultDelay is the time a Watcher that doesn't set one waits for changes to settle.
+const DefaultDelay = 100 * time.Millisecond
+
+// Result is the result of rendering a file.
+type Result struct {
+	// Path is the path of the file
+	Path string
+	// Output is the rendered file, if there's no error
+	Output []byte
+	// Err is the error that prevented reading or rendering the file
+	Err error
+}
+
+// Watcher renders markdown files with the markdown renderer each time they change.
+type Watcher struct {
+	// Paths are the files and directories to watch. Directories are searched recursively for
+	// markdown files, leaving out those ignored by .gitignore and .mdignore files like the commands
+	// of this module do, and files added to them later are watched too.
+	Paths []string
+	// Options are the options files are rendered with, as by markdown.FormatFile, such as
+	// markdown.WithTextTransformer to translate them
+	Options []markdown.Option
+	// Delay is the time to wait after a change for more before rendering, so that the several
+	// notifications of saving a file render it once, DefaultDelay if not positive
+	Delay time.Duration
+	// Callback is called with the result of each rendering, one at a time
+	Callback func(Result)
+}
+
+// Watch renders each file once, then again each time it's written or created, until ctx is done,
+// returning ctx.Err(). It returns an error without rendering any file if the paths can't be searched
+// or watched initially, while paths that are missing later, e.g. while an editor replaces a file,
+// are skipped until they're back. If notifications are lost, every file is rendered again.
+func (w *Watcher) Watch(ctx context.Context) error {
+	files, err := walk.Files(w.Paths, walk.Options{})
+	if err != nil {
+		return err
+	}
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer notifier.Close()
+	if err := w.watchDirs(notifier); err != nil {
+		return err
+	}
+	delay := w.Delay
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	for _, path := range files {
+		w.render(path)
+	}
+
+	// The paths of the files and directories that changed since the last rendering
+	changed := map[string]bool{}
+	timer := time.NewTimer(delay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-notifier.Events:
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Files may have been added before the directory was watched
+					_ = w.watchDirs(notifier)
+				}
+			}
+			changed[filepath.Clean(event.Name)] = true
+			timer.Reset(delay)
+		case <-notifier.Errors:
+			// Notifications were lost, such as when the queue of the platform overflowed
+			for _, root := range w.Paths {
+				changed[filepath.Clean(root)] = true
+			}
+			timer.Reset(delay)
+		case <-timer.C:
+			w.renderChanged(changed)
+			changed = map[string]bool{}
+		}
+	}
+}
+
+// watchDirs adds the directories under the paths of w, and those holding the paths that are files,
+// to notifier. Adding a directory again has no effect.
+func (w *Watcher) watchDirs(notifier *fsnotify.Watcher) error {
+	for _, root := range w.Paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		dirs := []string{filepath.Dir(root)}
+		if info.IsDir() {
+			if dirs, err = walk.Dirs([]string{root}, walk.Options{}); err != nil {
+				return err
+			}
+		}
+		for _, dir := range dirs {
+			if err := notifier.Add(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderChanged renders the files that are changed or under a changed directory, in the order they
+// are found in.
+func (w *Watcher) renderChanged(changed map[string]bool) {
+	for _, root := range w.Paths {
+		files, err := walk.Files([]string{root}, walk.Options{})
+		if err != nil {
+			continue
+		}
+		for _, path := range files {
+			if underChanged(filepath.Clean(path), changed) {
+				w.render(path)
+			}
+		}
+	}
+}
+
+// underChanged reports whether path or any of the directories holding it is in changed.
+func underChanged(path string, changed map[string]bool) bool {
+	for {
+		if changed[path] {
+			return true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+// render renders the file at path and passes the result to the Callback.
+func (w *Watcher) render(path string) {
+	output, err := markdown.FormatFile(path, w.Options...)
+	w.Callback(Result{Path: path, Output: output, Err: err})
+}
@@ -0,0 +1,68 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	markdown "github.com/teekennedy/goldmark-markdown"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.md")
+	require.NoError(t, os.WriteFile(a, []byte("Hello\n===\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("Not markdown\n"), 0o644))
+
+	results := make(chan Result)
+	w := &Watcher{
+		Paths:    []string{dir},
+		Options:  []markdown.Option{markdown.WithTextTransformer(markdown.MapTransformer{"Hello": "Bonjour"})},
+		Delay:    10 * time.Millisecond,
+		Callback: func(result Result) { results <- result },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Watch(ctx) }()
+
+	next := func() Result {
+		select {
+		case result := <-results:
+			return result
+		case <-time.After(5 * time.Second):
+			t.Fatal("no result")
+			return Result{}
+		}
+	}
+	assert.Equal(t, Result{Path: a, Output: []byte("# Bonjour\n")}, next())
+
+	// Changes of files and new files are rendered
+	require.NoError(t, os.WriteFile(a, []byte("Hello *world*\n"), 0o644))
+	assert.Equal(t, Result{Path: a, Output: []byte("Bonjour *world*\n")}, next())
+	b := filepath.Join(dir, "b.md")
+	require.NoError(t, os.WriteFile(b, []byte("* Hello\n"), 0o644))
+	assert.Equal(t, Result{Path: b, Output: []byte("* Bonjour\n")}, next())
+
+	// So are the files of new directories, but not those that are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("build/\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "build"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "build", "out.md"), []byte("Hello\n"), 0o644))
+	c := filepath.Join(dir, "docs", "c.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(c), 0o755))
+	require.NoError(t, os.WriteFile(c, []byte("> Hello\n"), 0o644))
+	assert.Equal(t, Result{Path: c, Output: []byte("> Bonjour\n")}, next())
+	require.NoError(t, os.WriteFile(c, []byte("## Hello\n"), 0o644))
+	assert.Equal(t, Result{Path: c, Output: []byte("## Bonjour\n")}, next())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchMissingPath(t *testing.T) {
+	w := &Watcher{Paths: []string{filepath.Join(t.TempDir(), "missing.md")}, Callback: func(Result) {}}
+	assert.ErrorIs(t, w.Watch(context.Background()), os.ErrNotExist)
+}
@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// collapseWhitespace returns text with each run of spaces and tabs replaced by a single space. If
+// normalize is true, other Unicode spaces, such as non-breaking and ideographic spaces, are replaced
+// too. Line breaks are kept.
+func collapseWhitespace(text string, normalize bool) string {
+	collapsed := strings.Builder{}
+	space := false
+	for _, c := range text {
+		if c == ' ' || c == '\t' || (normalize && c != '\n' && unicode.Is(unicode.Zs, c)) {
+			if !space {
+				collapsed.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		collapsed.WriteRune(c)
+		space = false
+	}
+	return collapsed.String()
+}
+
+// spaceCJK returns text with a space between each CJK character and a Latin letter or digit next to
+// it, as pangu does, or with the spaces between them removed if add is false.
+func spaceCJK(text string, add bool) string {
+	runes := []rune(text)
+	spaced := strings.Builder{}
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if i > 0 && add && isCJKPair(runes[i-1], c) {
+			spaced.WriteByte(' ')
+		}
+		if c == ' ' && !add && i > 0 {
+			// Skip the run of spaces if it separates CJK from Latin
+			end := i
+			for end < len(runes) && runes[end] == ' ' {
+				end++
+			}
+			if end < len(runes) && isCJKPair(runes[i-1], runes[end]) {
+				i = end - 1
+				continue
+			}
+		}
+		spaced.WriteRune(c)
+	}
+	return spaced.String()
+}
+
+// isCJKPair returns true if one of a and b is a CJK character and the other is a Latin letter or
+// digit.
+func isCJKPair(a, b rune) bool {
+	return (isCJK(a) && isLatinOrDigit(b)) || (isLatinOrDigit(a) && isCJK(b))
+}
+
+// isCJK returns true if r is a Chinese or Japanese character, leaving out punctuation, which isn't
+// spaced.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Bopomofo)
+}
+
+// isLatinOrDigit returns true if r is a Latin letter or an ASCII digit.
+func isLatinOrDigit(r rune) bool {
+	return unicode.Is(unicode.Latin, r) || (r >= '0' && r <= '9')
+}
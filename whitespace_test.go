@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+)
+
+func TestWhitespace(t *testing.T) {
+	source := "Some  messy\u00a0\u00a0text,\u3000pasted  \t here  *and  there*\nacross  lines  `code  span`\n\n    indented  code\n\n<b>html  kept</b> [a  link](u  \"t  t\")  \n"
+	testCases := []struct {
+		name       string
+		whitespace Whitespace
+		expected   string
+	}{
+		{
+			"Preserve",
+			WhitespacePreserve,
+			"Some  messy\u00a0\u00a0text,\u3000pasted  \t here  *and  there*\nacross  lines  `code  span`\n\n    indented  code\n\n<b>html  kept</b> [a  link](u \"t  t\")\n",
+		},
+		{
+			"Collapse",
+			WhitespaceCollapse,
+			"Some messy\u00a0\u00a0text,\u3000pasted here *and there*\nacross lines `code  span`\n\n    indented  code\n\n<b>html kept</b> [a link](u \"t  t\")\n",
+		},
+		{
+			"Normalize",
+			WhitespaceNormalize,
+			"Some messy text, pasted here *and there*\nacross lines `code  span`\n\n    indented  code\n\n<b>html kept</b> [a link](u \"t  t\")\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithWhitespace(tc.whitespace))))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestCJKSpacing(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spacing  CJKSpacing
+		source   string
+		expected string
+	}{
+		{
+			"Add",
+			CJKSpacingAdd,
+			"使用Go 1.22编译，运行`go build`命令。\n\n在Markdown中使用[链接](https://example.com/中文)和 API。\n",
+			"使用 Go 1.22 编译，运行`go build`命令。\n\n在 Markdown 中使用[链接](https://example.com/中文)和 API。\n",
+		},
+		{
+			"Remove",
+			CJKSpacingRemove,
+			"使用 Go  1.22 编译，运行 `go build` 命令。\n\nこれは Test です。Hello world.\n",
+			"使用Go  1.22编译，运行 `go build` 命令。\n\nこれはTestです。Hello world.\n",
+		},
+		{
+			"Preserve",
+			CJKSpacingPreserve,
+			"使用Go 1.22 编译\n",
+			"使用Go 1.22 编译\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithCJKSpacing(tc.spacing))))
+			buf := bytes.Buffer{}
+			assert.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"héllo", 5},
+		{"é", 1},
+		{"你好", 4},
+		{"한국어", 6},
+		{"ｆｕｌｌ", 8},
+		{"a🙂b", 4},
+		{"zero​width", 9},
+		{"\xff", 1},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, displayWidth([]byte(tc.text)), "%q", tc.text)
+	}
+}
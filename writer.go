@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark/util"
 )
@@ -31,6 +32,20 @@ type markdownWriter struct {
 	prefixes []linePrefix
 	// line is the current line number
 	line int
+	// written is the number of bytes written to output
+	written int
+	// widest is the width of the widest line flushed since the last call to StartMeasure
+	widest int
+	// measureStart is the offset into the current line where measuring started
+	measureStart int
+	// verbatim indicates whether trailing whitespace written to lines is kept
+	verbatim bool
+	// wrap indicates whether text written from now on can be wrapped at the configured line width
+	wrap bool
+	// noBreak is the number of unbreakable spans being written, within which lines aren't wrapped
+	noBreak int
+	// breaks holds the offsets into the buffer where lines can be wrapped, in order
+	breaks []int
 	// err holds the last write error. If non-nil, all write operations become no-ops
 	err error
 }
@@ -54,6 +69,13 @@ func (m *markdownWriter) Reset(w io.Writer) {
 	m.output = w
 	m.prefixes = make([]linePrefix, 0)
 	m.line = 0
+	m.written = 0
+	m.widest = 0
+	m.measureStart = 0
+	m.verbatim = false
+	m.wrap = false
+	m.noBreak = 0
+	m.breaks = m.breaks[:0]
 	m.err = nil
 }
 
@@ -78,6 +100,102 @@ func (m *markdownWriter) EndLine() {
 	_, _ = m.Write([]byte{lineDelim})
 }
 
+// AtLineStart returns true if nothing has been written to the current line.
+func (m *markdownWriter) AtLineStart() bool {
+	return m.buf.Len() == 0
+}
+
+// InsertAt inserts data at the given offset into the current line. data must not contain line
+// delimiters.
+func (m *markdownWriter) InsertAt(offset int, data []byte) {
+	m.ReplaceAt(offset, 0, data)
+}
+
+// ReplaceAt replaces n bytes at the given offset into the current line with data. data must not
+// contain line delimiters.
+func (m *markdownWriter) ReplaceAt(offset, n int, data []byte) {
+	line := bytes.Clone(m.buf.Bytes())
+	m.buf.Reset()
+	m.buf.Write(line[:offset])
+	m.buf.Write(data)
+	m.buf.Write(line[offset+n:])
+	// Move the breaks after the replaced bytes along with them
+	breaks := m.breaks[:0]
+	for _, b := range m.breaks {
+		if b >= offset+n {
+			breaks = append(breaks, b+len(data)-n)
+		} else if b < offset {
+			breaks = append(breaks, b)
+		}
+	}
+	m.breaks = breaks
+}
+
+// writerPosition locates a byte in the output of a markdownWriter.
+type writerPosition struct {
+	writer       *markdownWriter
+	line, offset int
+}
+
+// Position returns the position of the next byte written to the current line.
+func (m *markdownWriter) Position() writerPosition {
+	return writerPosition{writer: m, line: m.line, offset: m.buf.Len()}
+}
+
+// Buffer returns the bytes of the current line preceding pos, and the bytes from pos on. ok is false
+// if pos isn't in the current line.
+func (m *markdownWriter) Buffer(pos writerPosition) (before, after []byte, ok bool) {
+	if pos.writer != m || pos.line != m.line || pos.offset > m.buf.Len() {
+		return nil, nil, false
+	}
+	return m.buf.Bytes()[:pos.offset], m.buf.Bytes()[pos.offset:], true
+}
+
+// StartMeasure starts measuring the width of lines written from this point, excluding prefixes.
+func (m *markdownWriter) StartMeasure() {
+	m.widest = 0
+	m.measureStart = m.buf.Len()
+}
+
+// Measure returns the width of the widest line written since the last call to StartMeasure,
+// including the current line.
+func (m *markdownWriter) Measure() int {
+	return max(m.widest, m.lineWidth(m.buf.Bytes()[m.measureStart:]))
+}
+
+// lineWidth returns the display width of the given line, ignoring trailing whitespace.
+func (m *markdownWriter) lineWidth(line []byte) int {
+	return displayWidth(bytes.TrimRightFunc(line, unicode.IsSpace))
+}
+
+// SetVerbatim sets whether trailing whitespace is kept on lines written from now on. Whitespace-only
+// prefixes are still trimmed from otherwise empty lines.
+func (m *markdownWriter) SetVerbatim(verbatim bool) {
+	m.verbatim = verbatim
+}
+
+// SetWrap sets whether text written from now on can be wrapped at the configured line width, at its
+// spaces and between characters of scripts that don't separate words with spaces.
+func (m *markdownWriter) SetWrap(wrap bool) {
+	m.wrap = wrap
+}
+
+// Wrapping returns true if lines written from now on are wrapped at the configured line width.
+func (m *markdownWriter) Wrapping() bool {
+	return m.wrap && m.config.LineWidth > 0
+}
+
+// BeginNoBreak starts a span of text within which lines aren't wrapped, such as a code span or a
+// link. Spans are ended by EndNoBreak, and can be nested.
+func (m *markdownWriter) BeginNoBreak() {
+	m.noBreak++
+}
+
+// EndNoBreak ends the span started by the last call to BeginNoBreak.
+func (m *markdownWriter) EndNoBreak() {
+	m.noBreak--
+}
+
 // PushPrefix adds the given bytes as a prefix for lines written to the output. The prefix
 // will be added to the current line and all subsequent lines by default, but can optionally be
 // given a start line relative to the current line, and an end line relative to the start line.
@@ -110,35 +228,124 @@ func (m *markdownWriter) WriteBytes(data []byte) (n int) {
 	if m.err != nil {
 		return 0
 	}
+	if m.Wrapping() && m.noBreak == 0 {
+		m.addBreaks(data)
+	}
 	// Writing to a bytes.Buffer always returns a nil error
 	n, _ = m.buf.Write(data)
-	prefixedLine := bytes.Buffer{}
 	for bytes.Contains(m.buf.Bytes(), []byte{lineDelim}) {
 		// err will only be non-nil if lineDelim is not in m.buf, which we already checked for.
 		line, _ := m.buf.ReadBytes(lineDelim)
-		// build the prefix for the line
-		for _, prefix := range m.prefixes {
-			if prefix.startLine <= m.line && (prefix.endLine == -1 || m.line <= prefix.endLine) {
-				prefixedLine.Write(prefix.bytes)
+		m.widest = max(m.widest, m.lineWidth(line[min(m.measureStart, len(line)):]))
+		m.measureStart = 0
+		var breaks []int
+		for len(m.breaks) > 0 && m.breaks[0] < len(line) {
+			breaks = append(breaks, m.breaks[0])
+			m.breaks = m.breaks[1:]
+		}
+		for i := range m.breaks {
+			m.breaks[i] -= len(line)
+		}
+		for continued := false; m.err == nil && len(breaks) > 0; continued = true {
+			var wrapped []byte
+			if wrapped, line, breaks = m.wrapLine(line, breaks, continued); wrapped == nil {
+				break
 			}
+			m.writeLine(append(wrapped, lineDelim))
 		}
-		prefixedLine.Write(line)
-		// trim whitespace off the end of the line
-		trimmedSlice := bytes.TrimRightFunc(prefixedLine.Bytes(), unicode.IsSpace)
-		prefixedLine.Truncate(len(trimmedSlice))
-		prefixedLine.WriteByte(lineDelim)
-
-		_, err := m.output.Write(prefixedLine.Bytes())
-		if err != nil {
-			m.err = err
+		if m.writeLine(line); m.err != nil {
 			return 0
 		}
-		m.line += 1
-		prefixedLine.Reset()
 	}
 	return n
 }
 
+// addBreaks records where lines can be wrapped in data, which is about to be written to the buffer.
+func (m *markdownWriter) addBreaks(data []byte) {
+	offset := m.buf.Len()
+	prev, _ := utf8.DecodeLastRune(m.buf.Bytes())
+	for i, c := range string(data) {
+		if c == ' ' || isSpacelessScript(prev) && isSpacelessScript(c) {
+			m.breaks = append(m.breaks, offset+i)
+		}
+		prev = c
+	}
+}
+
+// wrapLine splits the first line that fits in the configured line width off line, at the last of
+// breaks that it can be split at, or the first if none fit. If line continues a line that was
+// wrapped, the line split off it must not start a block either. It returns the line, and the rest of
+// line with the breaks in it, or nil if line fits or can't be split.
+func (m *markdownWriter) wrapLine(line []byte, breaks []int, continued bool) (wrapped, rest []byte, restBreaks []int) {
+	width := int(m.config.LineWidth) - displayWidth(m.prefix())
+	if m.lineWidth(line) <= width {
+		return nil, line, nil
+	}
+	split := -1
+	for _, b := range breaks {
+		head := bytes.TrimRight(line[:b], " ")
+		tail := bytes.TrimLeft(line[b:], " ")
+		// Leave out breaks that would end the line with a hard line break, or start a block on
+		// the next line. The rest of line was checked when it was split off, but a setext underline
+		// or thematic break only takes the whole line, so the line split off it is checked again.
+		if len(bytes.TrimSpace(head)) == 0 || head[len(head)-1] == '\\' || len(bytes.TrimSpace(tail)) == 0 ||
+			tail[0] == '<' || !bytes.Equal(escapeLineStart(tail), tail) ||
+			continued && !bytes.Equal(escapeLineStart(head), head) {
+			continue
+		}
+		if split >= 0 && m.lineWidth(head) > width {
+			break
+		}
+		split = b
+	}
+	if split < 0 {
+		return nil, line, nil
+	}
+	wrapped = bytes.TrimRight(line[:split], " ")
+	rest = bytes.TrimLeft(line[split:], " ")
+	skipped := len(line) - len(rest)
+	for _, b := range breaks {
+		if b > skipped {
+			restBreaks = append(restBreaks, b-skipped)
+		}
+	}
+	return bytes.Clone(wrapped), rest, restBreaks
+}
+
+// prefix returns the prefix of the current line.
+func (m *markdownWriter) prefix() []byte {
+	var prefix []byte
+	for _, p := range m.prefixes {
+		if p.startLine <= m.line && (p.endLine == -1 || m.line <= p.endLine) {
+			prefix = append(prefix, p.bytes...)
+		}
+	}
+	return prefix
+}
+
+// writeLine writes line, which ends with a line delimiter, to the output with the prefix of the
+// current line.
+func (m *markdownWriter) writeLine(line []byte) {
+	prefixedLine := bytes.NewBuffer(m.prefix())
+	prefixedLine.Write(line)
+	// trim whitespace off the end of the line, unless it's part of verbatim content
+	if content := bytes.TrimSuffix(line, []byte{lineDelim}); !m.verbatim || len(content) == 0 {
+		trimmedSlice := bytes.TrimRightFunc(prefixedLine.Bytes(), unicode.IsSpace)
+		prefixedLine.Truncate(len(trimmedSlice))
+	} else {
+		prefixedLine.Truncate(prefixedLine.Len() - (len(line) - len(content)))
+	}
+	prefixedLine.WriteByte(lineDelim)
+
+	written, err := m.output.Write(prefixedLine.Bytes())
+	m.written += written
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.line += 1
+}
+
 // Err returns the last write error, or nil.
 func (m *markdownWriter) Err() error {
 	return m.err
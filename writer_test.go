@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
 )
 
 func TestWrite(t *testing.T) {
@@ -63,6 +64,21 @@ func TestEndLine(t *testing.T) {
 }
 
 // TestWriterOutputs tests that the writer produces expected output in various scenarios.
+// TestVerbatim tests that the writer keeps trailing whitespace of verbatim lines, but not of prefixes
+// on empty lines.
+func TestVerbatim(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	writer := newMarkdownWriter(buf, NewConfig())
+
+	writer.PushPrefix([]byte("    "))
+	writer.SetVerbatim(true)
+	writer.WriteBytes([]byte("a \t\n\n  \n"))
+	writer.SetVerbatim(false)
+	writer.WriteBytes([]byte("b \n"))
+	assert.Equal("    a \t\n\n      \n    b\n", buf.String())
+}
+
 func TestWriterOutputs(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -202,3 +218,77 @@ func TestWriteError(t *testing.T) {
 	assert.Equal(len(data), n, "Writes should succeed after Reset")
 	assert.Equal(len(data), writer.WriteLine(data), "Writes should succeed after Reset")
 }
+
+// TestWrap tests that paragraphs are reflowed to the line width, within the prefixes of their
+// containers and without breaking unbreakable spans or starting new blocks.
+func TestWrap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"Paragraph",
+			"The quick brown fox\njumps over the lazy dog and keeps running.\n",
+			"The quick brown fox jumps\nover the lazy dog and\nkeeps running.\n",
+		},
+		{
+			"Blockquote",
+			"> The quick brown fox jumps over the lazy dog.\n",
+			"> The quick brown fox\n> jumps over the lazy dog.\n",
+		},
+		{
+			"List",
+			"- The quick brown fox jumps over the lazy dog.\n  - Nested items are indented further.\n",
+			"- The quick brown fox\n  jumps over the lazy dog.\n  - Nested items are\n    indented further.\n",
+		},
+		{
+			"Unbreakable spans",
+			"See `go test ./...` and [the guide](https://example.com/guide) for more.\n",
+			"See `go test ./...` and\n[the guide](https://example.com/guide)\nfor more.\n",
+		},
+		{
+			"Long word",
+			"A https://example.com/a/very/long/path/that/does/not/fit here.\n",
+			"A\nhttps://example.com/a/very/long/path/that/does/not/fit\nhere.\n",
+		},
+		{
+			"Bullet list start",
+			"The quick brown fox jumps - over the lazy dog.\n",
+			"The quick brown fox\njumps - over the lazy dog.\n",
+		},
+		{
+			"Ordered list start",
+			"The quick brown fox jumps 1. over 2) the lazy dog.\n",
+			"The quick brown fox\njumps 1. over 2) the lazy\ndog.\n",
+		},
+		{
+			"HTML block start",
+			"The quick brown fox ju <div>mps</div> over.\n",
+			"The quick brown fox\nju <div>mps</div> over.\n",
+		},
+		{
+			"Hard line break",
+			"Short line\\\nand the quick brown fox jumps over the lazy dog.\n",
+			"Short line\\\nand the quick brown fox\njumps over the lazy dog.\n",
+		},
+		{
+			"CJK",
+			"敏捷的棕色狐狸跳过了懒狗，然后继续奔跑。\n",
+			"敏捷的棕色狐狸跳过了懒狗，\n然后继续奔跑。\n",
+		},
+		{
+			"Heading",
+			"# The quick brown fox jumps over the lazy dog\n",
+			"# The quick brown fox jumps over the lazy dog\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			md := goldmark.New(goldmark.WithRenderer(NewRenderer(WithLineWidth(26))))
+			require.NoError(t, md.Convert([]byte(tc.source), &buf))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}